@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"voicepaper/config"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/similarity"
+)
+
+// 离线计算文章相似度，结果写入 vp_article_similarities
+// 用法: go run ./cmd/build_similarity [-top 10] [-min 0.05] [-dry-run]
+func main() {
+	top := flag.Int("top", 10, "每篇文章保留的相关文章数")
+	minScore := flag.Float64("min", 0.05, "最低相似度，低于该值的不保存")
+	dryRun := flag.Bool("dry-run", false, "只计算并打印结果，不写入数据库")
+	flag.Parse()
+
+	cfg := config.GetConfig()
+	repository.InitDB(cfg)
+	db := repository.DB
+
+	start := time.Now()
+
+	// 1. 已发布的文章
+	var articles []model.Article
+	today := time.Now().Format("2006-01-02")
+	if err := db.Select("id", "title").
+		Where("audio_url != '' AND publish_date <= ?", today).
+		Find(&articles).Error; err != nil {
+		log.Fatalf("❌ 查询文章失败: %v", err)
+	}
+	fmt.Printf("📚 共 %d 篇已发布文章\n", len(articles))
+	if len(articles) < 2 {
+		fmt.Println("⏭️  文章数不足，无需计算")
+		return
+	}
+
+	ids := make([]uint, 0, len(articles))
+	docs := make(map[uint]*similarity.Document, len(articles))
+	titles := make(map[uint]string, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+		docs[a.ID] = &similarity.Document{ID: a.ID, Text: a.Title}
+		titles[a.ID] = a.Title
+	}
+
+	// 2. 句子正文
+	var sentences []model.Sentence
+	if err := db.Select("article_id", "text").Where("article_id IN ?", ids).Find(&sentences).Error; err != nil {
+		log.Fatalf("❌ 查询句子失败: %v", err)
+	}
+	texts := make(map[uint][]string, len(articles))
+	for _, s := range sentences {
+		texts[s.ArticleID] = append(texts[s.ArticleID], s.Text)
+	}
+	for id, parts := range texts {
+		if d, ok := docs[id]; ok {
+			d.Text = d.Text + "\n" + strings.Join(parts, "\n")
+		}
+	}
+
+	// 3. 重点单词
+	var words []model.Word
+	if err := db.Select("article_id", "text").Where("article_id IN ?", ids).Find(&words).Error; err != nil {
+		log.Fatalf("❌ 查询单词失败: %v", err)
+	}
+	for _, w := range words {
+		if d, ok := docs[w.ArticleID]; ok {
+			d.KeyWords = append(d.KeyWords, w.Text)
+		}
+	}
+
+	list := make([]similarity.Document, 0, len(docs))
+	for _, id := range ids {
+		list = append(list, *docs[id])
+	}
+
+	// 4. 计算
+	neighbors := similarity.TopK(list, *top, *minScore)
+
+	var rows []model.ArticleSimilarity
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		for i, n := range neighbors[id] {
+			terms := strings.Join(n.SharedTerms, ",")
+			if len(terms) > 255 {
+				terms = terms[:255]
+			}
+			rows = append(rows, model.ArticleSimilarity{
+				ArticleID:        id,
+				RelatedArticleID: n.ID,
+				Score:            n.Score,
+				Position:         i + 1,
+				SharedTerms:      terms,
+			})
+		}
+	}
+	fmt.Printf("🧮 计算完成，共 %d 条相似度记录，耗时 %v\n", len(rows), time.Since(start))
+
+	if *dryRun {
+		for _, id := range ids {
+			if len(neighbors[id]) == 0 {
+				continue
+			}
+			fmt.Printf("\n📄 [%d] %s\n", id, titles[id])
+			for i, n := range neighbors[id] {
+				fmt.Printf("   %d. [%d] %s  score=%.4f  (%s)\n", i+1, n.ID, titles[n.ID], n.Score, strings.Join(n.SharedTerms, ", "))
+			}
+		}
+		fmt.Println("\n⏭️  dry-run 模式，未写入数据库")
+		return
+	}
+
+	if err := repository.NewSimilarityRepository(db).ReplaceAll(rows); err != nil {
+		log.Fatalf("❌ 写入相似度失败: %v", err)
+	}
+	fmt.Println("✅ 相似度已写入 vp_article_similarities")
+}
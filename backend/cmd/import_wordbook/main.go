@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"voicepaper/config"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+)
+
+// 导入单词书内容（vp_wordbook 词条 + vp_wordbook_books 关联），并更新单词书的单词数
+// 用法: go run ./cmd/import_wordbook -book cet4 -file cet4.json [-format json|csv] [-name 四级词汇]
+//
+//	[-mode fill|overwrite] [-repair-links] [-dry-run] [-json] [-show 50]
+func main() {
+	file := flag.String("file", "", "导入文件（JSON 数组或带表头的 CSV，字段与 vp_wordbook 相同）")
+	book := flag.String("book", "", "单词书类型，如 cet4")
+	format := flag.String("format", "", "文件格式 json|csv，为空时按扩展名判断")
+	name := flag.String("name", "", "单词书不存在时创建的名称")
+	category := flag.String("category", "", "新建单词书的分类")
+	description := flag.String("description", "", "新建单词书的简介")
+	mode := flag.String("mode", service.WordbookImportFill, "已有词条的处理：fill 只补充空字段，overwrite 覆盖为导入的非空值")
+	repairLinks := flag.Bool("repair-links", false, "删除单词书中指向不存在词条的关联和重复关联")
+	dryRun := flag.Bool("dry-run", false, "只校验并打印差异报告，不写入数据库")
+	asJSON := flag.Bool("json", false, "以 JSON 输出完整报告")
+	show := flag.Int("show", 50, "每类明细最多打印的条数，0 表示全部")
+	flag.Parse()
+
+	if *file == "" || *book == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("❌ 读取文件失败: %v", err)
+	}
+	entries, err := service.ParseWordbookImport(data, *format)
+	if err != nil {
+		log.Fatalf("❌ 解析文件失败: %v", err)
+	}
+
+	cfg := config.GetConfig()
+	repository.InitDB(cfg)
+
+	report, err := service.NewWordbookImportService(repository.DB).Import(entries, service.WordbookImportOptions{
+		Book:        *book,
+		Name:        *name,
+		Category:    *category,
+		Description: *description,
+		Mode:        *mode,
+		RepairLinks: *repairLinks,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		log.Fatalf("❌ 导入失败: %v", err)
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printReport(report, *show)
+}
+
+func printReport(r *service.WordbookImportReport, show int) {
+	fmt.Printf("📚 单词书 %s：文件共 %d 条\n", r.Book, r.Total)
+	if r.BookCreated {
+		fmt.Println("🆕 单词书信息不存在，将新建")
+	}
+
+	if len(r.Invalid) > 0 {
+		fmt.Printf("\n❌ 校验失败 %d 条（已跳过）\n", len(r.Invalid))
+		for i, issue := range r.Invalid {
+			if show > 0 && i >= show {
+				fmt.Printf("   ... 另有 %d 条\n", len(r.Invalid)-show)
+				break
+			}
+			fmt.Printf("   第 %d 条 %q: %s\n", issue.Line, issue.Word, issue.Reason)
+		}
+	}
+	if len(r.DuplicateInFile) > 0 {
+		fmt.Printf("\n⚠️  文件内重复 %d 条\n", len(r.DuplicateInFile))
+		for i, issue := range r.DuplicateInFile {
+			if show > 0 && i >= show {
+				fmt.Printf("   ... 另有 %d 条\n", len(r.DuplicateInFile)-show)
+				break
+			}
+			fmt.Printf("   第 %d 条 %q: %s\n", issue.Line, issue.Word, issue.Reason)
+		}
+	}
+	if len(r.Conflicts) > 0 {
+		fmt.Printf("\n⚠️  词典中有多条词条的单词 %d 个（关联 ID 最小的一条）\n", len(r.Conflicts))
+		for i, c := range r.Conflicts {
+			if show > 0 && i >= show {
+				fmt.Printf("   ... 另有 %d 个\n", len(r.Conflicts)-show)
+				break
+			}
+			fmt.Printf("   %s: %v\n", c.Word, c.IDs)
+		}
+	}
+
+	fmt.Printf("\n+ 新建词条 %d 个\n", len(r.Created))
+	for i, word := range r.Created {
+		if show > 0 && i >= show {
+			fmt.Printf("   ... 另有 %d 个\n", len(r.Created)-show)
+			break
+		}
+		fmt.Printf("   + %s\n", word)
+	}
+	fmt.Printf("~ 更新已有词条 %d 个\n", len(r.Updated))
+	for i, d := range r.Updated {
+		if show > 0 && i >= show {
+			fmt.Printf("   ... 另有 %d 个\n", len(r.Updated)-show)
+			break
+		}
+		fmt.Printf("   ~ [%d] %s: %s\n", d.ID, d.Word, strings.Join(d.Fields, ", "))
+	}
+	fmt.Printf("= 已有且无变化 %d 个\n", r.Unchanged)
+
+	fmt.Printf("\n🔗 新关联 %d 个，已在单词书中 %d 个\n", r.Linked, r.AlreadyLinked)
+	if r.LinkIssues != nil && (len(r.LinkIssues.Orphaned) > 0 || len(r.LinkIssues.Duplicated) > 0) {
+		fmt.Printf("⚠️  单词书现有关联异常：悬空 %d 条，重复 %d 条", len(r.LinkIssues.Orphaned), len(r.LinkIssues.Duplicated))
+		if r.LinksRepaired > 0 {
+			fmt.Printf("，已删除 %d 条\n", r.LinksRepaired)
+		} else {
+			fmt.Println("（使用 -repair-links 删除）")
+		}
+	}
+	fmt.Printf("📊 单词数 %d -> %d\n", r.WordCountBefore, r.WordCountAfter)
+
+	if r.DryRun {
+		fmt.Println("\n⏭️  dry-run 模式，未写入数据库")
+		return
+	}
+	fmt.Println("\n✅ 导入完成")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+	"voicepaper/config"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+	"voicepaper/pkg/srs"
+)
+
+// 根据复习历史离线拟合每个用户的 FSRS 参数，结果写入 vp_user_srs_params
+// 用法: go run ./cmd/optimize_srs [-user 0] [-min-reviews 300] [-iterations 200] [-dry-run]
+func main() {
+	userID := flag.Uint("user", 0, "只拟合指定用户，0 表示所有复习记录足够的用户")
+	minReviews := flag.Int("min-reviews", 300, "最少可用复习记录数")
+	iterations := flag.Int("iterations", 200, "迭代次数")
+	dryRun := flag.Bool("dry-run", false, "只计算并打印结果，不写入数据库")
+	flag.Parse()
+
+	cfg := config.GetConfig()
+	repository.InitDB(cfg)
+	db := repository.DB
+
+	opts := srs.DefaultOptimizeOptions()
+	opts.MinReviews = *minReviews
+	opts.Iterations = *iterations
+
+	var userIDs []uint
+	if *userID > 0 {
+		userIDs = []uint{*userID}
+	} else {
+		ids, err := repository.NewSRSRepository(db).GetUserIDsWithReviews(*minReviews)
+		if err != nil {
+			log.Fatalf("❌ 查询用户失败: %v", err)
+		}
+		userIDs = ids
+	}
+	fmt.Printf("👥 共 %d 个用户待拟合\n", len(userIDs))
+
+	srsService := service.NewSRSService(db)
+	var applied, skipped int
+	for _, id := range userIDs {
+		start := time.Now()
+		outcome, err := srsService.Optimize(context.Background(), id, opts, *dryRun)
+		if err != nil {
+			if errors.Is(err, srs.ErrNotEnoughReviews) {
+				fmt.Printf("⏭️  用户 %d: 可用复习记录不足 %d 条\n", id, *minReviews)
+			} else {
+				fmt.Printf("❌ 用户 %d: %v\n", id, err)
+			}
+			skipped++
+			continue
+		}
+
+		r := outcome.Result
+		fmt.Printf("\n👤 用户 %d  样本 %d  耗时 %v\n", id, r.After.Samples, time.Since(start).Round(time.Millisecond))
+		fmt.Printf("   log_loss %.4f -> %.4f   rmse %.4f -> %.4f\n", r.Before.LogLoss, r.After.LogLoss, r.Before.RMSE, r.After.RMSE)
+		fmt.Printf("   保持率 实际 %.2f%%  预测 %.2f%% -> %.2f%%\n", r.After.ActualRetention*100, r.Before.PredictedRetention*100, r.After.PredictedRetention*100)
+		fmt.Printf("   参数 %v\n", r.Weights)
+		if outcome.Applied {
+			applied++
+			if outcome.Migration != nil {
+				fmt.Printf("   ✅ 已保存，重新计算 %d 张卡片\n", outcome.Migration.MigratedCards)
+			} else {
+				fmt.Println("   ✅ 已保存")
+			}
+		} else if !*dryRun {
+			fmt.Println("   ⚠️ 拟合未改善，保留原参数")
+		}
+	}
+
+	if *dryRun {
+		fmt.Println("\n⏭️  dry-run 模式，未写入数据库")
+		return
+	}
+	fmt.Printf("\n✅ 拟合完成: 保存 %d 个，跳过 %d 个\n", applied, skipped)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"voicepaper/config"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+	"voicepaper/internal/storage"
+)
+
+// 预先生成整本单词书的单词（和例句）发音，写入 vp_pronunciations 和存储
+// 用法: go run ./cmd/prewarm_pronunciations -book cet4,cet6 [-examples] [-concurrency 2] [-voice xxx] [-limit 0]
+// -book all 表示所有系统单词书
+func main() {
+	books := flag.String("book", "", "单词书类型，多个用逗号分隔，all 表示所有系统单词书")
+	examples := flag.Bool("examples", false, "同时生成例句发音")
+	concurrency := flag.Int("concurrency", 2, "同时调用 TTS 的数量")
+	voice := flag.String("voice", "", "音色，为空时使用配置中的音色")
+	limit := flag.Int("limit", 0, "最多生成的条数，0 表示不限制")
+	flag.Parse()
+
+	if *books == "" {
+		flag.Usage()
+		return
+	}
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
+	cfg := config.GetConfig()
+	repository.InitDB(cfg)
+	db := repository.DB
+
+	var st storage.Storage
+	if cfg.Storage.Type == "oss" {
+		var err error
+		if st, err = storage.NewOSSStorage(cfg); err != nil {
+			log.Fatalf("❌ 初始化 OSS 存储失败: %v", err)
+		}
+	} else {
+		st = storage.NewLocalStorage(cfg)
+	}
+	pronunciations := service.NewPronunciationService(db, st).WithVoice(*voice)
+	wordbookRepo := repository.NewWordbookRepository(db)
+
+	var types []string
+	if *books == "all" {
+		list, err := wordbookRepo.GetWordbookList()
+		if err != nil {
+			log.Fatalf("❌ 查询单词书失败: %v", err)
+		}
+		for _, b := range list {
+			types = append(types, b.Type)
+		}
+	} else {
+		for _, t := range strings.Split(*books, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	// 1. 收集单词和例句（跨单词书去重）
+	type job struct {
+		text string
+		kind string
+	}
+	var jobs []job
+	seen := make(map[string]bool)
+	add := func(text, kind string) {
+		key := kind + ":" + strings.Join(strings.Fields(text), " ")
+		if kind == model.PronunciationWord {
+			key = strings.ToLower(key)
+		}
+		if strings.TrimSpace(text) == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		jobs = append(jobs, job{text: text, kind: kind})
+	}
+	for _, t := range types {
+		for offset := 0; ; offset += 500 {
+			words, _, err := wordbookRepo.GetWordsByType(t, offset, 500)
+			if err != nil {
+				log.Fatalf("❌ 查询单词书 %s 失败: %v", t, err)
+			}
+			for _, w := range words {
+				add(w.Word, model.PronunciationWord)
+				if *examples {
+					add(w.Example, model.PronunciationSentence)
+				}
+			}
+			if len(words) < 500 {
+				break
+			}
+		}
+	}
+	if *limit > 0 && len(jobs) > *limit {
+		jobs = jobs[:*limit]
+	}
+	fmt.Printf("📚 单词书 %s：共 %d 条待检查\n", strings.Join(types, ","), len(jobs))
+
+	// 2. 并发生成（已生成的直接跳过）
+	start := time.Now()
+	var ready, generated, pending, failed, done int64
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				if _, ok := pronunciations.ReadyPath(j.text, j.kind); ok {
+					atomic.AddInt64(&ready, 1)
+					atomic.AddInt64(&done, 1)
+					continue
+				}
+				p, err := pronunciations.Generate(j.text, j.kind)
+				switch {
+				case err != nil:
+					atomic.AddInt64(&failed, 1)
+					log.Printf("❌ 生成发音失败 (%s): %v", j.text, err)
+				case p.Status == model.PronunciationReady:
+					atomic.AddInt64(&generated, 1)
+				case p.Status == model.PronunciationPending:
+					atomic.AddInt64(&pending, 1)
+				default:
+					atomic.AddInt64(&failed, 1)
+				}
+				if n := atomic.AddInt64(&done, 1); n%100 == 0 {
+					fmt.Printf("   ... %d/%d\n", n, len(jobs))
+				}
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	fmt.Printf("\n✅ 完成，耗时 %v\n", time.Since(start).Round(time.Second))
+	fmt.Printf("   已存在 %d，新生成 %d，其他进程生成中 %d，失败 %d\n", ready, generated, pending, failed)
+}
@@ -6,6 +6,7 @@ import (
 	"voicepaper/config"
 	v1 "voicepaper/internal/api/v1"
 	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -36,7 +37,10 @@ func main() {
 	// 7. Register Routes
 	v1.RegisterRoutes(r)
 
-	// 8. Start Server
+	// 8. Start background notification jobs
+	service.StartNotificationWorker(repository.DB, service.DefaultCalendar(), service.NotificationSweepInterval)
+
+	// 9. Start Server
 	serverAddr := cfg.Service.Port
 	if serverAddr == "" {
 		serverAddr = "8080"
@@ -103,6 +103,7 @@ CREATE TABLE IF NOT EXISTS `vp_users` (
   `nickname` varchar(100) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '昵称',
   `avatar` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '头像URL',
   `bio` varchar(500) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '个人简介',
+  `timezone` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '用户时区（IANA 名称），为空时使用服务器时区',
   `phone` varchar(20) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '手机号（加密存储）',
   `phone_verified` tinyint(1) NOT NULL DEFAULT '0' COMMENT '手机号是否已验证',
   `phone_verified_at` datetime(3) DEFAULT NULL COMMENT '手机号验证时间',
@@ -256,6 +257,10 @@ CREATE TABLE IF NOT EXISTS `vp_user_points` (
   `total_check_ins` INT NOT NULL DEFAULT 0 COMMENT '累计签到天数',
   `continuous_check_ins` INT NOT NULL DEFAULT 0 COMMENT '当前连续签到天数',
   `max_continuous_check_ins` INT NOT NULL DEFAULT 0 COMMENT '最大连续签到天数',
+  `streak_freezes` INT NOT NULL DEFAULT 0 COMMENT '拥有的连续学习冻结卡数量',
+  `total_reviews` INT NOT NULL DEFAULT 0 COMMENT '累计复习次数',
+  `perfect_streak` INT NOT NULL DEFAULT 0 COMMENT '当前连续默写全对次数',
+  `max_perfect_streak` INT NOT NULL DEFAULT 0 COMMENT '最大连续默写全对次数',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` DATETIME DEFAULT NULL COMMENT '软删除时间',
@@ -318,14 +323,24 @@ CREATE TABLE IF NOT EXISTS `vp_title_configs` (
   `condition_type` VARCHAR(50) NOT NULL COMMENT '条件类型',
   `condition_value` INT NOT NULL DEFAULT 0 COMMENT '条件数值',
   `condition_description` VARCHAR(255) DEFAULT NULL COMMENT '条件说明',
+  `condition_rule` TEXT NULL COMMENT '组合条件（JSON），condition_type 为 compound 时使用',
   `rarity` VARCHAR(20) DEFAULT 'common' COMMENT '稀有度',
   `sort_order` INT NOT NULL DEFAULT 0 COMMENT '排序',
   `is_active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `is_hidden` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '隐藏称号：获得前不显示名称、描述和条件',
+  `family_key` VARCHAR(50) DEFAULT NULL COMMENT '称号系列',
+  `tier` VARCHAR(20) DEFAULT NULL COMMENT '等级：bronze/silver/gold',
+  `start_at` DATETIME(3) DEFAULT NULL COMMENT '限时称号开始时间',
+  `end_at` DATETIME(3) DEFAULT NULL COMMENT '限时称号结束时间',
+  `reward_points` INT NOT NULL DEFAULT 0 COMMENT '解锁奖励积分',
+  `reward_makeup_cards` INT NOT NULL DEFAULT 0 COMMENT '解锁奖励补签卡',
+  `reward_streak_freezes` INT NOT NULL DEFAULT 0 COMMENT '解锁奖励冻结卡',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` DATETIME DEFAULT NULL COMMENT '软删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_title_key` (`title_key`),
+  KEY `idx_vp_title_configs_family_key` (`family_key`),
   KEY `idx_category` (`category`),
   KEY `idx_is_active` (`is_active`),
   KEY `idx_sort_order` (`sort_order`),
@@ -351,4 +366,466 @@ CREATE TABLE IF NOT EXISTS `vp_user_titles` (
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户称号表';
 
+-- Series
+CREATE TABLE IF NOT EXISTS `vp_series` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `title` VARCHAR(255) NOT NULL COMMENT '系列标题',
+  `description` TEXT COMMENT '系列简介',
+  `cover_url` VARCHAR(512) DEFAULT NULL COMMENT '封面图',
+  `category_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '所属分类，关联vp_categories.id',
+  `is_sequential` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否必须按顺序学习',
+  `completion_bonus` INT NOT NULL DEFAULT 0 COMMENT '完成奖励积分（0表示使用默认值）',
+  `sort_order` INT NOT NULL DEFAULT 0 COMMENT '排序',
+  `is_active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '软删除时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_category_id` (`category_id`),
+  KEY `idx_sort_order` (`sort_order`),
+  KEY `idx_is_active` (`is_active`),
+  KEY `idx_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系列/合集表';
+
+-- Series Episodes
+CREATE TABLE IF NOT EXISTS `vp_series_episodes` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `series_id` BIGINT UNSIGNED NOT NULL COMMENT '系列ID，关联vp_series.id',
+  `article_id` BIGINT UNSIGNED NOT NULL COMMENT '文章ID，关联vp_articles.id',
+  `episode_no` INT NOT NULL COMMENT '集数（从1开始）',
+  `title` VARCHAR(255) DEFAULT NULL COMMENT '剧集标题（为空时使用文章标题）',
+  `prerequisite_episode_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '前置剧集ID，关联vp_series_episodes.id',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_series_article` (`series_id`, `article_id`),
+  KEY `idx_series_episode` (`series_id`, `episode_no`),
+  KEY `idx_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系列剧集表';
+
+-- Series Completions
+CREATE TABLE IF NOT EXISTS `vp_series_completions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `series_id` BIGINT UNSIGNED NOT NULL COMMENT '系列ID，关联vp_series.id',
+  `completed_at` DATETIME NOT NULL COMMENT '完成时间',
+  `bonus_points` INT NOT NULL DEFAULT 0 COMMENT '发放的奖励积分',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_series` (`user_id`, `series_id`),
+  KEY `idx_series_id` (`series_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户完成系列记录表';
+
+-- Tags
+CREATE TABLE IF NOT EXISTS `vp_tags` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `name` VARCHAR(100) NOT NULL COMMENT '标签名称',
+  `slug` VARCHAR(100) NOT NULL COMMENT '标签标识（用于URL筛选）',
+  `type` VARCHAR(20) NOT NULL DEFAULT 'topic' COMMENT '标签类型：topic(话题), source(来源刊物)',
+  `description` VARCHAR(500) DEFAULT NULL COMMENT '标签描述',
+  `icon` VARCHAR(255) DEFAULT NULL COMMENT '标签图标',
+  `sort_order` INT NOT NULL DEFAULT 0 COMMENT '排序',
+  `is_active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_slug` (`slug`),
+  KEY `idx_type` (`type`),
+  KEY `idx_sort_order` (`sort_order`),
+  KEY `idx_is_active` (`is_active`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文章标签表';
+
+-- Article Tags
+CREATE TABLE IF NOT EXISTS `vp_article_tags` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `article_id` BIGINT UNSIGNED NOT NULL COMMENT '文章ID，关联vp_articles.id',
+  `tag_id` BIGINT UNSIGNED NOT NULL COMMENT '标签ID，关联vp_tags.id',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_tag` (`article_id`, `tag_id`),
+  KEY `idx_tag_id` (`tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文章标签关联表';
+
+-- User Tag Subscriptions
+CREATE TABLE IF NOT EXISTS `vp_user_tag_subscriptions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `tag_id` BIGINT UNSIGNED NOT NULL COMMENT '标签ID，关联vp_tags.id',
+  `notify_enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '有新文章时是否通知',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_tag` (`user_id`, `tag_id`),
+  KEY `idx_tag_id` (`tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户话题订阅表';
+
+-- Notifications
+CREATE TABLE IF NOT EXISTS `vp_notifications` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `type` VARCHAR(30) NOT NULL COMMENT '通知类型：article_published(订阅话题新文章), goal_reminder(学习目标提醒)',
+  `title` VARCHAR(255) NOT NULL COMMENT '标题',
+  `content` VARCHAR(500) DEFAULT NULL COMMENT '内容',
+  `ref_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '关联对象ID（文章ID、目标ID）',
+  `dedupe_key` VARCHAR(100) NOT NULL COMMENT '去重标识，同一用户相同标识只通知一次',
+  `read_at` DATETIME DEFAULT NULL COMMENT '已读时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_dedupe` (`user_id`, `dedupe_key`),
+  KEY `idx_user_read` (`user_id`, `read_at`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='站内通知';
+
+-- Article Similarities
+CREATE TABLE IF NOT EXISTS `vp_article_similarities` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `article_id` BIGINT UNSIGNED NOT NULL COMMENT '文章ID，关联vp_articles.id',
+  `related_article_id` BIGINT UNSIGNED NOT NULL COMMENT '相关文章ID，关联vp_articles.id',
+  `score` DOUBLE NOT NULL DEFAULT 0 COMMENT 'TF-IDF 余弦相似度（0-1）',
+  `position` INT NOT NULL DEFAULT 0 COMMENT '在相关列表中的排名（从1开始）',
+  `shared_terms` VARCHAR(255) DEFAULT NULL COMMENT '贡献最大的共同词（逗号分隔）',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_related` (`article_id`, `related_article_id`),
+  KEY `idx_article_position` (`article_id`, `position`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文章相似度表（由 cmd/build_similarity 离线生成）';
+
+-- Article Revisions
+CREATE TABLE IF NOT EXISTS `vp_article_revisions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `article_id` BIGINT UNSIGNED NOT NULL COMMENT '文章ID，关联vp_articles.id',
+  `revision_no` INT NOT NULL COMMENT '版本号（从1开始）',
+  `base_revision_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '基于哪个修订修改',
+  `title` VARCHAR(255) NOT NULL COMMENT '标题快照',
+  `markdown` LONGTEXT COMMENT 'Markdown 正文快照',
+  `sentences` LONGTEXT COMMENT '句子快照（JSON数组）',
+  `content_hash` VARCHAR(64) DEFAULT NULL COMMENT '内容哈希',
+  `note` VARCHAR(500) DEFAULT NULL COMMENT '修改说明',
+  `editor_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '编辑者用户ID',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'draft' COMMENT '状态：draft(草稿), published(已发布)',
+  `published_at` DATETIME DEFAULT NULL COMMENT '发布时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_revision` (`article_id`, `revision_no`),
+  KEY `idx_content_hash` (`content_hash`),
+  KEY `idx_editor_id` (`editor_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文章修订表（内容只增不改）';
+
+-- Sentence Remaps
+CREATE TABLE IF NOT EXISTS `vp_sentence_remaps` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `revision_id` BIGINT UNSIGNED NOT NULL COMMENT '修订ID，关联vp_article_revisions.id',
+  `article_id` BIGINT UNSIGNED NOT NULL COMMENT '文章ID，关联vp_articles.id',
+  `old_sentence_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '旧句子ID',
+  `new_sentence_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '新句子ID',
+  `action` VARCHAR(20) NOT NULL COMMENT '动作：keep/modify/replace/delete/insert',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_revision_id` (`revision_id`),
+  KEY `idx_article_id` (`article_id`),
+  KEY `idx_old_sentence_id` (`old_sentence_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='发布修订时的句子ID重映射记录';
+
+-- User SRS Params
+CREATE TABLE IF NOT EXISTS `vp_user_srs_params` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `algorithm` VARCHAR(20) NOT NULL DEFAULT 'sm2' COMMENT '复习算法：sm2/fsrs',
+  `weights` TEXT COMMENT 'FSRS 参数（JSON数组），为空使用默认参数',
+  `request_retention` DOUBLE NOT NULL DEFAULT 0.9 COMMENT '期望记忆保持率',
+  `maximum_interval` INT NOT NULL DEFAULT 36500 COMMENT '最大间隔（天）',
+  `migrated_at` DATETIME DEFAULT NULL COMMENT '最近一次按复习历史重放迁移的时间',
+  `new_cards_per_day` INT NOT NULL DEFAULT 20 COMMENT '每日新卡片上限',
+  `reviews_per_day` INT NOT NULL DEFAULT 200 COMMENT '每日复习上限',
+  `learning_steps` VARCHAR(100) NOT NULL DEFAULT '1m,10m,1d' COMMENT '学习步骤',
+  `relearning_steps` VARCHAR(100) NOT NULL DEFAULT '1m,10m,1d' COMMENT '重学步骤',
+  `optimized_at` DATETIME DEFAULT NULL COMMENT '最近一次参数拟合时间',
+  `optimize_report` TEXT COMMENT '最近一次拟合报告（JSON，预测保持率与实际对比）',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户复习算法设置';
+
+-- Review Sessions
+CREATE TABLE IF NOT EXISTS `vp_review_sessions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `session_date` VARCHAR(10) NOT NULL COMMENT '会话日期（YYYY-MM-DD）',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT '状态：active(进行中), finished(已完成)',
+  `queue` MEDIUMTEXT COMMENT '今日队列（JSON数组）',
+  `buried` TEXT COMMENT '今日埋藏的兄弟卡片ID（JSON数组）',
+  `new_limit` INT NOT NULL DEFAULT 0 COMMENT '新卡片上限',
+  `review_limit` INT NOT NULL DEFAULT 0 COMMENT '复习上限',
+  `new_done` INT NOT NULL DEFAULT 0 COMMENT '已学新卡片数',
+  `review_done` INT NOT NULL DEFAULT 0 COMMENT '已复习卡片数',
+  `learning_done` INT NOT NULL DEFAULT 0 COMMENT '学习/重学步骤的复习次数',
+  `current_vocabulary_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '当前展示的卡片',
+  `finished_at` DATETIME DEFAULT NULL COMMENT '完成时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_session_date` (`user_id`, `session_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='每日复习会话';
+
+-- Review Exercises
+CREATE TABLE IF NOT EXISTS `vp_review_exercises` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `vocabulary_id` BIGINT UNSIGNED NOT NULL COMMENT '生词ID，关联vp_vocabulary.id',
+  `type` VARCHAR(20) NOT NULL COMMENT '练习类型：choice/spell/cloze/listening',
+  `prompt` TEXT COMMENT '题面（JSON）',
+  `answer` VARCHAR(500) NOT NULL COMMENT '正确答案（选择题为选项下标）',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `answered_at` DATETIME DEFAULT NULL COMMENT '作答时间',
+  `user_answer` TEXT COMMENT '用户答案',
+  `is_correct` TINYINT(1) DEFAULT NULL COMMENT '是否正确',
+  `quality` INT DEFAULT NULL COMMENT '服务端推导的SM-2评分',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_vocabulary_id` (`vocabulary_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='服务端生成的复习练习';
+
+-- Mistakes
+CREATE TABLE IF NOT EXISTS `vp_mistakes` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `item_type` VARCHAR(20) NOT NULL COMMENT '题目类型：word/sentence/vocabulary',
+  `item_id` BIGINT UNSIGNED NOT NULL COMMENT '题目ID（vp_words/vp_sentences/vp_vocabulary）',
+  `article_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '文章ID',
+  `content` VARCHAR(500) NOT NULL COMMENT '正确答案',
+  `error_count` INT NOT NULL DEFAULT 0 COMMENT '出错次数',
+  `categories` TEXT COMMENT '错误类型累计次数（JSON）',
+  `primary_category` VARCHAR(30) DEFAULT NULL COMMENT '出现次数最多的错误类型',
+  `last_answer` TEXT COMMENT '最近一次的错误答案',
+  `last_diff` TEXT COMMENT '最近一次的逐词差异（JSON）',
+  `last_error_at` DATETIME NOT NULL COMMENT '最近一次出错时间',
+  `resolved_at` DATETIME DEFAULT NULL COMMENT '答对时间',
+  `vocabulary_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '推送后对应的生词ID',
+  `pushed_at` DATETIME DEFAULT NULL COMMENT '推送到复习队列的时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_item` (`user_id`, `item_type`, `item_id`),
+  KEY `idx_article_id` (`article_id`),
+  KEY `idx_primary_category` (`primary_category`),
+  KEY `idx_last_error_at` (`last_error_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='错题本';
+
+-- ----------------------------
+-- 5. Vocabulary & Wordbook Tables
+-- ----------------------------
+
+-- Vocabulary
+CREATE TABLE IF NOT EXISTS `vp_vocabulary` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间（软删除）',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `article_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '来源文章ID',
+  `sentence_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '来源句子ID',
+  `wordbook_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '来源单词书词条ID',
+  `wordbook_type` VARCHAR(50) DEFAULT NULL COMMENT '单词书类型：cet4/cet6/toefl等',
+  `source` VARCHAR(50) NOT NULL DEFAULT 'manual' COMMENT '来源：wordbook/article/manual',
+  `type` ENUM('word','phrase','sentence') NOT NULL DEFAULT 'word' COMMENT '类型',
+  `content` VARCHAR(500) NOT NULL COMMENT '内容',
+  `lemma` VARCHAR(100) NOT NULL DEFAULT '' COMMENT '单词原形（用于去重）',
+  `phonetic` VARCHAR(100) DEFAULT NULL COMMENT '音标',
+  `meaning` TEXT COMMENT '释义',
+  `example` TEXT COMMENT '例句',
+  `example_translation` TEXT COMMENT '例句翻译',
+  `context` TEXT COMMENT '原文上下文',
+  `note` TEXT COMMENT '笔记',
+  `morphemes` TEXT COMMENT '词根词缀分析结果（JSON）',
+  `mastery_level` INT DEFAULT 0 COMMENT '掌握等级 0-5',
+  `ease_factor` DECIMAL(4,2) DEFAULT 2.50 COMMENT 'SM-2易度因子',
+  `interval_days` INT DEFAULT 0 COMMENT '当前复习间隔（天）',
+  `repetitions` INT DEFAULT 0 COMMENT '连续正确次数',
+  `next_review_at` DATETIME DEFAULT NULL COMMENT '下次复习时间',
+  `last_review_at` DATETIME DEFAULT NULL COMMENT '上次复习时间',
+  `stability` DOUBLE DEFAULT 0 COMMENT 'FSRS记忆稳定性（天）',
+  `difficulty` DOUBLE DEFAULT 0 COMMENT 'FSRS难度 1-10',
+  `lapses` INT DEFAULT 0 COMMENT '遗忘次数',
+  `srs_state` VARCHAR(20) NOT NULL DEFAULT 'new' COMMENT '学习阶段：new/learning/review/relearning',
+  `learning_step` INT NOT NULL DEFAULT 0 COMMENT '当前学习/重学步骤',
+  `review_count` INT DEFAULT 0 COMMENT '复习次数',
+  `correct_count` INT DEFAULT 0 COMMENT '正确次数',
+  `wrong_count` INT DEFAULT 0 COMMENT '错误次数',
+  `tags` VARCHAR(500) DEFAULT NULL COMMENT '标签（JSON数组）',
+  `is_starred` TINYINT(1) DEFAULT 0 COMMENT '是否星标',
+  PRIMARY KEY (`id`),
+  KEY `idx_vp_vocabulary_deleted_at` (`deleted_at`),
+  KEY `idx_vp_vocabulary_user_id` (`user_id`),
+  KEY `idx_vp_vocabulary_article_id` (`article_id`),
+  KEY `idx_vp_vocabulary_wordbook_id` (`wordbook_id`),
+  KEY `idx_vp_vocabulary_source` (`source`),
+  KEY `idx_vp_vocabulary_srs_state` (`srs_state`),
+  KEY `idx_vp_vocabulary_user_lemma` (`user_id`, `lemma`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='生词本';
+
+-- Vocabulary Folders
+CREATE TABLE IF NOT EXISTS `vp_vocabulary_folders` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间（软删除）',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `name` VARCHAR(100) NOT NULL COMMENT '文件夹名称',
+  `description` VARCHAR(500) DEFAULT NULL COMMENT '描述',
+  `color` VARCHAR(20) DEFAULT '#3b82f6' COMMENT '颜色',
+  `icon` VARCHAR(50) DEFAULT NULL COMMENT '图标',
+  `sort_order` INT DEFAULT 0 COMMENT '排序',
+  `filter` TEXT NULL COMMENT '智能文件夹筛选条件（JSON），为空表示普通文件夹',
+  PRIMARY KEY (`id`),
+  KEY `idx_vp_vocabulary_folders_deleted_at` (`deleted_at`),
+  KEY `idx_vp_vocabulary_folders_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='生词本文件夹';
+
+-- Wordbook Info
+CREATE TABLE IF NOT EXISTS `vp_wordbook_info` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `type` VARCHAR(20) NOT NULL COMMENT '单词书类型：cet4/toefl 等，自建单词书为 u_xxxxxxxx',
+  `name` VARCHAR(100) NOT NULL COMMENT '名称',
+  `owner_id` BIGINT UNSIGNED NULL COMMENT '创建者，系统单词书为 NULL',
+  `visibility` VARCHAR(20) NOT NULL DEFAULT 'public' COMMENT '可见范围：private/link/public',
+  `share_token` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '分享链接令牌',
+  `description` VARCHAR(500) DEFAULT NULL COMMENT '描述',
+  `cover_url` VARCHAR(500) DEFAULT NULL COMMENT '封面图片URL',
+  `hotness` INT UNSIGNED DEFAULT 0 COMMENT '热度',
+  `study_count` INT UNSIGNED DEFAULT 0 COMMENT '学习人数',
+  `word_count` INT UNSIGNED DEFAULT 0 COMMENT '单词数',
+  `category` VARCHAR(50) DEFAULT NULL COMMENT '分类',
+  `is_active` TINYINT(1) DEFAULT 1 COMMENT '是否启用',
+  `sort_order` INT DEFAULT 0 COMMENT '排序',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间（软删除）',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_vp_wordbook_info_type` (`type`),
+  KEY `idx_vp_wordbook_info_owner_id` (`owner_id`),
+  KEY `idx_vp_wordbook_info_share_token` (`share_token`),
+  KEY `idx_vp_wordbook_info_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单词书信息（系统单词书和用户自建单词书）';
+
+-- Wordbook User Order
+CREATE TABLE IF NOT EXISTS `vp_wordbook_user_order` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `word_type` VARCHAR(20) NOT NULL COMMENT '单词书类型',
+  `is_random` TINYINT(1) DEFAULT 0 COMMENT '是否乱序模式',
+  `seed` BIGINT NOT NULL DEFAULT 0 COMMENT '乱序排列的种子，顺序模式为 0',
+  `version` INT NOT NULL DEFAULT 0 COMMENT '序列格式版本：0 旧版 JSON 序列，1 按种子排序',
+  `word_sequence` TEXT COMMENT '旧版单词ID序列（JSON），迁移后清空',
+  `current_index` INT DEFAULT 0 COMMENT '当前学习位置',
+  `total_words` INT DEFAULT 0 COMMENT '总单词数',
+  `target_date` DATE NULL COMMENT '计划背完日期',
+  `new_per_day` INT NOT NULL DEFAULT 0 COMMENT '每日新词数，0 表示使用复习设置',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME DEFAULT NULL COMMENT '删除时间（软删除）',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_word_type` (`user_id`, `word_type`),
+  KEY `idx_vp_wordbook_user_order_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户单词书学习序列和计划';
+
+-- Wordbook Cards
+CREATE TABLE IF NOT EXISTS `vp_wordbook_cards` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '第一次学习时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `word_type` VARCHAR(20) NOT NULL COMMENT '单词书类型',
+  `word_id` BIGINT UNSIGNED NOT NULL COMMENT '单词ID，关联vp_wordbook.id',
+  `ease_factor` DOUBLE DEFAULT 2.5 COMMENT 'SM-2易度因子',
+  `interval_days` BIGINT DEFAULT 0 COMMENT '当前复习间隔（天）',
+  `repetitions` BIGINT DEFAULT 0 COMMENT '连续正确次数',
+  `mastery_level` BIGINT DEFAULT 0 COMMENT '掌握等级 0-5',
+  `stability` DOUBLE DEFAULT 0 COMMENT 'FSRS记忆稳定性（天）',
+  `difficulty` DOUBLE DEFAULT 0 COMMENT 'FSRS难度 1-10',
+  `lapses` BIGINT DEFAULT 0 COMMENT '遗忘次数',
+  `srs_state` VARCHAR(20) DEFAULT 'new' COMMENT '学习阶段：new/learning/review/relearning',
+  `learning_step` BIGINT DEFAULT 0 COMMENT '当前学习/重学步骤',
+  `last_review_at` DATETIME(3) DEFAULT NULL COMMENT '上次复习时间',
+  `next_review_at` DATETIME(3) DEFAULT NULL COMMENT '下次复习时间',
+  `review_count` BIGINT DEFAULT 0 COMMENT '复习次数',
+  `mastered_at` DATETIME(3) DEFAULT NULL COMMENT '掌握时间，遗忘后清空',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_book_word` (`user_id`, `word_type`, `word_id`),
+  KEY `idx_user_book_due` (`user_id`, `word_type`, `next_review_at`),
+  KEY `idx_user_book_created` (`user_id`, `word_type`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单词书间隔重复卡片';
+
+-- Pronunciations
+CREATE TABLE IF NOT EXISTS `vp_pronunciations` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` DATETIME(3) DEFAULT NULL COMMENT '更新时间',
+  `text_hash` VARCHAR(64) NOT NULL COMMENT '规范化文本的 SHA-256',
+  `voice` VARCHAR(64) NOT NULL COMMENT '音色',
+  `kind` VARCHAR(20) NOT NULL DEFAULT 'word' COMMENT '类型：word/sentence',
+  `text` VARCHAR(1000) NOT NULL COMMENT '原文',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '生成状态：pending/ready/failed',
+  `path` VARCHAR(255) DEFAULT NULL COMMENT '音频存储路径',
+  `content_hash` VARCHAR(64) DEFAULT NULL COMMENT '音频内容的 SHA-256，内容相同的共用存储文件',
+  `size` BIGINT DEFAULT 0 COMMENT '音频大小（字节）',
+  `attempts` BIGINT DEFAULT 0 COMMENT '生成尝试次数',
+  `error` VARCHAR(500) DEFAULT NULL COMMENT '最近一次失败原因',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_text_voice` (`text_hash`, `voice`),
+  KEY `idx_vp_pronunciations_status` (`status`),
+  KEY `idx_vp_pronunciations_content_hash` (`content_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='TTS 发音缓存';
+
+-- User Goals
+CREATE TABLE IF NOT EXISTS `vp_user_goals` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` DATETIME(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` DATETIME(3) DEFAULT NULL COMMENT '删除时间（软删除）',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `title` VARCHAR(100) NOT NULL COMMENT '目标名称',
+  `word_type` VARCHAR(20) DEFAULT NULL COMMENT '目标单词书，为空表示只有学习时长目标',
+  `start_date` DATE NOT NULL COMMENT '开始日期（重新计划时更新）',
+  `deadline` DATE NOT NULL COMMENT '截止日期',
+  `daily_minutes` BIGINT DEFAULT 0 COMMENT '每日学习时长（分钟），0 表示不限',
+  `daily_new_words` BIGINT DEFAULT 0 COMMENT '每日最少新词数，0 表示按剩余单词和剩余天数计算',
+  `start_learned` BIGINT DEFAULT 0 COMMENT '开始时单词书中已学的单词数',
+  `planned_daily` BIGINT DEFAULT 0 COMMENT '计划的每日新词数',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT '状态：active/completed/abandoned/expired',
+  `completed_at` DATETIME(3) DEFAULT NULL COMMENT '完成时间',
+  `remind_enabled` TINYINT(1) DEFAULT 1 COMMENT '当日未完成时是否提醒',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_status` (`user_id`, `status`),
+  KEY `idx_vp_user_goals_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户学习目标';
+
+-- User Streak Protections
+CREATE TABLE IF NOT EXISTS `vp_user_streak_protections` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME(3) DEFAULT NULL COMMENT '创建时间',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `kind` VARCHAR(20) NOT NULL COMMENT '连续学习类型：check_in/review/study/dictation，冻结卡为 all',
+  `protect_date` DATE NOT NULL COMMENT '保护的日期',
+  `source` VARCHAR(20) NOT NULL COMMENT '来源：freeze 冻结卡/repair 补签卡',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_kind_date` (`user_id`, `kind`, `protect_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='连续学习保护记录（冻结卡、补签卡）';
+
+-- User Achievement Counters
+CREATE TABLE IF NOT EXISTS `vp_user_achievement_counters` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `created_at` DATETIME(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` DATETIME(3) DEFAULT NULL COMMENT '更新时间',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，关联vp_users.id',
+  `metric` VARCHAR(50) NOT NULL COMMENT '指标（称号条件类型）',
+  `stat_date` DATE NOT NULL COMMENT '统计日期（用户时区）',
+  `value` INT NOT NULL DEFAULT 0 COMMENT '当天累加值',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_metric_date` (`user_id`, `metric`, `stat_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户成就按天计数（时间窗口条件和限时称号使用）';
+
 SET FOREIGN_KEY_CHECKS = 1;
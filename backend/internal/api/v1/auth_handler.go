@@ -693,6 +693,7 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 			"nickname":        user.Nickname,
 			"avatar":          avatarURL,
 			"bio":             user.Bio,
+			"timezone":        user.Timezone,
 			"role":            user.Role,
 			"status":          user.Status,
 			"invite_code":     user.InviteCode,
@@ -706,6 +707,9 @@ type UpdateProfileRequest struct {
 	Nickname string `json:"nickname"` // 昵称
 	Avatar   string `json:"avatar"`   // 头像URL
 	Bio      string `json:"bio"`      // 个人简介
+	// 时区（IANA 名称，如 Asia/Shanghai），决定签到、每日统计等按哪天计算
+	// 不传时不修改，传空字符串恢复为服务器时区
+	Timezone *string `json:"timezone"`
 }
 
 // UpdateProfile 更新用户资料
@@ -727,6 +731,13 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	fmt.Printf("📥 收到更新用户资料请求: user_id=%v, nickname=%s, avatar=%s, bio=%s\n",
 		userID, req.Nickname, req.Avatar, req.Bio)
 
+	if req.Timezone != nil {
+		if err := service.ValidateTimezone(*req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// 先获取当前用户信息，如果某个字段未提供，使用原有值
 	currentUser, err := h.authService.GetUserByID(userID.(uint))
 	if err != nil {
@@ -755,6 +766,13 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败", "details": err.Error()})
 		return
 	}
+	if req.Timezone != nil && *req.Timezone != user.Timezone {
+		if err := h.authService.UpdateUserTimezone(userID.(uint), *req.Timezone); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败", "details": err.Error()})
+			return
+		}
+		user.Timezone = *req.Timezone
+	}
 
 	fmt.Printf("✅ 更新用户资料成功: user_id=%v\n", userID)
 
@@ -779,6 +797,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 			"nickname":   user.Nickname,
 			"avatar":     avatarURL,
 			"bio":        user.Bio,
+			"timezone":   user.Timezone,
 			"created_at": user.CreatedAt,
 		},
 	})
@@ -902,6 +921,7 @@ func (h *AuthHandler) UploadAvatar(c *gin.Context) {
 			"nickname":        user.Nickname,
 			"avatar":          signedAvatarURL,
 			"bio":             user.Bio,
+			"timezone":        user.Timezone,
 			"role":            user.Role,
 			"status":          user.Status,
 			"created_at":      user.CreatedAt,
@@ -1039,3 +1059,24 @@ func (h *AuthHandler) OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware 管理员权限中间件（需放在 AuthMiddleware 之后）
+func (h *AuthHandler) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+			c.Abort()
+			return
+		}
+
+		user, err := repository.NewUserRepository().FindByID(userID.(uint))
+		if err != nil || user.Role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "需要管理员权限"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,7 +3,6 @@ package v1
 import (
 	"net/http"
 	"strconv"
-	"time"
 	"voicepaper/internal/repository"
 	"voicepaper/internal/service"
 
@@ -12,11 +11,13 @@ import (
 
 type CheckInHandler struct {
 	checkInService *service.CheckInService
+	calendar       *service.CalendarService
 }
 
 func NewCheckInHandler() *CheckInHandler {
 	return &CheckInHandler{
-		checkInService: service.NewCheckInService(repository.DB),
+		checkInService: service.NewCheckInService(repository.DB, service.DefaultCalendar()),
+		calendar:       service.DefaultCalendar(),
 	}
 }
 
@@ -67,8 +68,9 @@ func (h *CheckInHandler) GetCheckInCalendar(c *gin.Context) {
 		return
 	}
 
-	// 获取年月参数（默认当前月）
-	now := time.Now()
+	// 获取年月参数（默认用户时区的当前月）
+	uid := userID.(uint)
+	now := h.calendar.Today(uid)
 	year, _ := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(now.Year())))
 	month, _ := strconv.Atoi(c.DefaultQuery("month", strconv.Itoa(int(now.Month()))))
 
@@ -80,7 +82,6 @@ func (h *CheckInHandler) GetCheckInCalendar(c *gin.Context) {
 		month = int(now.Month())
 	}
 
-	uid := userID.(uint)
 	calendar, err := h.checkInService.GetCheckInCalendar(uid, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取签到日历失败", "details": err.Error()})
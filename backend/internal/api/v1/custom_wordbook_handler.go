@@ -0,0 +1,228 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	customWordbookService     *service.CustomWordbookService
+	customWordbookServiceOnce sync.Once
+)
+
+// getCustomWordbookService 惰性初始化自建单词书服务
+func getCustomWordbookService() *service.CustomWordbookService {
+	customWordbookServiceOnce.Do(func() {
+		customWordbookService = service.NewCustomWordbookService(repository.DB)
+	})
+	return customWordbookService
+}
+
+// respondCustomWordbookError 自建单词书错误响应
+func respondCustomWordbookError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrWordbookNotFound), errors.Is(err, service.ErrWordbookShareExpired):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrWordbookForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTooManyWordbooks), errors.Is(err, service.ErrWordbookFull),
+		errors.Is(err, service.ErrNoWordbookEntries), errors.Is(err, service.ErrTooManyImportItems):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+// GetMyWordbooks 获取我创建的和正在学习的自建单词书
+// GET /api/v1/wordbooks/custom
+func GetMyWordbooks(c *gin.Context) {
+	result, err := getCustomWordbookService().ListMine(c.GetUint("user_id"))
+	if err != nil {
+		respondCustomWordbookError(c, err, "获取单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// CreateCustomWordbook 创建自建单词书
+// POST /api/v1/wordbooks/custom
+func CreateCustomWordbook(c *gin.Context) {
+	var req service.CreateWordbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	info, err := getCustomWordbookService().Create(c.GetUint("user_id"), &req)
+	if err != nil {
+		respondCustomWordbookError(c, err, "创建单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "单词书已创建", "data": info})
+}
+
+// GetCustomWordbook 获取自建单词书信息
+// GET /api/v1/wordbooks/custom/:type
+func GetCustomWordbook(c *gin.Context) {
+	info, err := getCustomWordbookService().Get(c.GetUint("user_id"), c.Param("type"))
+	if err != nil {
+		respondCustomWordbookError(c, err, "获取单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": info})
+}
+
+// UpdateCustomWordbook 更新单词书信息、可见范围或重置分享链接
+// PUT /api/v1/wordbooks/custom/:type
+func UpdateCustomWordbook(c *gin.Context) {
+	var req service.UpdateWordbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	info, err := getCustomWordbookService().Update(c.GetUint("user_id"), c.Param("type"), &req)
+	if err != nil {
+		respondCustomWordbookError(c, err, "更新单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "单词书已更新", "data": info})
+}
+
+// DeleteCustomWordbook 删除自建单词书
+// DELETE /api/v1/wordbooks/custom/:type
+func DeleteCustomWordbook(c *gin.Context) {
+	if err := getCustomWordbookService().Delete(c.GetUint("user_id"), c.Param("type")); err != nil {
+		respondCustomWordbookError(c, err, "删除单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "单词书已删除"})
+}
+
+// AddCustomWordbookWords 向自建单词书添加单词
+// POST /api/v1/wordbooks/custom/:type/words
+// JSON：{"source":"text","text":"..."} 粘贴单词列表，或 {"source":"articles","article_ids":[...]} 添加文章重点单词；
+// multipart/form-data：file 为 CSV 文件，mapping 为字段映射 JSON（与生词本导入相同）
+func AddCustomWordbookWords(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	wordType := c.Param("type")
+
+	var (
+		result *service.AddWordbookWordsResult
+		err    error
+	)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, ferr := c.FormFile("file")
+		if ferr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请上传文件", "details": ferr.Error()})
+			return
+		}
+		if fileHeader.Size > maxImportFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "文件大小不能超过 20MB"})
+			return
+		}
+		var mapping map[string]string
+		if raw := c.PostForm("mapping"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "字段映射格式错误", "details": err.Error()})
+				return
+			}
+		}
+		f, ferr := fileHeader.Open()
+		if ferr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败", "details": ferr.Error()})
+			return
+		}
+		defer f.Close()
+		data, ferr := io.ReadAll(io.LimitReader(f, maxImportFileSize+1))
+		if ferr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败", "details": ferr.Error()})
+			return
+		}
+		result, err = getCustomWordbookService().AddWordsCSV(userID, wordType, data, mapping)
+	} else {
+		var req service.AddWordbookWordsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+			return
+		}
+		result, err = getCustomWordbookService().AddWords(userID, wordType, &req)
+	}
+	if err != nil {
+		respondCustomWordbookError(c, err, "添加单词失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "单词已添加", "data": result})
+}
+
+// RemoveCustomWordbookWord 从自建单词书移除单词
+// DELETE /api/v1/wordbooks/custom/:type/words/:word_id
+func RemoveCustomWordbookWord(c *gin.Context) {
+	wordID, err := strconv.ParseUint(c.Param("word_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的单词 ID"})
+		return
+	}
+
+	if err := getCustomWordbookService().RemoveWord(c.GetUint("user_id"), c.Param("type"), uint(wordID)); err != nil {
+		respondCustomWordbookError(c, err, "移除单词失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "单词已移除"})
+}
+
+// GetPublicWordbooks 浏览公开的自建单词书
+// GET /api/v1/wordbooks/custom/public?keyword=&page=&page_size=
+func GetPublicWordbooks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	list, total, err := getCustomWordbookService().ListPublic(c.Query("keyword"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		respondCustomWordbookError(c, err, "获取单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetSharedWordbook 通过分享链接查看单词书
+// GET /api/v1/wordbooks/custom/shared/:token
+func GetSharedWordbook(c *gin.Context) {
+	info, err := getCustomWordbookService().GetShared(c.GetUint("user_id"), c.Param("token"))
+	if err != nil {
+		respondCustomWordbookError(c, err, "获取单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": info})
+}
+
+// JoinSharedWordbook 通过分享链接加入单词书，加入后即可使用单词书的学习接口
+// POST /api/v1/wordbooks/custom/shared/:token/join
+func JoinSharedWordbook(c *gin.Context) {
+	info, err := getCustomWordbookService().Join(c.GetUint("user_id"), c.Param("token"))
+	if err != nil {
+		respondCustomWordbookError(c, err, "加入单词书失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已加入单词书", "data": info})
+}
@@ -16,7 +16,7 @@ type DailyStatsHandler struct {
 func NewDailyStatsHandler(db *gorm.DB) *DailyStatsHandler {
 	return &DailyStatsHandler{
 		db:      db,
-		service: service.NewUserDailyStatsService(db),
+		service: service.NewUserDailyStatsService(db, service.DefaultCalendar()),
 	}
 }
 
@@ -45,10 +45,13 @@ func (h *DailyStatsHandler) SyncDuration(c *gin.Context) {
 		return
 	}
 
+	event := service.Events().Publish(service.Event{Kind: service.EventDurationSynced, UserID: uid, Seconds: req.DurationSeconds})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":          "同步成功",
 		"duration_seconds": req.DurationSeconds,
 		"duration_minutes": req.DurationSeconds / 60,
+		"new_titles":       event.UnlockedTitles,
 	})
 }
 
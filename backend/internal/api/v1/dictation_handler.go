@@ -8,27 +8,33 @@ import (
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 	"voicepaper/internal/service"
+	"voicepaper/pkg/dictation"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type DictationHandler struct {
-	repo         *repository.DictationRecordRepository
-	progressRepo *repository.DictationProgressRepository
-	pointService *service.PointService
+	repo           *repository.DictationRecordRepository
+	progressRepo   *repository.DictationProgressRepository
+	pointService   *service.PointService
+	seriesService  *service.SeriesService
+	mistakeService *service.MistakeService
 }
 
 func NewDictationHandler(db *gorm.DB) *DictationHandler {
 	return &DictationHandler{
-		repo:         repository.NewDictationRecordRepository(),
-		progressRepo: repository.NewDictationProgressRepository(db),
-		pointService: service.NewPointService(db),
+		repo:           repository.NewDictationRecordRepository(),
+		progressRepo:   repository.NewDictationProgressRepository(db),
+		pointService:   service.NewPointService(db),
+		seriesService:  service.NewSeriesService(db),
+		mistakeService: service.NewMistakeService(db),
 	}
 }
 
 // SaveRecord 保存默写记录
 // POST /api/v1/dictation/record
+// 服务端根据句子/单词原文判分，客户端提交的 is_correct 和 score 会被忽略
 func (h *DictationHandler) SaveRecord(c *gin.Context) {
 	var req struct {
 		ArticleID     uint   `json:"article_id" binding:"required"`
@@ -36,8 +42,6 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 		WordID        *uint  `json:"word_id,omitempty"`
 		SentenceID    *uint  `json:"sentence_id,omitempty"`
 		UserAnswer    string `json:"user_answer" binding:"required"`
-		IsCorrect     bool   `json:"is_correct"`
-		Score         int    `json:"score"`
 		AttemptCount  int    `json:"attempt_count"`
 		TimeSpent     int    `json:"time_spent"` // 秒
 	}
@@ -64,6 +68,22 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 		return
 	}
 
+	// 获取标准答案并判分
+	itemID := req.SentenceID
+	if dictationType == model.DictationTypeWord {
+		itemID = req.WordID
+	}
+	answerText, err := h.repo.GetAnswerText(dictationType, req.ArticleID, *itemID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "默写题目不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取题目失败", "details": err.Error()})
+		return
+	}
+	grading := dictation.Grade(answerText, req.UserAnswer)
+
 	// 获取用户ID（如果已登录）
 	var userID *uint
 	if userIDVal, exists := c.Get("user_id"); exists {
@@ -72,6 +92,16 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 		}
 	}
 
+	// 同一题目已经答对过的不再重复奖励积分
+	alreadyCorrect := false
+	if userID != nil && grading.IsCorrect {
+		alreadyCorrect, err = h.repo.HasCorrectRecord(*userID, dictationType, *itemID)
+		if err != nil {
+			log.Printf("⚠️ 查询默写记录失败: %v", err)
+			alreadyCorrect = true
+		}
+	}
+
 	// 创建记录
 	record := &model.DictationRecord{
 		UserID:        userID,
@@ -80,8 +110,8 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 		WordID:        req.WordID,
 		SentenceID:    req.SentenceID,
 		UserAnswer:    req.UserAnswer,
-		IsCorrect:     req.IsCorrect,
-		Score:         req.Score,
+		IsCorrect:     grading.IsCorrect,
+		Score:         grading.Score,
 		AttemptCount:  req.AttemptCount,
 		TimeSpent:     req.TimeSpent,
 		LastAttempt:   time.Now(),
@@ -93,25 +123,34 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 		return
 	}
 
-	// 如果用户已登录且答案正确，奖励积分
+	// 答错记入错题本，答对则标记已解决
+	if userID != nil {
+		mistakeType := model.MistakeItemSentence
+		if dictationType == model.DictationTypeWord {
+			mistakeType = model.MistakeItemWord
+		}
+		if grading.IsCorrect {
+			err = h.mistakeService.Resolve(*userID, mistakeType, *itemID)
+		} else {
+			err = h.mistakeService.RecordDictation(*userID, mistakeType, *itemID, req.ArticleID, answerText, req.UserAnswer, grading)
+		}
+		if err != nil {
+			log.Printf("⚠️ 更新错题本失败: %v", err)
+		}
+	}
+
+	// 如果用户已登录且首次答对，奖励积分
 	var pointsEarned int
 	var pointRecord *model.PointRecord
-	if userID != nil && req.IsCorrect {
+	if userID != nil && grading.IsCorrect && !alreadyCorrect {
 		// 确定积分类型
-		var pointType model.PointType
-		var itemText string
-
+		pointType := model.PointTypeSentenceDictation
 		if dictationType == model.DictationTypeWord {
 			pointType = model.PointTypeWordDictation
-			// 获取单词文本（简化处理，可以从数据库查询）
-			itemText = req.UserAnswer
-		} else {
-			pointType = model.PointTypeSentenceDictation
-			itemText = req.UserAnswer
 		}
 
 		// 奖励积分
-		_, pr, err := h.pointService.AwardDictationPoints(*userID, pointType, true, itemText, &record.ID)
+		_, pr, err := h.pointService.AwardDictationPoints(*userID, pointType, true, answerText, &record.ID)
 		if err != nil {
 			log.Printf("⚠️ 奖励默写积分失败: %v", err)
 		} else {
@@ -123,6 +162,13 @@ func (h *DictationHandler) SaveRecord(c *gin.Context) {
 	response := gin.H{
 		"message": "记录保存成功",
 		"record":  record,
+		"grading": grading,
+	}
+
+	// 发布默写事件（已答对过的题目再次答对不计入连续全对）
+	if userID != nil && !(grading.IsCorrect && alreadyCorrect) {
+		event := service.Events().Publish(service.Event{Kind: service.EventDictationCompleted, UserID: *userID, Correct: grading.IsCorrect})
+		response["new_titles"] = event.UnlockedTitles
 	}
 
 	// 如果有积分奖励，添加到响应中
@@ -299,6 +345,14 @@ func (h *DictationHandler) SaveProgress(c *gin.Context) {
 				response["bonus_message"] = "恭喜完成整篇文章默写！"
 			}
 		}
+
+		// 检查所属系列是否已全部完成
+		rewards, err := h.seriesService.CheckCompletion(userID, req.ArticleID)
+		if err != nil {
+			log.Printf("⚠️ 检查系列完成状态失败: %v", err)
+		} else if len(rewards) > 0 {
+			response["series_rewards"] = rewards
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"voicepaper/internal/model"
+	"voicepaper/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -70,10 +71,14 @@ func (h *DurationHandler) SyncReadingDuration(c *gin.Context) {
 
 	log.Printf("✅ 同步阅读时长成功 (user_id=%d, duration=%d分钟)", uid, durationMinutes)
 
+	// 累计时长是覆盖写入的，不计入按天的时长计数
+	event := service.Events().Publish(service.Event{Kind: service.EventDurationSynced, UserID: uid})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":          "同步成功",
 		"duration_minutes": durationMinutes,
 		"duration_seconds": req.TotalReadingSeconds,
+		"new_titles":       event.UnlockedTitles,
 	})
 }
 
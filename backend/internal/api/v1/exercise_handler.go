@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"voicepaper/config"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+	"voicepaper/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	exerciseService     *service.ExerciseService
+	exerciseServiceOnce sync.Once
+)
+
+// getExerciseService 惰性初始化复习练习服务
+func getExerciseService() *service.ExerciseService {
+	exerciseServiceOnce.Do(func() {
+		cfg := config.GetConfig()
+		var st storage.Storage
+		var err error
+
+		if cfg.Storage.Type == "oss" {
+			st, err = storage.NewOSSStorage(cfg)
+			if err != nil {
+				st = storage.NewLocalStorage(cfg)
+			}
+		} else {
+			st = storage.NewLocalStorage(cfg)
+		}
+
+		exerciseService = service.NewExerciseService(repository.DB, st, service.DefaultCalendar())
+	})
+	return exerciseService
+}
+
+// GetVocabularyExercise 为生词生成一道练习（答案保存在服务端）
+// GET /api/v1/vocabulary/:id/exercise?type=choice|spell|cloze|listening
+// type 为空时根据掌握等级自动选择
+func GetVocabularyExercise(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	exerciseType := c.Query("type")
+	switch exerciseType {
+	case "", "choice", "spell", "cloze", "listening":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的练习类型"})
+		return
+	}
+
+	exercise, err := getExerciseService().Generate(userID, uint(id), exerciseType)
+	if err != nil {
+		if errors.Is(err, service.ErrExerciseUnavailable) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": exercise})
+}
+
+// AnswerExerciseRequest 提交练习作答
+type AnswerExerciseRequest struct {
+	Answer  string `json:"answer"`  // 选择题可提交选项下标或选项文本
+	Session bool   `json:"session"` // 是否在今日复习会话中作答
+}
+
+// AnswerExercise 提交练习作答，由服务端判分并推导 SM-2 评分
+// POST /api/v1/vocabulary/exercises/:exercise_id/answer
+func AnswerExercise(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	exerciseID, err := strconv.ParseUint(c.Param("exercise_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的练习ID"})
+		return
+	}
+
+	var req AnswerExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	submit := getExerciseService().SubmitReview(userID)
+	var next *service.ReviewSessionView
+	if req.Session {
+		submit = func(r *service.SubmitReviewRequest) (*service.SubmitReviewResult, error) {
+			result, err := getReviewSessionService().Answer(userID, r)
+			if err != nil {
+				return nil, err
+			}
+			next = result.Next
+			return result.Review, nil
+		}
+	}
+
+	result, err := getExerciseService().Grade(userID, uint(exerciseID), req.Answer, submit)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "练习不存在"})
+		case errors.Is(err, service.ErrExerciseAnswered), errors.Is(err, service.ErrExerciseExpired), errors.Is(err, service.ErrCardNotInSession):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response := gin.H{
+		"message": "作答完成",
+		"data":    result,
+	}
+	if next != nil {
+		response["next"] = next
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	goalService     *service.GoalService
+	goalServiceOnce sync.Once
+)
+
+// getGoalService 惰性初始化学习目标服务
+func getGoalService() *service.GoalService {
+	goalServiceOnce.Do(func() {
+		goalService = service.NewGoalService(repository.DB, service.DefaultCalendar())
+	})
+	return goalService
+}
+
+// respondGoalError 学习目标错误响应
+func respondGoalError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrGoalNotFound), errors.Is(err, service.ErrWordbookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTooManyGoals), errors.Is(err, service.ErrEmptyGoal),
+		errors.Is(err, service.ErrInvalidGoalDeadline), errors.Is(err, service.ErrGoalBookTaken),
+		errors.Is(err, service.ErrGoalFinished), errors.Is(err, service.ErrInvalidGoalStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrGoalPlanDateTaken):
+		// 前端询问用户后，带上 replace_plan_date=true 重新提交
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+// parseGoalID 解析路径中的目标ID
+func parseGoalID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListGoals 获取学习目标列表
+// GET /api/v1/goals?status=active|completed|abandoned|expired
+func ListGoals(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", model.GoalStatusActive, model.GoalStatusCompleted, model.GoalStatusAbandoned, model.GoalStatusExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的状态"})
+		return
+	}
+
+	goals, err := getGoalService().List(c.GetUint("user_id"), status)
+	if err != nil {
+		respondGoalError(c, err, "获取学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+// CreateGoal 创建学习目标
+// POST /api/v1/goals
+func CreateGoal(c *gin.Context) {
+	var req service.CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	goal, err := getGoalService().Create(c.GetUint("user_id"), &req)
+	if err != nil {
+		respondGoalError(c, err, "创建学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "学习目标已创建", "data": goal})
+}
+
+// GetTodayGoals 获取进行中学习目标的今日配额和完成情况
+// GET /api/v1/goals/today
+func GetTodayGoals(c *gin.Context) {
+	goals, err := getGoalService().Today(c.GetUint("user_id"))
+	if err != nil {
+		respondGoalError(c, err, "获取学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+// GetGoalReminders 获取今日还没完成的学习目标提醒
+// GET /api/v1/goals/reminders
+func GetGoalReminders(c *gin.Context) {
+	reminders, err := getGoalService().Reminders(c.GetUint("user_id"))
+	if err != nil {
+		respondGoalError(c, err, "获取学习提醒失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reminders})
+}
+
+// GetGoal 获取学习目标详情
+// GET /api/v1/goals/:id
+func GetGoal(c *gin.Context) {
+	id, ok := parseGoalID(c)
+	if !ok {
+		return
+	}
+	goal, err := getGoalService().Get(c.GetUint("user_id"), id)
+	if err != nil {
+		respondGoalError(c, err, "获取学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": goal})
+}
+
+// UpdateGoal 修改学习目标（截止日期、每日时长/新词数、提醒），或 {"status":"abandoned"} 放弃目标
+// PUT /api/v1/goals/:id
+func UpdateGoal(c *gin.Context) {
+	id, ok := parseGoalID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	goal, err := getGoalService().Update(c.GetUint("user_id"), id, &req)
+	if err != nil {
+		respondGoalError(c, err, "更新学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "学习目标已更新", "data": goal})
+}
+
+// DeleteGoal 删除学习目标
+// DELETE /api/v1/goals/:id
+func DeleteGoal(c *gin.Context) {
+	id, ok := parseGoalID(c)
+	if !ok {
+		return
+	}
+	if err := getGoalService().Delete(c.GetUint("user_id"), id); err != nil {
+		respondGoalError(c, err, "删除学习目标失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "学习目标已删除"})
+}
+
+// GetGoalHistory 获取学习目标最近几天的完成情况
+// GET /api/v1/goals/:id/history?days=14
+func GetGoalHistory(c *gin.Context) {
+	id, ok := parseGoalID(c)
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "14"))
+
+	history, err := getGoalService().History(c.GetUint("user_id"), id, days)
+	if err != nil {
+		respondGoalError(c, err, "获取完成情况失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": history})
+}
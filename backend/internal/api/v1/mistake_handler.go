@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	mistakeService     *service.MistakeService
+	mistakeServiceOnce sync.Once
+)
+
+// getMistakeService 惰性初始化错题本服务
+func getMistakeService() *service.MistakeService {
+	mistakeServiceOnce.Do(func() {
+		mistakeService = service.NewMistakeService(repository.DB)
+	})
+	return mistakeService
+}
+
+// ListMistakes 获取错题列表
+// GET /api/v1/mistakes?item_type=word|sentence|vocabulary&category=spelling&article_id=&resolved=false&pushed=false&min_errors=2&keyword=&order_by=recent|count&limit=20&offset=0
+func ListMistakes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	params := &repository.MistakeListParams{
+		ItemType: c.Query("item_type"),
+		Category: c.Query("category"),
+		Keyword:  c.Query("keyword"),
+		OrderBy:  c.Query("order_by"),
+		Limit:    20, // 默认每页20条
+	}
+
+	if articleIDStr := c.Query("article_id"); articleIDStr != "" {
+		if articleID, err := strconv.ParseUint(articleIDStr, 10, 64); err == nil {
+			aid := uint(articleID)
+			params.ArticleID = &aid
+		}
+	}
+	if resolvedStr := c.Query("resolved"); resolvedStr != "" {
+		resolved := resolvedStr == "true"
+		params.Resolved = &resolved
+	}
+	if pushedStr := c.Query("pushed"); pushedStr != "" {
+		pushed := pushedStr == "true"
+		params.Pushed = &pushed
+	}
+	if minStr := c.Query("min_errors"); minStr != "" {
+		if minErrors, err := strconv.Atoi(minStr); err == nil {
+			params.MinErrors = minErrors
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+
+	mistakes, total, err := getMistakeService().List(userID, params)
+	if err != nil {
+		log.Printf("[ListMistakes] 错误: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   mistakes,
+		"total":  total,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	})
+}
+
+// PushMistakesRequest 推送错题请求
+type PushMistakesRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=200"`
+}
+
+// PushMistakes 把选中的错题推送到生词复习队列
+// POST /api/v1/mistakes/push
+func PushMistakes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	var req PushMistakesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	result, err := getMistakeService().Push(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "推送失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已加入复习队列",
+		"data":    result,
+	})
+}
+
+// RebuildMistakes 从历史默写和复习记录回填错题本（已有的错题不受影响）
+// POST /api/v1/mistakes/rebuild
+func RebuildMistakes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	added, err := getMistakeService().Backfill(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "回填失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "回填完成",
+		"added":   added,
+	})
+}
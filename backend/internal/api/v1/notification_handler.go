@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	notificationService     *service.NotificationService
+	notificationServiceOnce sync.Once
+)
+
+// getNotificationService 惰性初始化通知服务
+func getNotificationService() *service.NotificationService {
+	notificationServiceOnce.Do(func() {
+		notificationService = service.NewNotificationService(repository.DB)
+	})
+	return notificationService
+}
+
+// ListNotifications 获取通知列表
+// GET /api/v1/notifications?unread=true&page=1&page_size=20
+func ListNotifications(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	notifications, total, err := getNotificationService().List(userID, c.Query("unread") == "true", page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取通知失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": notifications, "total": total})
+}
+
+// GetUnreadNotificationCount 获取未读通知数
+// GET /api/v1/notifications/unread-count
+func GetUnreadNotificationCount(c *gin.Context) {
+	count, err := getNotificationService().UnreadCount(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取未读通知数失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// MarkNotificationRead 标记通知为已读
+// POST /api/v1/notifications/:id/read
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的通知ID"})
+		return
+	}
+
+	if err := getNotificationService().MarkRead(c.GetUint("user_id"), uint(id)); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记已读失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已标记为已读"})
+}
+
+// MarkAllNotificationsRead 标记全部通知为已读
+// POST /api/v1/notifications/read-all
+func MarkAllNotificationsRead(c *gin.Context) {
+	count, err := getNotificationService().MarkAllRead(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记已读失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已全部标记为已读", "count": count})
+}
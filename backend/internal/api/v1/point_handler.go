@@ -174,11 +174,13 @@ func (h *PointHandler) AwardReadPoints(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	event := service.Events().Publish(service.Event{Kind: service.EventArticleRead, UserID: uid})
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "阅读积分奖励成功",
 		"points_earned": record.Points,
 		"user_points":   userPoints,
 		"record":        record,
+		"new_titles":    event.UnlockedTitles,
 	})
 }
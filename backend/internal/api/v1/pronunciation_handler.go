@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"net/http"
+	"sync"
+	"voicepaper/config"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+	"voicepaper/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	pronunciationService     *service.PronunciationService
+	pronunciationServiceOnce sync.Once
+)
+
+// getPronunciationService 惰性初始化发音服务
+func getPronunciationService() *service.PronunciationService {
+	pronunciationServiceOnce.Do(func() {
+		cfg := config.GetConfig()
+		var st storage.Storage
+		var err error
+
+		if cfg.Storage.Type == "oss" {
+			st, err = storage.NewOSSStorage(cfg)
+			if err != nil {
+				st = storage.NewLocalStorage(cfg)
+			}
+		} else {
+			st = storage.NewLocalStorage(cfg)
+		}
+
+		pronunciationService = service.NewPronunciationService(repository.DB, st)
+	})
+	return pronunciationService
+}
+
+// GetPronunciation 获取单词或句子的发音，没有生成过时在后台生成并返回 pending
+// GET /api/v1/pronunciations?text=hello&kind=word|sentence
+func GetPronunciation(c *gin.Context) {
+	var req struct {
+		Text string `form:"text" binding:"required"`
+		Kind string `form:"kind" binding:"omitempty,oneof=word sentence"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = model.PronunciationWord
+	}
+
+	audio := getPronunciationService().Lookup(req.Text, req.Kind)
+	if audio.URL == "" && !audio.Pending {
+		c.JSON(http.StatusNotFound, gin.H{"error": "暂时无法生成发音"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": audio})
+}
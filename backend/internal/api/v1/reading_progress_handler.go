@@ -14,16 +14,18 @@ import (
 )
 
 type ReadingProgressHandler struct {
-	repo         *repository.ReadingProgressRepository
-	pointService *service.PointService
-	articleRepo  *repository.ArticleRepository
+	repo          *repository.ReadingProgressRepository
+	pointService  *service.PointService
+	seriesService *service.SeriesService
+	articleRepo   *repository.ArticleRepository
 }
 
 func NewReadingProgressHandler(db *gorm.DB) *ReadingProgressHandler {
 	return &ReadingProgressHandler{
-		repo:         repository.NewReadingProgressRepository(db),
-		pointService: service.NewPointService(db),
-		articleRepo:  repository.NewArticleRepository(),
+		repo:          repository.NewReadingProgressRepository(db),
+		pointService:  service.NewPointService(db),
+		seriesService: service.NewSeriesService(db),
+		articleRepo:   repository.NewArticleRepository(),
 	}
 }
 
@@ -120,11 +122,21 @@ func (h *ReadingProgressHandler) SaveProgress(c *gin.Context) {
 			if err != nil {
 				log.Printf("⚠️ 奖励阅读积分失败: %v", err)
 			} else if record != nil {
+				event := service.Events().Publish(service.Event{Kind: service.EventArticleRead, UserID: uid})
 				response["points_earned"] = record.Points
 				response["points_message"] = "恭喜完成阅读！"
+				response["new_titles"] = event.UnlockedTitles
 				log.Printf("✅ 用户 %d 完成文章《%s》阅读，获得 %d 积分", uid, article.Title, record.Points)
 			}
 		}
+
+		// 检查所属系列是否已全部完成
+		rewards, err := h.seriesService.CheckCompletion(uid, req.ArticleID)
+		if err != nil {
+			log.Printf("⚠️ 检查系列完成状态失败: %v", err)
+		} else if len(rewards) > 0 {
+			response["series_rewards"] = rewards
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RecommendationHandler struct {
+	service *service.RecommendationService
+}
+
+func NewRecommendationHandler(db *gorm.DB) *RecommendationHandler {
+	return &RecommendationHandler{
+		service: service.NewRecommendationService(db, service.DefaultCalendar()),
+	}
+}
+
+// GetRecommendations 获取推荐的下一篇文章
+// GET /api/v1/recommendations?limit=10
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	result, err := h.service.Recommend(userID.(uint), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取推荐失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
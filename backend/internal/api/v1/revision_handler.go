@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"voicepaper/config"
+	"voicepaper/internal/service"
+	"voicepaper/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RevisionHandler struct {
+	service *service.ArticleRevisionService
+}
+
+func NewRevisionHandler(db *gorm.DB) *RevisionHandler {
+	cfg := config.GetConfig()
+	var st storage.Storage
+	var err error
+
+	if cfg.Storage.Type == "oss" {
+		st, err = storage.NewOSSStorage(cfg)
+		if err != nil {
+			st = storage.NewLocalStorage(cfg)
+		}
+	} else {
+		st = storage.NewLocalStorage(cfg)
+	}
+
+	return &RevisionHandler{
+		service: service.NewArticleRevisionService(db, st),
+	}
+}
+
+// ListRevisions 获取文章的修订列表
+// GET /api/v1/admin/articles/:id/revisions
+func (h *RevisionHandler) ListRevisions(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(uint(articleID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取修订列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision 获取修订详情（含 Markdown 和句子快照）
+// GET /api/v1/admin/articles/:id/revisions/:rev
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	revisionNo, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的版本号"})
+		return
+	}
+
+	revision, err := h.service.GetRevision(uint(articleID), revisionNo)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "修订不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// CreateRevision 创建草稿修订
+// POST /api/v1/admin/articles/:id/revisions
+// body: {"title": "...", "markdown": "...", "sentences": [{"text": "...", "translation": "..."}], "note": "修正拼写"}
+// 没有提供的 title、markdown、sentences 沿用最新修订的内容
+func (h *RevisionHandler) CreateRevision(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var req service.RevisionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	var editorID uint
+	if userID, exists := c.Get("user_id"); exists {
+		editorID, _ = userID.(uint)
+	}
+
+	revision, err := h.service.CreateRevision(uint(articleID), editorID, req)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions 比较两个修订
+// GET /api/v1/admin/articles/:id/revisions/diff?from=1&to=2
+// to 默认为最新修订，from 默认为 to 的上一个版本
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	to, _ := strconv.Atoi(c.Query("to"))
+	if to <= 0 {
+		revisions, err := h.service.ListRevisions(uint(articleID))
+		if err != nil || len(revisions) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章没有修订"})
+			return
+		}
+		to = revisions[0].RevisionNo
+	}
+	from, _ := strconv.Atoi(c.Query("from"))
+	if from <= 0 {
+		from = to - 1
+	}
+	if from <= 0 || from == to {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "需要两个不同的版本才能比较"})
+		return
+	}
+
+	diff, err := h.service.Diff(uint(articleID), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// PublishRevision 发布修订（同步句子并迁移依赖数据）
+// POST /api/v1/admin/articles/:id/revisions/:rev/publish
+func (h *RevisionHandler) PublishRevision(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	revisionNo, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的版本号"})
+		return
+	}
+
+	result, err := h.service.Publish(uint(articleID), revisionNo)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "修订不存在"})
+			return
+		}
+		if errors.Is(err, service.ErrRevisionOutdated) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "发布失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
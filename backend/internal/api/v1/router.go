@@ -18,9 +18,12 @@ import (
 
 type ArticleHandler struct {
 	repo       *repository.ArticleRepository
+	tagRepo    *repository.TagRepository
+	simRepo    *repository.SimilarityRepository
 	ttsService *service.TTSService
 	storage    storage.Storage
 	isOSS      bool
+	calendar   *service.CalendarService
 }
 
 func NewArticleHandler() *ArticleHandler {
@@ -48,14 +51,23 @@ func NewArticleHandler() *ArticleHandler {
 
 	return &ArticleHandler{
 		repo:       repository.NewArticleRepository(),
+		tagRepo:    repository.NewTagRepository(repository.DB),
+		simRepo:    repository.NewSimilarityRepository(repository.DB),
 		ttsService: service.NewTTSService(),
 		storage:    st,
 		isOSS:      isOSS,
+		calendar:   service.DefaultCalendar(),
 	}
 }
 
 // GetArticles 获取文章列表
+// 支持按标签筛选：?tags=economy,tech&tag_mode=and|or（默认or）
 func (h *ArticleHandler) GetArticles(c *gin.Context) {
+	if tags := c.Query("tags"); tags != "" {
+		h.getArticlesByTags(c, tags, c.DefaultQuery("tag_mode", "or"))
+		return
+	}
+
 	// 判断是否是小程序请求
 	userAgent := c.GetHeader("User-Agent")
 	isMiniProgram := strings.Contains(userAgent, "MicroMessenger") || strings.Contains(userAgent, "miniProgram")
@@ -63,7 +75,8 @@ func (h *ArticleHandler) GetArticles(c *gin.Context) {
 		isMiniProgram = true
 	}
 
-	articles, err := h.repo.GetAll(isMiniProgram)
+	// 按用户时区的今天判断文章是否已发布（未登录时为服务器时区）
+	articles, err := h.repo.GetAll(isMiniProgram, h.calendar.TodayString(c.GetUint("user_id")))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -77,6 +90,52 @@ func (h *ArticleHandler) GetArticles(c *gin.Context) {
 	c.JSON(http.StatusOK, articles)
 }
 
+// getArticlesByTags 按标签筛选文章
+func (h *ArticleHandler) getArticlesByTags(c *gin.Context, tagsParam, mode string) {
+	if mode != "and" && mode != "or" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tag_mode 只能为 and 或 or"})
+		return
+	}
+
+	var slugs []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Split(tagsParam, ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" && !seen[s] {
+			seen[s] = true
+			slugs = append(slugs, s)
+		}
+	}
+
+	tags, err := h.tagRepo.FindBySlugs(slugs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// AND 模式下有不存在的标签时，不可能有文章同时满足
+	if len(tags) == 0 || (mode == "and" && len(tags) < len(slugs)) {
+		c.JSON(http.StatusOK, []interface{}{})
+		return
+	}
+
+	tagIDs := make([]uint, 0, len(tags))
+	for _, t := range tags {
+		tagIDs = append(tagIDs, t.ID)
+	}
+	articleIDs, err := h.tagRepo.GetArticleIDsByTags(tagIDs, mode == "and")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	articles, err := h.repo.GetPublishedByIDs(articleIDs, h.calendar.TodayString(c.GetUint("user_id")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, articles)
+}
+
 // GetArticle 获取文章详情 (包含音频、时间轴、文章内容)
 func (h *ArticleHandler) GetArticle(c *gin.Context) {
 	idStr := c.Param("id")
@@ -358,6 +417,43 @@ func (h *ArticleHandler) GetSentences(c *gin.Context) {
 	c.JSON(http.StatusOK, sentences)
 }
 
+// GetRelatedArticles 获取相关文章（基于离线计算的 TF-IDF 相似度）
+// GET /api/v1/articles/:id/related?limit=5
+func (h *ArticleHandler) GetRelatedArticles(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	rows, err := h.simRepo.GetRelated(uint(id), h.calendar.TodayString(c.GetUint("user_id")), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	related := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		var terms []string
+		if row.SharedTerms != "" {
+			terms = strings.Split(row.SharedTerms, ",")
+		}
+		related = append(related, gin.H{
+			"article":      row.RelatedArticle,
+			"score":        row.Score,
+			"shared_terms": terms,
+		})
+	}
+
+	c.JSON(http.StatusOK, related)
+}
+
 // GetCategories 获取所有分类/合集（包括文章和场景对话）
 func (h *ArticleHandler) GetCategories(c *gin.Context) {
 	var categories []struct {
@@ -455,6 +551,10 @@ func RegisterRoutes(r *gin.Engine) {
 	reportHandler := NewReportHandler(repository.DB)
 	appConfigHandler := NewAppConfigHandler()
 	bookHandler := NewBookHandler() // 添加书籍处理器
+	recommendationHandler := NewRecommendationHandler(repository.DB)
+	seriesHandler := NewSeriesHandler(repository.DB)
+	tagHandler := NewTagHandler(repository.DB)
+	revisionHandler := NewRevisionHandler(repository.DB)
 
 	v1 := r.Group("/api/v1")
 	{
@@ -476,12 +576,38 @@ func RegisterRoutes(r *gin.Engine) {
 		v1.GET("/categories", articleHandler.GetCategories)                      // 获取所有分类
 		v1.GET("/categories/:id/articles", articleHandler.GetArticlesByCategory) // 获取分类下的文章
 
+		// 系列（连载/课程）相关路由 - continue 必须放在 :id 之前
+		v1.GET("/series", seriesHandler.GetSeriesList)                                       // 获取系列列表
+		v1.GET("/series/continue", authHandler.AuthMiddleware(), seriesHandler.GetContinue)  // 继续学习的系列
+		v1.GET("/series/:id", authHandler.OptionalAuthMiddleware(), seriesHandler.GetSeries) // 系列详情（登录后附带进度）
+
+		// 标签/话题相关路由
+		v1.GET("/tags", tagHandler.GetTags)                                                      // 获取标签列表（含文章数）
+		v1.GET("/tags/subscriptions", authHandler.AuthMiddleware(), tagHandler.GetSubscriptions) // 我订阅的话题
+		v1.POST("/tags/:id/subscribe", authHandler.AuthMiddleware(), tagHandler.Subscribe)       // 订阅话题
+		v1.DELETE("/tags/:id/subscribe", authHandler.AuthMiddleware(), tagHandler.Unsubscribe)   // 取消订阅
+
 		// 单词书相关路由
 		// 注意：更具体的路由要放在更通用的路由之前，避免路由冲突
 		v1.GET("/wordbooks", wordbookHandler.GetWordbooks)
 		v1.GET("/wordbooks/debug/books", wordbookHandler.DebugWordbookBooks) // 调试API：检查vp_wordbook_books表数据
 		v1.POST("/wordbooks/progress", authHandler.AuthMiddleware(), wordbookHandler.SaveProgress)
 
+		// 单词、例句发音（TTS 生成并缓存）
+		v1.GET("/pronunciations", authHandler.AuthMiddleware(), GetPronunciation)
+
+		// 用户自建单词书（私有 / 链接分享 / 公开）
+		v1.GET("/wordbooks/custom", authHandler.AuthMiddleware(), GetMyWordbooks)
+		v1.POST("/wordbooks/custom", authHandler.AuthMiddleware(), CreateCustomWordbook)
+		v1.GET("/wordbooks/custom/public", GetPublicWordbooks)
+		v1.GET("/wordbooks/custom/shared/:token", authHandler.OptionalAuthMiddleware(), GetSharedWordbook)
+		v1.POST("/wordbooks/custom/shared/:token/join", authHandler.AuthMiddleware(), JoinSharedWordbook)
+		v1.GET("/wordbooks/custom/:type", authHandler.OptionalAuthMiddleware(), GetCustomWordbook)
+		v1.PUT("/wordbooks/custom/:type", authHandler.AuthMiddleware(), UpdateCustomWordbook)
+		v1.DELETE("/wordbooks/custom/:type", authHandler.AuthMiddleware(), DeleteCustomWordbook)
+		v1.POST("/wordbooks/custom/:type/words", authHandler.AuthMiddleware(), AddCustomWordbookWords)
+		v1.DELETE("/wordbooks/custom/:type/words/:word_id", authHandler.AuthMiddleware(), RemoveCustomWordbookWord)
+
 		// 顺序/乱序学习相关路由（需要认证）- 必须放在 :wordbook_id 和 :type 之前
 		v1.GET("/wordbooks/order/:type/words", authHandler.AuthMiddleware(), wordbookHandler.GetWordsWithOrder)
 
@@ -494,17 +620,25 @@ func RegisterRoutes(r *gin.Engine) {
 		v1.POST("/wordbooks/seq/:type/order", authHandler.AuthMiddleware(), wordbookHandler.SwitchOrderMode)
 		v1.PUT("/wordbooks/seq/:type/order/index", authHandler.AuthMiddleware(), wordbookHandler.UpdateOrderIndex)
 
+		// 间隔重复学习（每日计划 = 到期复习 + 新词）
+		v1.GET("/wordbooks/study/:type/plan", authHandler.AuthMiddleware(), wordbookHandler.GetStudyPlan)
+		v1.PUT("/wordbooks/study/:type/plan", authHandler.AuthMiddleware(), wordbookHandler.UpdateStudyPlan)
+		v1.POST("/wordbooks/study/:type/answer", authHandler.AuthMiddleware(), wordbookHandler.AnswerStudyWord)
+		v1.GET("/wordbooks/study/:type/progress", authHandler.AuthMiddleware(), wordbookHandler.GetStudyProgress)
+
 		// 进度和单词列表
 		v1.GET("/wordbooks/:type/progress", authHandler.AuthMiddleware(), wordbookHandler.GetProgress)
-		v1.GET("/wordbooks/:type/words", wordbookHandler.GetWords)
+		v1.GET("/wordbooks/:type/words", authHandler.OptionalAuthMiddleware(), wordbookHandler.GetWords)
 
 		// 文章相关路由
 		// 注意：更具体的路由要放在更通用的路由之前
-		v1.GET("/articles", articleHandler.GetArticles)
+		v1.GET("/articles", authHandler.OptionalAuthMiddleware(), articleHandler.GetArticles)
 		v1.GET("/articles/:id/timeline", articleHandler.GetArticleTimeline)
 		v1.GET("/articles/:id/export/pdf", articleHandler.ExportArticlePDF) // 导出文章PDF
 		v1.GET("/articles/:id/words", articleHandler.GetWords)              // 获取文章的重点单词
 		v1.GET("/articles/:id/sentences", articleHandler.GetSentences)      // 获取文章的句子
+		v1.GET("/articles/:id/tags", tagHandler.GetArticleTags)             // 获取文章的标签
+		v1.GET("/articles/:id/related", articleHandler.GetRelatedArticles)  // 获取相关文章
 		v1.GET("/articles/:id", articleHandler.GetArticle)                  // 这个要放在最后，因为它是通用路由
 		v1.POST("/articles", articleHandler.CreateArticle)
 
@@ -522,6 +656,28 @@ func RegisterRoutes(r *gin.Engine) {
 			dictation.DELETE("/progress/:article_id", authHandler.AuthMiddleware(), dictationHandler.ResetProgress) // 重置进度
 		}
 
+		// 管理员路由
+		admin := v1.Group("/admin", authHandler.AuthMiddleware(), authHandler.AdminMiddleware())
+		{
+			admin.POST("/tags", tagHandler.CreateTag)                  // 创建标签
+			admin.PUT("/tags/:id", tagHandler.UpdateTag)               // 更新标签
+			admin.DELETE("/tags/:id", tagHandler.DeleteTag)            // 删除标签
+			admin.PUT("/articles/:id/tags", tagHandler.SetArticleTags) // 设置文章标签
+
+			// 文章修订 - diff 必须放在 :rev 之前
+			admin.GET("/articles/:id/revisions", revisionHandler.ListRevisions)                 // 修订列表
+			admin.POST("/articles/:id/revisions", revisionHandler.CreateRevision)               // 创建草稿修订
+			admin.GET("/articles/:id/revisions/diff", revisionHandler.DiffRevisions)            // 比较修订
+			admin.GET("/articles/:id/revisions/:rev", revisionHandler.GetRevision)              // 修订详情
+			admin.POST("/articles/:id/revisions/:rev/publish", revisionHandler.PublishRevision) // 发布修订
+
+			// 称号配置
+			admin.GET("/titles", titleHandler.AdminListTitles)         // 称号列表（含未启用和隐藏的）
+			admin.POST("/titles", titleHandler.AdminCreateTitle)       // 创建称号
+			admin.PUT("/titles/:id", titleHandler.AdminUpdateTitle)    // 更新称号
+			admin.DELETE("/titles/:id", titleHandler.AdminDeleteTitle) // 删除称号
+		}
+
 		// 认证相关路由
 		auth := v1.Group("/auth")
 		{
@@ -588,6 +744,16 @@ func RegisterRoutes(r *gin.Engine) {
 			checkIn.POST("/makeup-card/use", checkInHandler.UseMakeupCard) // 使用补签卡
 		}
 
+		// 连续学习（签到、复习、学习时长、默写），冻结卡和补签卡
+		streaks := v1.Group("/streaks")
+		streaks.Use(authHandler.AuthMiddleware())
+		{
+			streaks.GET("", GetStreaks)                   // 所有类型的连续学习
+			streaks.GET("/:kind", GetStreak)              // 某种连续学习及历史
+			streaks.POST("/freezes/buy", BuyStreakFreeze) // 购买冻结卡
+			streaks.POST("/repair", RepairStreak)         // 使用补签卡修复中断
+		}
+
 		// 称号相关路由
 		titles := v1.Group("/titles")
 		{
@@ -615,6 +781,9 @@ func RegisterRoutes(r *gin.Engine) {
 			readingProgress.POST("/quick", readingProgressHandler.QuickSave)        // 快速保存进度（高频更新用）
 		}
 
+		// 文章推荐（需要认证）
+		v1.GET("/recommendations", authHandler.AuthMiddleware(), recommendationHandler.GetRecommendations) // 推荐下一篇文章
+
 		// 排名相关路由
 		ranking := v1.Group("/ranking")
 		{
@@ -669,9 +838,27 @@ func RegisterRoutes(r *gin.Engine) {
 			vocabulary.POST("/:id/star", ToggleVocabularyStar) // 切换标星
 			vocabulary.DELETE("/batch", BatchDeleteVocabulary) // 批量删除
 
+			// 导入导出（CSV / JSON / Anki .apkg）
+			vocabulary.GET("/export", ExportVocabulary)  // 导出生词本
+			vocabulary.POST("/import", ImportVocabulary) // 导入生词本
+
 			// 复习功能
-			vocabulary.GET("/review/today", GetTodayReviewList) // 获取今日待复习
-			vocabulary.POST("/:id/review", SubmitReview)        // 提交复习结果
+			vocabulary.GET("/review/today", GetTodayReviewList)            // 获取今日待复习
+			vocabulary.POST("/:id/review", SubmitReview)                   // 提交复习结果
+			vocabulary.GET("/review/session", GetReviewSession)            // 获取今日复习会话（可跨设备继续）
+			vocabulary.POST("/review/session/answer", AnswerReviewSession) // 在会话中提交答案
+
+			// 服务端出题和判分
+			vocabulary.GET("/:id/exercise", GetVocabularyExercise)            // 生成练习（选择/拼写/完形填空/听音选义）
+			vocabulary.POST("/exercises/:exercise_id/answer", AnswerExercise) // 提交练习作答
+
+			// 复习算法设置（SM-2 / FSRS）
+			vocabulary.GET("/srs/settings", GetSRSSettings)       // 获取算法设置
+			vocabulary.PUT("/srs/settings", UpdateSRSSettings)    // 更新算法设置
+			vocabulary.POST("/srs/migrate", MigrateSRSCards)      // 按复习历史重新计算卡片
+			vocabulary.POST("/srs/optimize", OptimizeSRSParams)   // 后台按复习历史拟合 FSRS 参数
+			vocabulary.GET("/srs/optimize", GetSRSOptimizeStatus) // 查询拟合任务
+			vocabulary.DELETE("/srs/optimize", CancelSRSOptimize) // 取消拟合任务
 
 			// 每日统计
 			vocabulary.GET("/stats/daily", GetVocabularyDailyStats) // 获取每日学习统计
@@ -685,5 +872,38 @@ func RegisterRoutes(r *gin.Engine) {
 			vocabulary.POST("/folders/:id/items", AddVocabularyToFolder)                  // 添加生词到文件夹
 			vocabulary.DELETE("/folders/:id/items/:vocab_id", RemoveVocabularyFromFolder) // 从文件夹移除生词
 		}
+
+		// 错题本（默写和生词复习中的错误）
+		mistakes := v1.Group("/mistakes")
+		mistakes.Use(authHandler.AuthMiddleware())
+		{
+			mistakes.GET("", ListMistakes)             // 获取错题列表（支持筛选）
+			mistakes.POST("/push", PushMistakes)       // 推送到生词复习队列
+			mistakes.POST("/rebuild", RebuildMistakes) // 从历史记录回填
+		}
+
+		// 学习目标（目标单词书、截止日期、每日时长和新词数）
+		goals := v1.Group("/goals")
+		goals.Use(authHandler.AuthMiddleware())
+		{
+			goals.GET("", ListGoals)                  // 获取学习目标列表
+			goals.POST("", CreateGoal)                // 创建学习目标
+			goals.GET("/today", GetTodayGoals)        // 今日配额和完成情况
+			goals.GET("/reminders", GetGoalReminders) // 今日未完成的目标提醒
+			goals.GET("/:id", GetGoal)                // 获取学习目标详情
+			goals.PUT("/:id", UpdateGoal)             // 修改或放弃学习目标
+			goals.DELETE("/:id", DeleteGoal)          // 删除学习目标
+			goals.GET("/:id/history", GetGoalHistory) // 每日完成情况
+		}
+
+		// 站内通知（订阅话题的新文章等，由后台任务生成）
+		notifications := v1.Group("/notifications")
+		notifications.Use(authHandler.AuthMiddleware())
+		{
+			notifications.GET("", ListNotifications)                       // 获取通知列表
+			notifications.GET("/unread-count", GetUnreadNotificationCount) // 未读通知数
+			notifications.POST("/read-all", MarkAllNotificationsRead)      // 全部标记为已读
+			notifications.POST("/:id/read", MarkNotificationRead)          // 标记为已读
+		}
 	}
 }
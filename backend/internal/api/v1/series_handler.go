@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SeriesHandler struct {
+	service *service.SeriesService
+}
+
+func NewSeriesHandler(db *gorm.DB) *SeriesHandler {
+	return &SeriesHandler{
+		service: service.NewSeriesService(db),
+	}
+}
+
+// GetSeriesList 获取系列列表
+// GET /api/v1/series?category_id=1&page=1&page_size=20
+func (h *SeriesHandler) GetSeriesList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	var categoryID *uint
+	if cid := c.Query("category_id"); cid != "" {
+		id, err := strconv.ParseUint(cid, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
+			return
+		}
+		v := uint(id)
+		categoryID = &v
+	}
+
+	list, total, err := h.service.GetSeriesList(categoryID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"series":    list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetSeries 获取系列详情及剧集列表（已登录时附带每集的学习状态和继续学习位置）
+// GET /api/v1/series/:id
+func (h *SeriesHandler) GetSeries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的系列ID"})
+		return
+	}
+
+	var uid uint
+	if userID, exists := c.Get("user_id"); exists {
+		uid, _ = userID.(uint)
+	}
+
+	result, err := h.service.GetSeriesDetail(uint(id), uid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "系列不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列详情失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetContinue 获取正在学习的系列及继续学习的剧集
+// GET /api/v1/series/continue?limit=10
+func (h *SeriesHandler) GetContinue(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	list, err := h.service.GetContinueList(userID.(uint), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取继续学习列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": list})
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	streakService     *service.StreakService
+	streakServiceOnce sync.Once
+)
+
+// getStreakService 惰性初始化连续学习服务
+func getStreakService() *service.StreakService {
+	streakServiceOnce.Do(func() {
+		streakService = service.NewStreakService(repository.DB, service.DefaultCalendar())
+	})
+	return streakService
+}
+
+// GetStreaks 获取所有类型的连续学习（签到、复习、学习时长、默写）
+// GET /api/v1/streaks
+func GetStreaks(c *gin.Context) {
+	overview, err := getStreakService().GetOverview(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取连续学习失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": overview})
+}
+
+// GetStreak 获取某种连续学习及历史
+// GET /api/v1/streaks/:kind?days=365
+func GetStreak(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+	view, err := getStreakService().GetStreak(c.GetUint("user_id"), c.Param("kind"), days)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStreakKind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取连续学习失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": view})
+}
+
+// BuyStreakFreeze 用积分购买冻结卡
+// POST /api/v1/streaks/freezes/buy
+func BuyStreakFreeze(c *gin.Context) {
+	var req struct {
+		Count int `json:"count"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		req.Count = 1 // 默认1张
+	}
+
+	result, err := getStreakService().BuyFreeze(c.GetUint("user_id"), req.Count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RepairStreak 使用补签卡修复某种连续学习的中断
+// POST /api/v1/streaks/repair {"kind":"review","date":"2024-01-02"}
+func RepairStreak(c *gin.Context) {
+	var req struct {
+		Kind string `json:"kind" binding:"required"`
+		Date string `json:"date" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请提供连续学习类型和修复日期"})
+		return
+	}
+
+	result, err := getStreakService().Repair(c.GetUint("user_id"), req.Kind, req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// slugPattern 标签标识只允许小写字母、数字和连字符
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type TagHandler struct {
+	repo        *repository.TagRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewTagHandler(db *gorm.DB) *TagHandler {
+	return &TagHandler{
+		repo:        repository.NewTagRepository(db),
+		articleRepo: repository.NewArticleRepository(),
+	}
+}
+
+// GetTags 获取标签列表（含文章数）
+// GET /api/v1/tags?type=topic
+func (h *TagHandler) GetTags(c *gin.Context) {
+	tagType := c.Query("type")
+	if tagType != "" && tagType != model.TagTypeTopic && tagType != model.TagTypeSource {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签类型"})
+		return
+	}
+
+	tags, err := h.repo.GetAllWithCount(tagType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetArticleTags 获取文章的标签
+// GET /api/v1/articles/:id/tags
+func (h *TagHandler) GetArticleTags(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	tags, err := h.repo.GetArticleTags(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章标签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetSubscriptions 获取我订阅的话题
+// GET /api/v1/tags/subscriptions
+func (h *TagHandler) GetSubscriptions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	subs, err := h.repo.GetSubscriptions(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取订阅失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// Subscribe 订阅话题
+// POST /api/v1/tags/:id/subscribe  body: {"notify_enabled": true}
+func (h *TagHandler) Subscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	var req struct {
+		NotifyEnabled *bool `json:"notify_enabled"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	notify := true
+	if req.NotifyEnabled != nil {
+		notify = *req.NotifyEnabled
+	}
+
+	tag, err := h.repo.FindByID(uint(tagID))
+	if err != nil || !tag.IsActive {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	sub, err := h.repo.Subscribe(userID.(uint), tag.ID, notify)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "订阅失败", "details": err.Error()})
+		return
+	}
+	sub.Tag = *tag
+
+	c.JSON(http.StatusOK, gin.H{"message": "订阅成功", "subscription": sub})
+}
+
+// Unsubscribe 取消订阅话题
+// DELETE /api/v1/tags/:id/subscribe
+func (h *TagHandler) Unsubscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	if err := h.repo.Unsubscribe(userID.(uint), uint(tagID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消订阅失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消订阅"})
+}
+
+// tagRequest 创建/更新标签的请求参数
+type tagRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Slug        string `json:"slug" binding:"required"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	SortOrder   int    `json:"sort_order"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+func (req *tagRequest) validate() string {
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(req.Slug) {
+		return "slug 只能包含小写字母、数字和连字符"
+	}
+	if req.Type == "" {
+		req.Type = model.TagTypeTopic
+	}
+	if req.Type != model.TagTypeTopic && req.Type != model.TagTypeSource {
+		return "无效的标签类型"
+	}
+	return ""
+}
+
+// CreateTag 创建标签（管理员）
+// POST /api/v1/admin/tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	tag := &model.Tag{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        req.Slug,
+		Type:        req.Type,
+		Description: req.Description,
+		Icon:        req.Icon,
+		SortOrder:   req.SortOrder,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := h.repo.Create(tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建标签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// UpdateTag 更新标签（管理员）
+// PUT /api/v1/admin/tags/:id
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	tag, err := h.repo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	tag.Name = strings.TrimSpace(req.Name)
+	tag.Slug = req.Slug
+	tag.Type = req.Type
+	tag.Description = req.Description
+	tag.Icon = req.Icon
+	tag.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		tag.IsActive = *req.IsActive
+	}
+	if err := h.repo.Update(tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新标签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag 删除标签（管理员）
+// DELETE /api/v1/admin/tags/:id
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	if err := h.repo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除标签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// SetArticleTags 设置文章标签（管理员，覆盖原有标签）
+// PUT /api/v1/admin/articles/:id/tags  body: {"tag_ids": [1,2]}
+func (h *TagHandler) SetArticleTags(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var req struct {
+		TagIDs []uint `json:"tag_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	if _, err := h.articleRepo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	if err := h.repo.SetArticleTags(uint(id), req.TagIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置文章标签失败", "details": err.Error()})
+		return
+	}
+
+	tags, _ := h.repo.GetArticleTags(uint(id))
+	c.JSON(http.StatusOK, gin.H{"message": "设置成功", "tags": tags})
+}
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 	"voicepaper/internal/service"
@@ -15,7 +17,7 @@ type TitleHandler struct {
 
 func NewTitleHandler() *TitleHandler {
 	return &TitleHandler{
-		titleService: service.NewTitleService(repository.DB),
+		titleService: service.NewTitleService(repository.DB, service.DefaultCalendar()),
 	}
 }
 
@@ -148,3 +150,88 @@ func (h *TitleHandler) GetTitlesByCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, titles)
 }
+
+// AdminListTitles 获取所有称号配置（管理员，含未启用和隐藏的）
+// GET /api/v1/admin/titles
+func (h *TitleHandler) AdminListTitles(c *gin.Context) {
+	titles, err := h.titleService.ListTitleConfigs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取称号列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, titles)
+}
+
+// AdminCreateTitle 创建称号配置（管理员）
+// POST /api/v1/admin/titles
+func (h *TitleHandler) AdminCreateTitle(c *gin.Context) {
+	var req service.TitleConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	title, err := h.titleService.CreateTitleConfig(&req)
+	if err != nil {
+		respondTitleConfigError(c, "创建称号失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, title)
+}
+
+// AdminUpdateTitle 更新称号配置（管理员）
+// PUT /api/v1/admin/titles/:id
+func (h *TitleHandler) AdminUpdateTitle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的称号ID"})
+		return
+	}
+
+	var req service.TitleConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	title, err := h.titleService.UpdateTitleConfig(uint(id), &req)
+	if err != nil {
+		respondTitleConfigError(c, "更新称号失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, title)
+}
+
+// AdminDeleteTitle 删除称号配置（管理员，用户已获得的称号保留）
+// DELETE /api/v1/admin/titles/:id
+func (h *TitleHandler) AdminDeleteTitle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的称号ID"})
+		return
+	}
+
+	if err := h.titleService.DeleteTitleConfig(uint(id)); err != nil {
+		respondTitleConfigError(c, "删除称号失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// respondTitleConfigError 按错误类型返回称号配置接口的错误
+func respondTitleConfigError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrTitleConfigNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTitleKeyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTitleConfig), errors.Is(err, service.ErrInvalidTitleRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -8,6 +10,7 @@ import (
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 	"voicepaper/internal/service"
+	"voicepaper/pkg/srs"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,11 +23,37 @@ var (
 // getVocabularyService 惰性初始化生词本服务
 func getVocabularyService() *service.VocabularyService {
 	vocabularyServiceOnce.Do(func() {
-		vocabularyService = service.NewVocabularyService()
+		vocabularyService = service.NewVocabularyService(service.DefaultCalendar())
 	})
 	return vocabularyService
 }
 
+var (
+	srsService     *service.SRSService
+	srsServiceOnce sync.Once
+)
+
+// getSRSService 惰性初始化复习算法设置服务
+func getSRSService() *service.SRSService {
+	srsServiceOnce.Do(func() {
+		srsService = service.NewSRSService(repository.DB)
+	})
+	return srsService
+}
+
+var (
+	reviewSessionService     *service.ReviewSessionService
+	reviewSessionServiceOnce sync.Once
+)
+
+// getReviewSessionService 惰性初始化复习会话服务
+func getReviewSessionService() *service.ReviewSessionService {
+	reviewSessionServiceOnce.Do(func() {
+		reviewSessionService = service.NewReviewSessionService(repository.DB, service.DefaultCalendar())
+	})
+	return reviewSessionService
+}
+
 // ==================== 生词 CRUD ====================
 
 // AddVocabulary 添加生词
@@ -42,16 +71,22 @@ func AddVocabulary(c *gin.Context) {
 		return
 	}
 
-	vocab, err := getVocabularyService().AddVocabulary(userID, &req)
+	vocab, related, err := getVocabularyService().AddVocabulary(userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	resp := gin.H{
 		"message": "添加成功",
 		"data":    vocab,
-	})
+	}
+	if related != nil {
+		// 同一单词的其他形式已在生词本中，只提示不拦截
+		resp["related"] = related
+		resp["hint"] = fmt.Sprintf("生词本中已有该单词的其他形式「%s」", related.Content)
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 // GetVocabulary 获取单个生词
@@ -195,6 +230,7 @@ func ListVocabulary(c *gin.Context) {
 	if vocabs == nil {
 		vocabs = make([]model.Vocabulary, 0)
 	}
+	getPronunciationService().AttachVocabularyAudio(vocabs)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":   vocabs,
@@ -278,7 +314,7 @@ func BatchDeleteVocabulary(c *gin.Context) {
 // ==================== 复习功能 ====================
 
 // GetTodayReviewList 获取今日待复习列表
-// GET /api/v1/vocabulary/review/today
+// GET /api/v1/vocabulary/review/today?folder_id=
 func GetTodayReviewList(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
@@ -293,7 +329,22 @@ func GetTodayReviewList(c *gin.Context) {
 		}
 	}
 
-	vocabs, err := getVocabularyService().GetTodayReviewList(userID, limit)
+	// folder_id 指定复习范围：普通文件夹复习其中的生词，智能文件夹按筛选条件
+	var filter *repository.VocabularyFilter
+	if folderIDStr := c.Query("folder_id"); folderIDStr != "" {
+		folderID, err := strconv.ParseUint(folderIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件夹ID"})
+			return
+		}
+		filter, err = getVocabularyService().FolderFilter(uint(folderID), userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	vocabs, err := getVocabularyService().GetTodayReviewList(userID, limit, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败"})
 		return
@@ -338,9 +389,187 @@ func SubmitReview(c *gin.Context) {
 		"data":          result.Vocabulary,
 		"points_earned": result.PointsEarned,
 		"total_points":  result.TotalPoints,
+		"new_titles":    result.NewTitles,
+	})
+}
+
+// GetReviewSession 获取今日复习会话及当前卡片（不存在时按每日上限生成）
+// GET /api/v1/vocabulary/review/session
+func GetReviewSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	view, err := getReviewSessionService().Current(userID)
+	if err != nil {
+		log.Printf("❌ 获取用户 %d 的复习会话失败: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取复习会话失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": view})
+}
+
+// AnswerReviewSession 在复习会话中提交答案，返回下一张卡片
+// POST /api/v1/vocabulary/review/session/answer
+// body: {"vocabulary_id": 1, "quality": 4, "review_type": "card"}
+func AnswerReviewSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	var req service.SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+	if req.VocabularyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 vocabulary_id"})
+		return
+	}
+
+	result, err := getReviewSessionService().Answer(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrCardNotInSession) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "复习完成",
+		"data":          result.Review.Vocabulary,
+		"points_earned": result.Review.PointsEarned,
+		"total_points":  result.Review.TotalPoints,
+		"new_titles":    result.Review.NewTitles,
+		"next":          result.Next,
+	})
+}
+
+// GetSRSSettings 获取复习算法设置
+// GET /api/v1/vocabulary/srs/settings
+func GetSRSSettings(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	settings, err := getSRSService().GetSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取设置失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// UpdateSRSSettings 更新复习算法设置（切换算法时按复习历史重新计算所有卡片）
+// PUT /api/v1/vocabulary/srs/settings
+// body: {"algorithm": "fsrs", "request_retention": 0.9, "weights": [...]}
+func UpdateSRSSettings(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	var req service.UpdateSRSSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	settings, migration, err := getSRSService().UpdateSettings(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "设置已更新",
+		"data":      settings,
+		"migration": migration,
 	})
 }
 
+// MigrateSRSCards 用当前算法重放复习历史，重新计算所有卡片的记忆状态
+// POST /api/v1/vocabulary/srs/migrate
+func MigrateSRSCards(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	result, err := getSRSService().MigrateCards(userID)
+	if err != nil {
+		log.Printf("❌ 迁移用户 %d 的卡片失败: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "迁移失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "迁移完成",
+		"data":    result,
+	})
+}
+
+// OptimizeSRSParams 在后台根据复习历史拟合 FSRS 参数（每个用户每 24 小时一次）
+// POST /api/v1/vocabulary/srs/optimize
+// 立即返回任务状态，通过 GET /api/v1/vocabulary/srs/optimize 查询结果（预测保持率与实际的对比）
+func OptimizeSRSParams(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	job, err := getSRSService().StartOptimize(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrOptimizeTooSoon) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ 启动用户 %d 的 FSRS 参数拟合失败: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "启动拟合失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "拟合任务已开始",
+		"data":        job,
+		"min_reviews": srs.DefaultOptimizeOptions().MinReviews,
+	})
+}
+
+// GetSRSOptimizeStatus 查询最近一次参数拟合任务
+// GET /api/v1/vocabulary/srs/optimize
+func GetSRSOptimizeStatus(c *gin.Context) {
+	job := getSRSService().OptimizeStatus(c.GetUint("user_id"))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "没有参数拟合任务"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// CancelSRSOptimize 取消进行中的参数拟合任务
+// DELETE /api/v1/vocabulary/srs/optimize
+func CancelSRSOptimize(c *gin.Context) {
+	if err := getSRSService().CancelOptimize(c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已取消"})
+}
+
 // ==================== 文件夹管理 ====================
 
 // CreateFolder 创建文件夹
@@ -532,7 +761,7 @@ func ListFolderVocabulary(c *gin.Context) {
 		}
 	}
 
-	vocabs, total, err := getVocabularyService().ListByFolder(uint(folderID), limit, offset)
+	vocabs, total, err := getVocabularyService().ListByFolder(uint(folderID), userID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败"})
 		return
@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"voicepaper/config"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
+	"voicepaper/internal/storage"
+	"voicepaper/pkg/apkg"
+	"voicepaper/pkg/sqlite"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 20 << 20
+
+var (
+	vocabularyTransferService     *service.VocabularyTransferService
+	vocabularyTransferServiceOnce sync.Once
+)
+
+// getVocabularyTransferService 惰性初始化生词本导入导出服务
+func getVocabularyTransferService() *service.VocabularyTransferService {
+	vocabularyTransferServiceOnce.Do(func() {
+		cfg := config.GetConfig()
+		var st storage.Storage
+		var err error
+
+		if cfg.Storage.Type == "oss" {
+			st, err = storage.NewOSSStorage(cfg)
+			if err != nil {
+				st = storage.NewLocalStorage(cfg)
+			}
+		} else {
+			st = storage.NewLocalStorage(cfg)
+		}
+
+		vocabularyTransferService = service.NewVocabularyTransferService(repository.DB, st, service.DefaultCalendar())
+	})
+	return vocabularyTransferService
+}
+
+// ExportVocabulary 导出生词本
+// GET /api/v1/vocabulary/export?format=csv|json|apkg&folder_id=&media=true
+// media 仅对 apkg 生效，附带已生成的单词发音
+func ExportVocabulary(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	format := c.DefaultQuery("format", service.TransferFormatCSV)
+	folderID, ok := parseOptionalFolderID(c, c.Query("folder_id"))
+	if !ok {
+		return
+	}
+	withMedia := c.Query("media") == "true" || c.Query("media") == "1"
+
+	file, err := getVocabularyTransferService().Export(userID, format, folderID, withMedia)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedTransferFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrFolderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "导出失败", "details": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Filename))
+	c.Header("X-Export-Count", strconv.Itoa(file.Count))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// ImportVocabulary 导入生词本
+// POST /api/v1/vocabulary/import（multipart/form-data）
+// 表单字段：file 文件；format csv|json|apkg（为空时按扩展名判断）；
+// mapping 字段映射 JSON，如 {"content":"Word","meaning":"释义"}；folder_id 导入到的文件夹；
+// with_srs 是否沿用源数据中的复习间隔和易度因子
+func ImportVocabulary(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传文件", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件大小不能超过 20MB"})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "字段映射格式错误", "details": err.Error()})
+			return
+		}
+	}
+
+	folderID, ok := parseOptionalFolderID(c, c.PostForm("folder_id"))
+	if !ok {
+		return
+	}
+	withSRS, _ := strconv.ParseBool(c.DefaultPostForm("with_srs", "false"))
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败", "details": err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败", "details": err.Error()})
+		return
+	}
+
+	result, err := getVocabularyTransferService().Import(userID, data, &service.ImportOptions{
+		Format:   format,
+		Mapping:  mapping,
+		FolderID: folderID,
+		WithSRS:  withSRS,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrFolderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUnsupportedTransferFormat), errors.Is(err, service.ErrTooManyImportItems),
+			errors.Is(err, service.ErrSmartFolderReadOnly),
+			errors.Is(err, apkg.ErrNoCollection), errors.Is(err, apkg.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, sqlite.ErrNotSQLite), errors.Is(err, sqlite.ErrCorrupt):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "牌组包已损坏", "details": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "导入失败", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("成功导入 %d 条", result.Imported),
+		"data":    result,
+	})
+}
+
+// parseOptionalFolderID 解析可选的文件夹ID，格式错误时直接返回 400
+func parseOptionalFolderID(c *gin.Context, raw string) (*uint, bool) {
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件夹ID"})
+		return nil, false
+	}
+	folderID := uint(id)
+	return &folderID, true
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"voicepaper/internal/model"
@@ -12,17 +14,36 @@ import (
 )
 
 type WordbookHandler struct {
-	repo         *repository.WordbookRepository
-	pointService *service.PointService
+	repo          *repository.WordbookRepository
+	pointService  *service.PointService
+	studyService  *service.WordbookStudyService
+	customService *service.CustomWordbookService
 }
 
 func NewWordbookHandler(db *gorm.DB) *WordbookHandler {
 	return &WordbookHandler{
-		repo:         repository.NewWordbookRepository(db),
-		pointService: service.NewPointService(db),
+		repo:          repository.NewWordbookRepository(db),
+		pointService:  service.NewPointService(db),
+		studyService:  service.NewWordbookStudyService(db, service.DefaultCalendar()),
+		customService: service.NewCustomWordbookService(db),
 	}
 }
 
+// checkAccess 检查用户能否访问单词书（自建的私有单词书只有创建者能访问，仅链接可见的需要先通过链接加入）
+// 没有权限时写入 404 响应并返回 false
+func (h *WordbookHandler) checkAccess(c *gin.Context, wordType string) bool {
+	ok, err := h.customService.CanAccess(c.GetUint("user_id"), wordType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取单词书失败", "details": err.Error()})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrWordbookNotFound.Error()})
+		return false
+	}
+	return true
+}
+
 // GetWordbooks 获取所有可用的单词书列表
 func (h *WordbookHandler) GetWordbooks(c *gin.Context) {
 	wordbooks, err := h.repo.GetWordbookList()
@@ -114,6 +135,9 @@ func (h *WordbookHandler) DebugWordbookBooks(c *gin.Context) {
 // GetWords 获取单词书中的单词
 func (h *WordbookHandler) GetWords(c *gin.Context) {
 	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
@@ -127,6 +151,7 @@ func (h *WordbookHandler) GetWords(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取单词失败"})
 		return
 	}
+	getPronunciationService().AttachWordbookAudio(words)
 
 	c.JSON(http.StatusOK, gin.H{
 		"words": words,
@@ -225,6 +250,9 @@ func (h *WordbookHandler) ImportWordToVocabulary(c *gin.Context) {
 func (h *WordbookHandler) GetUserOrder(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
 
 	order, err := h.repo.GetUserOrder(userID.(uint), wordType)
 	if err != nil {
@@ -253,6 +281,9 @@ func (h *WordbookHandler) GetUserOrder(c *gin.Context) {
 func (h *WordbookHandler) SwitchOrderMode(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
 
 	var req struct {
 		IsRandom bool `json:"is_random"`
@@ -285,6 +316,9 @@ func (h *WordbookHandler) SwitchOrderMode(c *gin.Context) {
 func (h *WordbookHandler) GetWordsWithOrder(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
@@ -297,6 +331,7 @@ func (h *WordbookHandler) GetWordsWithOrder(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取单词失败", "details": err.Error()})
 		return
 	}
+	getPronunciationService().AttachWordbookAudio(words)
 
 	c.JSON(http.StatusOK, gin.H{
 		"words":         words,
@@ -308,10 +343,14 @@ func (h *WordbookHandler) GetWordsWithOrder(c *gin.Context) {
 }
 
 // UpdateOrderIndex 更新学习进度位置
+// 学习位置以这里保存的值为准，只记录浏览进度，不创建卡片
 // PUT /api/v1/wordbooks/:type/order/index
 func (h *WordbookHandler) UpdateOrderIndex(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
 
 	var req struct {
 		CurrentIndex int `json:"current_index"`
@@ -321,12 +360,17 @@ func (h *WordbookHandler) UpdateOrderIndex(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.UpdateUserOrderIndex(userID.(uint), wordType, req.CurrentIndex); err != nil {
+	currentIndex, err := h.repo.UpdateUserOrderIndex(userID.(uint), wordType, req.CurrentIndex)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "单词书不存在或没有单词"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新进度失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "进度已更新", "current_index": req.CurrentIndex})
+	c.JSON(http.StatusOK, gin.H{"message": "进度已更新", "current_index": currentIndex})
 }
 
 // StudyWord 记录单词学习并奖励积分
@@ -342,12 +386,29 @@ func (h *WordbookHandler) StudyWord(c *gin.Context) {
 	var req struct {
 		Quality  string `json:"quality" binding:"required,oneof=forget fuzzy know"` // 不认识/模糊/认识
 		WordText string `json:"word_text"`                                          // 单词文本（用于积分记录描述）
+		WordType string `json:"word_type"`                                          // 正在学习的单词书，为空时使用单词本身的类型
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
 		return
 	}
 
+	// 记录单词书卡片的记忆状态
+	wordType := req.WordType
+	if wordType == "" {
+		if word, err := h.repo.GetWordByID(uint(wordbookID)); err == nil {
+			wordType = word.WordType
+		}
+	}
+	if wordType != "" {
+		if !h.checkAccess(c, wordType) {
+			return
+		}
+		if _, err := h.studyService.Answer(userID.(uint), wordType, uint(wordbookID), req.Quality); err != nil {
+			log.Printf("⚠️ 记录单词书卡片失败: user=%d, word=%d, type=%s, error=%v", userID.(uint), wordbookID, wordType, err)
+		}
+	}
+
 	// 如果是模糊或不认识，导入到生词本
 	if req.Quality == "fuzzy" || req.Quality == "forget" {
 		_, _ = h.repo.ImportWordToVocabulary(userID.(uint), uint(wordbookID), req.Quality)
@@ -377,3 +438,128 @@ func (h *WordbookHandler) StudyWord(c *gin.Context) {
 		"total_points":  userPoints.CurrentPoints,
 	})
 }
+
+// ==================== 间隔重复学习 ====================
+
+// GetStudyPlan 获取今日学习计划（到期复习 + 新词）
+// GET /api/v1/wordbooks/study/:type/plan
+func (h *WordbookHandler) GetStudyPlan(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
+
+	plan, err := h.studyService.GetPlan(userID, wordType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "单词书不存在或没有单词"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取学习计划失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// UpdateStudyPlan 设置背完日期和每日新词数
+// PUT /api/v1/wordbooks/study/:type/plan
+func (h *WordbookHandler) UpdateStudyPlan(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
+
+	var req service.UpdateWordbookPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	plan, err := h.studyService.UpdatePlan(userID, wordType, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTargetDate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "单词书不存在或没有单词"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新学习计划失败", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "学习计划已更新",
+		"data":    plan,
+	})
+}
+
+// AnswerStudyWord 提交单词书卡片的学习结果
+// POST /api/v1/wordbooks/study/:type/answer
+func (h *WordbookHandler) AnswerStudyWord(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
+
+	var req struct {
+		WordID   uint   `json:"word_id" binding:"required"`
+		Quality  string `json:"quality" binding:"required,oneof=forget fuzzy know"` // 不认识/模糊/认识
+		WordText string `json:"word_text"`                                          // 单词文本（用于积分记录描述）
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误", "details": err.Error()})
+		return
+	}
+
+	result, err := h.studyService.Answer(userID, wordType, req.WordID, req.Quality)
+	if err != nil {
+		if errors.Is(err, service.ErrWordNotInWordbook) || errors.Is(err, service.ErrInvalidStudyAnswer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交失败", "details": err.Error()})
+		return
+	}
+
+	// 奖励积分（与 StudyWord 相同，失败不影响主流程）
+	pointsEarned, totalPoints := 0, 0
+	userPoints, _, earned, err := h.pointService.AwardWordbookStudyPoints(userID, req.WordID, req.WordText, req.Quality)
+	if err != nil {
+		log.Printf("⚠️ 单词书学习奖励积分失败: user=%d, word=%d, error=%v", userID, req.WordID, err)
+	} else {
+		pointsEarned, totalPoints = earned, userPoints.CurrentPoints
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":          result,
+		"points_earned": pointsEarned,
+		"total_points":  totalPoints,
+	})
+}
+
+// GetStudyProgress 获取单词书学习进度（学过、学习中、已掌握）
+// GET /api/v1/wordbooks/study/:type/progress
+func (h *WordbookHandler) GetStudyProgress(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	wordType := c.Param("type")
+	if !h.checkAccess(c, wordType) {
+		return
+	}
+
+	progress, err := h.studyService.GetProgress(userID, wordType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "单词书不存在或没有单词"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取进度失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
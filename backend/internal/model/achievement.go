@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// UserAchievementCounter 成就计数器（按天累加，用于带时间窗口的称号条件）
+// 对应数据库表 vp_user_achievement_counters
+func (UserAchievementCounter) TableName() string {
+	return "vp_user_achievement_counters"
+}
+
+type UserAchievementCounter struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID   uint               `gorm:"not null;uniqueIndex:uk_user_metric_date,priority:1;column:user_id" json:"user_id"`
+	Metric   TitleConditionType `gorm:"size:50;not null;uniqueIndex:uk_user_metric_date,priority:2;column:metric" json:"metric"`
+	StatDate time.Time          `gorm:"type:date;not null;uniqueIndex:uk_user_metric_date,priority:3;column:stat_date" json:"stat_date"` // 用户时区的日期
+	Value    int                `gorm:"not null;default:0;column:value" json:"value"`
+}
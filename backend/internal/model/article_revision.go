@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// 修订状态
+const (
+	RevisionStatusDraft     = "draft"     // 草稿
+	RevisionStatusPublished = "published" // 已发布
+)
+
+// ArticleRevision 文章修订版本
+// 对应数据库表 vp_article_revisions
+// 内容字段（标题、Markdown、句子快照）创建后不再修改，发布时只更新状态和发布时间
+func (ArticleRevision) TableName() string {
+	return "vp_article_revisions"
+}
+
+type ArticleRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	ArticleID      uint   `gorm:"not null;uniqueIndex:uk_article_revision,priority:1;column:article_id" json:"article_id"`
+	RevisionNo     int    `gorm:"not null;uniqueIndex:uk_article_revision,priority:2;column:revision_no" json:"revision_no"` // 版本号（从1开始）
+	BaseRevisionID *uint  `gorm:"column:base_revision_id" json:"base_revision_id,omitempty"`                                 // 基于哪个版本修改
+	Title          string `gorm:"size:255;not null;column:title" json:"title"`
+	Markdown       string `gorm:"type:longtext;column:markdown" json:"markdown,omitempty"` // Markdown 正文快照
+	Sentences      string `gorm:"type:longtext;column:sentences" json:"-"`                 // 句子快照（JSON数组，见 RevisionSentence）
+	ContentHash    string `gorm:"size:64;index;column:content_hash" json:"content_hash"`   // 内容哈希（用于识别无变化的修订）
+	Note           string `gorm:"size:500;column:note" json:"note,omitempty"`              // 修改说明
+	EditorID       *uint  `gorm:"index;column:editor_id" json:"editor_id,omitempty"`       // 编辑者用户ID
+
+	Status      string     `gorm:"size:20;not null;default:'draft';index;column:status" json:"status"` // draft/published
+	PublishedAt *time.Time `gorm:"column:published_at" json:"published_at,omitempty"`
+
+	// 临时字段（从 Sentences 解析，不存储到数据库）
+	SentenceList []RevisionSentence `gorm:"-" json:"sentences,omitempty"`
+}
+
+// RevisionSentence 修订中的句子快照
+type RevisionSentence struct {
+	Text        string `json:"text"`
+	Translation string `json:"translation"`
+}
+
+// 句子ID重映射动作
+const (
+	SentenceRemapKeep    = "keep"    // 未修改，保留原ID
+	SentenceRemapModify  = "modify"  // 内容修改，保留原ID
+	SentenceRemapReplace = "replace" // 被替换，引用迁移到新句子
+	SentenceRemapDelete  = "delete"  // 被删除，引用置空
+	SentenceRemapInsert  = "insert"  // 新增句子
+)
+
+// SentenceRemap 发布修订时的句子ID重映射记录（用于审计和排查引用问题）
+// 对应数据库表 vp_sentence_remaps
+func (SentenceRemap) TableName() string {
+	return "vp_sentence_remaps"
+}
+
+type SentenceRemap struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	RevisionID    uint   `gorm:"not null;index;column:revision_id" json:"revision_id"`
+	ArticleID     uint   `gorm:"not null;index;column:article_id" json:"article_id"`
+	OldSentenceID *uint  `gorm:"index;column:old_sentence_id" json:"old_sentence_id,omitempty"`
+	NewSentenceID *uint  `gorm:"column:new_sentence_id" json:"new_sentence_id,omitempty"`
+	Action        string `gorm:"size:20;not null;column:action" json:"action"` // keep/modify/replace/delete/insert
+}
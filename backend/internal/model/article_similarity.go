@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// ArticleSimilarity 文章相似度（离线计算，见 cmd/build_similarity）
+// 对应数据库表 vp_article_similarities
+func (ArticleSimilarity) TableName() string {
+	return "vp_article_similarities"
+}
+
+type ArticleSimilarity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	ArticleID        uint    `gorm:"not null;uniqueIndex:uk_article_related,priority:1;index:idx_article_position,priority:1;column:article_id" json:"article_id"`
+	RelatedArticleID uint    `gorm:"not null;uniqueIndex:uk_article_related,priority:2;column:related_article_id" json:"related_article_id"`
+	Score            float64 `gorm:"not null;default:0;column:score" json:"score"`                                             // TF-IDF 余弦相似度（0-1）
+	Position         int     `gorm:"not null;default:0;index:idx_article_position,priority:2;column:position" json:"position"` // 在该文章相关列表中的排名（从1开始）
+	SharedTerms      string  `gorm:"size:255;column:shared_terms" json:"shared_terms"`                                         // 贡献最大的共同词（逗号分隔）
+
+	// 关联
+	RelatedArticle Article `gorm:"foreignKey:RelatedArticleID" json:"related_article,omitempty"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// MistakeItemType 错题来源题目类型
+type MistakeItemType string
+
+const (
+	MistakeItemWord       MistakeItemType = "word"       // 文章单词默写（vp_words.id）
+	MistakeItemSentence   MistakeItemType = "sentence"   // 文章句子默写（vp_sentences.id）
+	MistakeItemVocabulary MistakeItemType = "vocabulary" // 生词复习（vp_vocabulary.id）
+)
+
+// MistakeCategoryForgotten 生词复习时忘记（没有可比较的答案）
+const MistakeCategoryForgotten = "forgotten"
+
+// TableName 指定表名
+func (Mistake) TableName() string {
+	return "vp_mistakes"
+}
+
+// Mistake 错题本条目
+// 同一用户同一题目只保留一条，多次出错时累加次数和错误类型
+type Mistake struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID    uint            `gorm:"not null;uniqueIndex:uk_user_item,priority:1;column:user_id" json:"user_id"`
+	ItemType  MistakeItemType `gorm:"size:20;not null;uniqueIndex:uk_user_item,priority:2;column:item_type" json:"item_type"` // word/sentence/vocabulary
+	ItemID    uint            `gorm:"not null;uniqueIndex:uk_user_item,priority:3;column:item_id" json:"item_id"`
+	ArticleID *uint           `gorm:"index;column:article_id" json:"article_id,omitempty"`
+	Content   string          `gorm:"size:500;not null;column:content" json:"content"` // 正确答案（单词、句子或生词内容）
+
+	ErrorCount      int        `gorm:"default:0;column:error_count" json:"error_count"`
+	Categories      string     `gorm:"type:text;column:categories" json:"-"`                          // 错误类型累计次数（JSON对象，类型 -> 次数）
+	PrimaryCategory string     `gorm:"size:30;index;column:primary_category" json:"primary_category"` // 出现次数最多的错误类型
+	LastAnswer      string     `gorm:"type:text;column:last_answer" json:"last_answer,omitempty"`     // 最近一次的错误答案
+	LastDiff        string     `gorm:"type:text;column:last_diff" json:"-"`                           // 最近一次的逐词差异（JSON）
+	LastErrorAt     time.Time  `gorm:"not null;index;column:last_error_at" json:"last_error_at"`      // 最近一次出错时间
+	ResolvedAt      *time.Time `gorm:"column:resolved_at" json:"resolved_at,omitempty"`               // 之后答对的时间，再次出错时清空
+
+	// 推送到生词复习
+	VocabularyID *uint      `gorm:"column:vocabulary_id" json:"vocabulary_id,omitempty"`
+	PushedAt     *time.Time `gorm:"column:pushed_at" json:"pushed_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// NotificationType 通知类型
+type NotificationType string
+
+const (
+	NotificationArticlePublished NotificationType = "article_published" // 订阅的话题有新文章
+	NotificationGoalReminder     NotificationType = "goal_reminder"     // 学习目标今日配额未完成
+)
+
+// Notification 站内通知
+// 对应数据库表 vp_notifications
+func (Notification) TableName() string {
+	return "vp_notifications"
+}
+
+type Notification struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID    uint             `gorm:"not null;uniqueIndex:uk_user_dedupe,priority:1;index:idx_user_read,priority:1;column:user_id" json:"user_id"`
+	Type      NotificationType `gorm:"size:30;not null;column:type" json:"type"`
+	Title     string           `gorm:"size:255;not null;column:title" json:"title"`
+	Content   string           `gorm:"size:500;column:content" json:"content,omitempty"`
+	RefID     *uint            `gorm:"column:ref_id" json:"ref_id,omitempty"`                                              // 关联对象ID（文章ID、目标ID）
+	DedupeKey string           `gorm:"size:100;not null;uniqueIndex:uk_user_dedupe,priority:2;column:dedupe_key" json:"-"` // 去重标识，同一用户相同标识只通知一次
+	ReadAt    *time.Time       `gorm:"index:idx_user_read,priority:2;column:read_at" json:"read_at,omitempty"`             // 已读时间
+}
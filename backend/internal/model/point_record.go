@@ -18,7 +18,9 @@ const (
 	PointTypeCompleteArticle   PointType = "complete_article"    // 完成整篇文章默写
 	PointTypeVocabularyReview  PointType = "vocabulary_review"   // 生词复习
 	PointTypeWordbookStudy     PointType = "wordbook_study"      // 单词书学习
+	PointTypeSeriesComplete    PointType = "series_complete"     // 完成系列/合集
 	PointTypeAdminAdjust       PointType = "admin_adjust"        // 管理员调整
+	PointTypeTitleReward       PointType = "title_reward"        // 获得称号奖励
 )
 
 // PointRecord 积分记录表
@@ -58,6 +60,7 @@ var PointRewardConfig = map[PointType]int{
 	PointTypeSentenceDictation: 5,  // 句子默写正确 +5
 	PointTypeDailyCheckIn:      10, // 每日签到 +10
 	PointTypeCompleteArticle:   20, // 完成整篇默写 +20
+	PointTypeSeriesComplete:    50, // 完成系列 +50（系列可单独配置）
 }
 
 // WordbookStudyPointsConfig 单词书学习积分配置
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// 发音类型
+const (
+	PronunciationWord     = "word"     // 单词、短语（按小写文本去重）
+	PronunciationSentence = "sentence" // 例句（保留大小写）
+)
+
+// 发音生成状态
+const (
+	PronunciationPending = "pending" // 正在生成
+	PronunciationReady   = "ready"   // 已生成，Path 可用
+	PronunciationFailed  = "failed"  // 生成失败，稍后重试
+)
+
+// Pronunciation TTS 生成的发音缓存
+// 对应数据库表 vp_pronunciations
+// 按规范化文本的哈希和音色唯一；音频内容相同的共用同一个存储文件
+func (Pronunciation) TableName() string {
+	return "vp_pronunciations"
+}
+
+type Pronunciation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	TextHash string `gorm:"size:64;not null;uniqueIndex:uk_text_voice,priority:1;column:text_hash" json:"text_hash"` // 规范化文本的 SHA-256
+	Voice    string `gorm:"size:64;not null;uniqueIndex:uk_text_voice,priority:2;column:voice" json:"voice"`
+	Kind     string `gorm:"size:20;not null;default:'word';column:kind" json:"kind"` // word/sentence
+	Text     string `gorm:"size:1000;not null;column:text" json:"text"`
+
+	Status      string `gorm:"size:20;not null;default:'pending';index;column:status" json:"status"` // pending/ready/failed
+	Path        string `gorm:"size:255;column:path" json:"-"`                                        // 存储路径
+	ContentHash string `gorm:"size:64;index;column:content_hash" json:"-"`                           // 音频内容的 SHA-256，用于共用存储文件
+	Size        int64  `gorm:"default:0;column:size" json:"size"`
+	Attempts    int    `gorm:"default:0;column:attempts" json:"attempts"`
+	Error       string `gorm:"size:500;column:error" json:"error,omitempty"`
+}
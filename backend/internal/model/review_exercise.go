@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// ReviewExercise 服务端生成的复习练习
+// 对应数据库表 vp_review_exercises
+// 正确答案只保存在服务端，客户端提交作答后由服务端判分并推导 SM-2 评分
+func (ReviewExercise) TableName() string {
+	return "vp_review_exercises"
+}
+
+type ReviewExercise struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	UserID       uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	VocabularyID uint       `gorm:"not null;index;column:vocabulary_id" json:"vocabulary_id"`
+	Type         ReviewType `gorm:"size:20;not null;column:type" json:"type"` // choice/spell/cloze/listening
+	Prompt       string     `gorm:"type:text;column:prompt" json:"-"`         // 题面（JSON）
+	Answer       string     `gorm:"size:500;not null;column:answer" json:"-"` // 正确答案（选择题为选项下标）
+	ExpiresAt    time.Time  `gorm:"not null;column:expires_at" json:"expires_at"`
+
+	// 作答结果
+	AnsweredAt *time.Time `gorm:"column:answered_at" json:"answered_at,omitempty"`
+	UserAnswer string     `gorm:"type:text;column:user_answer" json:"user_answer,omitempty"`
+	IsCorrect  *bool      `gorm:"column:is_correct" json:"is_correct,omitempty"`
+	Quality    *int       `gorm:"column:quality" json:"quality,omitempty"` // 服务端推导的 SM-2 评分
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Series 系列/合集（连载小说、课程等多集内容）
+// 对应数据库表 vp_series
+func (Series) TableName() string {
+	return "vp_series"
+}
+
+type Series struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"`
+
+	Title           string `gorm:"size:255;not null;column:title" json:"title"`                        // 系列标题
+	Description     string `gorm:"type:text;column:description" json:"description"`                    // 系列简介
+	CoverURL        string `gorm:"size:512;column:cover_url" json:"cover_url"`                         // 封面图
+	CategoryID      *uint  `gorm:"index;column:category_id" json:"category_id"`                        // 所属分类（可选）
+	IsSequential    bool   `gorm:"not null;default:true;column:is_sequential" json:"is_sequential"`    // 是否必须按顺序学习（完成上一集才解锁下一集）
+	CompletionBonus int    `gorm:"not null;default:0;column:completion_bonus" json:"completion_bonus"` // 完成全部剧集的奖励积分（0表示使用默认值）
+	SortOrder       int    `gorm:"not null;default:0;index;column:sort_order" json:"sort_order"`       // 排序
+	IsActive        bool   `gorm:"not null;default:true;index;column:is_active" json:"is_active"`      // 是否启用
+
+	// 关联
+	Episodes []SeriesEpisode `gorm:"foreignKey:SeriesID" json:"episodes,omitempty"`
+}
+
+// SeriesEpisode 系列剧集（有序）
+// 对应数据库表 vp_series_episodes
+func (SeriesEpisode) TableName() string {
+	return "vp_series_episodes"
+}
+
+type SeriesEpisode struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	SeriesID  uint   `gorm:"not null;uniqueIndex:uk_series_article,priority:1;index:idx_series_episode,priority:1;column:series_id" json:"series_id"`
+	ArticleID uint   `gorm:"not null;uniqueIndex:uk_series_article,priority:2;index;column:article_id" json:"article_id"`
+	EpisodeNo int    `gorm:"not null;index:idx_series_episode,priority:2;column:episode_no" json:"episode_no"` // 集数（从1开始）
+	Title     string `gorm:"size:255;column:title" json:"title,omitempty"`                                     // 剧集标题（为空时使用文章标题）
+
+	// 前置剧集（可选）：必须先完成该剧集才能解锁本集，优先于系列的顺序规则
+	PrerequisiteEpisodeID *uint `gorm:"column:prerequisite_episode_id" json:"prerequisite_episode_id,omitempty"`
+
+	// 关联
+	Article Article `gorm:"foreignKey:ArticleID" json:"article,omitempty"`
+}
+
+// SeriesCompletion 用户完成系列记录（用于防止重复发放完成奖励）
+// 对应数据库表 vp_series_completions
+func (SeriesCompletion) TableName() string {
+	return "vp_series_completions"
+}
+
+type SeriesCompletion struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	UserID      uint      `gorm:"not null;uniqueIndex:uk_user_series,priority:1;column:user_id" json:"user_id"`
+	SeriesID    uint      `gorm:"not null;uniqueIndex:uk_user_series,priority:2;index;column:series_id" json:"series_id"`
+	CompletedAt time.Time `gorm:"not null;column:completed_at" json:"completed_at"`
+	BonusPoints int       `gorm:"not null;default:0;column:bonus_points" json:"bonus_points"` // 实际发放的奖励积分
+}
+
+// 剧集学习状态
+const (
+	EpisodeStatusLocked     = "locked"      // 未解锁
+	EpisodeStatusNotStarted = "not_started" // 未开始
+	EpisodeStatusInProgress = "in_progress" // 学习中
+	EpisodeStatusCompleted  = "completed"   // 已完成
+)
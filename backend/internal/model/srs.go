@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// UserSRSParams 用户的间隔重复算法设置
+// 对应数据库表 vp_user_srs_params
+// 没有记录的用户使用 SM-2 算法
+func (UserSRSParams) TableName() string {
+	return "vp_user_srs_params"
+}
+
+type UserSRSParams struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID           uint       `gorm:"not null;uniqueIndex;column:user_id" json:"user_id"`
+	Algorithm        string     `gorm:"size:20;not null;default:'sm2';column:algorithm" json:"algorithm"` // sm2/fsrs
+	Weights          string     `gorm:"type:text;column:weights" json:"-"`                                // FSRS 参数（JSON数组），为空时使用默认参数
+	RequestRetention float64    `gorm:"default:0.9;column:request_retention" json:"request_retention"`    // 期望记忆保持率
+	MaximumInterval  int        `gorm:"default:36500;column:maximum_interval" json:"maximum_interval"`    // 最大间隔（天）
+	MigratedAt       *time.Time `gorm:"column:migrated_at" json:"migrated_at,omitempty"`                  // 最近一次按复习历史重放迁移卡片的时间
+
+	// 每日复习会话（见 ReviewSession），允许为 0 或空，不设 gorm 默认值
+	NewCardsPerDay  int    `gorm:"column:new_cards_per_day" json:"new_cards_per_day"`        // 每日新卡片上限
+	ReviewsPerDay   int    `gorm:"column:reviews_per_day" json:"reviews_per_day"`            // 每日复习上限
+	LearningSteps   string `gorm:"size:100;column:learning_steps" json:"learning_steps"`     // 学习步骤
+	RelearningSteps string `gorm:"size:100;column:relearning_steps" json:"relearning_steps"` // 重学步骤
+
+	// 参数拟合（见 pkg/srs/optimizer.go）
+	OptimizedAt    *time.Time `gorm:"column:optimized_at" json:"optimized_at,omitempty"` // 最近一次拟合时间
+	OptimizeReport string     `gorm:"type:text;column:optimize_report" json:"-"`         // 最近一次拟合报告（JSON，预测保持率与实际对比）
+}
+
+// 复习会话状态
+const (
+	ReviewSessionActive   = "active"   // 进行中
+	ReviewSessionFinished = "finished" // 已完成
+)
+
+// ReviewSession 每日复习会话
+// 对应数据库表 vp_review_sessions
+// 每个用户每天一个会话，队列保存在服务端，换设备后可继续
+func (ReviewSession) TableName() string {
+	return "vp_review_sessions"
+}
+
+type ReviewSession struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;uniqueIndex:uk_user_session_date,priority:1;column:user_id" json:"user_id"`
+	SessionDate string `gorm:"size:10;not null;uniqueIndex:uk_user_session_date,priority:2;column:session_date" json:"session_date"` // YYYY-MM-DD
+	Status      string `gorm:"size:20;not null;default:'active';column:status" json:"status"`                                        // active/finished
+
+	Queue  string `gorm:"type:mediumtext;column:queue" json:"-"` // 今日队列（JSON数组，见 ReviewSessionItem）
+	Buried string `gorm:"type:text;column:buried" json:"-"`      // 今日被埋藏的兄弟卡片ID（JSON数组）
+
+	NewLimit     int `gorm:"default:0;column:new_limit" json:"new_limit"`         // 创建会话时的新卡片上限
+	ReviewLimit  int `gorm:"default:0;column:review_limit" json:"review_limit"`   // 创建会话时的复习上限
+	NewDone      int `gorm:"default:0;column:new_done" json:"new_done"`           // 已学新卡片数
+	ReviewDone   int `gorm:"default:0;column:review_done" json:"review_done"`     // 已复习卡片数
+	LearningDone int `gorm:"default:0;column:learning_done" json:"learning_done"` // 学习/重学步骤的复习次数
+
+	CurrentVocabularyID *uint      `gorm:"column:current_vocabulary_id" json:"current_vocabulary_id,omitempty"` // 当前展示的卡片（多端同步）
+	FinishedAt          *time.Time `gorm:"column:finished_at" json:"finished_at,omitempty"`
+
+	// 临时字段（从 Queue/Buried 解析，不存储到数据库）
+	Items     []ReviewSessionItem `gorm:"-" json:"-"`
+	BuriedIDs []uint              `gorm:"-" json:"-"`
+}
+
+// 会话队列中卡片的类型
+const (
+	ReviewItemNew    = "new"
+	ReviewItemReview = "review"
+)
+
+// ReviewSessionItem 会话队列中的一张卡片
+type ReviewSessionItem struct {
+	VocabularyID uint   `json:"vocabulary_id"`
+	Content      string `json:"content"` // 用于判断兄弟卡片
+	Kind         string `json:"kind"`    // new/review
+	Done         bool   `json:"done"`
+}
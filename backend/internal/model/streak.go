@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// 连续学习类型
+const (
+	StreakCheckIn   = "check_in"  // 每日签到（vp_user_check_ins）
+	StreakReview    = "review"    // 完成当天的生词复习队列（vp_review_sessions）
+	StreakStudy     = "study"     // 当天学习时长达到 StreakStudyMinutes 分钟（vp_user_daily_stats）
+	StreakDictation = "dictation" // 当天至少完成一次默写（vp_dictation_records）
+	StreakAll       = "all"       // 冻结卡保护当天所有类型的连续学习
+)
+
+// StreakKinds 所有连续学习类型（展示顺序）
+var StreakKinds = []string{StreakCheckIn, StreakReview, StreakStudy, StreakDictation}
+
+// StreakKindNames 连续学习类型名称
+var StreakKindNames = map[string]string{
+	StreakCheckIn:   "连续签到",
+	StreakReview:    "连续复习",
+	StreakStudy:     "连续学习",
+	StreakDictation: "连续默写",
+}
+
+// StreakStudyMinutes 学习时长类型的连续学习每天需要达到的分钟数
+const StreakStudyMinutes = 10
+
+// 连续学习保护来源
+const (
+	StreakProtectFreeze = "freeze" // 冻结卡（自动使用）
+	StreakProtectRepair = "repair" // 补签卡（用户手动修复）
+)
+
+// StreakProtection 连续学习保护记录：这一天没有学习，但不中断连续学习
+// 对应数据库表 vp_user_streak_protections
+// 冻结卡在某天未学习时自动使用，Kind 为 all；补签卡修复某一种连续学习，Kind 为具体类型
+func (StreakProtection) TableName() string {
+	return "vp_user_streak_protections"
+}
+
+type StreakProtection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	UserID      uint      `gorm:"not null;uniqueIndex:uk_user_kind_date,priority:1;column:user_id" json:"user_id"`
+	Kind        string    `gorm:"size:20;not null;uniqueIndex:uk_user_kind_date,priority:2;column:kind" json:"kind"`
+	ProtectDate time.Time `gorm:"type:date;not null;uniqueIndex:uk_user_kind_date,priority:3;column:protect_date" json:"protect_date"`
+	Source      string    `gorm:"size:20;not null;column:source" json:"source"` // freeze/repair
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+)
+
+// 标签类型
+const (
+	TagTypeTopic  = "topic"  // 话题（经济、科技、科学...）
+	TagTypeSource = "source" // 来源刊物（The Economist、BBC...）
+)
+
+// Tag 文章标签
+// 对应数据库表 vp_tags
+func (Tag) TableName() string {
+	return "vp_tags"
+}
+
+// 标签直接物理删除（slug 有唯一索引，软删除会占用 slug），没有 deleted_at 字段
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	Name        string `gorm:"size:100;not null;column:name" json:"name"`                      // 标签名称
+	Slug        string `gorm:"size:100;not null;uniqueIndex;column:slug" json:"slug"`          // 标签标识（用于URL筛选，如 economy）
+	Type        string `gorm:"size:20;not null;default:'topic';index;column:type" json:"type"` // 标签类型：topic/source
+	Description string `gorm:"size:500;column:description" json:"description"`                 // 标签描述
+	Icon        string `gorm:"size:255;column:icon" json:"icon"`                               // 标签图标
+	SortOrder   int    `gorm:"not null;default:0;index;column:sort_order" json:"sort_order"`   // 排序
+	IsActive    bool   `gorm:"not null;default:true;index;column:is_active" json:"is_active"`  // 是否启用
+}
+
+// ArticleTag 文章-标签关联
+// 对应数据库表 vp_article_tags
+func (ArticleTag) TableName() string {
+	return "vp_article_tags"
+}
+
+type ArticleTag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	ArticleID uint `gorm:"not null;uniqueIndex:uk_article_tag,priority:1;column:article_id" json:"article_id"`
+	TagID     uint `gorm:"not null;uniqueIndex:uk_article_tag,priority:2;index;column:tag_id" json:"tag_id"`
+}
+
+// UserTagSubscription 用户话题订阅
+// 对应数据库表 vp_user_tag_subscriptions
+func (UserTagSubscription) TableName() string {
+	return "vp_user_tag_subscriptions"
+}
+
+type UserTagSubscription struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	UserID        uint `gorm:"not null;uniqueIndex:uk_user_tag,priority:1;column:user_id" json:"user_id"`
+	TagID         uint `gorm:"not null;uniqueIndex:uk_user_tag,priority:2;index;column:tag_id" json:"tag_id"`
+	NotifyEnabled bool `gorm:"not null;default:true;column:notify_enabled" json:"notify_enabled"` // 有新文章时是否通知
+
+	// 关联
+	Tag Tag `gorm:"foreignKey:TagID" json:"tag,omitempty"`
+}
+
+// TagWithCount 标签及其文章数
+type TagWithCount struct {
+	Tag
+	ArticleCount int64 `gorm:"column:article_count" json:"article_count"`
+}
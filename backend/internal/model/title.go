@@ -34,8 +34,43 @@ const (
 	TitleConditionReviewCount         TitleConditionType = "review_count"         // 复习次数
 	TitleConditionTotalDuration       TitleConditionType = "total_duration"       // 学习时长(分钟)
 	TitleConditionPerfectStreak       TitleConditionType = "perfect_streak"       // 连续全对次数
+	TitleConditionCheckIns            TitleConditionType = "check_ins"            // 累计签到天数
+	TitleConditionCompound            TitleConditionType = "compound"             // 组合条件（见 ConditionRule）
 )
 
+// TitleTier 称号等级（同一系列的称号共用一个进度条，达到不同数值依次获得）
+type TitleTier string
+
+const (
+	TitleTierBronze TitleTier = "bronze" // 铜
+	TitleTierSilver TitleTier = "silver" // 银
+	TitleTierGold   TitleTier = "gold"   // 金
+)
+
+// TitleTierOrder 等级顺序
+var TitleTierOrder = map[TitleTier]int{
+	TitleTierBronze: 1,
+	TitleTierSilver: 2,
+	TitleTierGold:   3,
+}
+
+// 组合条件的逻辑运算
+const (
+	TitleRuleAnd = "and" // 全部满足
+	TitleRuleOr  = "or"  // 满足任意一个
+)
+
+// TitleRule 称号获取条件
+// 叶子节点为单个指标（Metric >= Value），WindowDays > 0 时只统计最近 WindowDays 天（含今天）；
+// 组合节点用 Op 连接子条件，例如 {"op":"and","rules":[{"metric":"review_count","value":100,"window_days":7},{"metric":"check_ins","value":7,"window_days":7}]}
+type TitleRule struct {
+	Op         string             `json:"op,omitempty"`
+	Rules      []TitleRule        `json:"rules,omitempty"`
+	Metric     TitleConditionType `json:"metric,omitempty"`
+	Value      int                `json:"value,omitempty"`
+	WindowDays int                `json:"window_days,omitempty"`
+}
+
 // TitleRarity 称号稀有度
 type TitleRarity string
 
@@ -68,11 +103,31 @@ type TitleConfig struct {
 	ConditionType        TitleConditionType `gorm:"size:50;not null;column:condition_type" json:"condition_type"`                 // 条件类型
 	ConditionValue       int                `gorm:"not null;default:0;column:condition_value" json:"condition_value"`             // 条件数值
 	ConditionDescription string             `gorm:"size:255;column:condition_description" json:"condition_description,omitempty"` // 条件说明
+	ConditionRule        string             `gorm:"type:text;column:condition_rule" json:"condition_rule,omitempty"`              // 组合条件（TitleRule JSON），条件类型为 compound 时使用
 
 	// 称号属性
 	Rarity    TitleRarity `gorm:"size:20;default:'common';column:rarity" json:"rarity"`          // 稀有度
 	SortOrder int         `gorm:"not null;default:0;index;column:sort_order" json:"sort_order"`  // 排序
 	IsActive  bool        `gorm:"not null;default:true;index;column:is_active" json:"is_active"` // 是否启用
+	IsHidden  bool        `gorm:"not null;default:false;column:is_hidden" json:"is_hidden"`      // 隐藏称号：获得前不显示名称、描述和条件
+
+	// 系列等级：同一系列的称号使用相同的条件指标，按等级依次提高数值
+	FamilyKey string    `gorm:"size:50;index;column:family_key" json:"family_key,omitempty"` // 称号系列
+	Tier      TitleTier `gorm:"size:20;column:tier" json:"tier,omitempty"`                   // 等级
+
+	// 限时称号：只能在有效期内获得，期间的阅读、复习等次数只统计有效期内的
+	StartAt *time.Time `gorm:"column:start_at" json:"start_at,omitempty"`
+	EndAt   *time.Time `gorm:"column:end_at" json:"end_at,omitempty"`
+
+	// 解锁奖励
+	RewardPoints        int `gorm:"not null;default:0;column:reward_points" json:"reward_points"`                 // 奖励积分
+	RewardMakeupCards   int `gorm:"not null;default:0;column:reward_makeup_cards" json:"reward_makeup_cards"`     // 奖励补签卡
+	RewardStreakFreezes int `gorm:"not null;default:0;column:reward_streak_freezes" json:"reward_streak_freezes"` // 奖励冻结卡（不超过持有上限）
+}
+
+// AvailableAt 称号在 t 时刻是否可以获得（限时称号在有效期内）
+func (c *TitleConfig) AvailableAt(t time.Time) bool {
+	return (c.StartAt == nil || !t.Before(*c.StartAt)) && (c.EndAt == nil || t.Before(*c.EndAt))
 }
 
 // UserTitle 用户称号表
@@ -22,6 +22,7 @@ type User struct {
 	Nickname string `gorm:"size:100" json:"nickname"` // 昵称
 	Avatar   string `gorm:"size:512" json:"avatar"`   // 头像URL
 	Bio      string `gorm:"size:500" json:"bio"`      // 个人简介
+	Timezone string `gorm:"size:64" json:"timezone"`  // IANA 时区（如 America/New_York），为空时使用服务器时区；签到、每日统计等按该时区划分日期
 
 	// 手机号登录（可选）
 	Phone           *string    `gorm:"size:20;uniqueIndex" json:"phone,omitempty"` // 手机号（加密存储，使用指针允许NULL）
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 学习目标状态
+const (
+	GoalStatusActive    = "active"    // 进行中
+	GoalStatusCompleted = "completed" // 已完成（单词书学完）
+	GoalStatusAbandoned = "abandoned" // 用户放弃
+	GoalStatusExpired   = "expired"   // 截止日期已过仍未完成
+)
+
+// UserGoal 用户学习目标
+// 对应数据库表 vp_user_goals
+// 目标可以是在截止日期前学完一本单词书（WordType），也可以只是每日学习时长；
+// 每日新词配额按剩余单词和剩余天数实时计算，落后时自动提高，对照 vp_user_daily_stats 统计完成情况
+func (UserGoal) TableName() string {
+	return "vp_user_goals"
+}
+
+type UserGoal struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"`
+
+	UserID   uint   `gorm:"not null;index:idx_user_status,priority:1;column:user_id" json:"user_id"`
+	Title    string `gorm:"size:100;not null;column:title" json:"title"`
+	WordType string `gorm:"size:20;column:word_type" json:"word_type,omitempty"` // 目标单词书，为空表示只有学习时长目标
+
+	StartDate time.Time `gorm:"type:date;not null;column:start_date" json:"start_date"`
+	Deadline  time.Time `gorm:"type:date;not null;column:deadline" json:"deadline"`
+
+	DailyMinutes  int `gorm:"default:0;column:daily_minutes" json:"daily_minutes"`     // 每日学习时长（分钟），0 表示不限
+	DailyNewWords int `gorm:"default:0;column:daily_new_words" json:"daily_new_words"` // 每日最少新词数，0 表示按剩余单词和剩余天数计算
+
+	StartLearned int `gorm:"default:0;column:start_learned" json:"start_learned"` // 创建目标时单词书中已学的单词数
+	PlannedDaily int `gorm:"default:0;column:planned_daily" json:"planned_daily"` // 创建目标时计划的每日新词数，用于判断进度是否落后
+
+	Status        string     `gorm:"size:20;not null;default:'active';index:idx_user_status,priority:2;column:status" json:"status"`
+	CompletedAt   *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	RemindEnabled bool       `gorm:"default:true;column:remind_enabled" json:"remind_enabled"` // 当日未完成时是否提醒
+}
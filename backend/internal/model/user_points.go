@@ -31,7 +31,11 @@ type UserPoints struct {
 	ContinuousCheckIns       int   `gorm:"not null;default:0;column:continuous_check_ins" json:"continuous_check_ins"`             // 当前连续签到天数
 	MaxContinuousCheckIns    int   `gorm:"not null;default:0;column:max_continuous_check_ins" json:"max_continuous_check_ins"`     // 最大连续签到天数
 	MakeupCards              int   `gorm:"not null;default:0;column:makeup_cards" json:"makeup_cards"`                             // 拥有的补签卡数量
+	StreakFreezes            int   `gorm:"not null;default:0;column:streak_freezes" json:"streak_freezes"`                         // 拥有的连续学习冻结卡数量
 	TotalDurationMinutes     int64 `gorm:"not null;default:0;column:total_duration_minutes" json:"total_duration_minutes"`         // 累积学习时长（分钟）
+	TotalReviews             int   `gorm:"not null;default:0;column:total_reviews" json:"total_reviews"`                           // 累计复习次数
+	PerfectStreak            int   `gorm:"not null;default:0;column:perfect_streak" json:"perfect_streak"`                         // 当前连续默写全对次数
+	MaxPerfectStreak         int   `gorm:"not null;default:0;column:max_perfect_streak" json:"max_perfect_streak"`                 // 最大连续默写全对次数
 
 	// 关联
 	User User `gorm:"foreignKey:UserID" json:"-"`
@@ -23,6 +23,8 @@ const (
 	ReviewTypeSpell     ReviewType = "spell"     // 拼写
 	ReviewTypeChoice    ReviewType = "choice"    // 选择题
 	ReviewTypeDictation ReviewType = "dictation" // 听写
+	ReviewTypeCloze     ReviewType = "cloze"     // 完形填空
+	ReviewTypeListening ReviewType = "listening" // 听音选义
 )
 
 // Vocabulary 生词本主表
@@ -48,6 +50,7 @@ type Vocabulary struct {
 	// 生词内容
 	Type               VocabularyType `gorm:"type:enum('word','phrase','sentence');not null;default:'word';column:type" json:"type"`
 	Content            string         `gorm:"size:500;not null;column:content" json:"content"`
+	Lemma              string         `gorm:"size:100;column:lemma" json:"lemma,omitempty"` // 单词原形（running -> run），用于去重，短语和句子为空
 	Phonetic           string         `gorm:"size:100;column:phonetic" json:"phonetic,omitempty"`
 	Meaning            string         `gorm:"type:text;column:meaning" json:"meaning,omitempty"`
 	Example            string         `gorm:"type:text;column:example" json:"example,omitempty"`
@@ -64,6 +67,15 @@ type Vocabulary struct {
 	NextReviewAt *time.Time `gorm:"column:next_review_at" json:"next_review_at,omitempty"`
 	LastReviewAt *time.Time `gorm:"column:last_review_at" json:"last_review_at,omitempty"`
 
+	// 学习状态（FSRS算法，见 pkg/srs）
+	Stability  float64 `gorm:"default:0;column:stability" json:"stability"`   // 记忆稳定性（天），0 表示尚未按 FSRS 学习
+	Difficulty float64 `gorm:"default:0;column:difficulty" json:"difficulty"` // 难度 1-10
+	Lapses     int     `gorm:"default:0;column:lapses" json:"lapses"`         // 遗忘次数
+
+	// 学习阶段（日内学习步骤，见 pkg/srs/steps.go）
+	SrsState     string `gorm:"size:20;not null;default:'new';index;column:srs_state" json:"srs_state"` // new/learning/review/relearning
+	LearningStep int    `gorm:"default:0;column:learning_step" json:"learning_step"`                    // 当前学习/重学步骤
+
 	// 统计
 	ReviewCount  int `gorm:"default:0;column:review_count" json:"review_count"`
 	CorrectCount int `gorm:"default:0;column:correct_count" json:"correct_count"`
@@ -73,6 +85,11 @@ type Vocabulary struct {
 	Tags      string `gorm:"size:500;column:tags" json:"tags,omitempty"` // JSON数组格式
 	IsStarred bool   `gorm:"default:false;column:is_starred" json:"is_starred"`
 
+	// 发音（见 vp_pronunciations），返回生词列表时填充
+	AudioURL        string `gorm:"-" json:"audio_url,omitempty"`
+	AudioPending    bool   `gorm:"-" json:"audio_pending,omitempty"` // 发音正在生成，稍后重新获取
+	ExampleAudioURL string `gorm:"-" json:"example_audio_url,omitempty"`
+
 	// 关联
 	User    User     `gorm:"foreignKey:UserID" json:"-"`
 	Article *Article `gorm:"foreignKey:ArticleID" json:"article,omitempty"`
@@ -92,7 +109,7 @@ type VocabularyReview struct {
 	VocabularyID uint `gorm:"not null;index;column:vocabulary_id" json:"vocabulary_id"`
 
 	// 复习详情
-	ReviewType     ReviewType `gorm:"type:enum('card','spell','choice','dictation');not null;default:'card';column:review_type" json:"review_type"`
+	ReviewType     ReviewType `gorm:"type:enum('card','spell','choice','dictation','cloze','listening');not null;default:'card';column:review_type" json:"review_type"`
 	Quality        int        `gorm:"not null;column:quality" json:"quality"` // SM-2评分 0-5
 	IsCorrect      bool       `gorm:"not null;column:is_correct" json:"is_correct"`
 	ResponseTimeMs *int       `gorm:"column:response_time_ms" json:"response_time_ms,omitempty"`
@@ -128,10 +145,18 @@ type VocabularyFolder struct {
 	Icon        string `gorm:"size:50;column:icon" json:"icon,omitempty"`
 	SortOrder   int    `gorm:"default:0;column:sort_order" json:"sort_order"`
 
+	// 智能文件夹：按筛选条件动态计算内容（JSON格式，见 repository.VocabularyFilter），为空表示普通文件夹
+	Filter string `gorm:"type:text;column:filter" json:"filter,omitempty"`
+
 	// 关联
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+// IsSmart 是否为智能文件夹
+func (f *VocabularyFolder) IsSmart() bool {
+	return f.Filter != ""
+}
+
 // VocabularyFolderItem 生词-文件夹关联
 // 对应数据库表 vp_vocabulary_folder_items
 func (VocabularyFolderItem) TableName() string {
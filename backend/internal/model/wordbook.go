@@ -38,17 +38,36 @@ type Wordbook struct {
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
 	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 发音（见 vp_pronunciations），返回单词列表时填充
+	AudioURL        string `gorm:"-" json:"audio_url,omitempty"`
+	AudioPending    bool   `gorm:"-" json:"audio_pending,omitempty"` // 发音正在生成，稍后重新获取
+	ExampleAudioURL string `gorm:"-" json:"example_audio_url,omitempty"`
 }
 
 func (Wordbook) TableName() string {
 	return "vp_wordbook"
 }
 
+// 单词书可见范围（系统单词书均为公开）
+const (
+	WordbookVisibilityPrivate = "private" // 仅创建者
+	WordbookVisibilityLink    = "link"    // 通过分享链接加入的用户
+	WordbookVisibilityPublic  = "public"  // 所有用户
+)
+
+// WordbookSourceCustom 用户自建单词书中新建的词条来源（词典中没有的单词），不参与词典查询
+const WordbookSourceCustom = "custom"
+
 // WordbookInfo 单词书基础信息
+// OwnerID 为空的是系统单词书（cet4、toefl 等），否则为用户自建的单词书，Type 形如 u_xxxxxxxx
 type WordbookInfo struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	Type        string         `gorm:"size:20;not null;uniqueIndex" json:"type"`
 	Name        string         `gorm:"size:100;not null" json:"name"`
+	OwnerID     *uint          `gorm:"index" json:"owner_id,omitempty"`
+	Visibility  string         `gorm:"size:20;default:'public'" json:"visibility"`
+	ShareToken  string         `gorm:"size:32;index" json:"share_token,omitempty"` // 分享链接的令牌，只返回给创建者
 	Description string         `gorm:"size:500" json:"description"`
 	CoverURL    string         `gorm:"column:cover_url;size:500" json:"cover_url"`
 	Hotness     uint           `gorm:"default:0" json:"hotness"`
@@ -66,6 +85,11 @@ func (WordbookInfo) TableName() string {
 	return "vp_wordbook_info"
 }
 
+// IsCustom 是否为用户自建的单词书
+func (w *WordbookInfo) IsCustom() bool {
+	return w.OwnerID != nil
+}
+
 // WordbookProgress 单词书学习进度
 type WordbookProgress struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
@@ -83,15 +107,27 @@ func (WordbookProgress) TableName() string {
 	return "vp_wordbook_progress"
 }
 
+// WordbookOrderVersion 当前的学习序列格式版本
+// 0：旧版，完整的单词ID序列以 JSON 保存在 WordSequence；1：按 Seed 实时计算的确定性排列。
+// 曾经短暂使用过的版本 2 把同样的排列预先保存在单独的表中，顺序与 1 相同，按 1 读取
+const WordbookOrderVersion = 1
+
 // WordbookUserOrder 用户单词书学习序列（支持顺序/乱序）
+// 序列 = 已学单词（按第一次学习的时间）+ 未学单词（顺序模式按单词ID，乱序模式按 Seed 确定的排列），
+// 单词书增删单词时只影响未学部分，不会打乱已学部分。
+// CurrentIndex 是前端保存的学习位置，以 PUT order/index 为准，不随卡片数变化
 type WordbookUserOrder struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	UserID       uint           `gorm:"not null;uniqueIndex:uk_user_word_type" json:"user_id"`
 	WordType     string         `gorm:"size:20;not null;uniqueIndex:uk_user_word_type" json:"word_type"`
 	IsRandom     bool           `gorm:"default:false" json:"is_random"` // 是否乱序模式
-	WordSequence string         `gorm:"type:text" json:"word_sequence"` // JSON格式的单词ID序列
+	Seed         int64          `gorm:"default:0" json:"-"`             // 乱序排列的种子，顺序模式为 0
+	Version      int            `gorm:"default:0" json:"-"`             // 序列格式版本，见 WordbookOrderVersion
+	WordSequence string         `gorm:"type:text" json:"-"`             // 旧版：JSON格式的单词ID序列，迁移后清空
 	CurrentIndex int            `gorm:"default:0" json:"current_index"` // 当前学习位置
 	TotalWords   int            `gorm:"default:0" json:"total_words"`   // 总单词数
+	TargetDate   *time.Time     `gorm:"type:date" json:"target_date"`   // 计划背完日期，设置后每日新词数按剩余天数计算
+	NewPerDay    int            `gorm:"default:0" json:"new_per_day"`   // 每日新词数，0 表示使用复习设置中的每日新卡片上限
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -112,3 +148,38 @@ type WordbookBook struct {
 func (WordbookBook) TableName() string {
 	return "vp_wordbook_books"
 }
+
+// WordbookMasteredInterval 单词书卡片的复习间隔达到该天数后视为已掌握
+const WordbookMasteredInterval = 21
+
+// WordbookCard 用户在某本单词书中每个单词的记忆状态（间隔重复卡片）
+// 第一次学习某个单词时创建，调度字段与生词本一致，使用同一个调度器
+type WordbookCard struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index:idx_user_book_created,priority:3" json:"created_at"` // 第一次学习的时间
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID   uint   `gorm:"not null;uniqueIndex:uk_user_book_word,priority:1;index:idx_user_book_due,priority:1;index:idx_user_book_created,priority:1" json:"user_id"`
+	WordType string `gorm:"size:20;not null;uniqueIndex:uk_user_book_word,priority:2;index:idx_user_book_due,priority:2;index:idx_user_book_created,priority:2" json:"word_type"`
+	WordID   uint   `gorm:"not null;uniqueIndex:uk_user_book_word,priority:3" json:"word_id"` // vp_wordbook.id
+
+	// 间隔重复（与 Vocabulary 的 SRS 字段含义相同）
+	EaseFactor   float64    `gorm:"default:2.5" json:"ease_factor"`
+	IntervalDays int        `gorm:"default:0" json:"interval_days"`
+	Repetitions  int        `gorm:"default:0" json:"repetitions"`
+	MasteryLevel int        `gorm:"default:0" json:"mastery_level"`
+	Stability    float64    `gorm:"default:0" json:"stability"`
+	Difficulty   float64    `gorm:"default:0" json:"difficulty"`
+	Lapses       int        `gorm:"default:0" json:"lapses"`
+	SrsState     string     `gorm:"size:20;default:'new'" json:"srs_state"`
+	LearningStep int        `gorm:"default:0" json:"learning_step"`
+	LastReviewAt *time.Time `json:"last_review_at"`
+	NextReviewAt *time.Time `gorm:"index:idx_user_book_due,priority:3" json:"next_review_at"`
+
+	ReviewCount int        `gorm:"default:0" json:"review_count"`
+	MasteredAt  *time.Time `json:"mastered_at"` // 间隔达到 WordbookMasteredInterval 的时间，遗忘后清空
+}
+
+func (WordbookCard) TableName() string {
+	return "vp_wordbook_cards"
+}
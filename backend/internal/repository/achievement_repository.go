@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementRepository 成就计数器仓库
+type AchievementRepository struct {
+	db *gorm.DB
+}
+
+func NewAchievementRepository(db *gorm.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// AddCounter 累加某天的计数（不存在时创建）
+func (r *AchievementRepository) AddCounter(userID uint, metric model.TitleConditionType, date time.Time, delta int) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "metric"}, {Name: "stat_date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("value + ?", delta), "updated_at": time.Now()}),
+	}).Create(&model.UserAchievementCounter{
+		UserID:   userID,
+		Metric:   metric,
+		StatDate: date,
+		Value:    delta,
+	}).Error
+}
+
+// SumCounters 统计 since 到 until（都含）之间各指标的计数之和
+func (r *AchievementRepository) SumCounters(userID uint, metrics []model.TitleConditionType, since, until time.Time) (map[model.TitleConditionType]int, error) {
+	var rows []struct {
+		Metric model.TitleConditionType
+		Total  int
+	}
+	err := r.db.Model(&model.UserAchievementCounter{}).
+		Select("metric, COALESCE(SUM(value), 0) AS total").
+		Where("user_id = ? AND metric IN ? AND stat_date BETWEEN ? AND ?", userID, metrics, since, until).
+		Group("metric").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[model.TitleConditionType]int, len(rows))
+	for _, row := range rows {
+		sums[row.Metric] = row.Total
+	}
+	return sums, nil
+}
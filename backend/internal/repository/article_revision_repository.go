@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleRevisionRepository 文章修订仓库
+type ArticleRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleRevisionRepository(db *gorm.DB) *ArticleRevisionRepository {
+	return &ArticleRevisionRepository{db: db}
+}
+
+// List 获取文章的所有修订（不含正文，按版本号倒序）
+func (r *ArticleRevisionRepository) List(articleID uint) ([]model.ArticleRevision, error) {
+	var revisions []model.ArticleRevision
+	err := r.db.Select("id", "created_at", "article_id", "revision_no", "base_revision_id", "title", "content_hash", "note", "editor_id", "status", "published_at").
+		Where("article_id = ?", articleID).
+		Order("revision_no DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// Count 获取文章的修订数量
+func (r *ArticleRevisionRepository) Count(articleID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ArticleRevision{}).Where("article_id = ?", articleID).Count(&count).Error
+	return count, err
+}
+
+// GetByNo 根据版本号获取修订
+func (r *ArticleRevisionRepository) GetByNo(articleID uint, revisionNo int) (*model.ArticleRevision, error) {
+	var revision model.ArticleRevision
+	err := r.db.Where("article_id = ? AND revision_no = ?", articleID, revisionNo).First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetLatest 获取最新的修订
+func (r *ArticleRevisionRepository) GetLatest(articleID uint) (*model.ArticleRevision, error) {
+	var revision model.ArticleRevision
+	err := r.db.Where("article_id = ?", articleID).Order("revision_no DESC").First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetLatestPublished 获取最新发布的修订（即线上版本），tx 为 nil 时不使用事务
+func (r *ArticleRevisionRepository) GetLatestPublished(tx *gorm.DB, articleID uint) (*model.ArticleRevision, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var revision model.ArticleRevision
+	err := tx.Where("article_id = ? AND status = ?", articleID, model.RevisionStatusPublished).
+		Order("revision_no DESC").
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetByID 根据ID获取修订，tx 为 nil 时不使用事务
+func (r *ArticleRevisionRepository) GetByID(tx *gorm.DB, id uint) (*model.ArticleRevision, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var revision model.ArticleRevision
+	if err := tx.First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// LockArticle 锁定文章行（在事务中执行），同一篇文章的修订依次发布
+func (r *ArticleRevisionRepository) LockArticle(tx *gorm.DB, articleID uint) error {
+	var article model.Article
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&article, articleID).Error
+}
+
+// Create 创建修订，版本号自动取当前最大版本号 + 1
+func (r *ArticleRevisionRepository) Create(revision *model.ArticleRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxNo int
+		if err := tx.Model(&model.ArticleRevision{}).
+			Where("article_id = ?", revision.ArticleID).
+			Select("COALESCE(MAX(revision_no), 0)").
+			Scan(&maxNo).Error; err != nil {
+			return err
+		}
+		revision.RevisionNo = maxNo + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// GetLiveSentences 获取文章当前线上的句子（按顺序）
+func (r *ArticleRevisionRepository) GetLiveSentences(tx *gorm.DB, articleID uint) ([]model.Sentence, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var sentences []model.Sentence
+	err := tx.Where("article_id = ?", articleID).
+		Order("`order` ASC, id ASC").
+		Find(&sentences).Error
+	return sentences, err
+}
@@ -20,16 +20,6 @@ func (r *CheckInRepository) Create(tx *gorm.DB, checkIn *model.UserCheckIn) erro
 	return tx.Create(checkIn).Error
 }
 
-// CheckTodayCheckIn 检查今天是否已签到
-func (r *CheckInRepository) CheckTodayCheckIn(userID uint) (bool, error) {
-	today := time.Now().Format("2006-01-02")
-	var count int64
-	err := r.db.Model(&model.UserCheckIn{}).
-		Where("user_id = ? AND check_in_date = ?", userID, today).
-		Count(&count).Error
-	return count > 0, err
-}
-
 // CheckDateCheckIn 检查指定日期是否已签到
 func (r *CheckInRepository) CheckDateCheckIn(userID uint, date time.Time) (bool, error) {
 	dateStr := date.Format("2006-01-02")
@@ -52,10 +42,10 @@ func (r *CheckInRepository) GetLastCheckIn(userID uint) (*model.UserCheckIn, err
 	return &checkIn, nil
 }
 
-// GetCheckInHistory 获取用户签到历史（最近N天）
-func (r *CheckInRepository) GetCheckInHistory(userID uint, days int) ([]model.UserCheckIn, error) {
+// GetCheckInHistory 获取用户签到历史（today 为用户的今天，取最近N天）
+func (r *CheckInRepository) GetCheckInHistory(userID uint, today time.Time, days int) ([]model.UserCheckIn, error) {
 	var checkIns []model.UserCheckIn
-	startDate := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+	startDate := today.AddDate(0, 0, -days).Format("2006-01-02")
 	err := r.db.Where("user_id = ? AND check_in_date >= ?", userID, startDate).
 		Order("check_in_date DESC").
 		Find(&checkIns).Error
@@ -63,8 +53,8 @@ func (r *CheckInRepository) GetCheckInHistory(userID uint, days int) ([]model.Us
 }
 
 // CalculateContinuousDays 计算连续签到天数
-// 从今天开始向前遍历，计算连续签到的天数
-func (r *CheckInRepository) CalculateContinuousDays(userID uint) (int, error) {
+// 从今天（用户时区的日期）开始向前遍历，计算连续签到的天数
+func (r *CheckInRepository) CalculateContinuousDays(userID uint, today time.Time) (int, error) {
 	// 获取用户所有签到记录，按日期倒序
 	var checkIns []model.UserCheckIn
 	err := r.db.Where("user_id = ?", userID).
@@ -85,8 +75,7 @@ func (r *CheckInRepository) CalculateContinuousDays(userID uint) (int, error) {
 	}
 
 	// 从今天开始向前计算连续天数
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
 
 	continuousDays := 0
 	for i := 0; i <= 365; i++ { // 最多检查365天
@@ -113,10 +102,9 @@ func (r *CheckInRepository) CalculateContinuousDays(userID uint) (int, error) {
 	return continuousDays, nil
 }
 
-// GetMonthCheckInCount 获取本月签到次数
-func (r *CheckInRepository) GetMonthCheckInCount(userID uint) (int64, error) {
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+// GetMonthCheckInCount 获取本月（today 所在月份）签到次数
+func (r *CheckInRepository) GetMonthCheckInCount(userID uint, today time.Time) (int64, error) {
+	startOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
 	nextMonth := startOfMonth.AddDate(0, 1, 0)
 
 	var count int64
@@ -141,12 +141,10 @@ func (r *ArticleRepository) UpdateOnline(id uint, online string) error {
 }
 
 // GetAll 获取所有文章列表（首页用）
-func (r *ArticleRepository) GetAll(isMiniProgram bool) ([]model.Article, error) {
+// today 为用户时区的今天（格式：2025-12-10），发布日期在今天之后的文章不返回
+func (r *ArticleRepository) GetAll(isMiniProgram bool, today string) ([]model.Article, error) {
 	var articles []model.Article
 
-	// 获取今天的日期（格式：2025-12-10）
-	today := time.Now().Format("2006-01-02")
-
 	// 基础查询：每日精读分类、有音频
 	query := DB.Unscoped().
 		Select("id", "title", "pic_url", "pic_1_1_url", "pic_5_4_url", "online", "category_id", "publish_date", "is_daily", "audio_url", "timeline_url", "article_url", "original_article_url", "created_at", "updated_at").
@@ -219,3 +217,87 @@ func (r *ArticleRepository) GetByCategory(categoryID uint) ([]model.Article, err
 		Find(&articles).Error
 	return articles, err
 }
+
+// GetPublishedByIDs 根据ID获取截至 today 已发布的文章（按发布日期倒序）
+func (r *ArticleRepository) GetPublishedByIDs(ids []uint, today string) ([]model.Article, error) {
+	var articles []model.Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	err := DB.Select("id", "title", "pic_url", "pic_1_1_url", "pic_5_4_url", "online", "category_id", "publish_date", "is_daily", "audio_url", "timeline_url", "article_url", "original_article_url", "created_at", "updated_at").
+		Where("id IN ? AND publish_date <= ?", ids, today).
+		Order("publish_date DESC, created_at DESC").
+		Find(&articles).Error
+	return articles, err
+}
+
+// GetRecommendCandidates 获取推荐候选文章（截至 today 已发布、有音频，排除指定文章），按发布日期倒序取最近 limit 篇
+func (r *ArticleRepository) GetRecommendCandidates(excludeIDs []uint, today string, limit int) ([]model.Article, error) {
+	var articles []model.Article
+
+	query := DB.Select("id", "title", "pic_url", "category_id", "publish_date", "is_daily", "audio_url", "created_at").
+		Where("audio_url != '' AND publish_date <= ?", today)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+
+	err := query.Order("publish_date DESC, created_at DESC").
+		Limit(limit).
+		Find(&articles).Error
+	return articles, err
+}
+
+// ArticleWordStats 文章单词统计（用于估算文章难度）
+type ArticleWordStats struct {
+	Words      []string
+	Difficulty float64 // 重点单词平均等级（0-5），没有单词时为0
+}
+
+// GetKeyWordsByArticleIDs 批量获取文章的重点单词（按文章和出现顺序）
+func (r *ArticleRepository) GetKeyWordsByArticleIDs(articleIDs []uint) ([]model.Word, error) {
+	var words []model.Word
+	if len(articleIDs) == 0 {
+		return words, nil
+	}
+	err := DB.Where("article_id IN ? AND is_key_word = ?", articleIDs, true).
+		Order("article_id ASC, `order` ASC").
+		Find(&words).Error
+	return words, err
+}
+
+// GetWordStatsByArticleIDs 批量获取文章的单词和难度
+func (r *ArticleRepository) GetWordStatsByArticleIDs(articleIDs []uint) (map[uint]*ArticleWordStats, error) {
+	stats := make(map[uint]*ArticleWordStats, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return stats, nil
+	}
+
+	var words []model.Word
+	err := DB.Select("article_id", "text", "level", "is_key_word").
+		Where("article_id IN ?", articleIDs).
+		Find(&words).Error
+	if err != nil {
+		return nil, err
+	}
+
+	levelSum := make(map[uint]int)
+	levelCount := make(map[uint]int)
+	for _, w := range words {
+		s, ok := stats[w.ArticleID]
+		if !ok {
+			s = &ArticleWordStats{}
+			stats[w.ArticleID] = s
+		}
+		s.Words = append(s.Words, w.Text)
+		if w.IsKeyWord && w.Level > 0 {
+			levelSum[w.ArticleID] += w.Level
+			levelCount[w.ArticleID]++
+		}
+	}
+	for id, s := range stats {
+		if levelCount[id] > 0 {
+			s.Difficulty = float64(levelSum[id]) / float64(levelCount[id])
+		}
+	}
+	return stats, nil
+}
@@ -137,3 +137,32 @@ func (r *DictationRecordRepository) GetStatistics(userID *uint, articleID uint)
 	}, nil
 }
 
+
+// GetAnswerText 获取默写题目的标准答案（单词或句子原文），题目必须属于该文章
+func (r *DictationRecordRepository) GetAnswerText(dictationType model.DictationType, articleID, itemID uint) (string, error) {
+	var text string
+	var err error
+	if dictationType == model.DictationTypeWord {
+		var word model.Word
+		err = DB.Select("text").Where("id = ? AND article_id = ?", itemID, articleID).First(&word).Error
+		text = word.Text
+	} else {
+		var sentence model.Sentence
+		err = DB.Select("text").Where("id = ? AND article_id = ?", itemID, articleID).First(&sentence).Error
+		text = sentence.Text
+	}
+	return text, err
+}
+
+// HasCorrectRecord 用户是否已经正确默写过该题目（用于避免重复奖励积分）
+func (r *DictationRecordRepository) HasCorrectRecord(userID uint, dictationType model.DictationType, itemID uint) (bool, error) {
+	var count int64
+	query := DB.Model(&model.DictationRecord{}).Where("user_id = ? AND dictation_type = ? AND is_correct = ?", userID, dictationType, true)
+	if dictationType == model.DictationTypeWord {
+		query = query.Where("word_id = ?", itemID)
+	} else {
+		query = query.Where("sentence_id = ?", itemID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// MistakeRepository 错题本仓库
+type MistakeRepository struct {
+	db *gorm.DB
+}
+
+func NewMistakeRepository(db *gorm.DB) *MistakeRepository {
+	return &MistakeRepository{db: db}
+}
+
+// MistakeListParams 错题列表查询参数
+type MistakeListParams struct {
+	ItemType  string
+	Category  string
+	ArticleID *uint
+	Resolved  *bool
+	Pushed    *bool
+	MinErrors int
+	Keyword   string
+	OrderBy   string // recent（默认）/count
+	Limit     int
+	Offset    int
+}
+
+// GetByItem 获取用户某个题目的错题记录
+func (r *MistakeRepository) GetByItem(userID uint, itemType model.MistakeItemType, itemID uint) (*model.Mistake, error) {
+	var mistake model.Mistake
+	err := r.db.Where("user_id = ? AND item_type = ? AND item_id = ?", userID, itemType, itemID).First(&mistake).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mistake, nil
+}
+
+// Save 创建或保存错题
+func (r *MistakeRepository) Save(mistake *model.Mistake) error {
+	return r.db.Save(mistake).Error
+}
+
+// MarkResolved 答对后标记为已解决
+func (r *MistakeRepository) MarkResolved(userID uint, itemType model.MistakeItemType, itemID uint) error {
+	return r.db.Model(&model.Mistake{}).
+		Where("user_id = ? AND item_type = ? AND item_id = ? AND resolved_at IS NULL", userID, itemType, itemID).
+		Update("resolved_at", gorm.Expr("NOW()")).Error
+}
+
+// List 分页查询错题
+func (r *MistakeRepository) List(userID uint, params *MistakeListParams) ([]model.Mistake, int64, error) {
+	var mistakes []model.Mistake
+	var total int64
+
+	query := r.db.Model(&model.Mistake{}).Where("user_id = ?", userID)
+
+	if params.ItemType != "" {
+		query = query.Where("item_type = ?", params.ItemType)
+	}
+	if params.Category != "" {
+		// categories 为 {"spelling":2,...} 格式
+		query = query.Where("categories LIKE ?", "%\""+params.Category+"\":%")
+	}
+	if params.ArticleID != nil {
+		query = query.Where("article_id = ?", *params.ArticleID)
+	}
+	if params.Resolved != nil {
+		if *params.Resolved {
+			query = query.Where("resolved_at IS NOT NULL")
+		} else {
+			query = query.Where("resolved_at IS NULL")
+		}
+	}
+	if params.Pushed != nil {
+		if *params.Pushed {
+			query = query.Where("pushed_at IS NOT NULL")
+		} else {
+			query = query.Where("pushed_at IS NULL")
+		}
+	}
+	if params.MinErrors > 0 {
+		query = query.Where("error_count >= ?", params.MinErrors)
+	}
+	if params.Keyword != "" {
+		query = query.Where("content LIKE ?", "%"+params.Keyword+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch params.OrderBy {
+	case "count":
+		query = query.Order("error_count DESC, last_error_at DESC")
+	default:
+		query = query.Order("last_error_at DESC")
+	}
+
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+	if params.Offset > 0 {
+		query = query.Offset(params.Offset)
+	}
+
+	err := query.Find(&mistakes).Error
+	return mistakes, total, err
+}
+
+// GetByIDs 获取用户的多条错题
+func (r *MistakeRepository) GetByIDs(userID uint, ids []uint) ([]model.Mistake, error) {
+	var mistakes []model.Mistake
+	err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(&mistakes).Error
+	return mistakes, err
+}
+
+// GetItemIDs 获取用户某类题目已有错题记录的题目ID（用于回填历史数据时跳过）
+func (r *MistakeRepository) GetItemIDs(userID uint, itemType model.MistakeItemType) (map[uint]bool, error) {
+	var ids []uint
+	err := r.db.Model(&model.Mistake{}).Where("user_id = ? AND item_type = ?", userID, itemType).Pluck("item_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+// WrongDictation 历史错误默写记录（带标准答案）
+type WrongDictation struct {
+	DictationType model.DictationType
+	WordID        *uint
+	SentenceID    *uint
+	ArticleID     uint
+	UserAnswer    string
+	AttemptCount  int
+	LastAttempt   time.Time
+	WordText      string
+	SentenceText  string
+}
+
+// GetWrongDictations 获取用户所有答错的默写记录及其标准答案
+func (r *MistakeRepository) GetWrongDictations(userID uint) ([]WrongDictation, error) {
+	var rows []WrongDictation
+	err := r.db.Table("vp_dictation_records AS d").
+		Select("d.dictation_type, d.word_id, d.sentence_id, d.article_id, d.user_answer, d.attempt_count, d.last_attempt, w.text AS word_text, s.text AS sentence_text").
+		Joins("LEFT JOIN vp_words AS w ON w.id = d.word_id").
+		Joins("LEFT JOIN vp_sentences AS s ON s.id = d.sentence_id").
+		Where("d.user_id = ? AND d.is_correct = ?", userID, false).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FailedReviewStat 生词复习失败汇总
+type FailedReviewStat struct {
+	VocabularyID uint
+	ArticleID    *uint
+	Content      string
+	Failures     int
+	LastAnswer   string
+	LastType     string
+	LastAt       time.Time
+}
+
+// GetFailedReviewStats 按生词汇总用户的复习失败记录
+func (r *MistakeRepository) GetFailedReviewStats(userID uint) ([]FailedReviewStat, error) {
+	var rows []FailedReviewStat
+	err := r.db.Raw(`
+		SELECT r.vocabulary_id, MAX(v.article_id) AS article_id, MAX(v.content) AS content,
+			COUNT(*) AS failures, MAX(r.created_at) AS last_at,
+			SUBSTRING_INDEX(GROUP_CONCAT(r.user_answer ORDER BY r.created_at DESC SEPARATOR '\n'), '\n', 1) AS last_answer,
+			SUBSTRING_INDEX(GROUP_CONCAT(r.review_type ORDER BY r.created_at DESC), ',', 1) AS last_type
+		FROM vp_vocabulary_reviews AS r
+		JOIN vp_vocabulary AS v ON v.id = r.vocabulary_id AND v.deleted_at IS NULL
+		WHERE r.user_id = ? AND r.is_correct = 0
+		GROUP BY r.vocabulary_id`, userID).
+		Scan(&rows).Error
+	return rows, err
+}
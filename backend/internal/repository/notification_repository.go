@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository 站内通知仓库
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateBatch 批量创建通知，同一用户已存在相同去重标识的通知时跳过，返回实际创建的数量
+func (r *NotificationRepository) CreateBatch(notifications []model.Notification) (int64, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(notifications, 500)
+	return result.RowsAffected, result.Error
+}
+
+// Exists 用户是否已有相同去重标识的通知
+func (r *NotificationRepository) Exists(userID uint, dedupeKey string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND dedupe_key = ?", userID, dedupeKey).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页获取用户的通知（最新的在前）
+func (r *NotificationRepository) List(userID uint, unreadOnly bool, page, pageSize int) ([]model.Notification, int64, error) {
+	query := r.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []model.Notification
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+// CountUnread 未读通知数
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 标记通知为已读，返回是否找到该通知
+func (r *NotificationRepository) MarkRead(userID, id uint) (bool, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	var count int64
+	err := r.db.Model(&model.Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// MarkAllRead 标记用户的全部通知为已读
+func (r *NotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
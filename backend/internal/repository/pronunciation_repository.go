@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PronunciationRepository 发音缓存仓库
+type PronunciationRepository struct {
+	db *gorm.DB
+}
+
+func NewPronunciationRepository(db *gorm.DB) *PronunciationRepository {
+	return &PronunciationRepository{db: db}
+}
+
+// Get 按文本哈希和音色获取发音
+func (r *PronunciationRepository) Get(textHash, voice string) (*model.Pronunciation, error) {
+	var p model.Pronunciation
+	err := r.db.Where("text_hash = ? AND voice = ?", textHash, voice).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetByHashes 批量获取发音（不存在的不返回）
+func (r *PronunciationRepository) GetByHashes(textHashes []string, voice string) ([]model.Pronunciation, error) {
+	var list []model.Pronunciation
+	if len(textHashes) == 0 {
+		return list, nil
+	}
+	err := r.db.Where("text_hash IN ? AND voice = ?", textHashes, voice).Find(&list).Error
+	return list, err
+}
+
+// Claim 认领生成任务：记录不存在时创建 pending 记录；
+// 已存在时，只有卡住的 pending（更新时间早于 staleBefore）或可重试的 failed（早于 retryBefore 且尝试次数未超限）才能认领。
+// 返回是否认领成功，同一条发音同时只有一个进程生成
+func (r *PronunciationRepository) Claim(p *model.Pronunciation, staleBefore, retryBefore time.Time, maxAttempts int) (bool, error) {
+	p.Status = model.PronunciationPending
+	p.Attempts = 1
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(p)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = r.db.Model(&model.Pronunciation{}).
+		Where("text_hash = ? AND voice = ?", p.TextHash, p.Voice).
+		Where("(status = ? AND updated_at < ?) OR (status = ? AND updated_at < ? AND attempts < ?)",
+			model.PronunciationPending, staleBefore, model.PronunciationFailed, retryBefore, maxAttempts).
+		Updates(map[string]interface{}{
+			"status":   model.PronunciationPending,
+			"attempts": gorm.Expr("attempts + 1"),
+			"error":    "",
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	claimed, err := r.Get(p.TextHash, p.Voice)
+	if err != nil {
+		return false, err
+	}
+	*p = *claimed
+	return true, nil
+}
+
+// MarkReady 标记为已生成
+func (r *PronunciationRepository) MarkReady(id uint, path, contentHash string, size int64) error {
+	return r.db.Model(&model.Pronunciation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       model.PronunciationReady,
+		"path":         path,
+		"content_hash": contentHash,
+		"size":         size,
+		"error":        "",
+	}).Error
+}
+
+// MarkFailed 标记为生成失败
+func (r *PronunciationRepository) MarkFailed(id uint, message string) error {
+	return r.db.Model(&model.Pronunciation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": model.PronunciationFailed,
+		"error":  message,
+	}).Error
+}
+
+// FindPathByContentHash 查找内容相同、已生成的发音的存储路径
+func (r *PronunciationRepository) FindPathByContentHash(contentHash string) (string, error) {
+	var p model.Pronunciation
+	err := r.db.Select("path").
+		Where("content_hash = ? AND status = ? AND path <> ''", contentHash, model.PronunciationReady).
+		Order("id ASC").
+		First(&p).Error
+	return p.Path, err
+}
@@ -175,3 +175,14 @@ func (r *ReadingProgressRepository) GetUserReadingStats(userID uint) (map[string
 		"completed_count":    result.CompletedCount,
 	}, nil
 }
+
+// GetAllByUser 获取用户全部阅读进度（预加载文章，用于推荐）
+func (r *ReadingProgressRepository) GetAllByUser(userID uint) ([]model.ReadingProgress, error) {
+	var progresses []model.ReadingProgress
+	err := r.db.Where("user_id = ?", userID).
+		Preload("Article", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "category_id", "publish_date")
+		}).
+		Find(&progresses).Error
+	return progresses, err
+}
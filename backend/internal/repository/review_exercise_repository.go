@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ReviewExerciseRepository 复习练习仓库
+type ReviewExerciseRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewExerciseRepository(db *gorm.DB) *ReviewExerciseRepository {
+	return &ReviewExerciseRepository{db: db}
+}
+
+// Create 创建练习
+func (r *ReviewExerciseRepository) Create(exercise *model.ReviewExercise) error {
+	return r.db.Create(exercise).Error
+}
+
+// FindByID 获取用户的练习
+func (r *ReviewExerciseRepository) FindByID(id, userID uint) (*model.ReviewExercise, error) {
+	var exercise model.ReviewExercise
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&exercise).Error
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+// MarkAnswered 记录作答结果（只有未作答的练习会被更新，返回是否更新成功，用于防止重复提交）
+func (r *ReviewExerciseRepository) MarkAnswered(exercise *model.ReviewExercise) (bool, error) {
+	result := r.db.Model(&model.ReviewExercise{}).
+		Where("id = ? AND answered_at IS NULL", exercise.ID).
+		Updates(map[string]interface{}{
+			"answered_at": exercise.AnsweredAt,
+			"user_answer": exercise.UserAnswer,
+			"is_correct":  exercise.IsCorrect,
+			"quality":     exercise.Quality,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseAnswer 撤销作答记录（提交复习失败时调用），练习可以重新作答
+func (r *ReviewExerciseRepository) ReleaseAnswer(id uint) error {
+	return r.db.Model(&model.ReviewExercise{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"answered_at": nil,
+			"user_answer": "",
+			"is_correct":  nil,
+			"quality":     nil,
+		}).Error
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ReviewSessionRepository 每日复习会话仓库
+type ReviewSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewSessionRepository(db *gorm.DB) *ReviewSessionRepository {
+	return &ReviewSessionRepository{db: db}
+}
+
+// GetByDate 获取用户某天的会话
+func (r *ReviewSessionRepository) GetByDate(userID uint, date string) (*model.ReviewSession, error) {
+	var session model.ReviewSession
+	err := r.db.Where("user_id = ? AND session_date = ?", userID, date).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Create 创建会话
+func (r *ReviewSessionRepository) Create(session *model.ReviewSession) error {
+	return r.db.Create(session).Error
+}
+
+// Save 保存会话
+func (r *ReviewSessionRepository) Save(session *model.ReviewSession) error {
+	return r.db.Save(session).Error
+}
+
+// GetDueReviews 获取到期的复习卡片（截止 before，按到期时间排序）
+func (r *ReviewSessionRepository) GetDueReviews(userID uint, before time.Time, limit int) ([]model.Vocabulary, error) {
+	var vocabs []model.Vocabulary
+	err := r.db.Select("id", "type", "content", "next_review_at").
+		Where("user_id = ? AND srs_state = ? AND (next_review_at IS NULL OR next_review_at <= ?)", userID, "review", before).
+		Order("next_review_at ASC, mastery_level ASC, id ASC").
+		Limit(limit).
+		Find(&vocabs).Error
+	return vocabs, err
+}
+
+// GetNewCards 获取未学习的新卡片（按添加顺序）
+func (r *ReviewSessionRepository) GetNewCards(userID uint, limit int) ([]model.Vocabulary, error) {
+	var vocabs []model.Vocabulary
+	err := r.db.Select("id", "type", "content", "next_review_at").
+		Where("user_id = ? AND srs_state = ?", userID, "new").
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&vocabs).Error
+	return vocabs, err
+}
+
+// GetLearningCards 获取学习/重学阶段中截止 before 到期的卡片（按到期时间排序）
+func (r *ReviewSessionRepository) GetLearningCards(userID uint, before time.Time) ([]model.Vocabulary, error) {
+	var vocabs []model.Vocabulary
+	err := r.db.Where("user_id = ? AND srs_state IN ? AND next_review_at <= ?", userID, []string{"learning", "relearning"}, before).
+		Order("next_review_at ASC, id ASC").
+		Find(&vocabs).Error
+	return vocabs, err
+}
+
+// GetNextLearningDue 获取学习/重学阶段中最近的到期时间，没有时返回 nil
+func (r *ReviewSessionRepository) GetNextLearningDue(userID uint, before time.Time) (*time.Time, error) {
+	var vocab model.Vocabulary
+	err := r.db.Select("next_review_at").
+		Where("user_id = ? AND srs_state IN ? AND next_review_at <= ?", userID, []string{"learning", "relearning"}, before).
+		Order("next_review_at ASC").
+		First(&vocab).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vocab.NextReviewAt, nil
+}
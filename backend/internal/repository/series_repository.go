@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeriesRepository 系列/合集仓库
+type SeriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// GetList 获取启用的系列列表（可按分类筛选）
+func (r *SeriesRepository) GetList(categoryID *uint, limit, offset int) ([]model.Series, int64, error) {
+	var list []model.Series
+	var total int64
+
+	query := r.db.Model(&model.Series{}).Where("is_active = ?", true)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("sort_order ASC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+// FindByID 根据ID获取系列
+func (r *SeriesRepository) FindByID(id uint) (*model.Series, error) {
+	var series model.Series
+	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&series).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// GetEpisodes 获取系列的剧集列表（按集数排序，附带文章基本信息）
+func (r *SeriesRepository) GetEpisodes(seriesID uint) ([]model.SeriesEpisode, error) {
+	var episodes []model.SeriesEpisode
+	err := r.db.Where("series_id = ?", seriesID).
+		Preload("Article", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, title, pic_url, pic_1_1_url, category_id, publish_date, created_at")
+		}).
+		Order("episode_no ASC, id ASC").
+		Find(&episodes).Error
+	return episodes, err
+}
+
+// GetEpisodesByArticleID 获取包含某篇文章的所有剧集（一篇文章可能属于多个系列）
+func (r *SeriesRepository) GetEpisodesByArticleID(articleID uint) ([]model.SeriesEpisode, error) {
+	var episodes []model.SeriesEpisode
+	err := r.db.Where("article_id = ?", articleID).Find(&episodes).Error
+	return episodes, err
+}
+
+// GetSeriesIDsByArticleIDs 获取包含指定文章的系列ID（去重）
+func (r *SeriesRepository) GetSeriesIDsByArticleIDs(articleIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(articleIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&model.SeriesEpisode{}).
+		Where("article_id IN ?", articleIDs).
+		Distinct().
+		Pluck("series_id", &ids).Error
+	return ids, err
+}
+
+// GetReadingProgressMap 获取用户在指定文章上的阅读进度（文章ID -> 进度）
+func (r *SeriesRepository) GetReadingProgressMap(userID uint, articleIDs []uint) (map[uint]model.ReadingProgress, error) {
+	result := make(map[uint]model.ReadingProgress)
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+
+	var progresses []model.ReadingProgress
+	err := r.db.Where("user_id = ? AND article_id IN ?", userID, articleIDs).Find(&progresses).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range progresses {
+		result[p.ArticleID] = p
+	}
+	return result, nil
+}
+
+// GetDictationProgressMap 获取用户在指定文章上的默写进度（文章ID -> 各类型进度）
+func (r *SeriesRepository) GetDictationProgressMap(userID uint, articleIDs []uint) (map[uint][]model.UserDictationProgress, error) {
+	result := make(map[uint][]model.UserDictationProgress)
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+
+	var progresses []model.UserDictationProgress
+	err := r.db.Where("user_id = ? AND article_id IN ?", userID, articleIDs).Find(&progresses).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range progresses {
+		result[p.ArticleID] = append(result[p.ArticleID], p)
+	}
+	return result, nil
+}
+
+// GetCompletion 获取用户完成系列的记录
+func (r *SeriesRepository) GetCompletion(userID, seriesID uint) (*model.SeriesCompletion, error) {
+	var completion model.SeriesCompletion
+	err := r.db.Where("user_id = ? AND series_id = ?", userID, seriesID).First(&completion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &completion, nil
+}
+
+// CreateCompletion 记录用户完成系列（在事务中执行）
+// 已存在时不插入，返回 false（保证完成奖励只发放一次）
+func (r *SeriesRepository) CreateCompletion(tx *gorm.DB, completion *model.SeriesCompletion) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(completion)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetStartedSeriesIDs 获取用户已开始学习（有阅读或默写记录）的系列ID
+func (r *SeriesRepository) GetStartedSeriesIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	readSub := r.db.Model(&model.ReadingProgress{}).Select("article_id").Where("user_id = ?", userID)
+	dictationSub := r.db.Model(&model.UserDictationProgress{}).Select("article_id").Where("user_id = ?", userID)
+	err := r.db.Model(&model.SeriesEpisode{}).
+		Joins("JOIN vp_series ON vp_series.id = vp_series_episodes.series_id AND vp_series.is_active = ? AND vp_series.deleted_at IS NULL", true).
+		Where("vp_series_episodes.article_id IN (?) OR vp_series_episodes.article_id IN (?)", readSub, dictationSub).
+		Distinct().
+		Pluck("vp_series_episodes.series_id", &ids).Error
+	return ids, err
+}
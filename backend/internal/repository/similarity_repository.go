@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SimilarityRepository 文章相似度仓库
+type SimilarityRepository struct {
+	db *gorm.DB
+}
+
+func NewSimilarityRepository(db *gorm.DB) *SimilarityRepository {
+	return &SimilarityRepository{db: db}
+}
+
+// ReplaceAll 用新的计算结果整体替换相似度表
+func (r *SimilarityRepository) ReplaceAll(rows []model.ArticleSimilarity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&model.ArticleSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// GetRelated 获取与指定文章最相似、截至 today 已发布的文章（按排名）
+func (r *SimilarityRepository) GetRelated(articleID uint, today string, limit int) ([]model.ArticleSimilarity, error) {
+	var rows []model.ArticleSimilarity
+	err := r.db.Joins("JOIN vp_articles ON vp_articles.id = vp_article_similarities.related_article_id AND vp_articles.deleted_at IS NULL AND vp_articles.publish_date <= ?", today).
+		Where("vp_article_similarities.article_id = ?", articleID).
+		Preload("RelatedArticle", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "title", "pic_url", "pic_1_1_url", "category_id", "publish_date", "audio_url", "created_at")
+		}).
+		Order("vp_article_similarities.position ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SRSRepository 间隔重复设置仓库
+type SRSRepository struct {
+	db *gorm.DB
+}
+
+func NewSRSRepository(db *gorm.DB) *SRSRepository {
+	return &SRSRepository{db: db}
+}
+
+// GetParams 获取用户的算法设置，没有设置时返回 nil
+func (r *SRSRepository) GetParams(userID uint) (*model.UserSRSParams, error) {
+	var params model.UserSRSParams
+	err := r.db.Where("user_id = ?", userID).First(&params).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// SaveParams 保存用户的算法设置（按 user_id 覆盖）
+func (r *SRSRepository) SaveParams(params *model.UserSRSParams) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"algorithm", "weights", "request_retention", "maximum_interval",
+			"new_cards_per_day", "reviews_per_day", "learning_steps", "relearning_steps", "migrated_at", "optimized_at", "optimize_report", "updated_at"}),
+	}).Create(params).Error
+}
+
+// GetReviewLogs 获取用户全部复习记录（按生词、时间排序，只取重放需要的字段）
+func (r *SRSRepository) GetReviewLogs(userID uint) ([]model.VocabularyReview, error) {
+	var reviews []model.VocabularyReview
+	err := r.db.Select("id", "vocabulary_id", "quality", "response_time_ms", "created_at").
+		Where("user_id = ?", userID).
+		Order("vocabulary_id ASC, created_at ASC, id ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// GetUserIDsWithReviews 获取复习记录数不少于 minCount 的用户ID
+func (r *SRSRepository) GetUserIDsWithReviews(minCount int) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&model.VocabularyReview{}).
+		Group("user_id").
+		Having("COUNT(*) >= ?", minCount).
+		Order("user_id ASC").
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// CardState 需要迁移的卡片记忆状态
+type CardState struct {
+	EaseFactor   float64
+	IntervalDays int
+	Repetitions  int
+	MasteryLevel int
+	Stability    float64
+	Difficulty   float64
+	Lapses       int
+	State        string
+	Step         int // 学习/重学步骤
+	LastReviewAt *time.Time
+	NextReviewAt *time.Time
+}
+
+// UpdateCardStates 批量更新卡片记忆状态（不修改复习次数等统计字段）
+func (r *SRSRepository) UpdateCardStates(userID uint, states map[uint]CardState) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for vocabID, st := range states {
+			if err := tx.Model(&model.Vocabulary{}).
+				Where("id = ? AND user_id = ?", vocabID, userID).
+				Updates(map[string]interface{}{
+					"ease_factor":    st.EaseFactor,
+					"interval_days":  st.IntervalDays,
+					"repetitions":    st.Repetitions,
+					"mastery_level":  st.MasteryLevel,
+					"stability":      st.Stability,
+					"difficulty":     st.Difficulty,
+					"lapses":         st.Lapses,
+					"srs_state":      st.State,
+					"learning_step":  st.Step,
+					"last_review_at": st.LastReviewAt,
+					"next_review_at": st.NextReviewAt,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/pkg/clock"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakRepository 连续学习仓库（按类型从签到、复习会话、每日统计、默写记录中查询学习日期）
+type StreakRepository struct {
+	db *gorm.DB
+}
+
+func NewStreakRepository(db *gorm.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+// ActiveDates 获取某种连续学习在 since 之后完成的日期（可能有重复，未排序）
+// loc 为用户时区，按时间戳记录的学习（默写）在该时区中划分日期
+func (r *StreakRepository) ActiveDates(userID uint, kind string, since time.Time, loc *time.Location) ([]time.Time, error) {
+	var dates []time.Time
+	var err error
+	switch kind {
+	case model.StreakCheckIn:
+		err = r.db.Model(&model.UserCheckIn{}).
+			Where("user_id = ? AND check_in_date >= ?", userID, since).
+			Pluck("check_in_date", &dates).Error
+	case model.StreakStudy:
+		err = r.db.Model(&model.UserDailyStats{}).
+			Where("user_id = ? AND stat_date >= ? AND total_duration_seconds >= ?", userID, since, model.StreakStudyMinutes*60).
+			Pluck("stat_date", &dates).Error
+	case model.StreakDictation:
+		var times []time.Time
+		err = r.db.Model(&model.DictationRecord{}).
+			Where("user_id = ? AND created_at >= ?", userID, clock.StartOf(since, loc)).
+			Pluck("created_at", &times).Error
+		for _, t := range times {
+			dates = append(dates, clock.Date(t, loc))
+		}
+	case model.StreakReview:
+		var days []string
+		err = r.db.Model(&model.ReviewSession{}).
+			Where("user_id = ? AND status = ? AND session_date >= ?", userID, model.ReviewSessionFinished, since.Format("2006-01-02")).
+			Pluck("session_date", &days).Error
+		for _, day := range days {
+			if date, parseErr := time.ParseInLocation("2006-01-02", day, time.Local); parseErr == nil {
+				dates = append(dates, date)
+			}
+		}
+	}
+	return dates, err
+}
+
+// ListProtections 获取 since 之后的保护记录（指定类型和冻结卡）
+func (r *StreakRepository) ListProtections(userID uint, kind string, since time.Time) ([]model.StreakProtection, error) {
+	var list []model.StreakProtection
+	err := r.db.Where("user_id = ? AND kind IN ? AND protect_date >= ?", userID, []string{kind, model.StreakAll}, since).
+		Order("protect_date ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// CreateProtection 创建保护记录（事务中执行），同一天已有同类型记录时返回 false
+func (r *StreakRepository) CreateProtection(tx *gorm.DB, p *model.StreakProtection) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(p)
+	return result.RowsAffected == 1, result.Error
+}
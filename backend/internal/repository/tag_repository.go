@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository 标签仓库
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// GetAllWithCount 获取启用的标签及每个标签下的文章数（tagType 为空时返回全部类型）
+func (r *TagRepository) GetAllWithCount(tagType string) ([]model.TagWithCount, error) {
+	var tags []model.TagWithCount
+	query := r.db.Table("vp_tags").
+		Select("vp_tags.*, COUNT(vp_articles.id) as article_count").
+		Joins("LEFT JOIN vp_article_tags ON vp_article_tags.tag_id = vp_tags.id").
+		Joins("LEFT JOIN vp_articles ON vp_articles.id = vp_article_tags.article_id AND vp_articles.deleted_at IS NULL").
+		Where("vp_tags.is_active = ?", true)
+	if tagType != "" {
+		query = query.Where("vp_tags.type = ?", tagType)
+	}
+	err := query.Group("vp_tags.id").
+		Order("vp_tags.sort_order ASC, vp_tags.id ASC").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// FindByID 根据ID获取标签
+func (r *TagRepository) FindByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindBySlugs 根据标识批量获取启用的标签
+func (r *TagRepository) FindBySlugs(slugs []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(slugs) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("slug IN ? AND is_active = ?", slugs, true).Find(&tags).Error
+	return tags, err
+}
+
+// Create 创建标签
+func (r *TagRepository) Create(tag *model.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// Update 更新标签
+func (r *TagRepository) Update(tag *model.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete 删除标签，同时移除文章关联和用户订阅
+// 直接物理删除：slug 有唯一索引，软删除会让已删除标签的 slug 无法再次使用
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&model.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&model.UserTagSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, id).Error
+	})
+}
+
+// GetArticleTags 获取文章的标签
+func (r *TagRepository) GetArticleTags(articleID uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.Joins("JOIN vp_article_tags ON vp_article_tags.tag_id = vp_tags.id").
+		Where("vp_article_tags.article_id = ? AND vp_tags.is_active = ?", articleID, true).
+		Order("vp_tags.sort_order ASC, vp_tags.id ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+// SetArticleTags 设置文章的标签（覆盖原有标签，不存在的标签ID会被忽略）
+func (r *TagRepository) SetArticleTags(articleID uint, tagIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", articleID).Delete(&model.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		var validIDs []uint
+		if err := tx.Model(&model.Tag{}).Where("id IN ?", tagIDs).Pluck("id", &validIDs).Error; err != nil {
+			return err
+		}
+		if len(validIDs) == 0 {
+			return nil
+		}
+		items := make([]model.ArticleTag, 0, len(validIDs))
+		for _, id := range validIDs {
+			items = append(items, model.ArticleTag{ArticleID: articleID, TagID: id})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&items).Error
+	})
+}
+
+// GetArticleIDsByTags 获取带有指定标签的文章ID
+// matchAll 为 true 时要求文章同时带有全部标签（AND），否则带有任一标签即可（OR）
+func (r *TagRepository) GetArticleIDsByTags(tagIDs []uint, matchAll bool) ([]uint, error) {
+	var ids []uint
+	if len(tagIDs) == 0 {
+		return ids, nil
+	}
+	query := r.db.Model(&model.ArticleTag{}).
+		Select("article_id").
+		Where("tag_id IN ?", tagIDs).
+		Group("article_id")
+	if matchAll {
+		query = query.Having("COUNT(DISTINCT tag_id) = ?", len(tagIDs))
+	}
+	err := query.Pluck("article_id", &ids).Error
+	return ids, err
+}
+
+// GetTagIDsByArticleIDs 批量获取文章的标签ID（文章ID -> 标签ID列表）
+func (r *TagRepository) GetTagIDsByArticleIDs(articleIDs []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+	var rows []model.ArticleTag
+	err := r.db.Select("article_id, tag_id").Where("article_id IN ?", articleIDs).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.ArticleID] = append(result[row.ArticleID], row.TagID)
+	}
+	return result, nil
+}
+
+// Subscribe 订阅话题（已订阅时更新通知开关）
+func (r *TagRepository) Subscribe(userID, tagID uint, notifyEnabled bool) (*model.UserTagSubscription, error) {
+	sub := &model.UserTagSubscription{
+		UserID:        userID,
+		TagID:         tagID,
+		NotifyEnabled: notifyEnabled,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "tag_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"notify_enabled": notifyEnabled, "updated_at": time.Now()}),
+	}).Create(sub).Error
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Unsubscribe 取消订阅
+func (r *TagRepository) Unsubscribe(userID, tagID uint) error {
+	return r.db.Where("user_id = ? AND tag_id = ?", userID, tagID).Delete(&model.UserTagSubscription{}).Error
+}
+
+// GetSubscriptions 获取用户订阅的话题
+func (r *TagRepository) GetSubscriptions(userID uint) ([]model.UserTagSubscription, error) {
+	var subs []model.UserTagSubscription
+	err := r.db.Where("user_id = ?", userID).
+		Preload("Tag").
+		Order("created_at DESC").
+		Find(&subs).Error
+	return subs, err
+}
+
+// GetSubscribedTagIDs 获取用户订阅的标签ID（供推荐使用）
+func (r *TagRepository) GetSubscribedTagIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.UserTagSubscription{}).
+		Where("user_id = ?", userID).
+		Pluck("tag_id", &ids).Error
+	return ids, err
+}
+
+// GetNotifySubscriberIDs 获取订阅了文章任一标签且开启通知的用户ID（供新文章通知使用）
+func (r *TagRepository) GetNotifySubscriberIDs(articleID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.UserTagSubscription{}).
+		Joins("JOIN vp_article_tags ON vp_article_tags.tag_id = vp_user_tag_subscriptions.tag_id").
+		Where("vp_article_tags.article_id = ? AND vp_user_tag_subscriptions.notify_enabled = ?", articleID, true).
+		Distinct().
+		Pluck("vp_user_tag_subscriptions.user_id", &ids).Error
+	return ids, err
+}
+
+// GetTaggedArticlesPublishedOn 获取某天发布且打了标签的文章（供新文章通知使用）
+func (r *TagRepository) GetTaggedArticlesPublishedOn(date string) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Select("id", "title").
+		Where("publish_date = ? AND EXISTS (SELECT 1 FROM vp_article_tags WHERE vp_article_tags.article_id = vp_articles.id)", date).
+		Find(&articles).Error
+	return articles, err
+}
@@ -44,6 +44,53 @@ func (r *TitleRepository) GetTitleConfigsByCategory(category model.TitleCategory
 	return configs, err
 }
 
+// ListTitleConfigs 获取所有称号配置（含未启用的，管理后台使用）
+func (r *TitleRepository) ListTitleConfigs() ([]model.TitleConfig, error) {
+	var configs []model.TitleConfig
+	err := r.db.Order("sort_order ASC, id ASC").Find(&configs).Error
+	return configs, err
+}
+
+// GetTitleConfigByID 根据ID获取称号配置
+func (r *TitleRepository) GetTitleConfigByID(id uint) (*model.TitleConfig, error) {
+	var config model.TitleConfig
+	if err := r.db.First(&config, id).Error; err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetTitleConfigsByFamily 获取同一系列的称号配置
+func (r *TitleRepository) GetTitleConfigsByFamily(familyKey string) ([]model.TitleConfig, error) {
+	var configs []model.TitleConfig
+	err := r.db.Where("family_key = ?", familyKey).Find(&configs).Error
+	return configs, err
+}
+
+// TitleKeyExists 称号标识是否已被其他配置使用（含已删除的，唯一索引仍然生效）
+func (r *TitleRepository) TitleKeyExists(key string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.TitleConfig{}).
+		Where("title_key = ? AND id <> ?", key, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateTitleConfig 创建称号配置
+func (r *TitleRepository) CreateTitleConfig(config *model.TitleConfig) error {
+	return r.db.Create(config).Error
+}
+
+// UpdateTitleConfig 更新称号配置
+func (r *TitleRepository) UpdateTitleConfig(config *model.TitleConfig) error {
+	return r.db.Save(config).Error
+}
+
+// DeleteTitleConfig 删除称号配置（软删除，已获得的用户称号保留）
+func (r *TitleRepository) DeleteTitleConfig(id uint) error {
+	return r.db.Delete(&model.TitleConfig{}, id).Error
+}
+
 // ============ UserTitle 相关 ============
 
 // CreateUserTitle 创建用户称号（在事务中执行）
@@ -61,6 +108,15 @@ func (r *TitleRepository) GetUserTitles(userID uint) ([]model.UserTitle, error)
 	return userTitles, err
 }
 
+// GetUserTitleIDs 获取用户已拥有称号的配置ID
+func (r *TitleRepository) GetUserTitleIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.UserTitle{}).
+		Where("user_id = ?", userID).
+		Pluck("title_config_id", &ids).Error
+	return ids, err
+}
+
 // CheckUserHasTitle 检查用户是否已拥有该称号
 func (r *TitleRepository) CheckUserHasTitle(userID, titleConfigID uint) (bool, error) {
 	var count int64
@@ -15,10 +15,9 @@ func NewUserDailyStatsRepository(db *gorm.DB) *UserDailyStatsRepository {
 	return &UserDailyStatsRepository{db: db}
 }
 
-// GetOrCreateTodayStats 获取或创建今日统计
-func (r *UserDailyStatsRepository) GetOrCreateTodayStats(userID uint) (*model.UserDailyStats, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+// GetOrCreateStats 获取或创建某天（用户时区的日期）的统计
+func (r *UserDailyStatsRepository) GetOrCreateStats(userID uint, date time.Time) (*model.UserDailyStats, error) {
+	today := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
 	var stats model.UserDailyStats
 	err := r.db.Where("user_id = ? AND stat_date = ?", userID, today).First(&stats).Error
@@ -40,9 +39,9 @@ func (r *UserDailyStatsRepository) GetOrCreateTodayStats(userID uint) (*model.Us
 	return &stats, nil
 }
 
-// UpdateDuration 更新今日时长（累加）
-func (r *UserDailyStatsRepository) UpdateDuration(userID uint, durationSeconds int) error {
-	stats, err := r.GetOrCreateTodayStats(userID)
+// UpdateDuration 更新某天的时长（累加）
+func (r *UserDailyStatsRepository) UpdateDuration(userID uint, date time.Time, durationSeconds int) error {
+	stats, err := r.GetOrCreateStats(userID, date)
 	if err != nil {
 		return err
 	}
@@ -54,9 +53,9 @@ func (r *UserDailyStatsRepository) UpdateDuration(userID uint, durationSeconds i
 		Error
 }
 
-// IncrementNewWords 增加新学单词数
-func (r *UserDailyStatsRepository) IncrementNewWords(userID uint, count int) error {
-	stats, err := r.GetOrCreateTodayStats(userID)
+// IncrementNewWords 增加某天的新学单词数
+func (r *UserDailyStatsRepository) IncrementNewWords(userID uint, date time.Time, count int) error {
+	stats, err := r.GetOrCreateStats(userID, date)
 	if err != nil {
 		return err
 	}
@@ -69,9 +68,9 @@ func (r *UserDailyStatsRepository) IncrementNewWords(userID uint, count int) err
 		}).Error
 }
 
-// IncrementReviewedWords 增加复习单词数
-func (r *UserDailyStatsRepository) IncrementReviewedWords(userID uint, count int) error {
-	stats, err := r.GetOrCreateTodayStats(userID)
+// IncrementReviewedWords 增加某天的复习单词数
+func (r *UserDailyStatsRepository) IncrementReviewedWords(userID uint, date time.Time, count int) error {
+	stats, err := r.GetOrCreateStats(userID, date)
 	if err != nil {
 		return err
 	}
@@ -84,9 +83,9 @@ func (r *UserDailyStatsRepository) IncrementReviewedWords(userID uint, count int
 		}).Error
 }
 
-// RecordAttempt 记录一次尝试（正确或错误）
-func (r *UserDailyStatsRepository) RecordAttempt(userID uint, isCorrect bool) error {
-	stats, err := r.GetOrCreateTodayStats(userID)
+// RecordAttempt 记录某天的一次尝试（正确或错误）
+func (r *UserDailyStatsRepository) RecordAttempt(userID uint, date time.Time, isCorrect bool) error {
+	stats, err := r.GetOrCreateStats(userID, date)
 	if err != nil {
 		return err
 	}
@@ -104,11 +103,6 @@ func (r *UserDailyStatsRepository) RecordAttempt(userID uint, isCorrect bool) er
 		Updates(updates).Error
 }
 
-// GetTodayStats 获取今日统计
-func (r *UserDailyStatsRepository) GetTodayStats(userID uint) (*model.UserDailyStats, error) {
-	return r.GetOrCreateTodayStats(userID)
-}
-
 // GetStatsByDate 获取指定日期的统计
 func (r *UserDailyStatsRepository) GetStatsByDate(userID uint, date time.Time) (*model.UserDailyStats, error) {
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
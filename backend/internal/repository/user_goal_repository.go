@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UserGoalRepository 学习目标仓库
+type UserGoalRepository struct {
+	db *gorm.DB
+}
+
+func NewUserGoalRepository(db *gorm.DB) *UserGoalRepository {
+	return &UserGoalRepository{db: db}
+}
+
+// Create 创建学习目标
+func (r *UserGoalRepository) Create(goal *model.UserGoal) error {
+	return r.db.Create(goal).Error
+}
+
+// Save 保存学习目标
+func (r *UserGoalRepository) Save(goal *model.UserGoal) error {
+	return r.db.Save(goal).Error
+}
+
+// Get 获取用户的某个学习目标
+func (r *UserGoalRepository) Get(id, userID uint) (*model.UserGoal, error) {
+	var goal model.UserGoal
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&goal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// ListByUser 获取用户的学习目标，status 为空时返回全部（进行中的在前）
+func (r *UserGoalRepository) ListByUser(userID uint, status string) ([]model.UserGoal, error) {
+	var goals []model.UserGoal
+	query := r.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("status = 'active' DESC, deadline ASC, id DESC").Find(&goals).Error
+	return goals, err
+}
+
+// ListActive 按 ID 分批获取所有用户进行中的学习目标（后台任务使用）
+func (r *UserGoalRepository) ListActive(afterID uint, limit int) ([]model.UserGoal, error) {
+	var goals []model.UserGoal
+	err := r.db.Where("status = ? AND id > ?", model.GoalStatusActive, afterID).
+		Order("id ASC").Limit(limit).Find(&goals).Error
+	return goals, err
+}
+
+// CountActive 统计用户进行中的学习目标数
+func (r *UserGoalRepository) CountActive(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UserGoal{}).
+		Where("user_id = ? AND status = ?", userID, model.GoalStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// ExistsActiveForBook 用户是否已有该单词书的进行中目标
+func (r *UserGoalRepository) ExistsActiveForBook(userID uint, wordType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.UserGoal{}).
+		Where("user_id = ? AND word_type = ? AND status = ?", userID, wordType, model.GoalStatusActive).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Delete 删除学习目标
+func (r *UserGoalRepository) Delete(id, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.UserGoal{}).Error
+}
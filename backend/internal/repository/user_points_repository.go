@@ -80,6 +80,29 @@ func (r *UserPointsRepository) IncrementDictationsCompleted(userID uint) error {
 		Error
 }
 
+// IncrementReviews 增加复习计数
+func (r *UserPointsRepository) IncrementReviews(userID uint) error {
+	return r.db.Model(&model.UserPoints{}).
+		Where("user_id = ?", userID).
+		UpdateColumn("total_reviews", gorm.Expr("total_reviews + ?", 1)).
+		Error
+}
+
+// UpdatePerfectStreak 更新连续默写全对次数：答对加一并刷新最大值，答错清零
+func (r *UserPointsRepository) UpdatePerfectStreak(userID uint, correct bool) error {
+	if !correct {
+		return r.db.Model(&model.UserPoints{}).
+			Where("user_id = ?", userID).
+			UpdateColumn("perfect_streak", 0).Error
+	}
+	// MySQL 按书写顺序执行 SET，先用更新前的 perfect_streak 计算最大值
+	return r.db.Exec(`
+		UPDATE vp_user_points
+		SET max_perfect_streak = GREATEST(max_perfect_streak, perfect_streak + 1),
+			perfect_streak = perfect_streak + 1
+		WHERE user_id = ? AND deleted_at IS NULL`, userID).Error
+}
+
 // UpdateCheckInStats 更新签到统计（在事务中执行）
 func (r *UserPointsRepository) UpdateCheckInStats(tx *gorm.DB, userID uint, continuousDays int) error {
 	// 一次性更新所有字段，使用 CASE WHEN 来更新最大连续签到天数
@@ -164,3 +187,37 @@ func (r *UserPointsRepository) DeductMakeupCards(tx *gorm.DB, userID uint, count
 		Where("user_id = ?", userID).
 		Update("makeup_cards", gorm.Expr("makeup_cards - ?", count)).Error
 }
+
+// AddStreakFreezes 增加连续学习冻结卡数量（事务中执行），增加后超过 limit 张时不修改并返回 false
+// 上限在 UPDATE 条件中检查，并发购买也不会超过
+func (r *UserPointsRepository) AddStreakFreezes(tx *gorm.DB, userID uint, count, limit int) (bool, error) {
+	result := tx.Model(&model.UserPoints{}).
+		Where("user_id = ? AND streak_freezes + ? <= ?", userID, count, limit).
+		Update("streak_freezes", gorm.Expr("streak_freezes + ?", count))
+	return result.RowsAffected == 1, result.Error
+}
+
+// AddStreakFreezesUpTo 增加冻结卡数量，最多到 limit 张（事务中执行）
+func (r *UserPointsRepository) AddStreakFreezesUpTo(tx *gorm.DB, userID uint, count, limit int) error {
+	return tx.Model(&model.UserPoints{}).
+		Where("user_id = ?", userID).
+		Update("streak_freezes", gorm.Expr("GREATEST(streak_freezes, LEAST(streak_freezes + ?, ?))", count, limit)).Error
+}
+
+// ListUserIDsWithStreakFreezes 按用户ID分批获取持有冻结卡的用户（后台任务使用）
+func (r *UserPointsRepository) ListUserIDsWithStreakFreezes(afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.UserPoints{}).
+		Where("streak_freezes > 0 AND user_id > ?", afterID).
+		Order("user_id ASC").Limit(limit).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// DeductStreakFreeze 扣除一张冻结卡（事务中执行），没有冻结卡时返回 false
+func (r *UserPointsRepository) DeductStreakFreeze(tx *gorm.DB, userID uint) (bool, error) {
+	result := tx.Model(&model.UserPoints{}).
+		Where("user_id = ? AND streak_freezes > 0", userID).
+		Update("streak_freezes", gorm.Expr("streak_freezes - 1"))
+	return result.RowsAffected == 1, result.Error
+}
@@ -82,6 +82,21 @@ func (r *UserRepository) Update(user *model.User) error {
 	return DB.Save(user).Error
 }
 
+// GetTimezone 获取用户设置的时区（未设置时为空）
+func (r *UserRepository) GetTimezone(userID uint) (string, error) {
+	var timezones []string
+	err := DB.Model(&model.User{}).Where("id = ?", userID).Limit(1).Pluck("timezone", &timezones).Error
+	if err != nil || len(timezones) == 0 {
+		return "", err
+	}
+	return timezones[0], nil
+}
+
+// UpdateTimezone 更新用户时区
+func (r *UserRepository) UpdateTimezone(userID uint, timezone string) error {
+	return DB.Model(&model.User{}).Where("id = ?", userID).Update("timezone", timezone).Error
+}
+
 // UpdateLastLogin 更新最后登录时间和IP
 func (r *UserRepository) UpdateLastLogin(userID uint, ip string) error {
 	now := time.Now()
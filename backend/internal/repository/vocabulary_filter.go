@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// VocabularyFilter 生词筛选条件（智能文件夹的定义，也用于复习范围和导出）
+// 各条件之间为「且」，未设置的条件不生效
+type VocabularyFilter struct {
+	Type            string   `json:"type,omitempty"`              // word/phrase/sentence
+	MasteryMin      *int     `json:"mastery_min,omitempty"`       // 掌握等级下限（含）
+	MasteryMax      *int     `json:"mastery_max,omitempty"`       // 掌握等级上限（含），「掌握等级 < 3」即 mastery_max=2
+	IsStarred       *bool    `json:"is_starred,omitempty"`        // 是否标星
+	Tags            []string `json:"tags,omitempty"`              // 包含任一标签
+	Source          string   `json:"source,omitempty"`            // 来源：article/manual/wordbook/import/mistake
+	SrsState        string   `json:"srs_state,omitempty"`         // 学习阶段：new/learning/review/relearning
+	ArticleID       *uint    `json:"article_id,omitempty"`        // 来源文章
+	CategoryID      *uint    `json:"category_id,omitempty"`       // 来源文章的分类
+	FolderID        *uint    `json:"folder_id,omitempty"`         // 属于某个普通文件夹
+	AddedSince      string   `json:"added_since,omitempty"`       // 添加时间：today/this_week/this_month
+	AddedWithinDays int      `json:"added_within_days,omitempty"` // 最近 N 天内添加
+	WrongCountMin   *int     `json:"wrong_count_min,omitempty"`   // 答错次数下限
+	LapsesMin       *int     `json:"lapses_min,omitempty"`        // 遗忘次数下限
+	Keyword         string   `json:"keyword,omitempty"`           // 内容或释义包含关键词
+}
+
+// 筛选条件错误
+var (
+	ErrEmptyVocabularyFilter   = errors.New("筛选条件不能为空")
+	ErrInvalidVocabularyFilter = errors.New("无效的筛选条件")
+)
+
+// ParseVocabularyFilter 解析智能文件夹保存的筛选条件 JSON
+func ParseVocabularyFilter(raw string) (*VocabularyFilter, error) {
+	var f VocabularyFilter
+	if err := json.Unmarshal([]byte(raw), &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate 检查筛选条件是否有效
+func (f *VocabularyFilter) Validate() error {
+	if f.IsEmpty() {
+		return ErrEmptyVocabularyFilter
+	}
+	switch f.Type {
+	case "", string(model.VocabularyTypeWord), string(model.VocabularyTypePhrase), string(model.VocabularyTypeSentence):
+	default:
+		return ErrInvalidVocabularyFilter
+	}
+	switch f.SrsState {
+	case "", "new", "learning", "review", "relearning":
+	default:
+		return ErrInvalidVocabularyFilter
+	}
+	switch f.AddedSince {
+	case "", "today", "this_week", "this_month":
+	default:
+		return ErrInvalidVocabularyFilter
+	}
+	if f.MasteryMin != nil && f.MasteryMax != nil && *f.MasteryMin > *f.MasteryMax {
+		return ErrInvalidVocabularyFilter
+	}
+	if f.AddedWithinDays < 0 || len(f.Tags) > 20 || len(f.Keyword) > 100 {
+		return ErrInvalidVocabularyFilter
+	}
+	return nil
+}
+
+// IsEmpty 是否没有设置任何条件
+func (f *VocabularyFilter) IsEmpty() bool {
+	return f.Type == "" && f.MasteryMin == nil && f.MasteryMax == nil && f.IsStarred == nil &&
+		len(f.Tags) == 0 && f.Source == "" && f.SrsState == "" && f.ArticleID == nil &&
+		f.CategoryID == nil && f.FolderID == nil && f.AddedSince == "" && f.AddedWithinDays == 0 &&
+		f.WrongCountMin == nil && f.LapsesMin == nil && f.Keyword == ""
+}
+
+// Apply 把筛选条件加到 vp_vocabulary 的查询上
+func (f *VocabularyFilter) Apply(query *gorm.DB, now time.Time) *gorm.DB {
+	if f == nil {
+		return query
+	}
+	if f.Type != "" {
+		query = query.Where("vp_vocabulary.type = ?", f.Type)
+	}
+	if f.MasteryMin != nil {
+		query = query.Where("vp_vocabulary.mastery_level >= ?", *f.MasteryMin)
+	}
+	if f.MasteryMax != nil {
+		query = query.Where("vp_vocabulary.mastery_level <= ?", *f.MasteryMax)
+	}
+	if f.IsStarred != nil {
+		query = query.Where("vp_vocabulary.is_starred = ?", *f.IsStarred)
+	}
+	if len(f.Tags) > 0 {
+		// tags 为 JSON 数组字符串，按带引号的标签匹配
+		conds := make([]string, 0, len(f.Tags))
+		args := make([]interface{}, 0, len(f.Tags))
+		for _, tag := range f.Tags {
+			encoded, _ := json.Marshal(tag)
+			conds = append(conds, "vp_vocabulary.tags LIKE ?")
+			args = append(args, "%"+escapeLike(string(encoded))+"%")
+		}
+		query = query.Where(strings.Join(conds, " OR "), args...)
+	}
+	if f.Source != "" {
+		query = query.Where("vp_vocabulary.source = ?", f.Source)
+	}
+	if f.SrsState != "" {
+		query = query.Where("vp_vocabulary.srs_state = ?", f.SrsState)
+	}
+	if f.ArticleID != nil {
+		query = query.Where("vp_vocabulary.article_id = ?", *f.ArticleID)
+	}
+	if f.CategoryID != nil {
+		query = query.Where("vp_vocabulary.article_id IN (?)",
+			query.Session(&gorm.Session{NewDB: true}).Model(&model.Article{}).Select("id").Where("category_id = ?", *f.CategoryID))
+	}
+	if f.FolderID != nil {
+		query = query.Where("vp_vocabulary.id IN (?)",
+			query.Session(&gorm.Session{NewDB: true}).Model(&model.VocabularyFolderItem{}).Select("vocabulary_id").Where("folder_id = ?", *f.FolderID))
+	}
+	if since := f.addedSince(now); !since.IsZero() {
+		query = query.Where("vp_vocabulary.created_at >= ?", since)
+	}
+	if f.WrongCountMin != nil {
+		query = query.Where("vp_vocabulary.wrong_count >= ?", *f.WrongCountMin)
+	}
+	if f.LapsesMin != nil {
+		query = query.Where("vp_vocabulary.lapses >= ?", *f.LapsesMin)
+	}
+	if f.Keyword != "" {
+		keyword := "%" + escapeLike(f.Keyword) + "%"
+		query = query.Where("vp_vocabulary.content LIKE ? OR vp_vocabulary.meaning LIKE ?", keyword, keyword)
+	}
+	return query
+}
+
+// addedSince 添加时间条件对应的起始时间，两个条件都设置时取较晚的
+func (f *VocabularyFilter) addedSince(now time.Time) time.Time {
+	var since time.Time
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch f.AddedSince {
+	case "today":
+		since = today
+	case "this_week":
+		// 一周从周一开始
+		offset := (int(today.Weekday()) + 6) % 7
+		since = today.AddDate(0, 0, -offset)
+	case "this_month":
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	if f.AddedWithinDays > 0 {
+		if t := now.AddDate(0, 0, -f.AddedWithinDays); t.After(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -35,13 +35,32 @@ func (r *VocabularyRepository) GetByID(id uint) (*model.Vocabulary, error) {
 }
 
 // GetByUserIDAndContent 检查用户是否已添加过该生词
-func (r *VocabularyRepository) GetByUserIDAndContent(userID uint, content string) (*model.Vocabulary, error) {
-	var vocab model.Vocabulary
-	err := r.db.Where("user_id = ? AND content = ?", userID, content).First(&vocab).Error
+// lemma 不为空时同一单词的其他形式也视为已添加（running 与 run），内容完全相同的优先返回
+func (r *VocabularyRepository) GetByUserIDAndContent(userID uint, content, lemma string) (*model.Vocabulary, error) {
+	if lemma == "" {
+		var vocab model.Vocabulary
+		err := r.db.Where("user_id = ? AND content = ?", userID, content).First(&vocab).Error
+		if err != nil {
+			return nil, err
+		}
+		return &vocab, nil
+	}
+
+	var vocabs []model.Vocabulary
+	err := r.db.Where("user_id = ? AND (content = ? OR lemma = ?)", userID, content, lemma).
+		Order("id ASC").Limit(10).Find(&vocabs).Error
 	if err != nil {
 		return nil, err
 	}
-	return &vocab, nil
+	if len(vocabs) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	for i := range vocabs {
+		if vocabs[i].Content == content {
+			return &vocabs[i], nil
+		}
+	}
+	return &vocabs[0], nil
 }
 
 // Update 更新生词
@@ -77,6 +96,9 @@ func (r *VocabularyRepository) ListByUserID(userID uint, params *VocabularyListP
 	if params.ArticleID != nil {
 		query = query.Where("article_id = ?", *params.ArticleID)
 	}
+	if params.Filter != nil {
+		query = params.Filter.Apply(query, params.Now)
+	}
 
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
@@ -124,14 +146,17 @@ type VocabularyListParams struct {
 	Limit        int
 	Offset       int
 	WithArticle  bool
+	Filter       *VocabularyFilter // 智能文件夹的筛选条件
+	Now          time.Time         // 用户时区的当前时刻，Filter 按它计算今天、本周、本月
 }
 
-// GetTodayReviewList 获取今日待复习列表
-func (r *VocabularyRepository) GetTodayReviewList(userID uint, limit int) ([]model.Vocabulary, error) {
+// GetTodayReviewList 获取今日待复习列表，filter 不为空时只复习符合条件的生词（如某个文件夹）
+// now 为用户时区的当前时刻
+func (r *VocabularyRepository) GetTodayReviewList(userID uint, limit int, filter *VocabularyFilter, now time.Time) ([]model.Vocabulary, error) {
 	var vocabs []model.Vocabulary
-	now := time.Now()
 
-	query := r.db.Where("user_id = ? AND (next_review_at IS NULL OR next_review_at <= ?)", userID, now).
+	query := r.db.Where("user_id = ? AND (next_review_at IS NULL OR next_review_at <= ?)", userID, now)
+	query = filter.Apply(query, now).
 		Order("CASE WHEN next_review_at IS NULL THEN 0 ELSE 1 END, next_review_at ASC, mastery_level ASC")
 
 	if limit > 0 {
@@ -356,3 +381,61 @@ func (r *VocabularyRepository) GetDailyStatsRange(userID uint, startDate, endDat
 		Order("stat_date ASC").Find(&stats).Error
 	return stats, err
 }
+
+// GetDueContents 获取在指定时间之前到期的生词内容（仅单词和短语）
+func (r *VocabularyRepository) GetDueContents(userID uint, before time.Time) ([]string, error) {
+	var contents []string
+	err := r.db.Model(&model.Vocabulary{}).
+		Where("user_id = ? AND type IN ? AND (next_review_at IS NULL OR next_review_at <= ?)",
+			userID, []model.VocabularyType{model.VocabularyTypeWord, model.VocabularyTypePhrase}, before).
+		Pluck("content", &contents).Error
+	return contents, err
+}
+
+// ==================== 导入导出 ====================
+
+// ListForExport 获取用户的全部生词，按添加顺序
+// folder 不为空时只取该文件夹中的（智能文件夹按筛选条件，now 为用户时区的当前时刻）
+func (r *VocabularyRepository) ListForExport(userID uint, folder *model.VocabularyFolder, now time.Time) ([]model.Vocabulary, error) {
+	var vocabs []model.Vocabulary
+	query := r.db.Where("user_id = ?", userID)
+	if folder != nil {
+		if folder.IsSmart() {
+			filter, err := ParseVocabularyFilter(folder.Filter)
+			if err != nil {
+				return nil, err
+			}
+			query = filter.Apply(query, now)
+		} else {
+			subQuery := r.db.Model(&model.VocabularyFolderItem{}).
+				Select("vocabulary_id").
+				Where("folder_id = ?", folder.ID)
+			query = query.Where("id IN (?)", subQuery)
+		}
+	}
+	err := query.Order("created_at ASC, id ASC").Find(&vocabs).Error
+	return vocabs, err
+}
+
+// GetFolderNames 获取用户每个生词所属的文件夹名称（vocabulary_id -> 名称列表）
+func (r *VocabularyRepository) GetFolderNames(userID uint) (map[uint][]string, error) {
+	var rows []struct {
+		VocabularyID uint
+		Name         string
+	}
+	err := r.db.Table("vp_vocabulary_folder_items AS i").
+		Select("i.vocabulary_id, f.name").
+		Joins("JOIN vp_vocabulary_folders AS f ON f.id = i.folder_id AND f.deleted_at IS NULL").
+		Where("f.user_id = ?", userID).
+		Order("f.sort_order ASC, f.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint][]string)
+	for _, row := range rows {
+		names[row.VocabularyID] = append(names[row.VocabularyID], row.Name)
+	}
+	return names, nil
+}
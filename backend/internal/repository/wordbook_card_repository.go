@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"time"
+	"voicepaper/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// WordbookCardRepository 单词书记忆卡片仓库
+type WordbookCardRepository struct {
+	db *gorm.DB
+}
+
+func NewWordbookCardRepository(db *gorm.DB) *WordbookCardRepository {
+	return &WordbookCardRepository{db: db}
+}
+
+// Get 获取用户在某本单词书中某个单词的卡片
+func (r *WordbookCardRepository) Get(userID uint, wordType string, wordID uint) (*model.WordbookCard, error) {
+	var card model.WordbookCard
+	err := r.db.Where("user_id = ? AND word_type = ? AND word_id = ?", userID, wordType, wordID).First(&card).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// Save 创建或更新卡片
+func (r *WordbookCardRepository) Save(card *model.WordbookCard) error {
+	return r.db.Save(card).Error
+}
+
+// GetDue 获取到期的卡片（截止 before，按到期时间排序）
+func (r *WordbookCardRepository) GetDue(userID uint, wordType string, before time.Time, limit int) ([]model.WordbookCard, error) {
+	var cards []model.WordbookCard
+	query := r.db.Where("user_id = ? AND word_type = ? AND next_review_at <= ?", userID, wordType, before).
+		Order("next_review_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&cards).Error
+	return cards, err
+}
+
+// CountDue 统计到期的卡片数
+func (r *WordbookCardRepository) CountDue(userID uint, wordType string, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookCard{}).
+		Where("user_id = ? AND word_type = ? AND next_review_at <= ?", userID, wordType, before).
+		Count(&count).Error
+	return count, err
+}
+
+// CountCreatedSince 统计某时间之后第一次学习的单词数（今日已学新词）
+func (r *WordbookCardRepository) CountCreatedSince(userID uint, wordType string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookCard{}).
+		Where("user_id = ? AND word_type = ? AND created_at >= ?", userID, wordType, since).
+		Count(&count).Error
+	return count, err
+}
+
+// GetCreatedTimes 获取时间段内第一次学习单词的时间（按天统计新词数）
+func (r *WordbookCardRepository) GetCreatedTimes(userID uint, wordType string, since, before time.Time) ([]time.Time, error) {
+	var times []time.Time
+	err := r.db.Model(&model.WordbookCard{}).
+		Where("user_id = ? AND word_type = ? AND created_at >= ? AND created_at < ?", userID, wordType, since, before).
+		Pluck("created_at", &times).Error
+	return times, err
+}
+
+// WordbookCardStats 单词书卡片统计
+type WordbookCardStats struct {
+	Learned  int64 `json:"learned"`  // 学过的单词
+	Learning int64 `json:"learning"` // 学习/重学中
+	Review   int64 `json:"review"`   // 已毕业，按间隔复习
+	Mastered int64 `json:"mastered"` // 已掌握
+}
+
+// GetStats 按学习阶段统计卡片
+func (r *WordbookCardRepository) GetStats(userID uint, wordType string) (*WordbookCardStats, error) {
+	var rows []struct {
+		SrsState string
+		Mastered bool
+		Count    int64
+	}
+	err := r.db.Model(&model.WordbookCard{}).
+		Select("srs_state, mastered_at IS NOT NULL AS mastered, COUNT(*) AS count").
+		Where("user_id = ? AND word_type = ?", userID, wordType).
+		Group("srs_state, mastered").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &WordbookCardStats{}
+	for _, row := range rows {
+		stats.Learned += row.Count
+		switch {
+		case row.Mastered:
+			stats.Mastered += row.Count
+		case row.SrsState == "review":
+			stats.Review += row.Count
+		default:
+			stats.Learning += row.Count
+		}
+	}
+	return stats, nil
+}
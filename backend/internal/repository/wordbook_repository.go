@@ -2,8 +2,11 @@ package repository
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math/rand"
+	"strings"
 	"time"
 	"voicepaper/internal/model"
 
@@ -59,6 +62,78 @@ func (r *WordbookRepository) GetWordByID(id uint) (*model.Wordbook, error) {
 	return &word, err
 }
 
+// dictionary 作为词典使用的词条（不含用户自建单词书中新建的词条）
+func (r *WordbookRepository) dictionary() *gorm.DB {
+	return r.db.Model(&model.Wordbook{}).Where("source IS NULL OR source <> ?", model.WordbookSourceCustom)
+}
+
+// FindByWord 按单词查找（排序规则不区分大小写，有多条时取第一条）
+func (r *WordbookRepository) FindByWord(word string) (*model.Wordbook, error) {
+	var w model.Wordbook
+	err := r.dictionary().Where("word = ?", word).Order("id ASC").First(&w).Error
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// FindByWords 批量按单词查找（同一个单词可能在多本单词书中各有一条）
+func (r *WordbookRepository) FindByWords(words []string) ([]model.Wordbook, error) {
+	var list []model.Wordbook
+	if len(words) == 0 {
+		return list, nil
+	}
+	err := r.dictionary().Where("word IN ?", words).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+// GetWordsByIDs 按ID批量获取单词，按传入的顺序返回（不存在的跳过）
+func (r *WordbookRepository) GetWordsByIDs(ids []uint) ([]model.Wordbook, error) {
+	if len(ids) == 0 {
+		return []model.Wordbook{}, nil
+	}
+	var words []model.Wordbook
+	if err := r.db.Where("id IN ?", ids).Find(&words).Error; err != nil {
+		return nil, err
+	}
+	wordMap := make(map[uint]model.Wordbook, len(words))
+	for _, w := range words {
+		wordMap[w.ID] = w
+	}
+	sorted := make([]model.Wordbook, 0, len(ids))
+	for _, id := range ids {
+		if w, ok := wordMap[id]; ok {
+			sorted = append(sorted, w)
+		}
+	}
+	return sorted, nil
+}
+
+// InBook 单词是否属于某本单词书
+func (r *WordbookRepository) InBook(wordID uint, wordType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookBook{}).Where("word_id = ? AND book_type = ?", wordID, wordType).Count(&count).Error
+	return count > 0, err
+}
+
+// ListWordForms 获取所有单词及其变形（用于加载词形还原词表）
+func (r *WordbookRepository) ListWordForms() ([]model.Wordbook, error) {
+	var list []model.Wordbook
+	err := r.dictionary().Select("word", "word_forms").Find(&list).Error
+	return list, err
+}
+
+// GetDistractorMeanings 随机获取难度相近的其他单词释义（用于选择题干扰项）
+func (r *WordbookRepository) GetDistractorMeanings(excludeWord string, minDifficulty, maxDifficulty uint8, limit int) ([]string, error) {
+	var meanings []string
+	err := r.dictionary().
+		Where("word != ? AND meaning != '' AND difficulty BETWEEN ? AND ?", excludeWord, minDifficulty, maxDifficulty).
+		Order("RAND()").
+		Limit(limit).
+		Pluck("meaning", &meanings).Error
+	return meanings, err
+}
+
 // GetProgress 获取用户学习进度
 func (r *WordbookRepository) GetProgress(userID uint, wordType string) (*model.WordbookProgress, error) {
 	var progress model.WordbookProgress
@@ -89,13 +164,257 @@ func (r *WordbookRepository) SaveProgress(progress *model.WordbookProgress) erro
 	return r.db.Save(&existing).Error
 }
 
-// GetWordbookList 获取所有单词书列表
+// GetWordbookList 获取所有系统单词书列表
 func (r *WordbookRepository) GetWordbookList() ([]model.WordbookInfo, error) {
 	var wordbooks []model.WordbookInfo
-	err := r.db.Where("is_active = ?", true).Order("sort_order ASC").Find(&wordbooks).Error
+	err := r.db.Where("is_active = ? AND owner_id IS NULL", true).Order("sort_order ASC").Find(&wordbooks).Error
 	return wordbooks, err
 }
 
+// ==================== 自建单词书 ====================
+
+// GetWordbookInfo 按类型获取单词书信息
+func (r *WordbookRepository) GetWordbookInfo(wordType string) (*model.WordbookInfo, error) {
+	var info model.WordbookInfo
+	err := r.db.Where("type = ?", wordType).First(&info).Error
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetWordbookInfoByShareToken 按分享令牌获取自建单词书
+func (r *WordbookRepository) GetWordbookInfoByShareToken(token string) (*model.WordbookInfo, error) {
+	var info model.WordbookInfo
+	err := r.db.Where("share_token = ? AND owner_id IS NOT NULL", token).First(&info).Error
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// SaveWordbookInfo 创建或更新单词书信息
+func (r *WordbookRepository) SaveWordbookInfo(info *model.WordbookInfo) error {
+	return r.db.Save(info).Error
+}
+
+// CountOwnedWordbooks 统计用户创建的单词书数量
+func (r *WordbookRepository) CountOwnedWordbooks(ownerID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookInfo{}).Where("owner_id = ?", ownerID).Count(&count).Error
+	return count, err
+}
+
+// ListOwnedWordbooks 获取用户创建的单词书
+func (r *WordbookRepository) ListOwnedWordbooks(ownerID uint) ([]model.WordbookInfo, error) {
+	var list []model.WordbookInfo
+	err := r.db.Where("owner_id = ?", ownerID).Order("updated_at DESC").Find(&list).Error
+	return list, err
+}
+
+// ListJoinedWordbooks 获取用户正在学习的他人自建单词书（有学习序列的）
+func (r *WordbookRepository) ListJoinedWordbooks(userID uint) ([]model.WordbookInfo, error) {
+	var list []model.WordbookInfo
+	err := r.db.Where("owner_id IS NOT NULL AND owner_id <> ? AND visibility <> ?", userID, model.WordbookVisibilityPrivate).
+		Where("type IN (?)", r.db.Model(&model.WordbookUserOrder{}).Select("word_type").Where("user_id = ?", userID)).
+		Order("updated_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// ListPublicWordbooks 获取公开的自建单词书（按学习人数排序）
+func (r *WordbookRepository) ListPublicWordbooks(keyword string, limit, offset int) ([]model.WordbookInfo, int64, error) {
+	var list []model.WordbookInfo
+	var total int64
+	query := r.db.Model(&model.WordbookInfo{}).
+		Where("owner_id IS NOT NULL AND visibility = ? AND is_active = ? AND word_count > 0", model.WordbookVisibilityPublic, true)
+	if keyword != "" {
+		query = query.Where("name LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("study_count DESC, updated_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+// HasUserOrder 用户是否有该单词书的学习序列（学习过或通过分享链接加入过）
+func (r *WordbookRepository) HasUserOrder(userID uint, wordType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookUserOrder{}).Where("user_id = ? AND word_type = ?", userID, wordType).Count(&count).Error
+	return count > 0, err
+}
+
+// wordbookJSONColumns 词条的 JSON 列，空字符串不是合法的 JSON，为空时保存为 NULL
+var wordbookJSONColumns = []string{"examples_json", "phrases", "word_forms"}
+
+// emptyJSONColumns 词条中为空的 JSON 列
+func emptyJSONColumns(w *model.Wordbook) []string {
+	var cols []string
+	for i, v := range []string{w.ExamplesJSON, w.Phrases, w.WordForms} {
+		if strings.TrimSpace(v) == "" {
+			cols = append(cols, wordbookJSONColumns[i])
+		}
+	}
+	return cols
+}
+
+// CreateWords 批量创建词条，创建后回填 ID
+// 按为空的 JSON 列分组插入，插入时省略这些列（保存为 NULL）
+func (r *WordbookRepository) CreateWords(words []model.Wordbook) error {
+	if len(words) == 0 {
+		return nil
+	}
+	groups := make(map[string][]int)
+	for i := range words {
+		key := strings.Join(emptyJSONColumns(&words[i]), ",")
+		groups[key] = append(groups[key], i)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for key, indexes := range groups {
+			batch := make([]model.Wordbook, len(indexes))
+			for j, i := range indexes {
+				batch[j] = words[i]
+			}
+			query := tx
+			if key != "" {
+				query = query.Omit(strings.Split(key, ",")...)
+			}
+			if err := query.CreateInBatches(batch, 200).Error; err != nil {
+				return err
+			}
+			for j, i := range indexes {
+				words[i].ID = batch[j].ID
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateWordFields 更新词条的部分字段（键为列名，值为 nil 时置为 NULL）
+func (r *WordbookRepository) UpdateWordFields(id uint, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.Wordbook{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// WordbookLinkIssues 单词书关联表中的异常数据
+type WordbookLinkIssues struct {
+	Orphaned   []uint `json:"orphaned"`   // 指向不存在（或已删除）词条的关联
+	Duplicated []uint `json:"duplicated"` // 同一个单词的重复关联（保留 ID 最小的一条，这里是多余的关联 ID）
+}
+
+// FindLinkIssues 检查单词书的关联：悬空关联和重复关联，返回有问题的关联 ID
+func (r *WordbookRepository) FindLinkIssues(bookType string) (*WordbookLinkIssues, error) {
+	issues := &WordbookLinkIssues{Orphaned: []uint{}, Duplicated: []uint{}}
+	err := r.db.Model(&model.WordbookBook{}).
+		Where("book_type = ?", bookType).
+		Where("NOT EXISTS (SELECT 1 FROM vp_wordbook WHERE vp_wordbook.id = vp_wordbook_books.word_id AND vp_wordbook.deleted_at IS NULL)").
+		Order("id ASC").
+		Pluck("id", &issues.Orphaned).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Model(&model.WordbookBook{}).
+		Where("book_type = ?", bookType).
+		Where("EXISTS (SELECT 1 FROM vp_wordbook_books b WHERE b.book_type = vp_wordbook_books.book_type AND b.word_id = vp_wordbook_books.word_id AND b.id < vp_wordbook_books.id)").
+		Order("id ASC").
+		Pluck("id", &issues.Duplicated).Error
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// DeleteLinks 按 ID 删除单词书关联
+func (r *WordbookRepository) DeleteLinks(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&model.WordbookBook{}).Error
+}
+
+// GetBookWordIDs 获取单词书中已关联的单词 ID（不检查词条是否存在）
+func (r *WordbookRepository) GetBookWordIDs(bookType string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.WordbookBook{}).Where("book_type = ?", bookType).Distinct().Pluck("word_id", &ids).Error
+	return ids, err
+}
+
+// AddWordsToBook 把单词加入单词书，已在书中的跳过，返回新加入的数量
+func (r *WordbookRepository) AddWordsToBook(wordType string, wordIDs []uint) (int, error) {
+	if len(wordIDs) == 0 {
+		return 0, nil
+	}
+	var existing []uint
+	if err := r.db.Model(&model.WordbookBook{}).
+		Where("book_type = ? AND word_id IN ?", wordType, wordIDs).
+		Pluck("word_id", &existing).Error; err != nil {
+		return 0, err
+	}
+	skip := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		skip[id] = true
+	}
+
+	links := make([]model.WordbookBook, 0, len(wordIDs))
+	for _, id := range wordIDs {
+		if skip[id] {
+			continue
+		}
+		skip[id] = true
+		links = append(links, model.WordbookBook{WordID: id, BookType: wordType})
+	}
+	if len(links) == 0 {
+		return 0, nil
+	}
+	if err := r.db.CreateInBatches(links, 500).Error; err != nil {
+		return 0, err
+	}
+	return len(links), nil
+}
+
+// RemoveWordFromBook 从单词书中移除单词，该单词书自己新建的词条一并删除
+func (r *WordbookRepository) RemoveWordFromBook(wordType string, wordID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_type = ? AND word_id = ?", wordType, wordID).Delete(&model.WordbookBook{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND word_type = ? AND source = ?", wordID, wordType, model.WordbookSourceCustom).
+			Delete(&model.Wordbook{}).Error
+	})
+}
+
+// UpdateWordCount 按当前关联更新单词书的单词数
+func (r *WordbookRepository) UpdateWordCount(wordType string) (int64, error) {
+	count, err := r.CountBookWords(wordType)
+	if err != nil {
+		return 0, err
+	}
+	err = r.db.Model(&model.WordbookInfo{}).Where("type = ?", wordType).Update("word_count", count).Error
+	return count, err
+}
+
+// DeleteCustomWordbook 删除自建单词书及其单词关联、新建的词条和所有用户的学习记录
+func (r *WordbookRepository) DeleteCustomWordbook(wordType string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_type = ?", wordType).Delete(&model.WordbookBook{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("word_type = ? AND source = ?", wordType, model.WordbookSourceCustom).Delete(&model.Wordbook{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("word_type = ?", wordType).Delete(&model.WordbookCard{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("word_type = ?", wordType).Delete(&model.WordbookUserOrder{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("type = ? AND owner_id IS NOT NULL", wordType).Delete(&model.WordbookInfo{}).Error
+	})
+}
+
 // ImportWordToVocabulary 将单词书中的单词导入到生词本
 func (r *WordbookRepository) ImportWordToVocabulary(userID, wordbookID uint, quality string) (*model.Vocabulary, error) {
 	// 1. 先从单词书表获取单词信息
@@ -125,6 +444,7 @@ func (r *WordbookRepository) ImportWordToVocabulary(userID, wordbookID uint, qua
 		UserID:             userID,
 		Type:               model.VocabularyTypeWord,
 		Content:            wordbookWord.Word,
+		Lemma:              strings.ToLower(wordbookWord.Word), // 单词书中的单词本身就是原形
 		Phonetic:           wordbookWord.Phonetic,
 		Meaning:            wordbookWord.Meaning,
 		Example:            wordbookWord.Example,
@@ -154,15 +474,33 @@ func (r *WordbookRepository) ImportWordToVocabulary(userID, wordbookID uint, qua
 // ==================== 顺序/乱序学习相关方法 ====================
 
 // GetUserOrder 获取用户的学习序列
+// 旧版序列会先迁移（见 migrateUserOrder），单词书的单词数变化时更新总数
 func (r *WordbookRepository) GetUserOrder(userID uint, wordType string) (*model.WordbookUserOrder, error) {
 	var order model.WordbookUserOrder
 	err := r.db.Where("user_id = ? AND word_type = ?", userID, wordType).First(&order).Error
 	if err != nil {
 		return nil, err
 	}
+	if order.Version < model.WordbookOrderVersion {
+		if err := r.migrateUserOrder(&order); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.refreshUserOrder(&order); err != nil {
+		return nil, err
+	}
 	return &order, nil
 }
 
+// GetOrCreateUserOrder 获取用户的学习序列，没有时创建顺序模式的序列
+func (r *WordbookRepository) GetOrCreateUserOrder(userID uint, wordType string) (*model.WordbookUserOrder, error) {
+	order, err := r.GetUserOrder(userID, wordType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.SwitchOrderMode(userID, wordType, false)
+	}
+	return order, err
+}
+
 // GetAllWordIDs 获取指定类型的所有单词ID（按ID顺序）
 // 通过 vp_wordbook_books 关联表查询，只返回 vp_wordbook 中存在的记录
 func (r *WordbookRepository) GetAllWordIDs(wordType string) ([]uint, error) {
@@ -184,195 +522,291 @@ func (r *WordbookRepository) GetAllWordIDs(wordType string) ([]uint, error) {
 	return ids, err
 }
 
-// CreateOrUpdateUserOrder 创建或更新用户学习序列
-func (r *WordbookRepository) CreateOrUpdateUserOrder(order *model.WordbookUserOrder) error {
-	var existing model.WordbookUserOrder
-	err := r.db.Where("user_id = ? AND word_type = ?", order.UserID, order.WordType).First(&existing).Error
-
-	if err == gorm.ErrRecordNotFound {
-		return r.db.Create(order).Error
-	} else if err != nil {
-		return err
-	}
-
-	// 更新现有记录
-	existing.IsRandom = order.IsRandom
-	existing.WordSequence = order.WordSequence
-	existing.CurrentIndex = order.CurrentIndex
-	existing.TotalWords = order.TotalWords
-	return r.db.Save(&existing).Error
+// UpdateUserOrderPlan 更新学习计划（背完日期、每日新词数）
+func (r *WordbookRepository) UpdateUserOrderPlan(userID uint, wordType string, targetDate *time.Time, newPerDay int) error {
+	return r.db.Model(&model.WordbookUserOrder{}).Where("user_id = ? AND word_type = ?", userID, wordType).
+		Updates(map[string]interface{}{"target_date": targetDate, "new_per_day": newPerDay}).Error
 }
 
-// UpdateUserOrderIndex 只更新学习位置
-func (r *WordbookRepository) UpdateUserOrderIndex(userID uint, wordType string, currentIndex int) error {
-	return r.db.Model(&model.WordbookUserOrder{}).Where("user_id = ? AND word_type = ?", userID, wordType).
-		Update("current_index", currentIndex).Error
+// UpdateUserOrderIndex 保存前端的学习位置，返回实际保存的位置（不超过单词总数）
+// 位置只是浏览进度，不创建卡片：单词只有在学习接口中学过才算已学
+func (r *WordbookRepository) UpdateUserOrderIndex(userID uint, wordType string, currentIndex int) (int, error) {
+	order, err := r.GetOrCreateUserOrder(userID, wordType)
+	if err != nil {
+		return 0, err
+	}
+	if currentIndex < 0 {
+		currentIndex = 0
+	}
+	if currentIndex > order.TotalWords {
+		currentIndex = order.TotalWords
+	}
+	err = r.db.Model(order).Update("current_index", currentIndex).Error
+	return currentIndex, err
 }
 
 // SwitchOrderMode 切换顺序/乱序模式
+// 只重新排列还没学过的单词，已学部分保持不变；每次切换到乱序都会换一个种子
 func (r *WordbookRepository) SwitchOrderMode(userID uint, wordType string, isRandom bool) (*model.WordbookUserOrder, error) {
-	// 1. 获取所有单词ID
-	allIDs, err := r.GetAllWordIDs(wordType)
+	total, err := r.CountBookWords(wordType)
 	if err != nil {
 		return nil, err
 	}
-	if len(allIDs) == 0 {
+	if total == 0 {
 		log.Printf("⚠️ 单词书 %s 没有单词", wordType)
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	// 2. 获取现有序列（如果有）
-	existingOrder, err := r.GetUserOrder(userID, wordType)
-	currentIndex := 0
-	if err == nil && existingOrder != nil {
-		currentIndex = existingOrder.CurrentIndex
-	}
-
-	// 3. 生成新序列
-	var newSequence []uint
+	var seed int64
 	if isRandom {
-		// 乱序模式：打乱剩余未学单词
-		if currentIndex > 0 && currentIndex < len(allIDs) {
-			// 保留已学部分，打乱剩余部分
-			learned := allIDs[:currentIndex]
-			remaining := make([]uint, len(allIDs)-currentIndex)
-			copy(remaining, allIDs[currentIndex:])
-			shuffleIDs(remaining)
-			newSequence = append(learned, remaining...)
-		} else {
-			// 全部打乱
-			newSequence = make([]uint, len(allIDs))
-			copy(newSequence, allIDs)
-			shuffleIDs(newSequence)
-		}
-	} else {
-		// 顺序模式：恢复原始顺序
-		newSequence = allIDs
+		seed = newOrderSeed()
 	}
 
-	// 4. 序列化为JSON
-	sequenceJSON, err := json.Marshal(newSequence)
-	if err != nil {
+	var order model.WordbookUserOrder
+	err = r.db.Where("user_id = ? AND word_type = ?", userID, wordType).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		order = model.WordbookUserOrder{
+			UserID:   userID,
+			WordType: wordType,
+			IsRandom: isRandom,
+			Seed:     seed,
+			Version:  model.WordbookOrderVersion,
+		}
+		if err := r.db.Create(&order).Error; err != nil {
+			return nil, err
+		}
+	} else if err != nil {
 		return nil, err
+	} else {
+		// 旧版序列先迁移，保留其中已学的部分
+		if order.Version < model.WordbookOrderVersion {
+			if err := r.migrateUserOrder(&order); err != nil {
+				return nil, err
+			}
+		}
+		order.IsRandom = isRandom
+		order.Seed = seed
+		if err := r.db.Model(&order).Updates(map[string]interface{}{"is_random": isRandom, "seed": seed}).Error; err != nil {
+			return nil, err
+		}
 	}
 
-	// 5. 保存
-	order := &model.WordbookUserOrder{
-		UserID:       userID,
-		WordType:     wordType,
-		IsRandom:     isRandom,
-		WordSequence: string(sequenceJSON),
-		CurrentIndex: currentIndex,
-		TotalWords:   len(newSequence),
-	}
-
-	if err := r.CreateOrUpdateUserOrder(order); err != nil {
+	if err := r.refreshUserOrder(&order); err != nil {
 		return nil, err
 	}
-
-	return order, nil
+	return &order, nil
 }
 
 // GetWordsByUserOrder 按用户序列获取单词（带分页）
+// 序列 = 已学单词（按第一次学习的时间）+ 未学单词（按种子排列），每页只查询需要的部分
 func (r *WordbookRepository) GetWordsByUserOrder(userID uint, wordType string, page, pageSize int) ([]model.Wordbook, int64, int, bool, error) {
-	// 1. 获取用户序列
-	order, err := r.GetUserOrder(userID, wordType)
+	order, err := r.GetOrCreateUserOrder(userID, wordType)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// 没有序列，创建默认顺序序列
-			order, err = r.SwitchOrderMode(userID, wordType, false)
-			if err != nil {
-				return nil, 0, 0, false, err
-			}
-		} else {
-			return nil, 0, 0, false, err
-		}
-	}
-
-	// 2. 解析序列
-	var sequence []uint
-	if err := json.Unmarshal([]byte(order.WordSequence), &sequence); err != nil {
 		return nil, 0, 0, false, err
 	}
-
-	// 3. 检查序列中的单词是否都存在（数据整合后可能有些ID失效了）
-	// 获取当前有效的单词ID列表
-	validIDs, err := r.GetAllWordIDs(wordType)
+	count, err := r.CountLearnedWords(userID, wordType)
 	if err != nil {
 		return nil, 0, 0, false, err
 	}
 
-	// 创建有效ID的map
-	validIDMap := make(map[uint]bool)
-	for _, id := range validIDs {
-		validIDMap[id] = true
+	learned := int(count)
+	total := int64(order.TotalWords)
+	offset := (page - 1) * pageSize
+	words := make([]model.Wordbook, 0, pageSize)
+	if int64(offset) >= total || pageSize <= 0 {
+		return words, total, order.CurrentIndex, order.IsRandom, nil
 	}
 
-	// 过滤序列，只保留有效的ID
-	var filteredSequence []uint
-	for _, id := range sequence {
-		if validIDMap[id] {
-			filteredSequence = append(filteredSequence, id)
+	if offset < learned {
+		limit := pageSize
+		if learned-offset < limit {
+			limit = learned - offset
 		}
-	}
-
-	// 如果过滤后的序列和原序列长度不一致，说明有些ID失效了，需要更新序列
-	if len(filteredSequence) != len(sequence) {
-		log.Printf("⚠️ 用户 %d 的单词书 %s 序列中有 %d 个无效ID，重新生成序列", userID, wordType, len(sequence)-len(filteredSequence))
-
-		// 重新生成序列（保持原来的乱序/顺序模式）
-		order, err = r.SwitchOrderMode(userID, wordType, order.IsRandom)
+		part, err := r.GetLearnedWords(userID, wordType, offset, limit)
 		if err != nil {
 			return nil, 0, 0, false, err
 		}
-
-		// 重新解析序列
-		if err := json.Unmarshal([]byte(order.WordSequence), &sequence); err != nil {
+		words = append(words, part...)
+	}
+	if rest := pageSize - len(words); rest > 0 {
+		unlearnedOffset := offset - learned
+		if unlearnedOffset < 0 {
+			unlearnedOffset = 0
+		}
+		part, err := r.GetUnlearnedWords(order, unlearnedOffset, rest)
+		if err != nil {
 			return nil, 0, 0, false, err
 		}
-	} else {
-		sequence = filteredSequence
+		words = append(words, part...)
 	}
 
-	total := int64(len(sequence))
-	offset := (page - 1) * pageSize
+	return words, total, order.CurrentIndex, order.IsRandom, nil
+}
+
+// GetLearnedWords 按第一次学习的时间获取用户在单词书中学过的单词
+func (r *WordbookRepository) GetLearnedWords(userID uint, wordType string, offset, limit int) ([]model.Wordbook, error) {
+	var words []model.Wordbook
+	err := r.inBookScope(r.db.Model(&model.Wordbook{}), wordType).
+		Joins("JOIN vp_wordbook_cards ON vp_wordbook_cards.word_id = vp_wordbook.id AND vp_wordbook_cards.user_id = ? AND vp_wordbook_cards.word_type = ?", userID, wordType).
+		Order("vp_wordbook_cards.created_at ASC, vp_wordbook_cards.id ASC").
+		Offset(offset).Limit(limit).
+		Find(&words).Error
+	return words, err
+}
+
+// GetUnlearnedWords 按序列顺序获取还没学过的单词
+// 顺序模式按单词ID，乱序模式按 CRC32(种子:单词ID) 排列：同一个种子的顺序固定，新增的单词插入到各自的位置
+func (r *WordbookRepository) GetUnlearnedWords(order *model.WordbookUserOrder, offset, limit int) ([]model.Wordbook, error) {
+	var words []model.Wordbook
+	orderBy := "vp_wordbook.id ASC"
+	if order.Seed != 0 {
+		orderBy = fmt.Sprintf("CRC32(CONCAT(%d, ':', vp_wordbook.id)) ASC, vp_wordbook.id ASC", order.Seed)
+	}
+	err := r.inBookScope(r.db.Model(&model.Wordbook{}), order.WordType).
+		Where("NOT EXISTS (SELECT 1 FROM vp_wordbook_cards WHERE vp_wordbook_cards.word_id = vp_wordbook.id AND vp_wordbook_cards.user_id = ? AND vp_wordbook_cards.word_type = ?)",
+			order.UserID, order.WordType).
+		Order(orderBy).
+		Offset(offset).Limit(limit).
+		Find(&words).Error
+	return words, err
+}
+
+// CountBookWords 统计单词书中的单词数
+func (r *WordbookRepository) CountBookWords(wordType string) (int64, error) {
+	var count int64
+	err := r.inBookScope(r.db.Model(&model.Wordbook{}), wordType).Count(&count).Error
+	return count, err
+}
+
+// CountLearnedWords 统计用户在单词书中学过（有卡片）的单词数，已从单词书移除的不计
+// 从用户的卡片出发统计，只扫描学过的单词
+func (r *WordbookRepository) CountLearnedWords(userID uint, wordType string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WordbookCard{}).
+		Where("user_id = ? AND word_type = ?", userID, wordType).
+		Where("EXISTS (SELECT 1 FROM vp_wordbook_books WHERE vp_wordbook_books.word_id = vp_wordbook_cards.word_id AND vp_wordbook_books.book_type = ?)", wordType).
+		Count(&count).Error
+	return count, err
+}
+
+// inBookScope 限定为属于某本单词书的单词（vp_wordbook_books 中可能有重复关联，用 EXISTS 避免重复）
+func (r *WordbookRepository) inBookScope(query *gorm.DB, wordType string) *gorm.DB {
+	return query.Where("EXISTS (SELECT 1 FROM vp_wordbook_books WHERE vp_wordbook_books.word_id = vp_wordbook.id AND vp_wordbook_books.book_type = ?)", wordType)
+}
+
+// refreshUserOrder 单词书的单词数变化时更新总数
+// 未学单词按种子在查询时排列，不需要同步；学习位置（CurrentIndex）由前端保存，这里不修改
+func (r *WordbookRepository) refreshUserOrder(order *model.WordbookUserOrder) error {
+	total, err := r.CountBookWords(order.WordType)
+	if err != nil {
+		return err
+	}
+	if int(total) == order.TotalWords {
+		return nil
+	}
+	if err := r.db.Model(order).Update("total_words", total).Error; err != nil {
+		return err
+	}
+	order.TotalWords = int(total)
+	return nil
+}
 
-	// 4. 获取当前页的单词ID
-	if offset >= len(sequence) {
-		return []model.Wordbook{}, total, order.CurrentIndex, order.IsRandom, nil
+// createOrderCards 为旧版序列中学过的单词创建卡片（立即到期，进入复习），返回创建的数量
+// 已有卡片或已不在单词书中的单词跳过，ids 的顺序即第一次学习的顺序
+func createOrderCards(tx *gorm.DB, order *model.WordbookUserOrder, ids []uint, createdAt time.Time) (int, error) {
+	var carded, valid []uint
+	if err := tx.Model(&model.WordbookCard{}).
+		Where("user_id = ? AND word_type = ? AND word_id IN ?", order.UserID, order.WordType, ids).
+		Pluck("word_id", &carded).Error; err != nil {
+		return 0, err
 	}
-	end := offset + pageSize
-	if end > len(sequence) {
-		end = len(sequence)
+	if err := tx.Model(&model.WordbookBook{}).
+		Where("book_type = ? AND word_id IN ?", order.WordType, ids).
+		Pluck("word_id", &valid).Error; err != nil {
+		return 0, err
 	}
-	pageIDs := sequence[offset:end]
 
-	// 5. 按ID查询单词（保持序列顺序）
-	var words []model.Wordbook
-	if err := r.db.Where("id IN ?", pageIDs).Find(&words).Error; err != nil {
-		return nil, 0, 0, false, err
+	skip := make(map[uint]bool, len(carded))
+	for _, id := range carded {
+		skip[id] = true
+	}
+	inBook := make(map[uint]bool, len(valid))
+	for _, id := range valid {
+		inBook[id] = true
 	}
 
-	// 6. 按序列顺序排序
-	wordMap := make(map[uint]model.Wordbook)
-	for _, w := range words {
-		wordMap[w.ID] = w
+	now := time.Now()
+	cards := make([]model.WordbookCard, 0, len(ids))
+	for _, id := range ids {
+		if skip[id] || !inBook[id] {
+			continue
+		}
+		skip[id] = true
+		cards = append(cards, model.WordbookCard{
+			CreatedAt:    createdAt,
+			UserID:       order.UserID,
+			WordType:     order.WordType,
+			WordID:       id,
+			EaseFactor:   2.5,
+			SrsState:     "new",
+			NextReviewAt: &now,
+		})
+	}
+	if len(cards) > 0 {
+		if err := tx.CreateInBatches(cards, 500).Error; err != nil {
+			return 0, err
+		}
 	}
-	sortedWords := make([]model.Wordbook, 0, len(pageIDs))
-	for _, id := range pageIDs {
-		if w, ok := wordMap[id]; ok {
-			sortedWords = append(sortedWords, w)
+	return len(cards), nil
+}
+
+// migrateUserOrder 把旧版的 JSON 序列迁移为按种子计算的排列
+// 旧序列中学习位置之前、还没有卡片的单词按原顺序补建卡片，保留用户已经学过的部分
+func (r *WordbookRepository) migrateUserOrder(order *model.WordbookUserOrder) error {
+	var prefix []uint
+	if order.WordSequence != "" {
+		if err := json.Unmarshal([]byte(order.WordSequence), &prefix); err != nil {
+			log.Printf("⚠️ 用户 %d 的单词书 %s 旧序列解析失败，不保留已学部分: %v", order.UserID, order.WordType, err)
+			prefix = nil
 		}
 	}
+	if order.CurrentIndex < len(prefix) {
+		prefix = prefix[:order.CurrentIndex]
+	}
 
-	return sortedWords, total, order.CurrentIndex, order.IsRandom, nil
+	var seed int64
+	if order.IsRandom {
+		seed = newOrderSeed()
+	}
+
+	created := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if len(prefix) > 0 {
+			n, err := createOrderCards(tx, order, prefix, order.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			created = n
+		}
+		return tx.Model(order).Updates(map[string]interface{}{
+			"seed":          seed,
+			"version":       model.WordbookOrderVersion,
+			"word_sequence": "",
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	order.Seed = seed
+	order.Version = model.WordbookOrderVersion
+	order.WordSequence = ""
+	log.Printf("✅ 用户 %d 的单词书 %s 学习序列已迁移: 补建 %d 张卡片", order.UserID, order.WordType, created)
+	return nil
 }
 
-// shuffleIDs 打乱切片
-func shuffleIDs(ids []uint) {
+// newOrderSeed 生成乱序排列的种子（非 0）
+func newOrderSeed() int64 {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := len(ids) - 1; i > 0; i-- {
-		j := r.Intn(i + 1)
-		ids[i], ids[j] = ids[j], ids[i]
-	}
+	return r.Int63n(1<<31-1) + 1
 }
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/clock"
+
+	"gorm.io/gorm"
+)
+
+const (
+	titleConfigTTL     = 5 * time.Minute // 称号配置缓存时间（本进程修改配置时立即清除）
+	maxTitleRuleDepth  = 3               // 组合条件最多嵌套层数
+	maxTitleRuleWindow = 365             // 时间窗口最多天数
+)
+
+var ErrInvalidTitleRule = errors.New("无效的称号条件")
+
+// eventMetrics 各事件可能改变的指标，事件发生时只检查用到这些指标的称号
+var eventMetrics = map[EventKind][]model.TitleConditionType{
+	EventArticleRead:        {model.TitleConditionArticlesRead, model.TitleConditionTotalPoints},
+	EventDictationCompleted: {model.TitleConditionDictationsCompleted, model.TitleConditionPerfectStreak, model.TitleConditionTotalPoints},
+	EventReviewSubmitted:    {model.TitleConditionReviewCount, model.TitleConditionVocabularyCount, model.TitleConditionTotalPoints},
+	EventCheckedIn:          {model.TitleConditionCheckIns, model.TitleConditionContinuousCheckIns, model.TitleConditionTotalPoints},
+	EventDurationSynced:     {model.TitleConditionTotalDuration},
+}
+
+// windowMetrics 支持时间窗口的指标（按天累加在 vp_user_achievement_counters 中）
+var windowMetrics = []model.TitleConditionType{
+	model.TitleConditionArticlesRead,
+	model.TitleConditionDictationsCompleted,
+	model.TitleConditionReviewCount,
+	model.TitleConditionCheckIns,
+	model.TitleConditionTotalDuration, // 按秒累加，比较时换算为分钟
+}
+
+// titleConfigCache 启用的称号配置及解析后的条件，所有 AchievementService 共用
+var titleConfigCache struct {
+	sync.Mutex
+	configs []model.TitleConfig
+	rules   map[uint]model.TitleRule
+	expires time.Time
+}
+
+// InvalidateTitleConfigs 清除称号配置缓存（修改称号配置后调用）
+func InvalidateTitleConfigs() {
+	titleConfigCache.Lock()
+	titleConfigCache.expires = time.Time{}
+	titleConfigCache.Unlock()
+}
+
+// AchievementService 成就服务
+// 订阅学习事件，维护增量计数器，并只检查与事件相关、用户还没有获得的称号。
+// 累计指标读 vp_user_points 上的计数（阅读、默写、签到、复习、连续全对、总积分、学习时长），
+// 带时间窗口的条件（如"7 天内复习 100 次"）和限时称号读按天累加的 vp_user_achievement_counters。
+// 获得称号时在同一事务中发放称号配置的奖励（积分、补签卡、冻结卡）。
+type AchievementService struct {
+	db              *gorm.DB
+	repo            *repository.AchievementRepository
+	titleRepo       *repository.TitleRepository
+	userPointsRepo  *repository.UserPointsRepository
+	pointRecordRepo *repository.PointRecordRepository
+	calendar        *CalendarService
+}
+
+func NewAchievementService(db *gorm.DB, calendar *CalendarService) *AchievementService {
+	return &AchievementService{
+		db:              db,
+		repo:            repository.NewAchievementRepository(db),
+		titleRepo:       repository.NewTitleRepository(db),
+		userPointsRepo:  repository.NewUserPointsRepository(db),
+		pointRecordRepo: repository.NewPointRecordRepository(db),
+		calendar:        calendar,
+	}
+}
+
+// Subscribe 订阅所有学习事件
+func (s *AchievementService) Subscribe(bus *EventBus) {
+	for kind := range eventMetrics {
+		bus.Subscribe(kind, s.handle)
+	}
+}
+
+// TitleProgress 称号进度
+type TitleProgress struct {
+	CurrentValue int     // 单个条件为当前数值，组合条件为已满足的子条件数
+	Percent      float64 // 0-100
+	Satisfied    bool
+}
+
+// CheckAll 检查所有称号（查看称号进度时使用，补发事件之外达成的称号）
+func (s *AchievementService) CheckAll(userID uint) ([]model.TitleConfig, error) {
+	return s.check(userID, nil)
+}
+
+// Progress 计算各称号的进度（自定义条件和无效条件不在结果中）
+func (s *AchievementService) Progress(userID uint) (map[uint]TitleProgress, error) {
+	configs, rules, err := s.titleConfigs()
+	if err != nil {
+		return nil, err
+	}
+	values := s.newMetricValues(userID)
+	result := make(map[uint]TitleProgress, len(configs))
+	for i := range configs {
+		cfg := &configs[i]
+		rule, ok := rules[cfg.ID]
+		if !ok {
+			continue
+		}
+		season := values.seasonRange(cfg)
+		satisfied, percent := s.evaluate(rule, values, season)
+		progress := TitleProgress{Percent: percent * 100, Satisfied: satisfied}
+		if rule.Metric != "" {
+			progress.CurrentValue = values.get(rule.Metric, rule.WindowDays, season)
+		} else {
+			for _, sub := range rule.Rules {
+				if ok, _ := s.evaluate(sub, values, season); ok {
+					progress.CurrentValue++
+				}
+			}
+		}
+		result[cfg.ID] = progress
+	}
+	return result, values.err
+}
+
+// handle 处理学习事件：更新计数器后检查相关称号
+func (s *AchievementService) handle(e Event, result *EventResult) error {
+	if err := s.record(e); err != nil {
+		log.Printf("⚠️ 更新成就计数失败 (kind=%s, user=%d): %v", e.Kind, e.UserID, err)
+	}
+	unlocked, err := s.check(e.UserID, eventMetrics[e.Kind])
+	result.UnlockedTitles = append(result.UnlockedTitles, unlocked...)
+	return err
+}
+
+// record 更新事件对应的计数器
+func (s *AchievementService) record(e Event) error {
+	today := s.calendar.Today(e.UserID)
+	switch e.Kind {
+	case EventArticleRead:
+		return s.repo.AddCounter(e.UserID, model.TitleConditionArticlesRead, today, 1)
+	case EventDictationCompleted:
+		if err := s.userPointsRepo.UpdatePerfectStreak(e.UserID, e.Correct); err != nil {
+			return err
+		}
+		if e.Correct {
+			return s.repo.AddCounter(e.UserID, model.TitleConditionDictationsCompleted, today, 1)
+		}
+	case EventReviewSubmitted:
+		if err := s.userPointsRepo.IncrementReviews(e.UserID); err != nil {
+			return err
+		}
+		return s.repo.AddCounter(e.UserID, model.TitleConditionReviewCount, today, 1)
+	case EventCheckedIn:
+		// 补签计入补签的那一天
+		if !e.Date.IsZero() {
+			today = s.calendar.Date(e.Date)
+		}
+		return s.repo.AddCounter(e.UserID, model.TitleConditionCheckIns, today, 1)
+	case EventDurationSynced:
+		if e.Seconds > 0 {
+			return s.repo.AddCounter(e.UserID, model.TitleConditionTotalDuration, today, e.Seconds)
+		}
+	}
+	return nil
+}
+
+// check 检查并颁发用户满足条件的称号，metrics 为空时检查所有称号，否则只检查用到这些指标的称号
+func (s *AchievementService) check(userID uint, metrics []model.TitleConditionType) ([]model.TitleConfig, error) {
+	configs, rules, err := s.titleConfigs()
+	if err != nil {
+		return nil, err
+	}
+	ownedIDs, err := s.titleRepo.GetUserTitleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[uint]bool, len(ownedIDs))
+	for _, id := range ownedIDs {
+		owned[id] = true
+	}
+
+	now := s.calendar.Now()
+	unlocked := []model.TitleConfig{}
+	var values *metricValues // 有需要检查的称号时才查询指标
+	for i := range configs {
+		cfg := &configs[i]
+		rule, ok := rules[cfg.ID]
+		if !ok || owned[cfg.ID] || !cfg.AvailableAt(now) || (metrics != nil && !ruleUsesAny(rule, metrics)) {
+			continue
+		}
+		if values == nil {
+			values = s.newMetricValues(userID)
+		}
+		if satisfied, _ := s.evaluate(rule, values, values.seasonRange(cfg)); !satisfied {
+			continue
+		}
+		if err := s.award(userID, cfg); err != nil {
+			log.Printf("⚠️ 颁发称号失败 (user=%d, title=%s): %v", userID, cfg.TitleKey, err)
+			continue
+		}
+		unlocked = append(unlocked, *cfg)
+		log.Printf("✅ 用户 %d 获得称号: %s", userID, cfg.TitleName)
+	}
+	if values != nil && values.err != nil {
+		return unlocked, values.err
+	}
+	return unlocked, nil
+}
+
+// award 颁发称号并发放奖励（同一事务，并发重复颁发时因唯一索引失败）
+func (s *AchievementService) award(userID uint, cfg *model.TitleConfig) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := s.titleRepo.CreateUserTitle(tx, &model.UserTitle{
+			UserID:        userID,
+			TitleConfigID: cfg.ID,
+			AwardedAt:     s.calendar.Now(),
+			IsEquipped:    false,
+		})
+		if err != nil {
+			return err
+		}
+		if cfg.RewardPoints > 0 {
+			up, err := s.userPointsRepo.AddPoints(tx, userID, cfg.RewardPoints)
+			if err != nil {
+				return fmt.Errorf("发放称号积分失败: %w", err)
+			}
+			err = s.pointRecordRepo.Create(tx, &model.PointRecord{
+				UserID:        userID,
+				Points:        cfg.RewardPoints,
+				Type:          model.PointTypeTitleReward,
+				Description:   fmt.Sprintf("获得称号「%s」", cfg.TitleName),
+				BalanceBefore: up.CurrentPoints - cfg.RewardPoints,
+				BalanceAfter:  up.CurrentPoints,
+			})
+			if err != nil {
+				return fmt.Errorf("创建积分记录失败: %w", err)
+			}
+		}
+		if cfg.RewardMakeupCards > 0 {
+			if err := s.userPointsRepo.AddMakeupCards(tx, userID, cfg.RewardMakeupCards); err != nil {
+				return fmt.Errorf("发放补签卡失败: %w", err)
+			}
+		}
+		if cfg.RewardStreakFreezes > 0 {
+			if err := s.userPointsRepo.AddStreakFreezesUpTo(tx, userID, cfg.RewardStreakFreezes, StreakFreezeMaxOwned); err != nil {
+				return fmt.Errorf("发放冻结卡失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// evaluate 条件是否满足及完成比例（0-1）：全部满足取平均，任意满足取最大。
+// season 不为空时（限时称号），支持按天统计的指标只统计有效期内的次数
+func (s *AchievementService) evaluate(rule model.TitleRule, values *metricValues, season *counterRange) (bool, float64) {
+	if rule.Metric != "" {
+		current := values.get(rule.Metric, rule.WindowDays, season)
+		if rule.Value <= 0 {
+			return true, 1
+		}
+		ratio := float64(current) / float64(rule.Value)
+		if ratio > 1 {
+			ratio = 1
+		}
+		return current >= rule.Value, ratio
+	}
+
+	allOK, anyOK := true, false
+	sum, best := 0.0, 0.0
+	for _, sub := range rule.Rules {
+		ok, ratio := s.evaluate(sub, values, season)
+		allOK, anyOK = allOK && ok, anyOK || ok
+		sum += ratio
+		if ratio > best {
+			best = ratio
+		}
+	}
+	if rule.Op == model.TitleRuleOr {
+		return anyOK, best
+	}
+	return allOK, sum / float64(len(rule.Rules))
+}
+
+// titleConfigs 启用的称号配置及解析后的条件（自定义条件和无效条件不在 rules 中）
+func (s *AchievementService) titleConfigs() ([]model.TitleConfig, map[uint]model.TitleRule, error) {
+	titleConfigCache.Lock()
+	defer titleConfigCache.Unlock()
+	if s.calendar.Now().Before(titleConfigCache.expires) {
+		return titleConfigCache.configs, titleConfigCache.rules, nil
+	}
+
+	configs, err := s.titleRepo.GetAllTitleConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+	rules := make(map[uint]model.TitleRule, len(configs))
+	for i := range configs {
+		if configs[i].ConditionType == model.TitleConditionCustom {
+			continue
+		}
+		rule, err := ParseTitleRule(&configs[i])
+		if err != nil {
+			log.Printf("⚠️ 称号条件无效，已跳过 (title=%s): %v", configs[i].TitleKey, err)
+			continue
+		}
+		rules[configs[i].ID] = rule
+	}
+	titleConfigCache.configs = configs
+	titleConfigCache.rules = rules
+	titleConfigCache.expires = s.calendar.Now().Add(titleConfigTTL)
+	return configs, rules, nil
+}
+
+// ParseTitleRule 解析称号条件：组合条件读 ConditionRule，其余为单个指标 ConditionType >= ConditionValue
+func ParseTitleRule(cfg *model.TitleConfig) (model.TitleRule, error) {
+	if cfg.ConditionType != model.TitleConditionCompound {
+		rule := model.TitleRule{Metric: cfg.ConditionType, Value: cfg.ConditionValue}
+		return rule, ValidateTitleRule(rule)
+	}
+	var rule model.TitleRule
+	if err := json.Unmarshal([]byte(cfg.ConditionRule), &rule); err != nil {
+		return rule, fmt.Errorf("%w: %v", ErrInvalidTitleRule, err)
+	}
+	return rule, ValidateTitleRule(rule)
+}
+
+// ValidateTitleRule 校验称号条件
+func ValidateTitleRule(rule model.TitleRule) error {
+	return validateTitleRule(rule, 1)
+}
+
+func validateTitleRule(rule model.TitleRule, depth int) error {
+	if depth > maxTitleRuleDepth {
+		return fmt.Errorf("%w: 最多嵌套 %d 层", ErrInvalidTitleRule, maxTitleRuleDepth)
+	}
+	if rule.Metric == "" {
+		if rule.Op != model.TitleRuleAnd && rule.Op != model.TitleRuleOr {
+			return fmt.Errorf("%w: 组合条件的 op 只能是 and 或 or", ErrInvalidTitleRule)
+		}
+		if len(rule.Rules) == 0 {
+			return fmt.Errorf("%w: 组合条件至少包含一个子条件", ErrInvalidTitleRule)
+		}
+		for _, sub := range rule.Rules {
+			if err := validateTitleRule(sub, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rule.Rules) > 0 || rule.Op != "" {
+		return fmt.Errorf("%w: 指标条件不能包含子条件", ErrInvalidTitleRule)
+	}
+	if !isLifetimeMetric(rule.Metric) {
+		return fmt.Errorf("%w: 不支持的指标 %s", ErrInvalidTitleRule, rule.Metric)
+	}
+	if rule.WindowDays < 0 || rule.WindowDays > maxTitleRuleWindow {
+		return fmt.Errorf("%w: 时间窗口为 0-%d 天", ErrInvalidTitleRule, maxTitleRuleWindow)
+	}
+	if rule.WindowDays > 0 && !isWindowMetric(rule.Metric) {
+		return fmt.Errorf("%w: 指标 %s 不支持时间窗口", ErrInvalidTitleRule, rule.Metric)
+	}
+	return nil
+}
+
+func isLifetimeMetric(metric model.TitleConditionType) bool {
+	switch metric {
+	case model.TitleConditionArticlesRead, model.TitleConditionDictationsCompleted,
+		model.TitleConditionContinuousCheckIns, model.TitleConditionTotalPoints,
+		model.TitleConditionVocabularyCount, model.TitleConditionReviewCount,
+		model.TitleConditionTotalDuration, model.TitleConditionPerfectStreak,
+		model.TitleConditionCheckIns:
+		return true
+	}
+	return false
+}
+
+func isWindowMetric(metric model.TitleConditionType) bool {
+	for _, m := range windowMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleOnlyWindowMetrics 条件是否只用到按天计数的指标（限时称号只能统计这些指标在有效期内的数值）
+func ruleOnlyWindowMetrics(rule model.TitleRule) bool {
+	if rule.Metric != "" {
+		return isWindowMetric(rule.Metric)
+	}
+	for _, sub := range rule.Rules {
+		if !ruleOnlyWindowMetrics(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleUsesAny 条件是否用到 metrics 中的任意指标
+func ruleUsesAny(rule model.TitleRule, metrics []model.TitleConditionType) bool {
+	if rule.Metric != "" {
+		for _, m := range metrics {
+			if m == rule.Metric {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sub := range rule.Rules {
+		if ruleUsesAny(sub, metrics) {
+			return true
+		}
+	}
+	return false
+}
+
+// counterRange 按天计数的统计范围（用户时区的日期，首尾都含）
+type counterRange struct {
+	since, until time.Time
+}
+
+// metricValues 一次检查中用到的指标，按需查询并缓存
+type metricValues struct {
+	s      *AchievementService
+	userID uint
+	today  time.Time
+	points *model.UserPoints
+	vocab  *int
+	sums   map[counterRange]map[model.TitleConditionType]int
+	err    error // 第一个查询错误，出错的指标按 0 计算
+}
+
+func (s *AchievementService) newMetricValues(userID uint) *metricValues {
+	return &metricValues{
+		s:      s,
+		userID: userID,
+		today:  s.calendar.Today(userID),
+		sums:   make(map[counterRange]map[model.TitleConditionType]int),
+	}
+}
+
+// seasonRange 限时称号的统计范围（开始日期到结束日期或今天），非限时称号返回 nil
+func (v *metricValues) seasonRange(cfg *model.TitleConfig) *counterRange {
+	if cfg.StartAt == nil {
+		return nil
+	}
+	loc := v.s.calendar.Location(v.userID)
+	r := &counterRange{since: clock.Date(*cfg.StartAt, loc), until: v.today}
+	if cfg.EndAt != nil {
+		if end := clock.Date(cfg.EndAt.Add(-time.Second), loc); end.Before(r.until) {
+			r.until = end // EndAt 为结束时刻（不含）
+		}
+	}
+	return r
+}
+
+// get 指标的当前值
+// windowDays > 0 时为最近 windowDays 天（含今天）的累计值，season 不为空时只统计有效期内（两者同时存在取交集）
+func (v *metricValues) get(metric model.TitleConditionType, windowDays int, season *counterRange) int {
+	if windowDays > 0 || (season != nil && isWindowMetric(metric)) {
+		r := counterRange{since: time.Time{}, until: v.today}
+		if windowDays > 0 {
+			r.since = v.today.AddDate(0, 0, -(windowDays - 1))
+		}
+		if season != nil {
+			if season.since.After(r.since) {
+				r.since = season.since
+			}
+			if season.until.Before(r.until) {
+				r.until = season.until
+			}
+		}
+		return v.sum(metric, r)
+	}
+
+	if metric == model.TitleConditionVocabularyCount {
+		if v.vocab == nil {
+			var count int64
+			if err := v.s.db.Model(&model.Vocabulary{}).Where("user_id = ?", v.userID).Count(&count).Error; err != nil {
+				v.fail(err)
+			}
+			n := int(count)
+			v.vocab = &n
+		}
+		return *v.vocab
+	}
+
+	if v.points == nil {
+		points, err := v.s.userPointsRepo.GetByUserID(v.userID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				v.fail(err)
+			}
+			points = &model.UserPoints{UserID: v.userID}
+		}
+		v.points = points
+	}
+	p := v.points
+	switch metric {
+	case model.TitleConditionArticlesRead:
+		return p.TotalArticlesRead
+	case model.TitleConditionDictationsCompleted:
+		return p.TotalDictationsCompleted
+	case model.TitleConditionTotalPoints:
+		return p.TotalPoints
+	case model.TitleConditionContinuousCheckIns:
+		return p.ContinuousCheckIns
+	case model.TitleConditionCheckIns:
+		return p.TotalCheckIns
+	case model.TitleConditionReviewCount:
+		return p.TotalReviews
+	case model.TitleConditionTotalDuration:
+		return int(p.TotalDurationMinutes)
+	case model.TitleConditionPerfectStreak:
+		return p.MaxPerfectStreak
+	}
+	return 0
+}
+
+// sum 按天计数的指标在 r 范围内的累计值（学习时长按秒累加，换算为分钟）
+func (v *metricValues) sum(metric model.TitleConditionType, r counterRange) int {
+	if r.until.Before(r.since) {
+		return 0
+	}
+	sums, ok := v.sums[r]
+	if !ok {
+		var err error
+		if sums, err = v.s.repo.SumCounters(v.userID, windowMetrics, r.since, r.until); err != nil {
+			v.fail(err)
+		}
+		v.sums[r] = sums
+	}
+	if metric == model.TitleConditionTotalDuration {
+		return sums[metric] / 60
+	}
+	return sums[metric]
+}
+
+func (v *metricValues) fail(err error) {
+	if v.err == nil {
+		v.err = err
+	}
+}
@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/storage"
+	"voicepaper/pkg/textdiff"
+
+	"gorm.io/gorm"
+)
+
+// RevisionSentenceMatchThreshold 句子相似度不低于该值时视为同一句被修改（保留句子ID）
+const RevisionSentenceMatchThreshold = 0.5
+
+// ArticleRevisionService 文章修订服务
+// 负责创建修订快照、比较修订差异，以及发布修订时同步句子并迁移依赖句子ID的数据
+type ArticleRevisionService struct {
+	db          *gorm.DB
+	repo        *repository.ArticleRevisionRepository
+	articleRepo *repository.ArticleRepository
+	storage     storage.Storage
+}
+
+func NewArticleRevisionService(db *gorm.DB, st storage.Storage) *ArticleRevisionService {
+	return &ArticleRevisionService{
+		db:          db,
+		repo:        repository.NewArticleRevisionRepository(db),
+		articleRepo: repository.NewArticleRepository(),
+		storage:     st,
+	}
+}
+
+// ErrRevisionOutdated 修订基于的版本已不是线上版本（发布后会覆盖之后的修改）
+var ErrRevisionOutdated = errors.New("修订基于的版本已不是线上版本，请基于最新版本重新创建修订")
+
+// RevisionInput 创建修订的输入
+// 没有提供的字段（标题为空、markdown 或 sentences 缺省）沿用最新修订的内容
+type RevisionInput struct {
+	Title     string                   `json:"title"`
+	Markdown  *string                  `json:"markdown"`
+	Sentences []model.RevisionSentence `json:"sentences"` // 缺省或为 null 时沿用，[] 表示清空
+	Note      string                   `json:"note"`
+}
+
+// SentenceChange 句子级差异
+type SentenceChange struct {
+	Action         string  `json:"action"` // keep/modify/insert/delete
+	OldIndex       int     `json:"old_index"`
+	NewIndex       int     `json:"new_index"`
+	OldText        string  `json:"old_text,omitempty"`
+	NewText        string  `json:"new_text,omitempty"`
+	OldTranslation string  `json:"old_translation,omitempty"`
+	NewTranslation string  `json:"new_translation,omitempty"`
+	Similarity     float64 `json:"similarity"`
+}
+
+// RevisionDiff 两个修订之间的差异
+type RevisionDiff struct {
+	From          int              `json:"from"`
+	To            int              `json:"to"`
+	OldTitle      string           `json:"old_title"`
+	NewTitle      string           `json:"new_title"`
+	TitleChanged  bool             `json:"title_changed"`
+	Markdown      []textdiff.Op    `json:"markdown"`
+	MarkdownStats textdiff.Stats   `json:"markdown_stats"`
+	Sentences     []SentenceChange `json:"sentences"`
+}
+
+// PublishResult 发布结果
+type PublishResult struct {
+	Revision *model.ArticleRevision `json:"revision"`
+	Kept     int                    `json:"kept"`
+	Modified int                    `json:"modified"`
+	Inserted int                    `json:"inserted"`
+	Replaced int                    `json:"replaced"`
+	Deleted  int                    `json:"deleted"`
+}
+
+// ListRevisions 获取文章的修订列表（首次调用时会为线上内容生成初始版本）
+func (s *ArticleRevisionService) ListRevisions(articleID uint) ([]model.ArticleRevision, error) {
+	if err := s.ensureBaseline(articleID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(articleID)
+}
+
+// GetRevision 获取修订详情（含句子快照）
+func (s *ArticleRevisionService) GetRevision(articleID uint, revisionNo int) (*model.ArticleRevision, error) {
+	revision, err := s.repo.GetByNo(articleID, revisionNo)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeSentences(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// CreateRevision 基于最新修订创建新的草稿修订
+func (s *ArticleRevisionService) CreateRevision(articleID, editorID uint, input RevisionInput) (*model.ArticleRevision, error) {
+	if err := s.ensureBaseline(articleID); err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repo.GetLatest(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeSentences(latest); err != nil {
+		return nil, err
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		input.Title = latest.Title
+	}
+	markdown := latest.Markdown
+	if input.Markdown != nil {
+		markdown = *input.Markdown
+	}
+	if input.Sentences == nil {
+		input.Sentences = latest.SentenceList
+	}
+	for i := range input.Sentences {
+		input.Sentences[i].Text = strings.TrimSpace(input.Sentences[i].Text)
+		input.Sentences[i].Translation = strings.TrimSpace(input.Sentences[i].Translation)
+		if input.Sentences[i].Text == "" {
+			return nil, fmt.Errorf("第 %d 个句子内容为空", i+1)
+		}
+	}
+
+	revision, err := newRevision(articleID, input.Title, markdown, input.Sentences)
+	if err != nil {
+		return nil, err
+	}
+	if revision.ContentHash == latest.ContentHash {
+		return nil, fmt.Errorf("内容与版本 %d 相同，无需创建新修订", latest.RevisionNo)
+	}
+
+	revision.BaseRevisionID = &latest.ID
+	revision.Note = input.Note
+	if editorID > 0 {
+		revision.EditorID = &editorID
+	}
+	if err := s.repo.Create(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Diff 比较两个修订
+func (s *ArticleRevisionService) Diff(articleID uint, from, to int) (*RevisionDiff, error) {
+	a, err := s.GetRevision(articleID, from)
+	if err != nil {
+		return nil, fmt.Errorf("版本 %d 不存在", from)
+	}
+	b, err := s.GetRevision(articleID, to)
+	if err != nil {
+		return nil, fmt.Errorf("版本 %d 不存在", to)
+	}
+
+	ops := textdiff.Diff(textdiff.Lines(a.Markdown), textdiff.Lines(b.Markdown))
+	diff := &RevisionDiff{
+		From:          from,
+		To:            to,
+		OldTitle:      a.Title,
+		NewTitle:      b.Title,
+		TitleChanged:  a.Title != b.Title,
+		Markdown:      ops,
+		MarkdownStats: textdiff.Count(ops),
+	}
+
+	for _, p := range textdiff.Align(sentenceTexts(a.SentenceList), sentenceTexts(b.SentenceList), RevisionSentenceMatchThreshold) {
+		change := SentenceChange{OldIndex: p.AIndex, NewIndex: p.BIndex, Similarity: p.Similarity}
+		if p.AIndex >= 0 {
+			change.OldText = a.SentenceList[p.AIndex].Text
+			change.OldTranslation = a.SentenceList[p.AIndex].Translation
+		}
+		if p.BIndex >= 0 {
+			change.NewText = b.SentenceList[p.BIndex].Text
+			change.NewTranslation = b.SentenceList[p.BIndex].Translation
+		}
+		switch {
+		case p.AIndex < 0:
+			change.Action = model.SentenceRemapInsert
+		case p.BIndex < 0:
+			change.Action = model.SentenceRemapDelete
+		case change.OldText == change.NewText && change.OldTranslation == change.NewTranslation:
+			change.Action = model.SentenceRemapKeep
+		default:
+			change.Action = model.SentenceRemapModify
+		}
+		diff.Sentences = append(diff.Sentences, change)
+	}
+
+	return diff, nil
+}
+
+// Publish 发布修订：上传 Markdown、同步 vp_sentences，并把默写记录、生词本、默写进度中的句子引用迁移到新句子
+func (s *ArticleRevisionService) Publish(articleID uint, revisionNo int) (*PublishResult, error) {
+	revision, err := s.GetRevision(articleID, revisionNo)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status == model.RevisionStatusPublished {
+		return nil, fmt.Errorf("版本 %d 已发布", revisionNo)
+	}
+	if err := s.checkBase(nil, revision); err != nil {
+		return nil, err
+	}
+
+	// 1. 上传 Markdown（事务外执行，失败时不改动数据库）
+	articleURL := ""
+	if revision.Markdown != "" {
+		if s.storage == nil {
+			return nil, fmt.Errorf("存储未配置，无法发布正文")
+		}
+		path := fmt.Sprintf("article/revisions/article_%d_v%d.md", articleID, revision.RevisionNo)
+		articleURL, err = s.storage.Save(context.Background(), path, []byte(revision.Markdown))
+		if err != nil {
+			return nil, fmt.Errorf("上传正文失败: %w", err)
+		}
+	}
+
+	result := &PublishResult{Revision: revision}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定文章后再次检查，避免并发发布同一篇文章的两个修订
+		if err := s.repo.LockArticle(tx, articleID); err != nil {
+			return err
+		}
+		if err := s.checkBase(tx, revision); err != nil {
+			return err
+		}
+
+		live, err := s.repo.GetLiveSentences(tx, articleID)
+		if err != nil {
+			return err
+		}
+		liveTexts := make([]string, len(live))
+		for i, st := range live {
+			liveTexts[i] = st.Text
+		}
+		pairs := textdiff.Align(liveTexts, sentenceTexts(revision.SentenceList), RevisionSentenceMatchThreshold)
+
+		// 2. 同步句子：匹配上的保留ID并更新内容，新增的创建，没有匹配的删除
+		var remaps []model.SentenceRemap
+		var pendingDeletes []int
+		indexMap := make(map[int]int, len(live)) // 旧句子下标 -> 新句子下标（用于迁移默写进度）
+		var deleted []int                        // 被删除（没有替换句）的旧句子下标
+		flushDeletes := func() {
+			deleted = append(deleted, pendingDeletes...)
+			pendingDeletes = pendingDeletes[:0]
+		}
+
+		for _, p := range pairs {
+			switch {
+			case p.AIndex >= 0 && p.BIndex >= 0:
+				flushDeletes()
+				old := live[p.AIndex]
+				rs := revision.SentenceList[p.BIndex]
+				action := model.SentenceRemapKeep
+				if old.Text != rs.Text || old.Translation != rs.Translation {
+					action = model.SentenceRemapModify
+					result.Modified++
+				} else {
+					result.Kept++
+				}
+				if err := tx.Model(&model.Sentence{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+					"text":        rs.Text,
+					"translation": rs.Translation,
+					"order":       p.BIndex + 1,
+				}).Error; err != nil {
+					return err
+				}
+				if action == model.SentenceRemapModify && old.Text != rs.Text {
+					if err := remapVocabularyContext(tx, old.ID, old.ID, old.Text, rs.Text); err != nil {
+						return err
+					}
+				}
+				id := old.ID
+				indexMap[p.AIndex] = p.BIndex
+				remaps = append(remaps, model.SentenceRemap{OldSentenceID: &id, NewSentenceID: &id, Action: action})
+
+			case p.AIndex >= 0:
+				pendingDeletes = append(pendingDeletes, p.AIndex)
+
+			default:
+				rs := revision.SentenceList[p.BIndex]
+				created := model.Sentence{
+					ArticleID:   articleID,
+					Text:        rs.Text,
+					Translation: rs.Translation,
+					Order:       p.BIndex + 1,
+				}
+				if err := tx.Create(&created).Error; err != nil {
+					return err
+				}
+				newID := created.ID
+
+				// 同一位置先删后增视为替换：引用迁移到新句子
+				if len(pendingDeletes) > 0 {
+					oldIdx := pendingDeletes[0]
+					pendingDeletes = pendingDeletes[1:]
+					old := live[oldIdx]
+					if err := remapSentenceRefs(tx, old.ID, &newID); err != nil {
+						return err
+					}
+					if err := remapVocabularyContext(tx, newID, old.ID, old.Text, rs.Text); err != nil {
+						return err
+					}
+					if err := tx.Delete(&model.Sentence{}, old.ID).Error; err != nil {
+						return err
+					}
+					oldID := old.ID
+					indexMap[oldIdx] = p.BIndex
+					remaps = append(remaps, model.SentenceRemap{OldSentenceID: &oldID, NewSentenceID: &newID, Action: model.SentenceRemapReplace})
+					result.Replaced++
+				} else {
+					remaps = append(remaps, model.SentenceRemap{NewSentenceID: &newID, Action: model.SentenceRemapInsert})
+					result.Inserted++
+				}
+			}
+		}
+		flushDeletes()
+
+		for _, idx := range deleted {
+			old := live[idx]
+			if err := remapSentenceRefs(tx, old.ID, nil); err != nil {
+				return err
+			}
+			if err := tx.Delete(&model.Sentence{}, old.ID).Error; err != nil {
+				return err
+			}
+			oldID := old.ID
+			remaps = append(remaps, model.SentenceRemap{OldSentenceID: &oldID, Action: model.SentenceRemapDelete})
+			result.Deleted++
+		}
+
+		// 3. 迁移句子默写进度（current_index 是句子下标）
+		if err := remapDictationProgress(tx, articleID, len(live), len(revision.SentenceList), indexMap); err != nil {
+			return err
+		}
+
+		// 4. 记录重映射
+		for i := range remaps {
+			remaps[i].RevisionID = revision.ID
+			remaps[i].ArticleID = articleID
+		}
+		if len(remaps) > 0 {
+			if err := tx.CreateInBatches(remaps, 200).Error; err != nil {
+				return err
+			}
+		}
+
+		// 5. 更新文章并标记修订已发布
+		updates := map[string]interface{}{"title": revision.Title}
+		if articleURL != "" {
+			updates["article_url"] = articleURL
+		}
+		if err := tx.Model(&model.Article{}).Where("id = ?", articleID).Updates(updates).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&model.ArticleRevision{}).Where("id = ?", revision.ID).Updates(map[string]interface{}{
+			"status":       model.RevisionStatusPublished,
+			"published_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		revision.Status = model.RevisionStatusPublished
+		revision.PublishedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 文章 %d 发布版本 %d: 保留%d 修改%d 新增%d 替换%d 删除%d",
+		articleID, revision.RevisionNo, result.Kept, result.Modified, result.Inserted, result.Replaced, result.Deleted)
+	return result, nil
+}
+
+// checkBase 检查修订是否基于当前线上版本
+// 沿 BaseRevisionID 向上找到第一个已发布的修订，它必须是最新发布的版本，否则发布会覆盖之后发布的修改
+func (s *ArticleRevisionService) checkBase(tx *gorm.DB, revision *model.ArticleRevision) error {
+	current, err := s.repo.GetLatestPublished(tx, revision.ArticleID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // 还没有发布过的版本
+	}
+	if err != nil {
+		return err
+	}
+
+	baseID := revision.BaseRevisionID
+	for baseID != nil {
+		base, err := s.repo.GetByID(tx, *baseID)
+		if err != nil {
+			return err
+		}
+		if base.Status == model.RevisionStatusPublished {
+			if base.ID != current.ID {
+				return fmt.Errorf("%w（基于版本 %d，线上为版本 %d）", ErrRevisionOutdated, base.RevisionNo, current.RevisionNo)
+			}
+			return nil
+		}
+		baseID = base.BaseRevisionID
+	}
+	return ErrRevisionOutdated
+}
+
+// ensureBaseline 文章还没有任何修订时，把线上内容保存为版本1
+func (s *ArticleRevisionService) ensureBaseline(articleID uint) error {
+	count, err := s.repo.Count(articleID)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	article, err := s.articleRepo.FindByID(articleID)
+	if err != nil {
+		return err
+	}
+	live, err := s.repo.GetLiveSentences(nil, articleID)
+	if err != nil {
+		return err
+	}
+	sentences := make([]model.RevisionSentence, 0, len(live))
+	for _, st := range live {
+		sentences = append(sentences, model.RevisionSentence{Text: st.Text, Translation: st.Translation})
+	}
+
+	revision, err := newRevision(articleID, article.Title, article.Content, sentences)
+	if err != nil {
+		return err
+	}
+	publishedAt := article.UpdatedAt
+	revision.Status = model.RevisionStatusPublished
+	revision.PublishedAt = &publishedAt
+	revision.Note = "初始版本"
+	return s.repo.Create(revision)
+}
+
+// newRevision 构造修订并计算内容哈希
+func newRevision(articleID uint, title, markdown string, sentences []model.RevisionSentence) (*model.ArticleRevision, error) {
+	if sentences == nil {
+		sentences = []model.RevisionSentence{}
+	}
+	data, err := json.Marshal(sentences)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(title + "\x00" + markdown + "\x00" + string(data)))
+	return &model.ArticleRevision{
+		ArticleID:    articleID,
+		Title:        title,
+		Markdown:     markdown,
+		Sentences:    string(data),
+		ContentHash:  hex.EncodeToString(sum[:]),
+		Status:       model.RevisionStatusDraft,
+		SentenceList: sentences,
+	}, nil
+}
+
+func decodeSentences(revision *model.ArticleRevision) error {
+	revision.SentenceList = []model.RevisionSentence{}
+	if revision.Sentences == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(revision.Sentences), &revision.SentenceList); err != nil {
+		return fmt.Errorf("解析句子快照失败: %w", err)
+	}
+	return nil
+}
+
+func sentenceTexts(list []model.RevisionSentence) []string {
+	texts := make([]string, len(list))
+	for i, st := range list {
+		texts[i] = st.Text
+	}
+	return texts
+}
+
+// remapSentenceRefs 把默写记录和生词本中对旧句子的引用迁移到新句子（newID 为 nil 时置空）
+func remapSentenceRefs(tx *gorm.DB, oldID uint, newID *uint) error {
+	if err := tx.Model(&model.DictationRecord{}).Where("sentence_id = ?", oldID).
+		Update("sentence_id", newID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.Vocabulary{}).Where("sentence_id = ?", oldID).
+		Update("sentence_id", newID).Error
+}
+
+// remapVocabularyContext 句子内容变化后，同步生词本中仍是旧句子原文的上下文
+func remapVocabularyContext(tx *gorm.DB, sentenceID, oldID uint, oldText, newText string) error {
+	return tx.Model(&model.Vocabulary{}).
+		Where("sentence_id IN ? AND context = ?", []uint{sentenceID, oldID}, oldText).
+		Update("context", newText).Error
+}
+
+// remapDictationProgress 迁移句子默写进度的 current_index
+// 被删除的句子映射到其后第一个保留下来的句子
+func remapDictationProgress(tx *gorm.DB, articleID uint, oldTotal, newTotal int, indexMap map[int]int) error {
+	var progresses []model.UserDictationProgress
+	if err := tx.Where("article_id = ? AND dictation_type = ?", articleID, string(model.DictationTypeSentence)).
+		Find(&progresses).Error; err != nil {
+		return err
+	}
+
+	for _, p := range progresses {
+		newIndex := newTotal
+		if p.CurrentIndex < oldTotal {
+			for i := p.CurrentIndex; i < oldTotal; i++ {
+				if idx, ok := indexMap[i]; ok {
+					newIndex = idx
+					break
+				}
+			}
+		}
+		if newIndex == p.CurrentIndex && newTotal == p.TotalItems {
+			continue
+		}
+		if err := tx.Model(&model.UserDictationProgress{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"current_index": newIndex,
+			"total_items":   newTotal,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -81,9 +81,9 @@ func (s *AuthService) LoginWithEmail(email, code, ipAddress string) (*model.User
 			// 不影响登录流程
 		}
 
-		fmt.Printf("✅ 用户创建成功: id=%d, email=%s, invite_code=%s\n", user.ID, user.Email, user.InviteCode)
+		fmt.Printf("✅ 用户创建成功: id=%d, email=%s, invite_code=%s\n", user.ID, stringValue(user.Email), user.InviteCode)
 	} else {
-		fmt.Printf("✅ 用户已存在: id=%d, email=%s\n", user.ID, user.Email)
+		fmt.Printf("✅ 用户已存在: id=%d, email=%s\n", user.ID, stringValue(user.Email))
 		// 更新邮箱验证状态
 		if !user.EmailVerified {
 			now := time.Now()
@@ -285,6 +285,19 @@ func (s *AuthService) UpdateUserProfile(userID uint, nickname, avatar, bio strin
 	return user, nil
 }
 
+// UpdateUserTimezone 更新用户时区（IANA 名称，空字符串表示使用服务器时区）
+func (s *AuthService) UpdateUserTimezone(userID uint, timezone string) error {
+	if err := ValidateTimezone(timezone); err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateTimezone(userID, timezone); err != nil {
+		return fmt.Errorf("更新用户时区失败: %w", err)
+	}
+	InvalidateUserLocation(userID)
+	fmt.Printf("✅ 用户时区更新成功: user_id=%d, timezone=%s\n", userID, timezone)
+	return nil
+}
+
 // generateDefaultAvatar 生成默认头像URL（使用邮箱首字母）
 func generateDefaultAvatar(email string) string {
 	// 提取邮箱首字母（@前面的第一个字符）
@@ -375,7 +388,7 @@ func (s *AuthService) Register(email, password, code, inviteCode, ipAddress stri
 		// 不影响注册流程
 	}
 
-	fmt.Printf("✅ 用户注册成功: id=%d, email=%s, invite_code=%s\n", user.ID, user.Email, user.InviteCode)
+	fmt.Printf("✅ 用户注册成功: id=%d, email=%s, invite_code=%s\n", user.ID, stringValue(user.Email), user.InviteCode)
 
 	// 更新最后登录信息
 	if err := s.userRepo.UpdateLastLogin(user.ID, ipAddress); err != nil {
@@ -443,7 +456,7 @@ func (s *AuthService) ResetPassword(email, code, newPassword string) error {
 		return fmt.Errorf("更新密码失败: %w", err)
 	}
 
-	fmt.Printf("✅ 用户密码重置成功: user_id=%d, email=%s\n", user.ID, user.Email)
+	fmt.Printf("✅ 用户密码重置成功: user_id=%d, email=%s\n", user.ID, stringValue(user.Email))
 	return nil
 }
 
@@ -451,3 +464,11 @@ func (s *AuthService) ResetPassword(email, code, newPassword string) error {
 func (s *AuthService) UpdateLastLogin(userID uint, ipAddress string) error {
 	return s.userRepo.UpdateLastLogin(userID, ipAddress)
 }
+
+// stringValue 获取字符串指针的值（为 nil 时返回空字符串）
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
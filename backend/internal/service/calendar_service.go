@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/clock"
+)
+
+// userLocationTTL 用户时区的缓存时间（本进程修改时区时立即清除）
+const userLocationTTL = 10 * time.Minute
+
+var ErrInvalidTimezone = errors.New("无效的时区，请使用 IANA 时区名称，如 Asia/Shanghai")
+
+type cachedLocation struct {
+	loc     *time.Location
+	expires time.Time
+}
+
+// userLocations 用户时区缓存（userID -> cachedLocation），所有 CalendarService 共用
+var userLocations sync.Map
+
+// InvalidateUserLocation 清除用户时区缓存（修改时区后调用）
+func InvalidateUserLocation(userID uint) {
+	userLocations.Delete(userID)
+}
+
+// ValidateTimezone 校验 IANA 时区名称，空字符串表示使用服务器时区
+func ValidateTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := clock.LoadLocation(name); !ok {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// CalendarService 按用户时区计算"今天"
+// 签到、每日统计、连续学习、复习会话等按天划分的功能都通过它取日期，不直接使用服务器的 time.Now()。
+// 日期（Today）用服务器时区当天 0 点表示，与数据库 DATE 字段一致；
+// 按时间戳字段统计某一天时，用 DayStart 换算出用户当天开始的时刻。
+type CalendarService struct {
+	clock    clock.Clock
+	timezone func(userID uint) (string, error) // 查询用户时区设置
+}
+
+func NewCalendarService() *CalendarService {
+	return NewCalendarServiceWithClock(clock.System)
+}
+
+var (
+	defaultCalendar     *CalendarService
+	defaultCalendarOnce sync.Once
+)
+
+// DefaultCalendar 使用系统时钟的日历，handler 和后台任务创建服务时传入（测试时传入 NewCalendarServiceWithClock 创建的日历）
+func DefaultCalendar() *CalendarService {
+	defaultCalendarOnce.Do(func() {
+		defaultCalendar = NewCalendarService()
+	})
+	return defaultCalendar
+}
+
+// NewCalendarServiceWithClock 使用指定时钟（测试时传入 clock.Fake）
+func NewCalendarServiceWithClock(c clock.Clock) *CalendarService {
+	return &CalendarService{
+		clock:    c,
+		timezone: repository.NewUserRepository().GetTimezone,
+	}
+}
+
+// Now 当前时刻
+func (s *CalendarService) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Location 用户所在时区，未设置、无效或未登录（userID 为 0）时为服务器时区
+func (s *CalendarService) Location(userID uint) *time.Location {
+	if userID == 0 {
+		return time.Local
+	}
+	now := s.clock.Now()
+	if cached, ok := userLocations.Load(userID); ok && now.Before(cached.(cachedLocation).expires) {
+		return cached.(cachedLocation).loc
+	}
+
+	loc := time.Local
+	name, err := s.timezone(userID)
+	if err != nil {
+		log.Printf("⚠️ 查询用户时区失败 (user=%d): %v", userID, err)
+		return loc // 查询失败不缓存
+	}
+	if l, ok := clock.LoadLocation(name); ok {
+		loc = l
+	}
+	userLocations.Store(userID, cachedLocation{loc: loc, expires: now.Add(userLocationTTL)})
+	return loc
+}
+
+// LocalNow 用户时区中的当前时刻，用于按用户的日历计算今天、本周、本月
+func (s *CalendarService) LocalNow(userID uint) time.Time {
+	return s.clock.Now().In(s.Location(userID))
+}
+
+// Today 用户的今天（服务器时区 0 点表示的日期）
+func (s *CalendarService) Today(userID uint) time.Time {
+	return clock.Date(s.clock.Now(), s.Location(userID))
+}
+
+// TodayString 用户的今天（YYYY-MM-DD）
+func (s *CalendarService) TodayString(userID uint) string {
+	return s.Today(userID).Format("2006-01-02")
+}
+
+// Date 数据库 DATE 字段读出的日期（数据库驱动可能按 UTC 解析）换成与 Today 相同的表示，用于比较和计算天数
+func (s *CalendarService) Date(t time.Time) time.Time {
+	return clock.Date(t, t.Location())
+}
+
+// DayStart 用户时区中日期 date 开始的时刻
+func (s *CalendarService) DayStart(userID uint, date time.Time) time.Time {
+	return clock.StartOf(date, s.Location(userID))
+}
+
+// TodayStart 用户今天开始的时刻
+func (s *CalendarService) TodayStart(userID uint) time.Time {
+	return s.DayStart(userID, s.Today(userID))
+}
+
+// TomorrowStart 用户明天开始的时刻（今天结束）
+func (s *CalendarService) TomorrowStart(userID uint) time.Time {
+	return s.DayStart(userID, s.Today(userID).AddDate(0, 0, 1))
+}
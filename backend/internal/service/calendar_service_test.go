@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"voicepaper/pkg/clock"
+)
+
+// 测试用户及其时区（用户ID取较大的值，避免与其他测试共用时区缓存）
+const (
+	testUserShanghai uint = 90001
+	testUserNewYork  uint = 90002
+	testUserServer   uint = 90003
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("加载时区 %s 失败: %v", name, err)
+	}
+	return loc
+}
+
+// newTestCalendar 使用假时钟和固定时区设置的日历
+func newTestCalendar(t *testing.T, now time.Time, timezones map[uint]string) (*CalendarService, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(now)
+	cal := NewCalendarServiceWithClock(fake)
+	cal.timezone = func(userID uint) (string, error) {
+		return timezones[userID], nil
+	}
+	t.Cleanup(func() {
+		for userID := range timezones {
+			InvalidateUserLocation(userID)
+		}
+	})
+	return cal, fake
+}
+
+func TestCalendarTodayAcrossTimezones(t *testing.T) {
+	shanghai := mustLoad(t, "Asia/Shanghai")
+	// 上海 2026-03-01 23:30，纽约 2026-03-01 10:30
+	cal, fake := newTestCalendar(t, time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC), map[uint]string{
+		testUserShanghai: "Asia/Shanghai",
+		testUserNewYork:  "America/New_York",
+		testUserServer:   "",
+	})
+
+	check := func(userID uint, want string) {
+		t.Helper()
+		today := cal.Today(userID)
+		if got := today.Format("2006-01-02"); got != want {
+			t.Errorf("Today(%d) = %s, want %s", userID, got, want)
+		}
+		if today.Location() != time.Local || today.Hour() != 0 {
+			t.Errorf("Today(%d) = %v, 应为服务器时区 0 点", userID, today)
+		}
+	}
+	check(testUserShanghai, "2026-03-01")
+	check(testUserNewYork, "2026-03-01")
+	check(testUserServer, fake.Now().In(time.Local).Format("2006-01-02"))
+
+	// 一小时后上海已是第二天，纽约还是同一天
+	fake.Advance(time.Hour)
+	check(testUserShanghai, "2026-03-02")
+	check(testUserNewYork, "2026-03-01")
+
+	if got, want := cal.TodayStart(testUserShanghai), time.Date(2026, 3, 2, 0, 0, 0, 0, shanghai); !got.Equal(want) {
+		t.Errorf("TodayStart = %v, want %v", got, want)
+	}
+	if got, want := cal.TomorrowStart(testUserShanghai), time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("TomorrowStart = %v, want %v", got, want)
+	}
+	if got := cal.Today(0).Format("2006-01-02"); got != fake.Now().In(time.Local).Format("2006-01-02") {
+		t.Errorf("未登录用户的 Today = %s, 应为服务器时区的日期", got)
+	}
+}
+
+func TestCalendarLocationCacheUsesClock(t *testing.T) {
+	timezones := map[uint]string{testUserShanghai: "Asia/Shanghai"}
+	cal, fake := newTestCalendar(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), timezones)
+
+	if got := cal.Location(testUserShanghai).String(); got != "Asia/Shanghai" {
+		t.Fatalf("Location = %s, want Asia/Shanghai", got)
+	}
+	// 其他进程修改了时区：缓存过期前仍使用旧时区，按日历的时钟过期
+	timezones[testUserShanghai] = "America/New_York"
+	fake.Advance(userLocationTTL - time.Second)
+	if got := cal.Location(testUserShanghai).String(); got != "Asia/Shanghai" {
+		t.Errorf("缓存过期前 Location = %s, want Asia/Shanghai", got)
+	}
+	fake.Advance(2 * time.Second)
+	if got := cal.Location(testUserShanghai).String(); got != "America/New_York" {
+		t.Errorf("缓存过期后 Location = %s, want America/New_York", got)
+	}
+}
+
+func TestCalendarLocationFallback(t *testing.T) {
+	cal, _ := newTestCalendar(t, time.Now(), map[uint]string{testUserServer: "Mars/Olympus"})
+	if got := cal.Location(testUserServer); got != time.Local {
+		t.Errorf("无效时区 Location = %v, want 服务器时区", got)
+	}
+
+	cal.timezone = func(uint) (string, error) { return "", errors.New("db down") }
+	InvalidateUserLocation(testUserServer)
+	if got := cal.Location(testUserServer); got != time.Local {
+		t.Errorf("查询失败时 Location = %v, want 服务器时区", got)
+	}
+}
+
+func TestCalendarDate(t *testing.T) {
+	cal, _ := newTestCalendar(t, time.Now(), nil)
+	want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+	// 数据库驱动按 UTC 或其他时区解析出的 DATE 字段，日历日期不变
+	for _, loc := range []*time.Location{time.UTC, mustLoad(t, "Pacific/Kiritimati"), mustLoad(t, "America/New_York")} {
+		if got := cal.Date(time.Date(2026, 3, 5, 0, 0, 0, 0, loc)); !got.Equal(want) {
+			t.Errorf("Date(%s) = %v, want %v", loc, got, want)
+		}
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	kiritimati := mustLoad(t, "Pacific/Kiritimati") // UTC+14
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"同一天", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), 0},
+		{"跨月", time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), 3},
+		{"夏令时开始", time.Date(2026, 3, 7, 0, 0, 0, 0, newYork), time.Date(2026, 3, 9, 0, 0, 0, 0, newYork), 2},
+		// 两个日期在不同时区：按各自的日历日计算，不按时刻相减
+		{"不同时区", time.Date(2026, 3, 1, 0, 0, 0, 0, newYork), time.Date(2026, 3, 3, 0, 0, 0, 0, kiritimati), 2},
+		{"倒序", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("daysBetween = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalDeadlineAcrossTimezones(t *testing.T) {
+	// 上海 2026-03-01 23:30，纽约 2026-03-01 10:30
+	cal, fake := newTestCalendar(t, time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC), map[uint]string{
+		testUserShanghai: "Asia/Shanghai",
+		testUserNewYork:  "America/New_York",
+	})
+
+	for _, userID := range []uint{testUserShanghai, testUserNewYork} {
+		if _, err := parseGoalDeadline("2026-03-01", cal.Today(userID)); err != nil {
+			t.Errorf("用户 %d: 截止日期为今天应该有效, err = %v", userID, err)
+		}
+	}
+
+	// 上海跨过零点后，截止日期 03-01 已经过去；纽约仍是 03-01
+	fake.Advance(time.Hour)
+	if _, err := parseGoalDeadline("2026-03-01", cal.Today(testUserShanghai)); !errors.Is(err, ErrInvalidGoalDeadline) {
+		t.Errorf("上海用户: err = %v, want ErrInvalidGoalDeadline", err)
+	}
+	deadline, err := parseGoalDeadline("2026-03-01", cal.Today(testUserNewYork))
+	if err != nil {
+		t.Fatalf("纽约用户: err = %v", err)
+	}
+	if left := daysBetween(cal.Today(testUserNewYork), cal.Date(deadline)) + 1; left != 1 {
+		t.Errorf("纽约用户剩余天数 = %d, want 1", left)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 	"voicepaper/config"
@@ -19,11 +20,12 @@ type CheckInService struct {
 	checkInRepo     *repository.CheckInRepository
 	userPointsRepo  *repository.UserPointsRepository
 	pointRecordRepo *repository.PointRecordRepository
-	pointService    *PointService
+	goalService     *GoalService
+	calendar        *CalendarService
 	storage         storage.Storage
 }
 
-func NewCheckInService(db *gorm.DB) *CheckInService {
+func NewCheckInService(db *gorm.DB, calendar *CalendarService) *CheckInService {
 	cfg := config.GetConfig()
 	var st storage.Storage
 	var err error
@@ -42,15 +44,17 @@ func NewCheckInService(db *gorm.DB) *CheckInService {
 		checkInRepo:     repository.NewCheckInRepository(db),
 		userPointsRepo:  repository.NewUserPointsRepository(db),
 		pointRecordRepo: repository.NewPointRecordRepository(db),
-		pointService:    NewPointService(db),
+		goalService:     NewGoalService(db, calendar),
+		calendar:        calendar,
 		storage:         st,
 	}
 }
 
 // CheckIn 用户签到
 func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
-	// 1. 检查今天是否已签到
-	alreadyCheckedIn, err := s.checkInRepo.CheckTodayCheckIn(userID)
+	// 1. 检查今天（用户时区）是否已签到
+	today := s.calendar.Today(userID)
+	alreadyCheckedIn, err := s.checkInRepo.CheckDateCheckIn(userID, today)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +63,7 @@ func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
 	}
 
 	// 2. 计算连续签到天数
-	continuousDays, err := s.checkInRepo.CalculateContinuousDays(userID)
+	continuousDays, err := s.checkInRepo.CalculateContinuousDays(userID, today)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +80,6 @@ func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
 	}
 
 	totalPoints := basePoints + bonusPoints
-	today := time.Now()
 
 	var userPoints *model.UserPoints
 	var checkInRecord *model.UserCheckIn
@@ -157,8 +160,8 @@ func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	// 5. 异步检查是否达成新称号
-	go s.pointService.CheckAndAwardTitles(userID)
+	// 5. 发布签到事件（检查是否达成新称号）
+	event := Events().Publish(Event{Kind: EventCheckedIn, UserID: userID})
 
 	// 6. 获取最新的用户积分统计（包含累计签到和最大连续签到）
 	latestUserPoints, err := s.userPointsRepo.GetByUserID(userID)
@@ -166,7 +169,7 @@ func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
 		latestUserPoints = userPoints // 如果获取失败，使用之前的数据
 	}
 
-	// 7. 返回签到结果
+	// 7. 返回签到结果（附带进行中学习目标的今日完成情况）
 	return map[string]interface{}{
 		"success":             true,
 		"message":             "签到成功",
@@ -181,13 +184,16 @@ func (s *CheckInService) CheckIn(userID uint) (map[string]interface{}, error) {
 		"check_in_date":       checkInRecord.CheckInDate.Format("2006-01-02"),
 		"total_check_ins":     latestUserPoints.TotalCheckIns,         // 累计签到天数
 		"max_continuous_days": latestUserPoints.MaxContinuousCheckIns, // 最大连续签到天数
+		"goals":               s.todayGoals(userID),
+		"new_titles":          event.UnlockedTitles,
 	}, nil
 }
 
 // GetCheckInStatus 获取签到状态
 func (s *CheckInService) GetCheckInStatus(userID uint) (map[string]interface{}, error) {
-	// 1. 检查今天是否已签到
-	todayCheckedIn, err := s.checkInRepo.CheckTodayCheckIn(userID)
+	// 1. 检查今天（用户时区）是否已签到
+	today := s.calendar.Today(userID)
+	todayCheckedIn, err := s.checkInRepo.CheckDateCheckIn(userID, today)
 	if err != nil {
 		return nil, err
 	}
@@ -196,20 +202,20 @@ func (s *CheckInService) GetCheckInStatus(userID uint) (map[string]interface{},
 	userPoints, err := s.userPointsRepo.GetByUserID(userID)
 	if err != nil {
 		// 如果没有积分记录，实时计算连续签到天数
-		continuousDays, _ := s.checkInRepo.CalculateContinuousDays(userID)
+		continuousDays, _ := s.checkInRepo.CalculateContinuousDays(userID, today)
 		userPoints = &model.UserPoints{
 			ContinuousCheckIns: continuousDays,
 		}
 	}
 
 	// 3. 获取本月签到次数
-	monthCheckInCount, err := s.checkInRepo.GetMonthCheckInCount(userID)
+	monthCheckInCount, err := s.checkInRepo.GetMonthCheckInCount(userID, today)
 	if err != nil {
 		monthCheckInCount = 0
 	}
 
 	// 4. 获取最近7天的签到历史
-	recentCheckIns, err := s.checkInRepo.GetCheckInHistory(userID, 7)
+	recentCheckIns, err := s.checkInRepo.GetCheckInHistory(userID, today, 7)
 	if err != nil {
 		recentCheckIns = []model.UserCheckIn{}
 	}
@@ -234,9 +240,20 @@ func (s *CheckInService) GetCheckInStatus(userID uint) (map[string]interface{},
 		"next_milestone":        nextMilestone,
 		"next_milestone_reward": nextMilestoneReward,
 		"check_in_rewards":      model.ContinuousCheckInRewards,
+		"goals":                 s.todayGoals(userID),
 	}, nil
 }
 
+// todayGoals 进行中学习目标的今日配额和完成情况，查询失败时返回空列表，不影响签到
+func (s *CheckInService) todayGoals(userID uint) []GoalProgress {
+	goals, err := s.goalService.Today(userID)
+	if err != nil {
+		log.Printf("⚠️ 获取学习目标失败 (user=%d): %v", userID, err)
+		return []GoalProgress{}
+	}
+	return goals
+}
+
 // GetCheckInRanking 获取签到排行榜
 func (s *CheckInService) GetCheckInRanking(limit int, sortBy string, userID uint) (map[string]interface{}, error) {
 	if limit <= 0 || limit > 100 {
@@ -378,11 +395,10 @@ func (s *CheckInService) GetMakeupCardInfo(userID uint) (map[string]interface{},
 
 // getMakeupAvailableDates 获取可补签的日期列表
 func (s *CheckInService) getMakeupAvailableDates(userID uint) ([]string, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today := s.calendar.Today(userID)
 
 	// 获取最近7天的签到记录
-	checkIns, err := s.checkInRepo.GetCheckInHistory(userID, MakeupCardMaxDays+1)
+	checkIns, err := s.checkInRepo.GetCheckInHistory(userID, today, MakeupCardMaxDays+1)
 	if err != nil {
 		return nil, err
 	}
@@ -486,9 +502,8 @@ func (s *CheckInService) UseMakeupCard(userID uint, dateStr string) (map[string]
 	}
 
 	// 2. 检查日期是否在可补签范围内
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	makeupDateNormalized := time.Date(makeupDate.Year(), makeupDate.Month(), makeupDate.Day(), 0, 0, 0, 0, now.Location())
+	today := s.calendar.Today(userID)
+	makeupDateNormalized := time.Date(makeupDate.Year(), makeupDate.Month(), makeupDate.Day(), 0, 0, 0, 0, today.Location())
 
 	if !makeupDateNormalized.Before(today) {
 		return nil, fmt.Errorf("只能补签今天之前的日期")
@@ -549,7 +564,7 @@ func (s *CheckInService) UseMakeupCard(userID uint, dateStr string) (map[string]
 	}
 
 	// 6. 重新计算连续签到天数（补签后可能会影响连续天数）
-	newContinuousDays, err := s.checkInRepo.CalculateContinuousDays(userID)
+	newContinuousDays, err := s.checkInRepo.CalculateContinuousDays(userID, today)
 	if err != nil {
 		newContinuousDays = 0
 	}
@@ -568,6 +583,9 @@ func (s *CheckInService) UseMakeupCard(userID uint, dateStr string) (map[string]
 		latestUserPoints.MaxContinuousCheckIns = newContinuousDays
 	}
 
+	// 8. 发布签到事件（补签改变了累计和连续签到天数，检查是否达成新称号）
+	event := Events().Publish(Event{Kind: EventCheckedIn, UserID: userID, Date: makeupDate})
+
 	return map[string]interface{}{
 		"success":         true,
 		"message":         fmt.Sprintf("成功补签 %s", dateStr),
@@ -575,5 +593,6 @@ func (s *CheckInService) UseMakeupCard(userID uint, dateStr string) (map[string]
 		"makeup_cards":    latestUserPoints.MakeupCards,
 		"total_check_ins": latestUserPoints.TotalCheckIns,
 		"continuous_days": newContinuousDays,
+		"new_titles":      event.UnlockedTitles,
 	}, nil
 }
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// 自建单词书限制
+const (
+	maxCustomWordbooks     = 50   // 每个用户最多创建的单词书
+	maxCustomWordbookWords = 5000 // 每本单词书最多的单词数
+	customWordbookPrefix   = "u_" // 自建单词书的类型前缀
+)
+
+// 自建单词书错误
+var (
+	ErrWordbookNotFound     = errors.New("单词书不存在")
+	ErrWordbookForbidden    = errors.New("只有创建者可以修改该单词书")
+	ErrTooManyWordbooks     = errors.New("创建的单词书数量已达上限")
+	ErrWordbookFull         = errors.New("单词书的单词数量已达上限")
+	ErrNoWordbookEntries    = errors.New("没有可添加的单词")
+	ErrWordbookShareExpired = errors.New("分享链接无效或单词书已设为私有")
+)
+
+// CustomWordbookService 用户自建单词书服务
+// 自建单词书与系统单词书共用 vp_wordbook_info / vp_wordbook_books，学习进度、学习序列和间隔重复学习接口都按类型通用。
+// 添加的单词先在词典（vp_wordbook 中的系统词条）中查找，找到则直接关联词条，找不到时按提供的释义新建词条。
+type CustomWordbookService struct {
+	repo        *repository.WordbookRepository
+	articleRepo *repository.ArticleRepository
+	enricher    *VocabularyEnricher
+}
+
+func NewCustomWordbookService(db *gorm.DB) *CustomWordbookService {
+	return &CustomWordbookService{
+		repo:        repository.NewWordbookRepository(db),
+		articleRepo: repository.NewArticleRepository(),
+		enricher:    NewVocabularyEnricher(db),
+	}
+}
+
+// CreateWordbookRequest 创建自建单词书请求
+type CreateWordbookRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	CoverURL    string `json:"cover_url" binding:"max=500"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private link public"` // 默认 private
+}
+
+// UpdateWordbookRequest 更新自建单词书请求，未传的字段保持原值
+type UpdateWordbookRequest struct {
+	Name            *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description     *string `json:"description" binding:"omitempty,max=500"`
+	CoverURL        *string `json:"cover_url" binding:"omitempty,max=500"`
+	Visibility      *string `json:"visibility" binding:"omitempty,oneof=private link public"`
+	ResetShareToken bool    `json:"reset_share_token"` // 重新生成分享链接，旧链接失效
+}
+
+// AddWordbookWordsRequest 添加单词请求
+// source=text：粘贴的单词列表，每行一个，可用 Tab 分隔释义（单词\t释义），没有释义的行也可用逗号分隔多个单词；
+// source=articles：所选文章的重点单词
+type AddWordbookWordsRequest struct {
+	Source     string `json:"source" binding:"required,oneof=text articles"`
+	Text       string `json:"text"`
+	ArticleIDs []uint `json:"article_ids"`
+}
+
+// AddWordbookWordsResult 添加单词结果
+type AddWordbookWordsResult struct {
+	Added      int      `json:"added"`      // 新加入单词书的单词
+	Matched    int      `json:"matched"`    // 在词典中找到的
+	Created    int      `json:"created"`    // 词典中没有，新建词条的
+	Duplicates int      `json:"duplicates"` // 已在单词书中的
+	Unresolved []string `json:"unresolved"` // 词典中没有且没有提供释义的（已新建词条，建议补充释义）
+	WordCount  int64    `json:"word_count"` // 单词书当前的单词数
+}
+
+// MyWordbooks 我的自建单词书
+type MyWordbooks struct {
+	Owned  []model.WordbookInfo `json:"owned"`  // 我创建的
+	Joined []model.WordbookInfo `json:"joined"` // 通过分享链接或公开列表学习的
+}
+
+// wordbookEntry 待添加的单词及用户提供的释义等
+type wordbookEntry struct {
+	Word               string
+	Phonetic           string
+	Meaning            string
+	Example            string
+	ExampleTranslation string
+}
+
+// Create 创建自建单词书
+func (s *CustomWordbookService) Create(userID uint, req *CreateWordbookRequest) (*model.WordbookInfo, error) {
+	count, err := s.repo.CountOwnedWordbooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxCustomWordbooks {
+		return nil, ErrTooManyWordbooks
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = model.WordbookVisibilityPrivate
+	}
+	info := &model.WordbookInfo{
+		Type:        customWordbookPrefix + randomHex(8),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CoverURL:    req.CoverURL,
+		OwnerID:     &userID,
+		Visibility:  visibility,
+		ShareToken:  randomHex(16),
+		Category:    "custom",
+		IsActive:    true,
+	}
+	if err := s.repo.SaveWordbookInfo(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Get 获取单词书信息（需要有访问权限），分享令牌只返回给创建者
+func (s *CustomWordbookService) Get(userID uint, wordType string) (*model.WordbookInfo, error) {
+	info, err := s.getInfo(wordType)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.canAccessInfo(userID, info)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWordbookNotFound
+	}
+	return viewFor(userID, info), nil
+}
+
+// Update 更新单词书信息和可见范围
+func (s *CustomWordbookService) Update(userID uint, wordType string, req *UpdateWordbookRequest) (*model.WordbookInfo, error) {
+	info, err := s.getOwned(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		info.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		info.Description = *req.Description
+	}
+	if req.CoverURL != nil {
+		info.CoverURL = *req.CoverURL
+	}
+	if req.Visibility != nil {
+		info.Visibility = *req.Visibility
+	}
+	if req.ResetShareToken || info.ShareToken == "" {
+		info.ShareToken = randomHex(16)
+	}
+	if err := s.repo.SaveWordbookInfo(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Delete 删除单词书（所有学习者的进度一并删除）
+func (s *CustomWordbookService) Delete(userID uint, wordType string) error {
+	if _, err := s.getOwned(userID, wordType); err != nil {
+		return err
+	}
+	return s.repo.DeleteCustomWordbook(wordType)
+}
+
+// ListMine 获取我创建的和正在学习的自建单词书
+func (s *CustomWordbookService) ListMine(userID uint) (*MyWordbooks, error) {
+	owned, err := s.repo.ListOwnedWordbooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	joined, err := s.repo.ListJoinedWordbooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range joined {
+		joined[i].ShareToken = ""
+	}
+	return &MyWordbooks{Owned: owned, Joined: joined}, nil
+}
+
+// ListPublic 获取公开的自建单词书
+func (s *CustomWordbookService) ListPublic(keyword string, limit, offset int) ([]model.WordbookInfo, int64, error) {
+	list, total, err := s.repo.ListPublicWordbooks(keyword, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		list[i].ShareToken = ""
+	}
+	return list, total, nil
+}
+
+// GetShared 通过分享令牌获取单词书（私有的单词书链接无效）
+func (s *CustomWordbookService) GetShared(userID uint, token string) (*model.WordbookInfo, error) {
+	info, err := s.repo.GetWordbookInfoByShareToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWordbookShareExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Visibility == model.WordbookVisibilityPrivate && *info.OwnerID != userID {
+		return nil, ErrWordbookShareExpired
+	}
+	return viewFor(userID, info), nil
+}
+
+// Join 通过分享链接加入单词书：创建学习序列，之后可以用单词书的学习接口学习
+func (s *CustomWordbookService) Join(userID uint, token string) (*model.WordbookInfo, error) {
+	info, err := s.GetShared(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetOrCreateUserOrder(userID, info.Type); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoWordbookEntries
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// CanAccess 用户能否学习某本单词书：系统单词书、自己创建的、公开的，以及通过分享链接加入过的
+// 没有单词书信息的类型（只有单词关联）视为系统单词书
+func (s *CustomWordbookService) CanAccess(userID uint, wordType string) (bool, error) {
+	info, err := s.repo.GetWordbookInfo(wordType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return s.canAccessInfo(userID, info)
+}
+
+func (s *CustomWordbookService) canAccessInfo(userID uint, info *model.WordbookInfo) (bool, error) {
+	if !info.IsCustom() || *info.OwnerID == userID || info.Visibility == model.WordbookVisibilityPublic {
+		return true, nil
+	}
+	if info.Visibility == model.WordbookVisibilityLink && userID != 0 {
+		return s.repo.HasUserOrder(userID, info.Type)
+	}
+	return false, nil
+}
+
+// AddWords 从粘贴的单词列表或文章重点单词添加单词
+func (s *CustomWordbookService) AddWords(userID uint, wordType string, req *AddWordbookWordsRequest) (*AddWordbookWordsResult, error) {
+	var entries []wordbookEntry
+	switch req.Source {
+	case "text":
+		entries = parseWordList(req.Text)
+	case "articles":
+		words, err := s.articleRepo.GetKeyWordsByArticleIDs(req.ArticleIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range words {
+			entries = append(entries, wordbookEntry{
+				Word:               w.Text,
+				Phonetic:           w.Phonetic,
+				Meaning:            w.Meaning,
+				Example:            w.Example,
+				ExampleTranslation: w.ExampleTranslation,
+			})
+		}
+	}
+	return s.addEntries(userID, wordType, entries)
+}
+
+// AddWordsCSV 从 CSV 添加单词，表头识别方式与生词本导入相同（word/单词、meaning/释义、phonetic、example 等）
+func (s *CustomWordbookService) AddWordsCSV(userID uint, wordType string, data []byte, mapping map[string]string) (*AddWordbookWordsResult, error) {
+	records, err := parseCSVImport(data, mapping)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]wordbookEntry, 0, len(records))
+	for _, rec := range records {
+		entries = append(entries, wordbookEntry{
+			Word:               rec.item.Content,
+			Phonetic:           rec.item.Phonetic,
+			Meaning:            rec.item.Meaning,
+			Example:            rec.item.Example,
+			ExampleTranslation: rec.item.ExampleTranslation,
+		})
+	}
+	return s.addEntries(userID, wordType, entries)
+}
+
+// RemoveWord 从单词书中移除单词
+func (s *CustomWordbookService) RemoveWord(userID uint, wordType string, wordID uint) error {
+	if _, err := s.getOwned(userID, wordType); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveWordFromBook(wordType, wordID); err != nil {
+		return err
+	}
+	_, err := s.repo.UpdateWordCount(wordType)
+	return err
+}
+
+// addEntries 把单词解析为词条并加入单词书
+func (s *CustomWordbookService) addEntries(userID uint, wordType string, entries []wordbookEntry) (*AddWordbookWordsResult, error) {
+	if _, err := s.getOwned(userID, wordType); err != nil {
+		return nil, err
+	}
+
+	// 去重（不区分大小写），保留第一次出现的
+	seen := make(map[string]bool, len(entries))
+	unique := entries[:0]
+	for _, e := range entries {
+		e.Word = truncateRunes(strings.TrimSpace(e.Word), 100)
+		key := strings.ToLower(e.Word)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, e)
+	}
+	if len(unique) == 0 {
+		return nil, ErrNoWordbookEntries
+	}
+
+	current, err := s.repo.CountBookWords(wordType)
+	if err != nil {
+		return nil, err
+	}
+	if int(current)+len(unique) > maxCustomWordbookWords {
+		return nil, ErrWordbookFull
+	}
+
+	result := &AddWordbookWordsResult{Unresolved: []string{}}
+	ids := make([]uint, 0, len(unique))
+	var created []model.Wordbook
+	for _, e := range unique {
+		if w, err := s.enricher.Lookup(e.Word); err == nil {
+			ids = append(ids, w.ID)
+			result.Matched++
+			continue
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		if e.Meaning == "" {
+			result.Unresolved = append(result.Unresolved, e.Word)
+		}
+		created = append(created, model.Wordbook{
+			Word:               e.Word,
+			Phonetic:           truncateRunes(e.Phonetic, 100),
+			WordType:           wordType,
+			Difficulty:         3,
+			Source:             model.WordbookSourceCustom,
+			Meaning:            e.Meaning,
+			Example:            e.Example,
+			ExampleTranslation: e.ExampleTranslation,
+		})
+	}
+	if err := s.repo.CreateWords(created); err != nil {
+		return nil, err
+	}
+	for _, w := range created {
+		ids = append(ids, w.ID)
+	}
+	result.Created = len(created)
+
+	result.Added, err = s.repo.AddWordsToBook(wordType, ids)
+	if err != nil {
+		return nil, err
+	}
+	result.Duplicates = len(ids) - result.Added
+	result.WordCount, err = s.repo.UpdateWordCount(wordType)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *CustomWordbookService) getInfo(wordType string) (*model.WordbookInfo, error) {
+	info, err := s.repo.GetWordbookInfo(wordType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWordbookNotFound
+	}
+	return info, err
+}
+
+// getOwned 获取用户自己创建的单词书
+func (s *CustomWordbookService) getOwned(userID uint, wordType string) (*model.WordbookInfo, error) {
+	info, err := s.getInfo(wordType)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsCustom() || *info.OwnerID != userID {
+		return nil, ErrWordbookForbidden
+	}
+	return info, nil
+}
+
+// viewFor 非创建者看不到分享令牌
+func viewFor(userID uint, info *model.WordbookInfo) *model.WordbookInfo {
+	if info.IsCustom() && *info.OwnerID != userID {
+		info.ShareToken = ""
+	}
+	return info
+}
+
+// parseWordList 解析粘贴的单词列表
+// 每行一个单词或短语，Tab 后为释义；没有 Tab 的行按逗号、分号拆成多个单词；# 开头的行忽略
+func parseWordList(text string) []wordbookEntry {
+	var entries []wordbookEntry
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if word, meaning, ok := strings.Cut(line, "\t"); ok {
+			entries = append(entries, wordbookEntry{Word: word, Meaning: strings.TrimSpace(meaning)})
+			continue
+		}
+		for _, word := range strings.FieldsFunc(line, func(r rune) bool {
+			return strings.ContainsRune(",，;；、", r)
+		}) {
+			entries = append(entries, wordbookEntry{Word: word})
+		}
+	}
+	return entries
+}
+
+// randomHex 生成 n 字节的随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+)
+
+// EventKind 学习事件类型
+type EventKind string
+
+const (
+	EventArticleRead        EventKind = "article_read"        // 读完一篇文章（首次获得阅读积分）
+	EventDictationCompleted EventKind = "dictation_completed" // 提交一次默写（已答对过的题目再次答对不算）
+	EventReviewSubmitted    EventKind = "review_submitted"    // 生词本复习一张卡片
+	EventCheckedIn          EventKind = "checked_in"          // 每日签到
+	EventDurationSynced     EventKind = "duration_synced"     // 同步学习时长
+	EventPointsCreated      EventKind = "points_created"      // 首次创建用户积分记录（新用户）
+)
+
+// Event 学习事件
+type Event struct {
+	Kind    EventKind
+	UserID  uint
+	Correct bool      // DictationCompleted：是否答对
+	Seconds int       // DurationSynced：本次累加到今天的学习秒数（只同步累计时长时为 0）
+	Date    time.Time // CheckedIn：补签的日期（零值为今天）
+}
+
+// EventResult 订阅者处理事件的结果，随触发事件的接口一起返回
+type EventResult struct {
+	UnlockedTitles []model.TitleConfig // 本次新获得的称号
+}
+
+// EventHandler 事件订阅者
+type EventHandler func(e Event, result *EventResult) error
+
+// EventBus 进程内事件总线
+// 订阅者按注册顺序同步执行，结果随触发事件的请求返回；订阅者出错只记录日志，不影响已完成的业务操作。
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[EventKind][]EventHandler
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[EventKind][]EventHandler)}
+}
+
+// Subscribe 订阅某类事件
+func (b *EventBus) Subscribe(kind EventKind, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[kind] = append(b.handlers[kind], handler)
+}
+
+// Publish 发布事件，返回各订阅者的处理结果
+func (b *EventBus) Publish(e Event) *EventResult {
+	b.mu.RLock()
+	handlers := b.handlers[e.Kind]
+	b.mu.RUnlock()
+
+	result := &EventResult{UnlockedTitles: []model.TitleConfig{}}
+	for _, handler := range handlers {
+		if err := handler(e, result); err != nil {
+			log.Printf("⚠️ 处理事件失败 (kind=%s, user=%d): %v", e.Kind, e.UserID, err)
+		}
+	}
+	return result
+}
+
+var (
+	events     *EventBus
+	eventsOnce sync.Once
+)
+
+// Events 全局事件总线（首次使用时注册成就订阅者）
+func Events() *EventBus {
+	eventsOnce.Do(func() {
+		events = NewEventBus()
+		NewAchievementService(repository.DB, DefaultCalendar()).Subscribe(events)
+		NewPointService(repository.DB).Subscribe(events)
+	})
+	return events
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/storage"
+	"voicepaper/pkg/textdiff"
+
+	"gorm.io/gorm"
+)
+
+const (
+	exerciseTTL       = 30 * time.Minute // 练习有效期
+	choiceOptionCount = 4                // 选择题选项数
+	meaningMaxRunes   = 40               // 选项释义最长字符数
+)
+
+var (
+	ErrExerciseUnavailable = errors.New("该生词无法生成此类练习")
+	ErrExerciseExpired     = errors.New("练习已过期，请重新获取")
+	ErrExerciseAnswered    = errors.New("该练习已作答")
+)
+
+// ExerciseService 复习练习服务
+// 服务端为生词生成选择题、拼写、完形填空和听音选义练习，保存正确答案；
+// 客户端提交作答后由服务端判分，结合服务端计时推导 SM-2 评分，再走正常的复习流程。
+type ExerciseService struct {
+	db            *gorm.DB
+	exerciseRepo  *repository.ReviewExerciseRepository
+	wordbookRepo  *repository.WordbookRepository
+	vocabService  *VocabularyService
+	pronunciation *PronunciationService
+	calendar      *CalendarService
+}
+
+func NewExerciseService(db *gorm.DB, st storage.Storage, calendar *CalendarService) *ExerciseService {
+	return &ExerciseService{
+		db:            db,
+		exerciseRepo:  repository.NewReviewExerciseRepository(db),
+		wordbookRepo:  repository.NewWordbookRepository(db),
+		vocabService:  NewVocabularyService(calendar),
+		pronunciation: NewPronunciationService(db, st),
+		calendar:      calendar,
+	}
+}
+
+// Exercise 下发给客户端的练习（不含答案）
+type Exercise struct {
+	ID           uint             `json:"id"`
+	VocabularyID uint             `json:"vocabulary_id"`
+	Type         model.ReviewType `json:"type"`
+	Word         string           `json:"word,omitempty"`     // 选择题题干
+	Phonetic     string           `json:"phonetic,omitempty"` // 音标
+	Meaning      string           `json:"meaning,omitempty"`  // 拼写/完形填空的中文提示
+	Sentence     string           `json:"sentence,omitempty"` // 完形填空句子（挖空处为 ____）
+	Translation  string           `json:"translation,omitempty"`
+	Hint         string           `json:"hint,omitempty"`    // 首字母和长度提示，如 "w____ (5)"
+	Options      []string         `json:"options,omitempty"` // 选择题/听音选义的选项
+	AudioURL     string           `json:"audio_url,omitempty"`
+	AudioPending bool             `json:"audio_pending,omitempty"` // 发音正在生成，稍后重新获取
+	ExpiresAt    time.Time        `json:"expires_at"`
+}
+
+// ExerciseResult 判分结果
+type ExerciseResult struct {
+	ExerciseID     uint                `json:"exercise_id"`
+	IsCorrect      bool                `json:"is_correct"`
+	Quality        int                 `json:"quality"`        // 服务端推导的 SM-2 评分
+	CorrectAnswer  string              `json:"correct_answer"` // 正确答案（选择题为选项文本）
+	ResponseTimeMs int                 `json:"response_time_ms"`
+	Review         *SubmitReviewResult `json:"review"`
+}
+
+// Generate 为生词生成练习
+// exerciseType 为空时根据掌握等级自动选择：0-1 选择题，2 听音选义，3 完形填空，4-5 拼写
+func (s *ExerciseService) Generate(userID, vocabID uint, exerciseType string) (*Exercise, error) {
+	vocab, err := s.vocabService.GetVocabulary(vocabID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := []model.ReviewType{model.ReviewType(exerciseType)}
+	if exerciseType == "" {
+		types = autoExerciseTypes(vocab)
+	}
+
+	for _, t := range types {
+		exercise, answer, err := s.build(vocab, t)
+		if errors.Is(err, ErrExerciseUnavailable) && len(types) > 1 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		prompt, _ := json.Marshal(exercise)
+		record := &model.ReviewExercise{
+			UserID:       userID,
+			VocabularyID: vocab.ID,
+			Type:         t,
+			Prompt:       string(prompt),
+			Answer:       answer,
+			ExpiresAt:    s.calendar.Now().Add(exerciseTTL),
+		}
+		if err := s.exerciseRepo.Create(record); err != nil {
+			return nil, err
+		}
+		exercise.ID = record.ID
+		exercise.ExpiresAt = record.ExpiresAt
+		return exercise, nil
+	}
+	return nil, ErrExerciseUnavailable
+}
+
+// Grade 判分并提交复习结果
+// submit 为实际提交复习的函数（普通复习或复习会话），便于在会话中复用
+func (s *ExerciseService) Grade(userID, exerciseID uint, answer string, submit func(req *SubmitReviewRequest) (*SubmitReviewResult, error)) (*ExerciseResult, error) {
+	exercise, err := s.exerciseRepo.FindByID(exerciseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if exercise.AnsweredAt != nil {
+		return nil, ErrExerciseAnswered
+	}
+	now := s.calendar.Now()
+	if now.After(exercise.ExpiresAt) {
+		return nil, ErrExerciseExpired
+	}
+
+	var prompt Exercise
+	_ = json.Unmarshal([]byte(exercise.Prompt), &prompt)
+
+	// 拼写、完形填空填了单词原形时得 3 分：完形填空的原形是生词本身，拼写的原形是生词的 lemma
+	base := ""
+	if exercise.Type == model.ReviewTypeCloze || exercise.Type == model.ReviewTypeSpell {
+		vocab, err := s.vocabService.GetVocabulary(exercise.VocabularyID, userID)
+		if err != nil {
+			return nil, err
+		}
+		base = vocab.Lemma
+		if exercise.Type == model.ReviewTypeCloze {
+			base = vocab.Content
+		}
+	}
+
+	elapsed := now.Sub(exercise.CreatedAt)
+	correct, quality, correctAnswer := gradeExercise(exercise.Type, exercise.Answer, base, answer, prompt.Options, elapsed)
+	responseMs := int(elapsed / time.Millisecond)
+
+	// 先占用练习防止并发重复提交；提交复习失败时释放，练习可以重新作答
+	exercise.AnsweredAt = &now
+	exercise.UserAnswer = answer
+	exercise.IsCorrect = &correct
+	exercise.Quality = &quality
+	updated, err := s.exerciseRepo.MarkAnswered(exercise)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		// 并发提交
+		return nil, ErrExerciseAnswered
+	}
+
+	review, err := submit(&SubmitReviewRequest{
+		VocabularyID:   exercise.VocabularyID,
+		Quality:        quality,
+		ReviewType:     string(exercise.Type),
+		ResponseTimeMs: &responseMs,
+		UserAnswer:     answer,
+	})
+	if err != nil {
+		if releaseErr := s.exerciseRepo.ReleaseAnswer(exercise.ID); releaseErr != nil {
+			log.Printf("⚠️ 释放练习失败 (exercise=%d): %v", exercise.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	return &ExerciseResult{
+		ExerciseID:     exercise.ID,
+		IsCorrect:      correct,
+		Quality:        quality,
+		CorrectAnswer:  correctAnswer,
+		ResponseTimeMs: responseMs,
+		Review:         review,
+	}, nil
+}
+
+// SubmitReview 普通复习提交（不经过复习会话）
+func (s *ExerciseService) SubmitReview(userID uint) func(req *SubmitReviewRequest) (*SubmitReviewResult, error) {
+	return func(req *SubmitReviewRequest) (*SubmitReviewResult, error) {
+		return s.vocabService.SubmitReview(userID, req)
+	}
+}
+
+// build 生成题面和答案
+func (s *ExerciseService) build(vocab *model.Vocabulary, t model.ReviewType) (*Exercise, string, error) {
+	exercise := &Exercise{VocabularyID: vocab.ID, Type: t}
+	meaning := s.meaningOf(vocab)
+
+	switch t {
+	case model.ReviewTypeChoice, model.ReviewTypeListening:
+		if meaning == "" {
+			return nil, "", ErrExerciseUnavailable
+		}
+		options, answerIdx, err := s.choiceOptions(vocab.Content, meaning)
+		if err != nil {
+			return nil, "", err
+		}
+		exercise.Options = options
+		if t == model.ReviewTypeChoice {
+			exercise.Word = vocab.Content
+			exercise.Phonetic = vocab.Phonetic
+		} else {
+			exercise.AudioURL, exercise.AudioPending = s.wordAudio(vocab.Content)
+		}
+		return exercise, strconv.Itoa(answerIdx), nil
+
+	case model.ReviewTypeSpell:
+		if vocab.Type == model.VocabularyTypeSentence {
+			return nil, "", ErrExerciseUnavailable
+		}
+		exercise.Meaning = meaning
+		exercise.Phonetic = vocab.Phonetic
+		exercise.Hint = spellingHint(vocab.Content)
+		exercise.AudioURL, exercise.AudioPending = s.wordAudio(vocab.Content)
+		return exercise, vocab.Content, nil
+
+	case model.ReviewTypeCloze:
+		if vocab.Type == model.VocabularyTypeSentence {
+			return nil, "", ErrExerciseUnavailable
+		}
+		for _, candidate := range []struct{ text, translation string }{
+			{vocab.Context, ""},
+			{vocab.Example, vocab.ExampleTranslation},
+		} {
+			sentence, surface, ok := makeCloze(candidate.text, vocab.Content)
+			if !ok {
+				continue
+			}
+			exercise.Sentence = sentence
+			exercise.Translation = candidate.translation
+			exercise.Meaning = meaning
+			exercise.Hint = spellingHint(surface)
+			return exercise, surface, nil
+		}
+		return nil, "", ErrExerciseUnavailable
+
+	default:
+		return nil, "", fmt.Errorf("不支持的练习类型: %s", t)
+	}
+}
+
+// meaningOf 生词释义，生词本中没有时从单词书补充
+func (s *ExerciseService) meaningOf(vocab *model.Vocabulary) string {
+	if m := shortMeaning(vocab.Meaning); m != "" {
+		return m
+	}
+	if w, err := s.wordbookRepo.FindByWord(vocab.Content); err == nil {
+		return shortMeaning(w.Meaning)
+	}
+	return ""
+}
+
+// choiceOptions 生成选项：正确释义 + 难度相近的单词书释义作为干扰项，打乱顺序
+func (s *ExerciseService) choiceOptions(word, meaning string) ([]string, int, error) {
+	difficulty := uint8(3)
+	if w, err := s.wordbookRepo.FindByWord(word); err == nil && w.Difficulty > 0 {
+		difficulty = w.Difficulty
+	}
+	minD, maxD := difficulty, difficulty+1
+	if minD > 1 {
+		minD--
+	}
+
+	candidates, err := s.wordbookRepo.GetDistractorMeanings(word, minD, maxD, choiceOptionCount*4)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	options := []string{meaning}
+	seen := map[string]bool{meaning: true}
+	for _, c := range candidates {
+		c = shortMeaning(c)
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		options = append(options, c)
+		if len(options) == choiceOptionCount {
+			break
+		}
+	}
+	if len(options) < 2 {
+		return nil, 0, ErrExerciseUnavailable
+	}
+
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	for i, o := range options {
+		if o == meaning {
+			return options, i, nil
+		}
+	}
+	return options, 0, nil
+}
+
+// wordAudio 单词发音地址：已生成时直接返回，否则在后台调用 TTS 生成并返回 pending
+func (s *ExerciseService) wordAudio(text string) (string, bool) {
+	audio := s.pronunciation.Lookup(text, model.PronunciationWord)
+	return audio.URL, audio.Pending
+}
+
+// autoExerciseTypes 根据掌握等级选择练习类型（按优先级，无法生成时依次尝试下一个）
+func autoExerciseTypes(vocab *model.Vocabulary) []model.ReviewType {
+	if vocab.Type == model.VocabularyTypeSentence {
+		return []model.ReviewType{model.ReviewTypeChoice}
+	}
+	switch {
+	case vocab.MasteryLevel <= 1:
+		return []model.ReviewType{model.ReviewTypeChoice, model.ReviewTypeSpell}
+	case vocab.MasteryLevel == 2:
+		return []model.ReviewType{model.ReviewTypeListening, model.ReviewTypeChoice, model.ReviewTypeSpell}
+	case vocab.MasteryLevel == 3:
+		return []model.ReviewType{model.ReviewTypeCloze, model.ReviewTypeSpell, model.ReviewTypeChoice}
+	default:
+		return []model.ReviewType{model.ReviewTypeSpell, model.ReviewTypeCloze, model.ReviewTypeChoice}
+	}
+}
+
+// gradeExercise 判分并推导 SM-2 评分
+//   - 选择题/听音选义：答对且用时短 5，答对 4，答错 1，未作答 0
+//   - 拼写/完形填空：完全正确且用时短 5，完全正确 4，
+//     只差一个字母（5 个字母以上）或填了原形 base 3，相差两个字母 2，其余 1，未作答 0
+func gradeExercise(t model.ReviewType, expected, base, answer string, options []string, elapsed time.Duration) (bool, int, string) {
+	answer = strings.TrimSpace(answer)
+
+	if t == model.ReviewTypeChoice || t == model.ReviewTypeListening {
+		idx, _ := strconv.Atoi(expected)
+		correctText := expected
+		if idx >= 0 && idx < len(options) {
+			correctText = options[idx]
+		}
+		if answer == "" {
+			return false, 0, correctText
+		}
+		// 支持提交选项下标或选项文本
+		if answer == expected || answer == correctText {
+			if elapsed <= 5*time.Second {
+				return true, 5, correctText
+			}
+			return true, 4, correctText
+		}
+		return false, 1, correctText
+	}
+
+	if answer == "" {
+		return false, 0, expected
+	}
+	a, e := strings.ToLower(answer), strings.ToLower(expected)
+	fast := elapsed <= 5*time.Second+time.Duration(len([]rune(e)))*time.Second
+	switch dist := textdiff.EditDistance(a, e); {
+	case dist == 0:
+		if fast {
+			return true, 5, expected
+		}
+		return true, 4, expected
+	case dist == 1 && len([]rune(e)) >= 5, base != "" && a == strings.ToLower(strings.TrimSpace(base)):
+		return true, 3, expected
+	case dist <= 2:
+		return false, 2, expected
+	default:
+		return false, 1, expected
+	}
+}
+
+// makeCloze 在句子中挖掉生词（不区分大小写，允许词尾变化如 -s/-ed/-ing）
+// 返回挖空后的句子和被挖掉的原文
+func makeCloze(sentence, word string) (string, string, bool) {
+	sentence = strings.TrimSpace(sentence)
+	word = strings.TrimSpace(word)
+	if sentence == "" || word == "" {
+		return "", "", false
+	}
+	re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(word) + `[a-z]{0,3}\b`)
+	if err != nil {
+		return "", "", false
+	}
+	loc := re.FindStringIndex(sentence)
+	if loc == nil {
+		return "", "", false
+	}
+	surface := sentence[loc[0]:loc[1]]
+	return sentence[:loc[0]] + "____" + sentence[loc[1]:], surface, true
+}
+
+// spellingHint 首字母 + 长度提示，如 "w____ (5)"
+func spellingHint(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%c%s (%d)", runes[0], strings.Repeat("_", len(runes)-1), len(runes))
+}
+
+// shortMeaning 取释义第一行并截断，用于选项展示
+func shortMeaning(meaning string) string {
+	meaning = strings.TrimSpace(meaning)
+	if i := strings.IndexAny(meaning, "\r\n"); i >= 0 {
+		meaning = strings.TrimSpace(meaning[:i])
+	}
+	runes := []rune(meaning)
+	if len(runes) > meaningMaxRunes {
+		meaning = string(runes[:meaningMaxRunes]) + "…"
+	}
+	return meaning
+}
@@ -0,0 +1,767 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/clock"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxActiveGoals     = 5   // 进行中的学习目标上限
+	maxGoalHistoryDays = 90  // 每日完成情况最多查询的天数
+	goalReminderHour   = 20  // 用户当地时间几点之后发送学习目标提醒
+	goalSweepBatch     = 200 // 后台任务每批处理的目标数
+)
+
+// 学习进度
+const (
+	GoalPaceOnTrack = "on_track" // 按计划进行
+	GoalPaceAhead   = "ahead"    // 领先计划一天以上
+	GoalPaceBehind  = "behind"   // 落后计划一天以上，每日配额已自动提高
+	GoalPaceAtRisk  = "at_risk"  // 剩余每天要学的新词超过计划的两倍（或超过上限），建议延后截止日期
+	GoalPaceDone    = "done"     // 目标已结束
+)
+
+// 学习目标错误
+var (
+	ErrGoalNotFound        = errors.New("学习目标不存在")
+	ErrTooManyGoals        = fmt.Errorf("进行中的学习目标最多 %d 个", maxActiveGoals)
+	ErrEmptyGoal           = errors.New("请设置目标单词书、每日学习时长或每日新词数")
+	ErrInvalidGoalDeadline = errors.New("截止日期格式应为 YYYY-MM-DD，且不能早于今天")
+	ErrGoalBookTaken       = errors.New("这本单词书已有进行中的学习目标")
+	ErrGoalFinished        = errors.New("学习目标已结束，不能修改")
+	ErrInvalidGoalStatus   = errors.New("只能将学习目标设为放弃（abandoned）")
+	ErrGoalPlanDateTaken   = errors.New("单词书已设置了背完日期，确认后才会改为目标的截止日期")
+)
+
+// GoalService 学习目标服务
+// 目标单词书的每日新词配额 = (剩余单词 + 今天已学) / 剩余天数（含今天），每天按实际进度重新计算：
+// 落后时配额自动提高，领先时自动降低；设置了每日最少新词数时不低于该值。
+// 今日完成情况对照单词书卡片（新词）和 vp_user_daily_stats（学习时长、不限单词书的新词）统计。
+// 查询接口只计算进度，不修改目标；到期、学完的目标由后台任务（FinishDueGoals）结束，提醒由 NotifyGoalReminders 发送。
+type GoalService struct {
+	repo         *repository.UserGoalRepository
+	wordbookRepo *repository.WordbookRepository
+	cardRepo     *repository.WordbookCardRepository
+	statsRepo    *repository.UserDailyStatsRepository
+	notifyRepo   *repository.NotificationRepository
+	wordbooks    *CustomWordbookService
+	calendar     *CalendarService
+}
+
+func NewGoalService(db *gorm.DB, calendar *CalendarService) *GoalService {
+	return &GoalService{
+		repo:         repository.NewUserGoalRepository(db),
+		wordbookRepo: repository.NewWordbookRepository(db),
+		cardRepo:     repository.NewWordbookCardRepository(db),
+		statsRepo:    repository.NewUserDailyStatsRepository(db),
+		notifyRepo:   repository.NewNotificationRepository(db),
+		wordbooks:    NewCustomWordbookService(db),
+		calendar:     calendar,
+	}
+}
+
+// CreateGoalRequest 创建学习目标请求
+type CreateGoalRequest struct {
+	Title         string `json:"title" binding:"max=100"`
+	WordType      string `json:"word_type" binding:"max=20"`              // 目标单词书，可为空
+	Deadline      string `json:"deadline" binding:"required"`             // YYYY-MM-DD
+	DailyMinutes  int    `json:"daily_minutes" binding:"min=0,max=600"`   // 每日学习时长（分钟）
+	DailyNewWords int    `json:"daily_new_words" binding:"min=0,max=500"` // 每日最少新词数
+	RemindEnabled *bool  `json:"remind_enabled"`                          // 默认开启
+
+	ReplacePlanDate bool `json:"replace_plan_date"` // 单词书已有用户自己设置的背完日期时，确认改为截止日期
+}
+
+// UpdateGoalRequest 更新学习目标请求（只修改传入的字段）
+type UpdateGoalRequest struct {
+	Title         *string `json:"title" binding:"omitempty,max=100"`
+	Deadline      *string `json:"deadline"`
+	DailyMinutes  *int    `json:"daily_minutes" binding:"omitempty,min=0,max=600"`
+	DailyNewWords *int    `json:"daily_new_words" binding:"omitempty,min=0,max=500"`
+	RemindEnabled *bool   `json:"remind_enabled"`
+	Status        *string `json:"status"` // 只支持 abandoned
+
+	ReplacePlanDate bool `json:"replace_plan_date"` // 同 CreateGoalRequest
+}
+
+// GoalToday 今日配额和完成情况
+type GoalToday struct {
+	NewWordsQuota int  `json:"new_words_quota"` // 今日新词配额（含已学的），0 表示不要求
+	NewWords      int  `json:"new_words"`
+	MinutesQuota  int  `json:"minutes_quota"`
+	Minutes       int  `json:"minutes"`
+	Met           bool `json:"met"` // 今日配额是否都已完成
+}
+
+// GoalProgress 学习目标进度
+type GoalProgress struct {
+	Goal              model.UserGoal `json:"goal"`
+	BookName          string         `json:"book_name,omitempty"`
+	TotalWords        int            `json:"total_words,omitempty"`
+	Learned           int            `json:"learned,omitempty"`
+	Remaining         int            `json:"remaining,omitempty"`
+	Percent           float64        `json:"percent"`                      // 单词书完成进度或时间进度（0-100）
+	DaysTotal         int            `json:"days_total"`                   // 目标总天数
+	DaysLeft          int            `json:"days_left"`                    // 剩余天数（含今天）
+	ExpectedLearned   int            `json:"expected_learned,omitempty"`   // 按计划截至昨天应学的单词数
+	Behind            int            `json:"behind"`                       // 落后计划的单词数，领先时为负数
+	Pace              string         `json:"pace"`                         // on_track/ahead/behind/at_risk/done
+	SuggestedDeadline *time.Time     `json:"suggested_deadline,omitempty"` // at_risk 时按计划速度建议的截止日期
+	Today             GoalToday      `json:"today"`
+}
+
+// GoalDay 某天的完成情况
+type GoalDay struct {
+	Date     string `json:"date"`
+	NewWords int    `json:"new_words"`
+	Minutes  int    `json:"minutes"`
+	Met      bool   `json:"met"` // 按计划的每日新词数和每日时长判断
+}
+
+// GoalReminder 学习提醒（今日配额未完成的目标）
+type GoalReminder struct {
+	GoalID  uint   `json:"goal_id"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// Create 创建学习目标
+func (s *GoalService) Create(userID uint, req *CreateGoalRequest) (*GoalProgress, error) {
+	wordType := strings.TrimSpace(req.WordType)
+	if wordType == "" && req.DailyMinutes == 0 && req.DailyNewWords == 0 {
+		return nil, ErrEmptyGoal
+	}
+	today := s.calendar.Today(userID)
+	deadline, err := parseGoalDeadline(req.Deadline, today)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxActiveGoals {
+		return nil, ErrTooManyGoals
+	}
+
+	goal := &model.UserGoal{
+		UserID:        userID,
+		Title:         strings.TrimSpace(req.Title),
+		WordType:      wordType,
+		StartDate:     today,
+		Deadline:      deadline,
+		DailyMinutes:  req.DailyMinutes,
+		DailyNewWords: req.DailyNewWords,
+		PlannedDaily:  req.DailyNewWords,
+		Status:        model.GoalStatusActive,
+		RemindEnabled: req.RemindEnabled == nil || *req.RemindEnabled,
+	}
+
+	if wordType != "" {
+		info, err := s.checkBook(userID, wordType)
+		if err != nil {
+			return nil, err
+		}
+		total, learned, err := s.bookCounts(userID, wordType)
+		if err != nil {
+			return nil, err
+		}
+		goal.StartLearned = learned
+		days := daysBetween(today, deadline) + 1
+		if planned := (total - learned + days - 1) / days; planned > goal.PlannedDaily {
+			goal.PlannedDaily = planned
+		}
+		if goal.Title == "" && info != nil {
+			goal.Title = fmt.Sprintf("%s 前学完%s", deadline.Format("01月02日"), info.Name)
+		}
+		if !req.ReplacePlanDate {
+			if err := s.checkPlanDate(goal, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if goal.Title == "" {
+		goal.Title = fmt.Sprintf("坚持学习到 %s", deadline.Format("01月02日"))
+	}
+
+	if err := s.repo.Create(goal); err != nil {
+		return nil, err
+	}
+	s.syncWordbookPlan(goal)
+	return s.evaluate(goal)
+}
+
+// Get 获取学习目标及进度
+func (s *GoalService) Get(userID, id uint) (*GoalProgress, error) {
+	goal, err := s.get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(goal)
+}
+
+// List 获取学习目标列表，status 为空时返回全部
+// 已到期、还没被后台任务结束的目标按实际状态筛选，所以查询全部后再筛选
+func (s *GoalService) List(userID uint, status string) ([]GoalProgress, error) {
+	goals, err := s.repo.ListByUser(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]GoalProgress, 0, len(goals))
+	for i := range goals {
+		progress, err := s.evaluate(&goals[i])
+		if err != nil {
+			return nil, err
+		}
+		if status != "" && progress.Goal.Status != status {
+			continue
+		}
+		result = append(result, *progress)
+	}
+	return result, nil
+}
+
+// Today 进行中的学习目标及今日完成情况（签到、首页使用）
+func (s *GoalService) Today(userID uint) ([]GoalProgress, error) {
+	return s.List(userID, model.GoalStatusActive)
+}
+
+// Update 修改学习目标，或放弃目标
+func (s *GoalService) Update(userID, id uint, req *UpdateGoalRequest) (*GoalProgress, error) {
+	goal, err := s.get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status != model.GoalStatusActive {
+		return nil, ErrGoalFinished
+	}
+	current, err := s.evaluate(goal)
+	if err != nil {
+		return nil, err
+	}
+	if status := current.Goal.Status; status != model.GoalStatusActive {
+		if err := s.finish(goal, status, s.calendar.Now()); err != nil {
+			return nil, err
+		}
+		return nil, ErrGoalFinished
+	}
+
+	if req.Status != nil {
+		if *req.Status != model.GoalStatusAbandoned {
+			return nil, ErrInvalidGoalStatus
+		}
+		goal.Status = model.GoalStatusAbandoned
+		if err := s.repo.Save(goal); err != nil {
+			return nil, err
+		}
+		s.clearWordbookPlan(goal)
+		return s.evaluate(goal)
+	}
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) != "" {
+		goal.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.DailyMinutes != nil {
+		goal.DailyMinutes = *req.DailyMinutes
+	}
+	if req.DailyNewWords != nil {
+		goal.DailyNewWords = *req.DailyNewWords
+	}
+	if req.RemindEnabled != nil {
+		goal.RemindEnabled = *req.RemindEnabled
+	}
+	if goal.WordType == "" && goal.DailyMinutes == 0 && goal.DailyNewWords == 0 {
+		return nil, ErrEmptyGoal
+	}
+
+	// 修改截止日期或每日新词数后，从今天起重新计划
+	previous := goal.Deadline
+	if req.Deadline != nil || req.DailyNewWords != nil {
+		if req.Deadline != nil {
+			if goal.Deadline, err = parseGoalDeadline(*req.Deadline, s.calendar.Today(userID)); err != nil {
+				return nil, err
+			}
+			if !req.ReplacePlanDate {
+				if err := s.checkPlanDate(goal, &previous); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := s.replan(goal); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Save(goal); err != nil {
+		return nil, err
+	}
+	if req.Deadline != nil {
+		s.syncWordbookPlan(goal)
+	}
+	return s.evaluate(goal)
+}
+
+// Delete 删除学习目标
+func (s *GoalService) Delete(userID, id uint) error {
+	goal, err := s.get(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(goal.ID, userID); err != nil {
+		return err
+	}
+	if goal.Status == model.GoalStatusActive {
+		s.clearWordbookPlan(goal)
+	}
+	return nil
+}
+
+// History 学习目标最近几天的完成情况（从目标开始日期算起，今天在前）
+func (s *GoalService) History(userID, id uint, days int) ([]GoalDay, error) {
+	goal, err := s.get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxGoalHistoryDays {
+		days = maxGoalHistoryDays
+	}
+
+	end := s.calendar.Today(userID)
+	if deadline := s.calendar.Date(goal.Deadline); deadline.Before(end) {
+		end = deadline
+	}
+	start := end.AddDate(0, 0, -(days - 1))
+	if goalStart := s.calendar.Date(goal.StartDate); start.Before(goalStart) {
+		start = goalStart
+	}
+	if end.Before(start) {
+		return []GoalDay{}, nil
+	}
+
+	stats, err := s.statsRepo.GetStatsRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	minutes := make(map[string]int, len(stats))
+	statsNewWords := make(map[string]int, len(stats))
+	for _, st := range stats {
+		date := st.StatDate.Format("2006-01-02")
+		minutes[date] = st.TotalDurationSeconds / 60
+		statsNewWords[date] = st.NewWords
+	}
+	newWords := statsNewWords
+	if goal.WordType != "" {
+		newWords = make(map[string]int)
+		created, err := s.cardRepo.GetCreatedTimes(userID, goal.WordType,
+			s.calendar.DayStart(userID, start), s.calendar.DayStart(userID, end.AddDate(0, 0, 1)))
+		if err != nil {
+			return nil, err
+		}
+		loc := s.calendar.Location(userID)
+		for _, t := range created {
+			newWords[clock.Date(t, loc).Format("2006-01-02")]++
+		}
+	}
+
+	result := make([]GoalDay, 0, daysBetween(start, end)+1)
+	for day := end; !day.Before(start); day = day.AddDate(0, 0, -1) {
+		date := day.Format("2006-01-02")
+		result = append(result, GoalDay{
+			Date:     date,
+			NewWords: newWords[date],
+			Minutes:  minutes[date],
+			Met:      newWords[date] >= goal.PlannedDaily && minutes[date] >= goal.DailyMinutes,
+		})
+	}
+	return result, nil
+}
+
+// Reminders 今日配额还没完成、且开启了提醒的目标（客户端据此发送本地提醒）
+func (s *GoalService) Reminders(userID uint) ([]GoalReminder, error) {
+	goals, err := s.Today(userID)
+	if err != nil {
+		return nil, err
+	}
+	reminders := []GoalReminder{}
+	for i := range goals {
+		p := &goals[i]
+		if !p.Goal.RemindEnabled || p.Today.Met {
+			continue
+		}
+		reminders = append(reminders, GoalReminder{GoalID: p.Goal.ID, Title: p.Goal.Title, Message: reminderMessage(p)})
+	}
+	return reminders, nil
+}
+
+// FinishDueGoals 结束截止日期已过或单词书已学完的目标（后台任务定时执行）
+func (s *GoalService) FinishDueGoals(now time.Time) error {
+	return s.eachActiveGoal(func(goal *model.UserGoal) error {
+		p, err := s.evaluate(goal)
+		if err != nil || p.Goal.Status == model.GoalStatusActive {
+			return err
+		}
+		return s.finish(goal, p.Goal.Status, now)
+	})
+}
+
+// NotifyGoalReminders 用户当地时间 20 点后，今日配额还没完成、且开启了提醒的目标发送站内通知（每个目标每天一次）
+func (s *GoalService) NotifyGoalReminders(now time.Time) error {
+	return s.eachActiveGoal(func(goal *model.UserGoal) error {
+		if !goal.RemindEnabled {
+			return nil
+		}
+		local := now.In(s.calendar.Location(goal.UserID))
+		if local.Hour() < goalReminderHour {
+			return nil
+		}
+		key := fmt.Sprintf("goal:%d:%s", goal.ID, local.Format("2006-01-02"))
+		sent, err := s.notifyRepo.Exists(goal.UserID, key)
+		if err != nil || sent {
+			return err
+		}
+
+		p, err := s.evaluate(goal)
+		if err != nil || p.Goal.Status != model.GoalStatusActive || p.Today.Met {
+			return err
+		}
+		goalID := goal.ID
+		_, err = s.notifyRepo.CreateBatch([]model.Notification{{
+			UserID:    goal.UserID,
+			Type:      model.NotificationGoalReminder,
+			Title:     "今天的学习目标还没完成",
+			Content:   reminderMessage(p),
+			RefID:     &goalID,
+			DedupeKey: key,
+		}})
+		return err
+	})
+}
+
+// eachActiveGoal 分批遍历所有用户进行中的目标，单个目标处理失败只记录日志
+func (s *GoalService) eachActiveGoal(fn func(goal *model.UserGoal) error) error {
+	var lastID uint
+	for {
+		goals, err := s.repo.ListActive(lastID, goalSweepBatch)
+		if err != nil {
+			return err
+		}
+		for i := range goals {
+			if err := fn(&goals[i]); err != nil {
+				log.Printf("⚠️ 处理学习目标失败 (goal=%d): %v", goals[i].ID, err)
+			}
+		}
+		if len(goals) < goalSweepBatch {
+			return nil
+		}
+		lastID = goals[len(goals)-1].ID
+	}
+}
+
+// reminderMessage 今日配额还差多少
+func reminderMessage(p *GoalProgress) string {
+	var parts []string
+	if left := p.Today.NewWordsQuota - p.Today.NewWords; left > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个新词", left))
+	}
+	if left := p.Today.MinutesQuota - p.Today.Minutes; left > 0 {
+		parts = append(parts, fmt.Sprintf("%d 分钟", left))
+	}
+	message := fmt.Sprintf("「%s」今天还差%s", p.Goal.Title, strings.Join(parts, "、"))
+	if p.Pace == GoalPaceBehind || p.Pace == GoalPaceAtRisk {
+		message += fmt.Sprintf("，已落后计划 %d 个单词", p.Behind)
+	}
+	return message
+}
+
+func (s *GoalService) get(userID, id uint) (*model.UserGoal, error) {
+	goal, err := s.repo.Get(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGoalNotFound
+	}
+	return goal, err
+}
+
+// evaluate 计算目标进度和今日配额，不修改目标
+// 目标到期或单词书已学完时，返回的 Goal 中为结束后的状态（由 FinishDueGoals 保存）
+func (s *GoalService) evaluate(goal *model.UserGoal) (*GoalProgress, error) {
+	now := s.calendar.Now()
+	today := s.calendar.Today(goal.UserID)
+	deadline := s.calendar.Date(goal.Deadline)
+	start := s.calendar.Date(goal.StartDate)
+
+	p := &GoalProgress{
+		DaysTotal: daysBetween(start, deadline) + 1,
+		DaysLeft:  daysBetween(today, deadline) + 1,
+		Pace:      GoalPaceDone,
+	}
+	if p.DaysLeft < 0 {
+		p.DaysLeft = 0
+	}
+	if p.DaysTotal > 0 {
+		p.Percent = float64(p.DaysTotal-p.DaysLeft) / float64(p.DaysTotal) * 100
+	}
+
+	stats, err := s.statsRepo.GetStatsByDate(goal.UserID, today)
+	if err != nil {
+		return nil, err
+	}
+	p.Today.Minutes = stats.TotalDurationSeconds / 60
+	p.Today.NewWords = stats.NewWords
+
+	todayNew := 0
+	if goal.WordType != "" {
+		if info, err := s.wordbookRepo.GetWordbookInfo(goal.WordType); err == nil {
+			p.BookName = info.Name
+		}
+		total, learned, err := s.bookCounts(goal.UserID, goal.WordType)
+		if err != nil {
+			return nil, err
+		}
+		created, err := s.cardRepo.CountCreatedSince(goal.UserID, goal.WordType, s.calendar.DayStart(goal.UserID, today))
+		if err != nil {
+			return nil, err
+		}
+		todayNew = int(created)
+		p.TotalWords, p.Learned, p.Today.NewWords = total, learned, todayNew
+		if p.Remaining = total - learned; p.Remaining < 0 {
+			p.Remaining = 0
+		}
+		if total > 0 {
+			p.Percent = float64(learned) / float64(total) * 100
+		}
+	}
+
+	// 状态：单词书学完即完成；截止日期已过时，单词书目标为过期，只有时长/新词数的目标为完成
+	p.Goal = *goal
+	if goal.Status == model.GoalStatusActive {
+		switch {
+		case goal.WordType != "" && p.TotalWords > 0 && p.Remaining == 0:
+			p.Goal.Status = model.GoalStatusCompleted
+		case today.After(deadline) && goal.WordType != "":
+			p.Goal.Status = model.GoalStatusExpired
+		case today.After(deadline):
+			p.Goal.Status = model.GoalStatusCompleted
+		}
+		if p.Goal.Status == model.GoalStatusCompleted {
+			p.Goal.CompletedAt = &now
+		}
+	}
+	if p.Goal.Status != model.GoalStatusActive {
+		return p, nil
+	}
+
+	// 今日配额
+	p.Today.MinutesQuota = goal.DailyMinutes
+	if goal.WordType != "" {
+		left := p.Remaining + todayNew
+		quota := (left + p.DaysLeft - 1) / p.DaysLeft
+		if goal.DailyNewWords > quota {
+			quota = goal.DailyNewWords
+		}
+		if quota > left {
+			quota = left
+		}
+		p.Today.NewWordsQuota = quota
+
+		// 对照计划进度：截至昨天应学 StartLearned + 每日计划 × 已过天数
+		planned := goal.PlannedDaily
+		if planned < 1 {
+			planned = 1
+		}
+		p.ExpectedLearned = goal.StartLearned + planned*daysBetween(start, today)
+		if p.ExpectedLearned > p.TotalWords {
+			p.ExpectedLearned = p.TotalWords
+		}
+		p.Behind = p.ExpectedLearned - (p.Learned - todayNew)
+
+		switch {
+		case quota > maxWordbookNewPerDay || quota > planned*2:
+			p.Pace = GoalPaceAtRisk
+			suggested := today.AddDate(0, 0, (left+planned-1)/planned-1)
+			p.SuggestedDeadline = &suggested
+		case p.Behind >= planned:
+			p.Pace = GoalPaceBehind
+		case -p.Behind >= planned:
+			p.Pace = GoalPaceAhead
+		default:
+			p.Pace = GoalPaceOnTrack
+		}
+		if p.Today.NewWordsQuota > maxWordbookNewPerDay {
+			p.Today.NewWordsQuota = maxWordbookNewPerDay
+		}
+	} else {
+		p.Today.NewWordsQuota = goal.DailyNewWords
+		p.Pace = GoalPaceOnTrack
+	}
+	p.Today.Met = p.Today.NewWords >= p.Today.NewWordsQuota && p.Today.Minutes >= p.Today.MinutesQuota
+	return p, nil
+}
+
+// finish 结束目标
+func (s *GoalService) finish(goal *model.UserGoal, status string, now time.Time) error {
+	goal.Status = status
+	if status == model.GoalStatusCompleted {
+		goal.CompletedAt = &now
+	}
+	if err := s.repo.Save(goal); err != nil {
+		return err
+	}
+	if status == model.GoalStatusExpired {
+		s.clearWordbookPlan(goal)
+	}
+	log.Printf("✅ 学习目标已结束 (goal=%d, user=%d): %s", goal.ID, goal.UserID, status)
+	return nil
+}
+
+// replan 从今天起重新计算计划的每日新词数
+func (s *GoalService) replan(goal *model.UserGoal) error {
+	today := s.calendar.Today(goal.UserID)
+	goal.StartDate = today
+	goal.PlannedDaily = goal.DailyNewWords
+	if goal.WordType == "" {
+		return nil
+	}
+	total, learned, err := s.bookCounts(goal.UserID, goal.WordType)
+	if err != nil {
+		return err
+	}
+	todayNew, err := s.cardRepo.CountCreatedSince(goal.UserID, goal.WordType, s.calendar.DayStart(goal.UserID, today))
+	if err != nil {
+		return err
+	}
+	goal.StartLearned = learned - int(todayNew)
+	days := daysBetween(today, s.calendar.Date(goal.Deadline)) + 1
+	if planned := (total - goal.StartLearned + days - 1) / days; planned > goal.PlannedDaily {
+		goal.PlannedDaily = planned
+	}
+	return nil
+}
+
+// checkBook 检查目标单词书：有权限、有单词，且没有其他进行中的目标
+func (s *GoalService) checkBook(userID uint, wordType string) (*model.WordbookInfo, error) {
+	ok, err := s.wordbooks.CanAccess(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWordbookNotFound
+	}
+	count, err := s.wordbookRepo.CountBookWords(wordType)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrWordbookNotFound
+	}
+	taken, err := s.repo.ExistsActiveForBook(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrGoalBookTaken
+	}
+	info, err := s.wordbookRepo.GetWordbookInfo(wordType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return info, err
+}
+
+// bookCounts 单词书的单词数和用户已学的单词数
+// 前端保存学习位置时会为经过的单词创建卡片（旧版序列在读取时迁移，见 WordbookRepository.GetUserOrder），
+// 所以按卡片统计也包括只用逐词浏览的用户；卡片数仍少于学习位置时以学习位置为准
+func (s *GoalService) bookCounts(userID uint, wordType string) (int, int, error) {
+	total, err := s.wordbookRepo.CountBookWords(wordType)
+	if err != nil {
+		return 0, 0, err
+	}
+	order, err := s.wordbookRepo.GetUserOrder(userID, wordType)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, err
+	}
+	cards, err := s.wordbookRepo.CountLearnedWords(userID, wordType)
+	if err != nil {
+		return 0, 0, err
+	}
+	learned := int(cards)
+	if order != nil && order.CurrentIndex > learned {
+		learned = order.CurrentIndex
+	}
+	if learned > int(total) {
+		learned = int(total)
+	}
+	return int(total), learned, nil
+}
+
+// checkPlanDate 单词书学习计划已有背完日期、且不是该目标设置的（previous 为修改前的截止日期）时，
+// 需要用户确认（ReplacePlanDate）后才改为目标的截止日期
+func (s *GoalService) checkPlanDate(goal *model.UserGoal, previous *time.Time) error {
+	order, err := s.wordbookRepo.GetUserOrder(goal.UserID, goal.WordType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if order.TargetDate == nil {
+		return nil
+	}
+	target := s.calendar.Date(*order.TargetDate)
+	if target.Equal(s.calendar.Date(goal.Deadline)) || (previous != nil && target.Equal(s.calendar.Date(*previous))) {
+		return nil
+	}
+	return fmt.Errorf("%w（当前为 %s）", ErrGoalPlanDateTaken, target.Format("2006-01-02"))
+}
+
+// syncWordbookPlan 将目标的截止日期设为单词书学习计划的背完日期，每日学习计划按同样的剩余天数安排新词
+func (s *GoalService) syncWordbookPlan(goal *model.UserGoal) {
+	if goal.WordType == "" {
+		return
+	}
+	order, err := s.wordbookRepo.GetOrCreateUserOrder(goal.UserID, goal.WordType)
+	if err == nil {
+		deadline := goal.Deadline
+		err = s.wordbookRepo.UpdateUserOrderPlan(goal.UserID, goal.WordType, &deadline, order.NewPerDay)
+	}
+	if err != nil {
+		log.Printf("⚠️ 同步单词书学习计划失败 (goal=%d): %v", goal.ID, err)
+	}
+}
+
+// clearWordbookPlan 目标结束时取消由目标设置的背完日期（用户之后自行修改过的不动）
+func (s *GoalService) clearWordbookPlan(goal *model.UserGoal) {
+	if goal.WordType == "" {
+		return
+	}
+	order, err := s.wordbookRepo.GetUserOrder(goal.UserID, goal.WordType)
+	if err != nil || order.TargetDate == nil || !s.calendar.Date(*order.TargetDate).Equal(s.calendar.Date(goal.Deadline)) {
+		return
+	}
+	if err := s.wordbookRepo.UpdateUserOrderPlan(goal.UserID, goal.WordType, nil, order.NewPerDay); err != nil {
+		log.Printf("⚠️ 取消单词书背完日期失败 (goal=%d): %v", goal.ID, err)
+	}
+}
+
+// parseGoalDeadline 解析截止日期，不能早于用户的今天
+func parseGoalDeadline(value string, today time.Time) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil || date.Before(today) {
+		return time.Time{}, ErrInvalidGoalDeadline
+	}
+	return date, nil
+}
+
+// daysBetween 两个日期相差的天数（按各自时区中的日历日，与时区和夏令时无关）
+func daysBetween(from, to time.Time) int {
+	from, to = clock.Date(from, from.Location()), clock.Date(to, to.Location())
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
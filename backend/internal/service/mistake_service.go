@@ -0,0 +1,366 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/dictation"
+
+	"gorm.io/gorm"
+)
+
+// MistakeService 错题本服务
+// 默写答错和生词复习失败时记录错题，按题目聚合出错次数和错误类型，
+// 之后答对时标记为已解决，用户可以把错题推送到生词复习队列。
+type MistakeService struct {
+	db        *gorm.DB
+	repo      *repository.MistakeRepository
+	vocabRepo *repository.VocabularyRepository
+}
+
+// NewMistakeService 创建错题本服务实例
+func NewMistakeService(db *gorm.DB) *MistakeService {
+	return &MistakeService{
+		db:        db,
+		repo:      repository.NewMistakeRepository(db),
+		vocabRepo: repository.NewVocabularyRepository(),
+	}
+}
+
+// MistakeView 错题（附带解析后的错误类型统计和最近一次的逐词差异）
+type MistakeView struct {
+	model.Mistake
+	Categories map[string]int    `json:"categories"`
+	Diff       []dictation.Token `json:"diff,omitempty"`
+}
+
+// PushMistakesResult 推送错题到复习队列的结果
+type PushMistakesResult struct {
+	Pushed        int    `json:"pushed"`         // 推送成功的错题数
+	Created       int    `json:"created"`        // 新加入生词本的数量
+	VocabularyIDs []uint `json:"vocabulary_ids"` // 对应的生词ID
+	Skipped       []uint `json:"skipped"`        // 无法推送的错题ID（题目已删除或内容过长）
+}
+
+// RecordDictation 记录一次答错的默写
+func (s *MistakeService) RecordDictation(userID uint, itemType model.MistakeItemType, itemID, articleID uint, expected, answer string, grading *dictation.Result) error {
+	categories := make(map[string]int)
+	for c, n := range dictation.Classify(grading) {
+		categories[string(c)] = n
+	}
+	diff, _ := json.Marshal(grading.Tokens)
+	return s.record(userID, itemType, itemID, &articleID, expected, answer, categories, string(diff), time.Now())
+}
+
+// RecordReview 记录一次失败的生词复习
+// 拼写、默写、完形填空有用户答案时按单词差异分类，否则记为忘记
+func (s *MistakeService) RecordReview(userID uint, vocab *model.Vocabulary, reviewType model.ReviewType, answer string) error {
+	categories, diff := classifyReview(vocab.Content, reviewType, answer)
+	return s.record(userID, model.MistakeItemVocabulary, vocab.ID, vocab.ArticleID, vocab.Content, answer, categories, diff, time.Now())
+}
+
+// Resolve 答对后把错题标记为已解决（没有错题记录时不做任何事）
+func (s *MistakeService) Resolve(userID uint, itemType model.MistakeItemType, itemID uint) error {
+	return s.repo.MarkResolved(userID, itemType, itemID)
+}
+
+// record 新建或累加一条错题
+func (s *MistakeService) record(userID uint, itemType model.MistakeItemType, itemID uint, articleID *uint, content, answer string, categories map[string]int, diff string, at time.Time) error {
+	mistake, err := s.repo.GetByItem(userID, itemType, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		mistake = &model.Mistake{
+			UserID:   userID,
+			ItemType: itemType,
+			ItemID:   itemID,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	total := decodeCategories(mistake.Categories)
+	for c, n := range categories {
+		total[c] += n
+	}
+	encoded, _ := json.Marshal(total)
+
+	mistake.ArticleID = articleID
+	mistake.Content = truncateRunes(content, 500)
+	mistake.ErrorCount++
+	mistake.Categories = string(encoded)
+	mistake.PrimaryCategory = primaryCategory(total)
+	mistake.LastAnswer = answer
+	mistake.LastDiff = diff
+	mistake.LastErrorAt = at
+	mistake.ResolvedAt = nil
+
+	return s.repo.Save(mistake)
+}
+
+// List 分页查询错题
+func (s *MistakeService) List(userID uint, params *repository.MistakeListParams) ([]MistakeView, int64, error) {
+	mistakes, total, err := s.repo.List(userID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	views := make([]MistakeView, 0, len(mistakes))
+	for _, m := range mistakes {
+		view := MistakeView{Mistake: m, Categories: decodeCategories(m.Categories)}
+		if m.LastDiff != "" {
+			_ = json.Unmarshal([]byte(m.LastDiff), &view.Diff)
+		}
+		views = append(views, view)
+	}
+	return views, total, nil
+}
+
+// Push 把选中的错题推送到生词复习队列
+// 生词复习产生的错题直接设为立即到期；默写产生的错题先加入生词本（已存在则设为立即到期）
+func (s *MistakeService) Push(userID uint, ids []uint) (*PushMistakesResult, error) {
+	mistakes, err := s.repo.GetByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PushMistakesResult{VocabularyIDs: []uint{}, Skipped: []uint{}}
+	found := make(map[uint]bool, len(mistakes))
+	now := time.Now()
+
+	for i := range mistakes {
+		m := &mistakes[i]
+		found[m.ID] = true
+
+		vocabID, created, err := s.pushOne(userID, m, now)
+		if err != nil {
+			return nil, err
+		}
+		if vocabID == 0 {
+			result.Skipped = append(result.Skipped, m.ID)
+			continue
+		}
+
+		m.VocabularyID = &vocabID
+		m.PushedAt = &now
+		if err := s.repo.Save(m); err != nil {
+			return nil, err
+		}
+
+		result.Pushed++
+		if created {
+			result.Created++
+		}
+		result.VocabularyIDs = append(result.VocabularyIDs, vocabID)
+	}
+
+	for _, id := range ids {
+		if !found[id] {
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+	return result, nil
+}
+
+// pushOne 推送单条错题，返回对应的生词ID（0 表示无法推送）和是否新建了生词
+func (s *MistakeService) pushOne(userID uint, m *model.Mistake, now time.Time) (uint, bool, error) {
+	if m.ItemType == model.MistakeItemVocabulary {
+		res := s.db.Model(&model.Vocabulary{}).
+			Where("id = ? AND user_id = ?", m.ItemID, userID).
+			Update("next_review_at", now)
+		if res.Error != nil {
+			return 0, false, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return 0, false, nil
+		}
+		return m.ItemID, false, nil
+	}
+
+	vocab := &model.Vocabulary{
+		UserID:       userID,
+		ArticleID:    m.ArticleID,
+		Source:       "mistake",
+		EaseFactor:   2.5,
+		NextReviewAt: &now,
+	}
+	switch m.ItemType {
+	case model.MistakeItemWord:
+		var word model.Word
+		if err := s.db.First(&word, m.ItemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		vocab.Type = model.VocabularyTypeWord
+		vocab.Content = word.Text
+		vocab.Phonetic = word.Phonetic
+		vocab.Meaning = word.Meaning
+		vocab.Example = word.Example
+		vocab.ExampleTranslation = word.ExampleTranslation
+	case model.MistakeItemSentence:
+		var sentence model.Sentence
+		if err := s.db.First(&sentence, m.ItemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		if len([]rune(sentence.Text)) > 500 {
+			return 0, false, nil
+		}
+		vocab.Type = model.VocabularyTypeSentence
+		vocab.Content = sentence.Text
+		vocab.Meaning = sentence.Translation
+		vocab.SentenceID = &sentence.ID
+	default:
+		return 0, false, nil
+	}
+
+	// 已经在生词本中的设为立即到期
+	vocab.Lemma = vocabularyLemma(vocab.Type, vocab.Content)
+	existing, err := s.vocabRepo.GetByUserIDAndContent(userID, vocab.Content, vocab.Lemma)
+	if err == nil {
+		if err := s.db.Model(existing).Update("next_review_at", now).Error; err != nil {
+			return 0, false, err
+		}
+		return existing.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+
+	if err := s.vocabRepo.Create(vocab); err != nil {
+		return 0, false, err
+	}
+	return vocab.ID, true, nil
+}
+
+// Backfill 从历史默写记录和复习记录回填错题本，已有错题记录的题目跳过
+// 返回新增的错题数
+func (s *MistakeService) Backfill(userID uint) (int, error) {
+	added := 0
+
+	// 默写记录只保留最近一次答案，出错次数按尝试次数估算
+	dictations, err := s.repo.GetWrongDictations(userID)
+	if err != nil {
+		return 0, err
+	}
+	existingWords, err := s.repo.GetItemIDs(userID, model.MistakeItemWord)
+	if err != nil {
+		return 0, err
+	}
+	existingSentences, err := s.repo.GetItemIDs(userID, model.MistakeItemSentence)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range dictations {
+		itemType, itemID, expected, existing := model.MistakeItemSentence, d.SentenceID, d.SentenceText, existingSentences
+		if d.DictationType == model.DictationTypeWord {
+			itemType, itemID, expected, existing = model.MistakeItemWord, d.WordID, d.WordText, existingWords
+		}
+		if itemID == nil || expected == "" || existing[*itemID] {
+			continue
+		}
+
+		grading := dictation.Grade(expected, d.UserAnswer)
+		categories := make(map[string]int)
+		for c, n := range dictation.Classify(grading) {
+			categories[string(c)] = n
+		}
+		diff, _ := json.Marshal(grading.Tokens)
+		if err := s.record(userID, itemType, *itemID, &d.ArticleID, expected, d.UserAnswer, categories, string(diff), d.LastAttempt); err != nil {
+			return added, err
+		}
+		if d.AttemptCount > 1 {
+			s.db.Model(&model.Mistake{}).
+				Where("user_id = ? AND item_type = ? AND item_id = ?", userID, itemType, *itemID).
+				Update("error_count", d.AttemptCount)
+		}
+		existing[*itemID] = true
+		added++
+	}
+
+	// 复习记录按生词汇总失败次数
+	reviews, err := s.repo.GetFailedReviewStats(userID)
+	if err != nil {
+		return added, err
+	}
+	existingVocabs, err := s.repo.GetItemIDs(userID, model.MistakeItemVocabulary)
+	if err != nil {
+		return added, err
+	}
+	for _, r := range reviews {
+		if existingVocabs[r.VocabularyID] {
+			continue
+		}
+		categories, diff := classifyReview(r.Content, model.ReviewType(r.LastType), r.LastAnswer)
+		if err := s.record(userID, model.MistakeItemVocabulary, r.VocabularyID, r.ArticleID, r.Content, r.LastAnswer, categories, diff, r.LastAt); err != nil {
+			return added, err
+		}
+		if r.Failures > 1 {
+			s.db.Model(&model.Mistake{}).
+				Where("user_id = ? AND item_type = ? AND item_id = ?", userID, model.MistakeItemVocabulary, r.VocabularyID).
+				Update("error_count", r.Failures)
+		}
+		added++
+	}
+
+	return added, nil
+}
+
+// classifyReview 对复习失败分类，返回错误类型统计和逐词差异（JSON）
+func classifyReview(content string, reviewType model.ReviewType, answer string) (map[string]int, string) {
+	switch reviewType {
+	case model.ReviewTypeSpell, model.ReviewTypeDictation, model.ReviewTypeCloze:
+		if answer == "" {
+			break
+		}
+		grading := dictation.Grade(content, answer)
+		categories := make(map[string]int)
+		for c, n := range dictation.Classify(grading) {
+			categories[string(c)] = n
+		}
+		if len(categories) == 0 {
+			break
+		}
+		diff, _ := json.Marshal(grading.Tokens)
+		return categories, string(diff)
+	}
+	return map[string]int{model.MistakeCategoryForgotten: 1}, ""
+}
+
+func decodeCategories(raw string) map[string]int {
+	categories := make(map[string]int)
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &categories)
+	}
+	return categories
+}
+
+// primaryCategory 出现次数最多的错误类型（次数相同时按名称排序取第一个）
+func primaryCategory(categories map[string]int) string {
+	names := make([]string, 0, len(categories))
+	for c := range categories {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	best := ""
+	for _, c := range names {
+		if best == "" || categories[c] > categories[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// NotificationSweepInterval 定时生成通知的间隔
+const NotificationSweepInterval = 10 * time.Minute
+
+var ErrNotificationNotFound = errors.New("通知不存在")
+
+// NotificationService 站内通知服务
+// 通知由后台任务定时生成（新文章、学习目标提醒等），每条通知带去重标识，重复生成不会重复通知。
+type NotificationService struct {
+	db      *gorm.DB
+	repo    *repository.NotificationRepository
+	tagRepo *repository.TagRepository
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		db:      db,
+		repo:    repository.NewNotificationRepository(db),
+		tagRepo: repository.NewTagRepository(db),
+	}
+}
+
+// Notify 给多个用户发送同一条通知（template 的 UserID 会被替换），返回实际新增的数量
+func (s *NotificationService) Notify(userIDs []uint, template model.Notification) (int64, error) {
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		n := template
+		n.UserID = userID
+		notifications = append(notifications, n)
+	}
+	return s.repo.CreateBatch(notifications)
+}
+
+// List 分页获取用户的通知
+func (s *NotificationService) List(userID uint, unreadOnly bool, page, pageSize int) ([]model.Notification, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.repo.List(userID, unreadOnly, page, pageSize)
+}
+
+// UnreadCount 未读通知数
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+// MarkRead 标记通知为已读
+func (s *NotificationService) MarkRead(userID, id uint) error {
+	found, err := s.repo.MarkRead(userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead 标记全部通知为已读
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID)
+}
+
+// NotifyPublishedArticles 今天发布的文章通知订阅了其标签、并开启通知的用户
+// 文章按发布日期上线（全站统一，使用服务器日期），每篇文章每个用户只通知一次
+func (s *NotificationService) NotifyPublishedArticles(now time.Time) error {
+	articles, err := s.tagRepo.GetTaggedArticlesPublishedOn(now.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	for _, article := range articles {
+		userIDs, err := s.tagRepo.GetNotifySubscriberIDs(article.ID)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			continue
+		}
+		articleID := article.ID
+		created, err := s.Notify(userIDs, model.Notification{
+			Type:      model.NotificationArticlePublished,
+			Title:     "你订阅的话题有新文章",
+			Content:   fmt.Sprintf("《%s》", article.Title),
+			RefID:     &articleID,
+			DedupeKey: fmt.Sprintf("article:%d", article.ID),
+		})
+		if err != nil {
+			return err
+		}
+		if created > 0 {
+			log.Printf("✅ 新文章《%s》通知了 %d 位订阅用户", article.Title, created)
+		}
+	}
+	return nil
+}
+
+// notificationJob 定时生成通知的任务
+type notificationJob struct {
+	name string
+	run  func(now time.Time) error
+}
+
+// StartNotificationWorker 启动后台任务，每隔 interval 生成一次通知、结束到期的学习目标、自动使用冻结卡（启动时立即执行一次）
+func StartNotificationWorker(db *gorm.DB, calendar *CalendarService, interval time.Duration) {
+	notifications := NewNotificationService(db)
+	goals := NewGoalService(db, calendar)
+	streaks := NewStreakService(db, calendar)
+	jobs := []notificationJob{
+		{name: "新文章通知", run: notifications.NotifyPublishedArticles},
+		{name: "结束到期的学习目标", run: goals.FinishDueGoals},
+		{name: "学习目标提醒", run: goals.NotifyGoalReminders},
+		{name: "自动使用冻结卡", run: streaks.FreezeBrokenStreaks},
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			now := calendar.Now()
+			for _, job := range jobs {
+				if err := job.run(now); err != nil {
+					log.Printf("⚠️ 通知任务失败 (%s): %v", job.name, err)
+				}
+			}
+			<-ticker.C
+		}
+	}()
+	log.Printf("✅ 通知任务已启动（每 %s 执行一次）", interval)
+}
@@ -562,7 +562,7 @@ func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*
 		fmt.Printf("❌ 处理Google用户失败: %v\n", err)
 		return nil, fmt.Errorf("处理Google用户失败: %w", err)
 	}
-	fmt.Printf("✅ 用户处理成功: user_id=%d, email=%s\n", user.ID, user.Email)
+	fmt.Printf("✅ 用户处理成功: user_id=%d, email=%s\n", user.ID, stringValue(user.Email))
 
 	return user, nil
 }
@@ -44,8 +44,8 @@ func (s *PointService) GetUserPoints(userID uint) (*model.UserPoints, error) {
 				return nil, err
 			}
 
-			// 自动颁发"初学者"称号
-			go s.AwardBeginnerTitle(userID)
+			// 发布事件，由订阅者颁发"初学者"称号
+			Events().Publish(Event{Kind: EventPointsCreated, UserID: userID})
 
 			return userPoints, nil
 		}
@@ -68,38 +68,48 @@ func (s *PointService) AwardPoints(
 
 	// 使用事务确保数据一致性
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. 获取并锁定用户积分记录
-		up, err := s.userPointsRepo.AddPoints(tx, userID, points)
-		if err != nil {
-			return fmt.Errorf("添加积分失败: %w", err)
-		}
-		userPoints = up
-
-		// 2. 创建积分记录
-		pointRecord = &model.PointRecord{
-			UserID:            userID,
-			Points:            points,
-			Type:              pointType,
-			Description:       description,
-			ArticleID:         articleID,
-			DictationRecordID: dictationRecordID,
-			BalanceBefore:     userPoints.CurrentPoints - points,
-			BalanceAfter:      userPoints.CurrentPoints,
-		}
-		err = s.pointRecordRepo.Create(tx, pointRecord)
-		if err != nil {
-			return fmt.Errorf("创建积分记录失败: %w", err)
-		}
-
-		return nil
+		var err error
+		userPoints, pointRecord, err = s.AwardPointsTx(tx, userID, points, pointType, description, articleID, dictationRecordID)
+		return err
 	})
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// 异步检查是否达成新称号
-	go s.CheckAndAwardTitles(userID)
+	return userPoints, pointRecord, nil
+}
+
+// AwardPointsTx 在调用方的事务中奖励积分（与其他写操作一起提交或回滚）
+func (s *PointService) AwardPointsTx(
+	tx *gorm.DB,
+	userID uint,
+	points int,
+	pointType model.PointType,
+	description string,
+	articleID *uint,
+	dictationRecordID *uint,
+) (*model.UserPoints, *model.PointRecord, error) {
+	// 1. 获取并锁定用户积分记录
+	userPoints, err := s.userPointsRepo.AddPoints(tx, userID, points)
+	if err != nil {
+		return nil, nil, fmt.Errorf("添加积分失败: %w", err)
+	}
+
+	// 2. 创建积分记录
+	pointRecord := &model.PointRecord{
+		UserID:            userID,
+		Points:            points,
+		Type:              pointType,
+		Description:       description,
+		ArticleID:         articleID,
+		DictationRecordID: dictationRecordID,
+		BalanceBefore:     userPoints.CurrentPoints - points,
+		BalanceAfter:      userPoints.CurrentPoints,
+	}
+	if err := s.pointRecordRepo.Create(tx, pointRecord); err != nil {
+		return nil, nil, fmt.Errorf("创建积分记录失败: %w", err)
+	}
 
 	return userPoints, pointRecord, nil
 }
@@ -216,6 +226,13 @@ func (s *PointService) GetPointStatistics(userID uint) (map[string]interface{},
 	}, nil
 }
 
+// Subscribe 订阅积分事件：新用户创建积分记录时颁发"初学者"称号
+func (s *PointService) Subscribe(bus *EventBus) {
+	bus.Subscribe(EventPointsCreated, func(e Event, result *EventResult) error {
+		return s.AwardBeginnerTitle(e.UserID)
+	})
+}
+
 // AwardBeginnerTitle 自动颁发"初学者"称号
 func (s *PointService) AwardBeginnerTitle(userID uint) error {
 	titleConfig, err := s.titleRepo.GetTitleConfigByKey("beginner")
@@ -241,75 +258,6 @@ func (s *PointService) AwardBeginnerTitle(userID uint) error {
 	})
 }
 
-// CheckAndAwardTitles 检查并自动颁发称号
-func (s *PointService) CheckAndAwardTitles(userID uint) error {
-	userPoints, err := s.GetUserPoints(userID)
-	if err != nil {
-		return err
-	}
-
-	// 获取所有启用的称号配置
-	allTitles, err := s.titleRepo.GetAllTitleConfigs()
-	if err != nil {
-		return err
-	}
-
-	// 检查每个称号的条件
-	for _, titleConfig := range allTitles {
-		// 跳过特殊称号
-		if titleConfig.ConditionType == model.TitleConditionCustom {
-			continue
-		}
-
-		// 检查是否已拥有
-		hasTitle, _ := s.titleRepo.CheckUserHasTitle(userID, titleConfig.ID)
-		if hasTitle {
-			continue
-		}
-
-		// 检查是否满足条件
-		shouldAward := false
-		switch titleConfig.ConditionType {
-		case model.TitleConditionArticlesRead:
-			shouldAward = userPoints.TotalArticlesRead >= titleConfig.ConditionValue
-		case model.TitleConditionDictationsCompleted:
-			shouldAward = userPoints.TotalDictationsCompleted >= titleConfig.ConditionValue
-		case model.TitleConditionTotalPoints:
-			shouldAward = userPoints.TotalPoints >= titleConfig.ConditionValue
-		case model.TitleConditionContinuousCheckIns:
-			shouldAward = userPoints.ContinuousCheckIns >= titleConfig.ConditionValue
-		case model.TitleConditionVocabularyCount:
-			// 获取生词本收藏数
-			var vocabCount int64
-			s.db.Model(&model.Vocabulary{}).Where("user_id = ?", userID).Count(&vocabCount)
-			shouldAward = int(vocabCount) >= titleConfig.ConditionValue
-		case model.TitleConditionReviewCount:
-			// 获取复习次数
-			var reviewCount int64
-			s.db.Model(&model.VocabularyReview{}).Where("user_id = ?", userID).Count(&reviewCount)
-			shouldAward = int(reviewCount) >= titleConfig.ConditionValue
-		case model.TitleConditionTotalDuration:
-			// 获取学习时长(分钟)
-			shouldAward = int(userPoints.TotalDurationMinutes) >= titleConfig.ConditionValue
-		}
-
-		// 颁发称号
-		if shouldAward {
-			_ = s.db.Transaction(func(tx *gorm.DB) error {
-				userTitle := &model.UserTitle{
-					UserID:        userID,
-					TitleConfigID: titleConfig.ID,
-					AwardedAt:     time.Now(),
-					IsEquipped:    false,
-				}
-				return s.titleRepo.CreateUserTitle(tx, userTitle)
-			})
-		}
-	}
-
-	return nil
-}
-
 // GetEquippedTitle 获取用户佩戴的称号
 func (s *PointService) GetEquippedTitle(userID uint) (*model.UserTitle, error) {
 	return s.titleRepo.GetEquippedTitle(userID)
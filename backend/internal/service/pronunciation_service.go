@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"voicepaper/config"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/storage"
+	"voicepaper/pkg/minimax"
+
+	"gorm.io/gorm"
+)
+
+const (
+	pronunciationURLExpires   = 3600             // 发音签名 URL 有效期（秒）
+	pronunciationStaleAfter   = 10 * time.Minute // pending 超过该时间视为生成中断，可重新认领
+	pronunciationRetryAfter   = 30 * time.Minute // 生成失败后的重试间隔
+	pronunciationMaxAttempts  = 3                // 最多尝试次数
+	pronunciationMaxTextRunes = 500              // 发音文本最长字符数
+	pronunciationConcurrency  = 4                // 后台生成的并发数（进程内）
+)
+
+// pronunciationSlots 后台生成发音的并发槽位，槽位已满时本次不生成，下次请求时再尝试
+var pronunciationSlots = make(chan struct{}, pronunciationConcurrency)
+
+var ErrInvalidPronunciationText = errors.New("发音文本为空或过长")
+
+// PronunciationAudio 发音地址，Pending 表示正在生成，稍后重新获取
+type PronunciationAudio struct {
+	URL     string `json:"url,omitempty"`
+	Pending bool   `json:"pending,omitempty"`
+}
+
+// PronunciationService 单词和例句发音服务
+// 发音按规范化文本的哈希和音色缓存（vp_pronunciations），第一次请求时在后台调用 TTS 生成并保存到存储；
+// 存储路径按音频内容的哈希命名，内容相同的发音共用一个文件。
+type PronunciationService struct {
+	repo       *repository.PronunciationRepository
+	storage    storage.Storage
+	voice      string
+	synthesize func(text, voice string) ([]byte, error)
+}
+
+func NewPronunciationService(db *gorm.DB, st storage.Storage) *PronunciationService {
+	return &PronunciationService{
+		repo:       repository.NewPronunciationRepository(db),
+		storage:    st,
+		voice:      config.GetConfig().TTS.VoiceID,
+		synthesize: minimax.GenerateSpeechWithVoice,
+	}
+}
+
+// WithVoice 返回使用指定音色的服务（共用仓库和存储）
+func (s *PronunciationService) WithVoice(voice string) *PronunciationService {
+	clone := *s
+	if voice != "" {
+		clone.voice = voice
+	}
+	return &clone
+}
+
+// Lookup 获取发音地址：已生成时返回 URL，否则在后台生成并返回 pending
+func (s *PronunciationService) Lookup(text, kind string) PronunciationAudio {
+	return s.LookupMany([]string{text}, kind)[text]
+}
+
+// LookupMany 批量获取发音地址（key 为传入的文本），没有生成过的在后台生成
+func (s *PronunciationService) LookupMany(texts []string, kind string) map[string]PronunciationAudio {
+	result := make(map[string]PronunciationAudio, len(texts))
+	if s.storage == nil || len(texts) == 0 {
+		return result
+	}
+
+	hashes := make([]string, 0, len(texts))
+	byHash := make(map[string][]string, len(texts))
+	for _, text := range texts {
+		normalized := normalizePronunciationText(text, kind)
+		if normalized == "" || utf8.RuneCountInString(normalized) > pronunciationMaxTextRunes {
+			continue
+		}
+		hash := pronunciationHash(normalized)
+		if _, ok := byHash[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+		byHash[hash] = append(byHash[hash], text)
+	}
+
+	rows, err := s.repo.GetByHashes(hashes, s.voice)
+	if err != nil {
+		log.Printf("⚠️ 查询发音缓存失败: %v", err)
+		return result
+	}
+	found := make(map[string]*model.Pronunciation, len(rows))
+	for i := range rows {
+		found[rows[i].TextHash] = &rows[i]
+	}
+
+	now := time.Now()
+	for _, hash := range hashes {
+		audio := PronunciationAudio{}
+		p := found[hash]
+		switch {
+		case p != nil && p.Status == model.PronunciationReady:
+			audio.URL = s.URL(p.Path)
+		case p != nil && p.Status == model.PronunciationPending && now.Sub(p.UpdatedAt) < pronunciationStaleAfter:
+			audio.Pending = true
+		case p != nil && p.Status == model.PronunciationFailed &&
+			(p.Attempts >= pronunciationMaxAttempts || now.Sub(p.UpdatedAt) < pronunciationRetryAfter):
+			// 失败且暂不重试，不返回地址
+		default:
+			audio.Pending = true
+			s.generateAsync(byHash[hash][0], kind)
+		}
+		for _, text := range byHash[hash] {
+			result[text] = audio
+		}
+	}
+	return result
+}
+
+// Generate 同步生成发音（已生成或其他进程正在生成时直接返回已有记录）
+func (s *PronunciationService) Generate(text, kind string) (*model.Pronunciation, error) {
+	if s.storage == nil {
+		return nil, errors.New("未配置存储")
+	}
+	normalized := normalizePronunciationText(text, kind)
+	if normalized == "" || utf8.RuneCountInString(normalized) > pronunciationMaxTextRunes {
+		return nil, ErrInvalidPronunciationText
+	}
+
+	now := time.Now()
+	p := &model.Pronunciation{
+		TextHash: pronunciationHash(normalized),
+		Voice:    s.voice,
+		Kind:     kind,
+		Text:     normalized,
+	}
+	claimed, err := s.repo.Claim(p, now.Add(-pronunciationStaleAfter), now.Add(-pronunciationRetryAfter), pronunciationMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return s.repo.Get(p.TextHash, p.Voice)
+	}
+
+	path, contentHash, size, err := s.produce(normalized, kind)
+	if err != nil {
+		if markErr := s.repo.MarkFailed(p.ID, truncateRunes(err.Error(), 500)); markErr != nil {
+			log.Printf("⚠️ 标记发音生成失败出错 (%s): %v", normalized, markErr)
+		}
+		return nil, err
+	}
+	if err := s.repo.MarkReady(p.ID, path, contentHash, size); err != nil {
+		return nil, err
+	}
+	p.Status, p.Path, p.ContentHash, p.Size, p.Error = model.PronunciationReady, path, contentHash, size, ""
+	return p, nil
+}
+
+// ReadyPath 已生成的发音在存储中的路径（不触发生成）
+func (s *PronunciationService) ReadyPath(text, kind string) (string, bool) {
+	normalized := normalizePronunciationText(text, kind)
+	if normalized == "" {
+		return "", false
+	}
+	p, err := s.repo.Get(pronunciationHash(normalized), s.voice)
+	if err != nil || p.Status != model.PronunciationReady {
+		return "", false
+	}
+	return p.Path, true
+}
+
+// URL 发音的访问地址（私有 Bucket 使用签名 URL）
+func (s *PronunciationService) URL(path string) string {
+	url, err := s.storage.GetSignedURL(context.Background(), path, pronunciationURLExpires)
+	if err != nil {
+		return s.storage.GetURL(path)
+	}
+	return url
+}
+
+// AttachWordbookAudio 为单词书单词填充单词和例句的发音地址
+func (s *PronunciationService) AttachWordbookAudio(words []model.Wordbook) {
+	if s.storage == nil || len(words) == 0 {
+		return
+	}
+	texts := make([]string, 0, len(words))
+	examples := make([]string, 0, len(words))
+	for _, w := range words {
+		texts = append(texts, w.Word)
+		if w.Example != "" {
+			examples = append(examples, w.Example)
+		}
+	}
+	audio := s.LookupMany(texts, model.PronunciationWord)
+	exampleAudio := s.LookupMany(examples, model.PronunciationSentence)
+	for i := range words {
+		a := audio[words[i].Word]
+		words[i].AudioURL, words[i].AudioPending = a.URL, a.Pending
+		words[i].ExampleAudioURL = exampleAudio[words[i].Example].URL
+	}
+}
+
+// AttachVocabularyAudio 为生词填充发音地址（句子按句子发音）和例句发音地址
+func (s *PronunciationService) AttachVocabularyAudio(vocabs []model.Vocabulary) {
+	if s.storage == nil || len(vocabs) == 0 {
+		return
+	}
+	var words, sentences []string
+	for _, v := range vocabs {
+		if v.Type == model.VocabularyTypeSentence {
+			sentences = append(sentences, v.Content)
+		} else {
+			words = append(words, v.Content)
+		}
+		if v.Example != "" {
+			sentences = append(sentences, v.Example)
+		}
+	}
+	wordAudio := s.LookupMany(words, model.PronunciationWord)
+	sentenceAudio := s.LookupMany(sentences, model.PronunciationSentence)
+	for i := range vocabs {
+		a := wordAudio[vocabs[i].Content]
+		if vocabs[i].Type == model.VocabularyTypeSentence {
+			a = sentenceAudio[vocabs[i].Content]
+		}
+		vocabs[i].AudioURL, vocabs[i].AudioPending = a.URL, a.Pending
+		vocabs[i].ExampleAudioURL = sentenceAudio[vocabs[i].Example].URL
+	}
+}
+
+// generateAsync 在后台生成发音，并发槽位已满时跳过
+func (s *PronunciationService) generateAsync(text, kind string) {
+	select {
+	case pronunciationSlots <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-pronunciationSlots }()
+		p, err := s.Generate(text, kind)
+		if err != nil {
+			log.Printf("❌ 生成发音失败 (%s): %v", text, err)
+			return
+		}
+		if p.Status == model.PronunciationReady {
+			log.Printf("✅ 发音已生成: %s -> %s", text, p.Path)
+		}
+	}()
+}
+
+// produce 生成音频并保存，返回存储路径、内容哈希和大小
+// 默认音色的单词先复用旧版按文本命名的发音文件（audio/words/），内容相同的音频共用已有文件
+func (s *PronunciationService) produce(text, kind string) (string, string, int64, error) {
+	ctx := context.Background()
+	if kind == model.PronunciationWord && s.voice == config.GetConfig().TTS.VoiceID {
+		legacy := legacyWordAudioPath(text)
+		if exists, err := s.storage.Exists(ctx, legacy); err == nil && exists {
+			size, _ := s.storage.GetSize(ctx, legacy)
+			return legacy, "", size, nil
+		}
+	}
+
+	audio, err := s.synthesize(text, s.voice)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if len(audio) == 0 {
+		return "", "", 0, errors.New("TTS 返回的音频为空")
+	}
+	sum := sha256.Sum256(audio)
+	contentHash := hex.EncodeToString(sum[:])
+
+	if path, err := s.repo.FindPathByContentHash(contentHash); err == nil {
+		return path, contentHash, int64(len(audio)), nil
+	}
+	path := fmt.Sprintf("audio/pronunciations/%s/%s.mp3", contentHash[:2], contentHash)
+	if _, err := s.storage.Save(ctx, path, audio); err != nil {
+		return "", "", 0, err
+	}
+	return path, contentHash, int64(len(audio)), nil
+}
+
+// normalizePronunciationText 规范化发音文本：合并空白；单词不区分大小写
+func normalizePronunciationText(text, kind string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if kind == model.PronunciationWord {
+		text = strings.ToLower(text)
+	}
+	return text
+}
+
+func pronunciationHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// legacyWordAudioPath 旧版单词发音在存储中的路径（按小写文本的哈希命名）
+func legacyWordAudioPath(text string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(text))))
+	return fmt.Sprintf("audio/words/%s.mp3", hex.EncodeToString(sum[:16]))
+}
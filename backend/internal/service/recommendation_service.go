@@ -0,0 +1,156 @@
+package service
+
+import (
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/recommend"
+
+	"gorm.io/gorm"
+)
+
+// RecommendCandidatePool 参与打分的候选文章数量上限
+const RecommendCandidatePool = 200
+
+// RecommendationService 下一篇文章推荐服务
+// 负责从数据库组装数据，打分逻辑在 pkg/recommend 中
+type RecommendationService struct {
+	db           *gorm.DB
+	progressRepo *repository.ReadingProgressRepository
+	articleRepo  *repository.ArticleRepository
+	vocabRepo    *repository.VocabularyRepository
+	tagRepo      *repository.TagRepository
+	calendar     *CalendarService
+	weights      recommend.Weights
+}
+
+func NewRecommendationService(db *gorm.DB, calendar *CalendarService) *RecommendationService {
+	return &RecommendationService{
+		db:           db,
+		calendar:     calendar,
+		progressRepo: repository.NewReadingProgressRepository(db),
+		articleRepo:  repository.NewArticleRepository(),
+		vocabRepo:    repository.NewVocabularyRepository(),
+		tagRepo:      repository.NewTagRepository(db),
+		weights:      recommend.DefaultWeights,
+	}
+}
+
+// RecommendedArticle 推荐结果（文章 + 分项得分）
+type RecommendedArticle struct {
+	Article   model.Article       `json:"article"`
+	Score     float64             `json:"score"`
+	Breakdown recommend.Breakdown `json:"breakdown"`
+}
+
+// RecommendationResult 推荐接口返回
+type RecommendationResult struct {
+	Items    []RecommendedArticle `json:"items"`
+	Level    float64              `json:"level"`     // 估算的用户水平
+	DueWords int                  `json:"due_words"` // 参与匹配的待复习单词数
+}
+
+// Recommend 为用户推荐未读文章
+func (s *RecommendationService) Recommend(userID uint, limit int) (*RecommendationResult, error) {
+	now := s.calendar.Now()
+
+	// 1. 阅读历史
+	progresses, err := s.progressRepo.GetAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	readIDs := make([]uint, 0, len(progresses))
+	for _, p := range progresses {
+		readIDs = append(readIDs, p.ArticleID)
+	}
+
+	// 2. 已读文章的难度（用于估算用户水平）
+	readStats, err := s.articleRepo.GetWordStatsByArticleIDs(readIDs)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]recommend.ReadingSignal, 0, len(progresses))
+	for _, p := range progresses {
+		signal := recommend.ReadingSignal{
+			ArticleID:   p.ArticleID,
+			CategoryID:  p.Article.CategoryID,
+			Progress:    p.Progress,
+			IsCompleted: p.IsCompleted,
+			LastReadAt:  p.LastReadAt,
+		}
+		if st, ok := readStats[p.ArticleID]; ok {
+			signal.Difficulty = st.Difficulty
+		}
+		history = append(history, signal)
+	}
+
+	// 3. 待复习单词（未来24小时内到期的也算）
+	dueWords, err := s.vocabRepo.GetDueContents(userID, now.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 订阅的话题
+	subscribedTags, err := s.tagRepo.GetSubscribedTagIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := recommend.BuildProfile(history, dueWords, subscribedTags, now)
+
+	// 5. 候选文章（未读）
+	articles, err := s.articleRepo.GetRecommendCandidates(readIDs, s.calendar.TodayString(userID), RecommendCandidatePool)
+	if err != nil {
+		return nil, err
+	}
+	candidateIDs := make([]uint, 0, len(articles))
+	for _, a := range articles {
+		candidateIDs = append(candidateIDs, a.ID)
+	}
+	candidateStats, err := s.articleRepo.GetWordStatsByArticleIDs(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	candidateTags, err := s.tagRepo.GetTagIDsByArticleIDs(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	articleMap := make(map[uint]model.Article, len(articles))
+	candidates := make([]recommend.Candidate, 0, len(articles))
+	for _, a := range articles {
+		articleMap[a.ID] = a
+		c := recommend.Candidate{
+			ArticleID:  a.ID,
+			CategoryID: a.CategoryID,
+			TagIDs:     candidateTags[a.ID],
+		}
+		if a.PublishDate != nil {
+			c.PublishedAt = *a.PublishDate
+		} else {
+			c.PublishedAt = a.CreatedAt
+		}
+		if st, ok := candidateStats[a.ID]; ok {
+			c.Words = st.Words
+			c.Difficulty = st.Difficulty
+		}
+		candidates = append(candidates, c)
+	}
+
+	// 6. 打分排序
+	ranked := recommend.Rank(profile, candidates, s.weights, limit)
+	items := make([]RecommendedArticle, 0, len(ranked))
+	for _, r := range ranked {
+		items = append(items, RecommendedArticle{
+			Article:   articleMap[r.ArticleID],
+			Score:     r.Score,
+			Breakdown: r.Breakdown,
+		})
+	}
+
+	return &RecommendationResult{
+		Items:    items,
+		Level:    profile.Level,
+		DueWords: len(profile.DueWords),
+	}, nil
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/srs"
+	"voicepaper/pkg/textdiff"
+
+	"gorm.io/gorm"
+)
+
+// learnAheadLimit 队列已空时，提前展示该时间内到期的学习卡片，避免用户干等几分钟
+const learnAheadLimit = 20 * time.Minute
+
+// ErrCardNotInSession 卡片不在今日队列中且未到期
+var ErrCardNotInSession = errors.New("该卡片不在今日复习队列中或尚未到期")
+
+// ReviewSessionService 每日复习会话服务
+// 每天按用户设置的上限生成一次队列（到期复习 + 新卡片交错排列，兄弟卡片埋藏到明天），
+// 学习/重学步骤中的卡片到期后优先插队。队列和当前卡片保存在服务端，换设备后可继续。
+type ReviewSessionService struct {
+	db           *gorm.DB
+	sessionRepo  *repository.ReviewSessionRepository
+	srsService   *SRSService
+	vocabService *VocabularyService
+	calendar     *CalendarService
+}
+
+func NewReviewSessionService(db *gorm.DB, calendar *CalendarService) *ReviewSessionService {
+	return &ReviewSessionService{
+		db:           db,
+		sessionRepo:  repository.NewReviewSessionRepository(db),
+		srsService:   NewSRSService(db),
+		vocabService: NewVocabularyService(calendar),
+		calendar:     calendar,
+	}
+}
+
+// ReviewSessionView 会话当前状态
+type ReviewSessionView struct {
+	Session         *model.ReviewSession `json:"session"`
+	Card            *model.Vocabulary    `json:"card,omitempty"`        // 当前卡片，为空表示暂时没有可学的卡片
+	CardKind        string               `json:"card_kind,omitempty"`   // new/review/learning/relearning
+	NewRemaining    int                  `json:"new_remaining"`         // 队列中剩余新卡片
+	ReviewRemaining int                  `json:"review_remaining"`      // 队列中剩余复习卡片
+	LearningDue     int                  `json:"learning_due"`          // 已到期的学习/重学卡片
+	BuriedCount     int                  `json:"buried_count"`          // 今日埋藏的兄弟卡片
+	NextDueAt       *time.Time           `json:"next_due_at,omitempty"` // 暂无卡片时，下一张学习卡片的到期时间
+	Finished        bool                 `json:"finished"`              // 今日已全部完成
+}
+
+// ReviewSessionAnswerResult 会话中提交答案的结果
+type ReviewSessionAnswerResult struct {
+	Review *SubmitReviewResult `json:"review"`
+	Next   *ReviewSessionView  `json:"next"`
+}
+
+// Current 获取今日会话及当前卡片（没有会话时自动创建）
+func (s *ReviewSessionService) Current(userID uint) (*ReviewSessionView, error) {
+	session, err := s.getOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.next(session)
+}
+
+// Answer 在会话中提交一张卡片的复习结果，返回下一张卡片
+func (s *ReviewSessionService) Answer(userID uint, req *SubmitReviewRequest) (*ReviewSessionAnswerResult, error) {
+	session, err := s.getOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	vocab, err := s.vocabService.GetVocabulary(req.VocabularyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.calendar.Now()
+	idx := -1
+	for i, item := range session.Items {
+		if item.VocabularyID == vocab.ID && !item.Done {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 && !isLearningDue(vocab, now.Add(learnAheadLimit)) {
+		return nil, ErrCardNotInSession
+	}
+
+	result, err := s.vocabService.SubmitReview(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if idx >= 0 {
+		session.Items[idx].Done = true
+		if session.Items[idx].Kind == model.ReviewItemNew {
+			session.NewDone++
+		} else {
+			session.ReviewDone++
+		}
+	} else {
+		session.LearningDone++
+	}
+	s.burySiblings(session, vocab.ID, vocab.Content)
+	session.CurrentVocabularyID = nil
+
+	next, err := s.next(session)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewSessionAnswerResult{Review: result, Next: next}, nil
+}
+
+// next 选出下一张卡片并保存会话
+// 优先级：当前卡片 > 已到期的学习卡片 > 队列 > 20分钟内到期的学习卡片
+func (s *ReviewSessionService) next(session *model.ReviewSession) (*ReviewSessionView, error) {
+	now := s.calendar.Now()
+	dayEnd := s.calendar.TomorrowStart(session.UserID)
+	view := &ReviewSessionView{Session: session}
+
+	learning, err := s.sessionRepo.GetLearningCards(session.UserID, now.Add(learnAheadLimit))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range learning {
+		if !v.NextReviewAt.After(now) {
+			view.LearningDue++
+		}
+	}
+
+	// 1. 当前卡片（另一台设备上正在展示的卡片）
+	if session.CurrentVocabularyID != nil {
+		if v, err := s.vocabService.GetVocabulary(*session.CurrentVocabularyID, session.UserID); err == nil &&
+			(s.inQueue(session, v.ID) || isLearningDue(v, now.Add(learnAheadLimit))) {
+			view.Card = v
+		}
+	}
+
+	// 2. 已到期的学习卡片
+	if view.Card == nil && view.LearningDue > 0 {
+		view.Card = &learning[0]
+	}
+
+	// 3. 队列中的下一张
+	if view.Card == nil {
+		for i := range session.Items {
+			item := &session.Items[i]
+			if item.Done {
+				continue
+			}
+			v, err := s.vocabService.GetVocabulary(item.VocabularyID, session.UserID)
+			if err != nil || !stillQueued(item, v, dayEnd) {
+				// 已删除或已在会话外复习过
+				item.Done = true
+				continue
+			}
+			view.Card = v
+			break
+		}
+	}
+
+	// 4. 提前展示即将到期的学习卡片
+	if view.Card == nil && len(learning) > 0 {
+		view.Card = &learning[0]
+	}
+
+	for _, item := range session.Items {
+		if item.Done {
+			continue
+		}
+		if item.Kind == model.ReviewItemNew {
+			view.NewRemaining++
+		} else {
+			view.ReviewRemaining++
+		}
+	}
+	view.BuriedCount = len(session.BuriedIDs)
+
+	if view.Card != nil {
+		view.CardKind = srs.StateOf(cardFromVocabulary(view.Card))
+		session.CurrentVocabularyID = &view.Card.ID
+		session.Status = model.ReviewSessionActive
+		session.FinishedAt = nil
+	} else {
+		session.CurrentVocabularyID = nil
+		view.NextDueAt, err = s.sessionRepo.GetNextLearningDue(session.UserID, dayEnd)
+		if err != nil {
+			return nil, err
+		}
+		if view.NextDueAt == nil {
+			view.Finished = true
+			if session.Status != model.ReviewSessionFinished {
+				session.Status = model.ReviewSessionFinished
+				session.FinishedAt = &now
+			}
+		}
+	}
+
+	if err := s.save(session); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// getOrCreate 获取今日会话，不存在时按用户设置生成队列
+func (s *ReviewSessionService) getOrCreate(userID uint) (*model.ReviewSession, error) {
+	date := s.calendar.TodayString(userID)
+
+	session, err := s.sessionRepo.GetByDate(userID, date)
+	if err == nil {
+		s.decode(session)
+		return session, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	session, err = s.build(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		// 并发创建（多端同时打开）时以先创建的为准
+		if existing, getErr := s.sessionRepo.GetByDate(userID, date); getErr == nil {
+			s.decode(existing)
+			return existing, nil
+		}
+		return nil, err
+	}
+	log.Printf("✅ 用户 %d 的复习会话已创建: %s, 新卡片 %d, 复习 %d, 埋藏 %d",
+		userID, date, countItems(session.Items, model.ReviewItemNew), countItems(session.Items, model.ReviewItemReview), len(session.BuriedIDs))
+	return session, nil
+}
+
+// build 生成今日队列
+func (s *ReviewSessionService) build(userID uint, date string) (*model.ReviewSession, error) {
+	settings, err := s.srsService.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	dayEnd := s.calendar.TomorrowStart(userID)
+
+	learning, err := s.sessionRepo.GetLearningCards(userID, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	var reviews, news []model.Vocabulary
+	if settings.ReviewsPerDay > 0 {
+		// 多取一些，埋藏兄弟卡片后仍能填满上限
+		if reviews, err = s.sessionRepo.GetDueReviews(userID, dayEnd, settings.ReviewsPerDay*2); err != nil {
+			return nil, err
+		}
+	}
+	if settings.NewCardsPerDay > 0 {
+		if news, err = s.sessionRepo.GetNewCards(userID, settings.NewCardsPerDay*2); err != nil {
+			return nil, err
+		}
+	}
+
+	// 兄弟卡片（如单词和包含它的短语）同一天只出现一张，其余埋藏到明天
+	var kept []map[string]bool
+	var buried []uint
+	claim := func(content string) bool {
+		tokens := siblingTokens(content)
+		for _, k := range kept {
+			if isSibling(tokens, k) {
+				return false
+			}
+		}
+		kept = append(kept, tokens)
+		return true
+	}
+	pick := func(candidates []model.Vocabulary, limit int, kind string) []model.ReviewSessionItem {
+		var items []model.ReviewSessionItem
+		for _, v := range candidates {
+			if len(items) >= limit {
+				break
+			}
+			if !claim(v.Content) {
+				buried = append(buried, v.ID)
+				continue
+			}
+			items = append(items, model.ReviewSessionItem{VocabularyID: v.ID, Content: v.Content, Kind: kind})
+		}
+		return items
+	}
+
+	for _, v := range learning {
+		kept = append(kept, siblingTokens(v.Content))
+	}
+	reviewItems := pick(reviews, settings.ReviewsPerDay, model.ReviewItemReview)
+	newItems := pick(news, settings.NewCardsPerDay, model.ReviewItemNew)
+
+	session := &model.ReviewSession{
+		UserID:      userID,
+		SessionDate: date,
+		Status:      model.ReviewSessionActive,
+		NewLimit:    settings.NewCardsPerDay,
+		ReviewLimit: settings.ReviewsPerDay,
+		Items:       interleave(reviewItems, newItems),
+		BuriedIDs:   buried,
+	}
+	s.encode(session)
+	return session, nil
+}
+
+// burySiblings 埋藏队列中与刚复习的卡片互为兄弟的卡片
+func (s *ReviewSessionService) burySiblings(session *model.ReviewSession, vocabID uint, content string) {
+	tokens := siblingTokens(content)
+	for i := range session.Items {
+		item := &session.Items[i]
+		if item.Done || item.VocabularyID == vocabID {
+			continue
+		}
+		if isSibling(tokens, siblingTokens(item.Content)) {
+			item.Done = true
+			session.BuriedIDs = append(session.BuriedIDs, item.VocabularyID)
+		}
+	}
+}
+
+func (s *ReviewSessionService) inQueue(session *model.ReviewSession, vocabID uint) bool {
+	for _, item := range session.Items {
+		if item.VocabularyID == vocabID && !item.Done {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ReviewSessionService) save(session *model.ReviewSession) error {
+	s.encode(session)
+	return s.sessionRepo.Save(session)
+}
+
+func (s *ReviewSessionService) encode(session *model.ReviewSession) {
+	queue, _ := json.Marshal(session.Items)
+	session.Queue = string(queue)
+	buried, _ := json.Marshal(session.BuriedIDs)
+	session.Buried = string(buried)
+}
+
+func (s *ReviewSessionService) decode(session *model.ReviewSession) {
+	if session.Queue != "" {
+		if err := json.Unmarshal([]byte(session.Queue), &session.Items); err != nil {
+			log.Printf("⚠️ 解析复习会话 %d 的队列失败: %v", session.ID, err)
+		}
+	}
+	if session.Buried != "" {
+		if err := json.Unmarshal([]byte(session.Buried), &session.BuriedIDs); err != nil {
+			log.Printf("⚠️ 解析复习会话 %d 的埋藏列表失败: %v", session.ID, err)
+		}
+	}
+}
+
+// interleave 把新卡片均匀地插入复习卡片之间
+func interleave(reviews, news []model.ReviewSessionItem) []model.ReviewSessionItem {
+	total := len(reviews) + len(news)
+	items := make([]model.ReviewSessionItem, 0, total)
+	ri, ni := 0, 0
+	for len(items) < total {
+		// 第 ni 张新卡片的目标位置：(ni+1) * total / (新卡片数+1)
+		if ni < len(news) && (ri >= len(reviews) || len(items) >= (ni+1)*total/(len(news)+1)) {
+			items = append(items, news[ni])
+			ni++
+		} else {
+			items = append(items, reviews[ri])
+			ri++
+		}
+	}
+	return items
+}
+
+// stillQueued 队列中的卡片是否仍需在今天学习（可能已在会话外复习过）
+func stillQueued(item *model.ReviewSessionItem, v *model.Vocabulary, endOfDay time.Time) bool {
+	state := srs.StateOf(cardFromVocabulary(v))
+	if item.Kind == model.ReviewItemNew {
+		return state == srs.StateNew
+	}
+	return state == srs.StateReview && (v.NextReviewAt == nil || !v.NextReviewAt.After(endOfDay))
+}
+
+// isLearningDue 卡片是否处于学习/重学阶段且在 before 之前到期
+func isLearningDue(v *model.Vocabulary, before time.Time) bool {
+	state := srs.StateOf(cardFromVocabulary(v))
+	if state != srs.StateLearning && state != srs.StateRelearning {
+		return false
+	}
+	return v.NextReviewAt != nil && !v.NextReviewAt.After(before)
+}
+
+// siblingTokens 卡片内容的单词集合（忽略大小写和标点）
+func siblingTokens(content string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, w := range textdiff.Words(content) {
+		if n := textdiff.NormalizeWord(w); n != "" {
+			tokens[n] = true
+		}
+	}
+	return tokens
+}
+
+// isSibling 一张卡片的单词全部包含在另一张中即视为兄弟（如 take / take off / 含该词的句子）
+// 过短的单个词（a、to 等）不参与判断
+func isSibling(a, b map[string]bool) bool {
+	small, large := a, b
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	if len(small) == 0 {
+		return false
+	}
+	if len(small) == 1 {
+		for w := range small {
+			if len([]rune(w)) < 3 {
+				return false
+			}
+		}
+	}
+	for w := range small {
+		if !large[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func countItems(items []model.ReviewSessionItem, kind string) int {
+	n := 0
+	for _, item := range items {
+		if item.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
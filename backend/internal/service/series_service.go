@@ -0,0 +1,308 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// SeriesService 系列/合集服务
+// 汇总用户在各剧集上的阅读进度和默写进度，计算解锁状态、继续学习位置，并发放完成奖励
+type SeriesService struct {
+	db           *gorm.DB
+	seriesRepo   *repository.SeriesRepository
+	pointService *PointService
+}
+
+func NewSeriesService(db *gorm.DB) *SeriesService {
+	return &SeriesService{
+		db:           db,
+		seriesRepo:   repository.NewSeriesRepository(db),
+		pointService: NewPointService(db),
+	}
+}
+
+// EpisodeProgress 单集的学习进度
+type EpisodeProgress struct {
+	model.SeriesEpisode
+	Status             string  `json:"status"`              // locked/not_started/in_progress/completed
+	Progress           float64 `json:"progress"`            // 综合进度（0-100）
+	ReadingProgress    float64 `json:"reading_progress"`    // 阅读进度（0-100）
+	ReadingCompleted   bool    `json:"reading_completed"`   // 是否已完成阅读
+	DictationProgress  float64 `json:"dictation_progress"`  // 默写进度（0-100，取各类型中最高值）
+	DictationCompleted bool    `json:"dictation_completed"` // 是否完成过整篇默写
+	LockedReason       string  `json:"locked_reason,omitempty"`
+
+	lastActiveAt time.Time
+}
+
+// SeriesProgress 用户在某个系列上的进度
+type SeriesProgress struct {
+	Series         model.Series            `json:"series"`
+	Episodes       []EpisodeProgress       `json:"episodes"`
+	TotalEpisodes  int                     `json:"total_episodes"`
+	CompletedCount int                     `json:"completed_count"`
+	Progress       float64                 `json:"progress"`                 // 完成集数百分比（0-100）
+	IsCompleted    bool                    `json:"is_completed"`             // 是否已完成全部剧集
+	Continue       *EpisodeProgress        `json:"continue,omitempty"`       // 继续学习的剧集（全部完成时为空）
+	Completion     *model.SeriesCompletion `json:"completion,omitempty"`     // 完成记录（含奖励积分）
+	LastActiveAt   *time.Time              `json:"last_active_at,omitempty"` // 最近一次学习时间
+}
+
+// SeriesReward 完成系列时发放的奖励
+type SeriesReward struct {
+	SeriesID    uint   `json:"series_id"`
+	SeriesTitle string `json:"series_title"`
+	Points      int    `json:"points"`
+}
+
+// GetSeriesList 获取系列列表
+func (s *SeriesService) GetSeriesList(categoryID *uint, page, pageSize int) ([]model.Series, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.seriesRepo.GetList(categoryID, pageSize, offset)
+}
+
+// GetSeriesDetail 获取系列详情（含剧集列表）
+// userID 为0时只返回剧集，不计算个人进度
+func (s *SeriesService) GetSeriesDetail(seriesID, userID uint) (*SeriesProgress, error) {
+	series, err := s.seriesRepo.FindByID(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildProgress(series, userID)
+}
+
+// GetContinueList 获取用户正在学习的系列（按最近学习时间倒序），每个系列带继续学习的剧集
+func (s *SeriesService) GetContinueList(userID uint, limit int) ([]SeriesProgress, error) {
+	seriesIDs, err := s.seriesRepo.GetStartedSeriesIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SeriesProgress, 0, len(seriesIDs))
+	for _, id := range seriesIDs {
+		series, err := s.seriesRepo.FindByID(id)
+		if err != nil {
+			continue
+		}
+		sp, err := s.buildProgress(series, userID)
+		if err != nil {
+			return nil, err
+		}
+		// 已完成的系列不再出现在“继续学习”中
+		if sp.IsCompleted || sp.Continue == nil {
+			continue
+		}
+		result = append(result, *sp)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		ti, tj := result[i].LastActiveAt, result[j].LastActiveAt
+		if ti == nil || tj == nil {
+			return ti != nil
+		}
+		return ti.After(*tj)
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// CheckCompletion 文章学习完成后检查其所属系列是否全部完成，完成则发放奖励（每个系列只发一次）
+func (s *SeriesService) CheckCompletion(userID, articleID uint) ([]SeriesReward, error) {
+	seriesIDs, err := s.seriesRepo.GetSeriesIDsByArticleIDs([]uint{articleID})
+	if err != nil {
+		return nil, err
+	}
+
+	var rewards []SeriesReward
+	for _, id := range seriesIDs {
+		series, err := s.seriesRepo.FindByID(id)
+		if err != nil {
+			continue
+		}
+		sp, err := s.buildProgress(series, userID)
+		if err != nil {
+			return rewards, err
+		}
+		if !sp.IsCompleted || sp.Completion != nil {
+			continue
+		}
+
+		points := series.CompletionBonus
+		if points <= 0 {
+			points = model.PointRewardConfig[model.PointTypeSeriesComplete]
+		}
+		description := fmt.Sprintf("完成系列《%s》", series.Title)
+
+		// 完成记录和奖励积分在同一事务中写入：发放失败时完成记录一起回滚，下次还能重试
+		created := false
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			completion := &model.SeriesCompletion{
+				UserID:      userID,
+				SeriesID:    series.ID,
+				CompletedAt: time.Now(),
+				BonusPoints: points,
+			}
+			var err error
+			if created, err = s.seriesRepo.CreateCompletion(tx, completion); err != nil || !created {
+				return err // 未插入说明并发请求已经记录过
+			}
+			_, _, err = s.pointService.AwardPointsTx(tx, userID, points, model.PointTypeSeriesComplete, description, nil, nil)
+			return err
+		})
+		if err != nil {
+			log.Printf("⚠️ 发放系列完成奖励失败: user=%d series=%d err=%v", userID, series.ID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		rewards = append(rewards, SeriesReward{
+			SeriesID:    series.ID,
+			SeriesTitle: series.Title,
+			Points:      points,
+		})
+		log.Printf("✅ 用户 %d 完成系列《%s》，获得 %d 积分", userID, series.Title, points)
+	}
+
+	return rewards, nil
+}
+
+// buildProgress 汇总阅读进度和默写进度，计算每一集的状态
+func (s *SeriesService) buildProgress(series *model.Series, userID uint) (*SeriesProgress, error) {
+	episodes, err := s.seriesRepo.GetEpisodes(series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &SeriesProgress{
+		Series:        *series,
+		Episodes:      make([]EpisodeProgress, len(episodes)),
+		TotalEpisodes: len(episodes),
+	}
+	for i, ep := range episodes {
+		sp.Episodes[i] = EpisodeProgress{SeriesEpisode: ep, Status: model.EpisodeStatusNotStarted}
+	}
+
+	if userID == 0 {
+		return sp, nil
+	}
+
+	articleIDs := make([]uint, 0, len(episodes))
+	for _, ep := range episodes {
+		articleIDs = append(articleIDs, ep.ArticleID)
+	}
+	readingMap, err := s.seriesRepo.GetReadingProgressMap(userID, articleIDs)
+	if err != nil {
+		return nil, err
+	}
+	dictationMap, err := s.seriesRepo.GetDictationProgressMap(userID, articleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 汇总每集的阅读/默写进度
+	completedByID := make(map[uint]bool, len(episodes))
+	for i := range sp.Episodes {
+		ep := &sp.Episodes[i]
+		if rp, ok := readingMap[ep.ArticleID]; ok {
+			ep.ReadingProgress = rp.Progress
+			ep.ReadingCompleted = rp.IsCompleted
+			ep.lastActiveAt = rp.LastReadAt
+		}
+		for _, dp := range dictationMap[ep.ArticleID] {
+			percent := 0.0
+			if dp.Completed {
+				percent = 100
+				ep.DictationCompleted = true
+			} else if dp.TotalItems > 0 {
+				percent = float64(dp.CurrentIndex) / float64(dp.TotalItems) * 100
+			}
+			if percent > ep.DictationProgress {
+				ep.DictationProgress = math.Min(percent, 100)
+			}
+			if dp.LastPracticeAt.After(ep.lastActiveAt) {
+				ep.lastActiveAt = dp.LastPracticeAt
+			}
+		}
+
+		ep.Progress = math.Max(ep.ReadingProgress, ep.DictationProgress)
+		switch {
+		case ep.ReadingCompleted || ep.DictationCompleted:
+			ep.Status = model.EpisodeStatusCompleted
+			ep.Progress = 100
+			completedByID[ep.ID] = true
+			sp.CompletedCount++
+		case ep.Progress > 0:
+			ep.Status = model.EpisodeStatusInProgress
+		}
+
+		if !ep.lastActiveAt.IsZero() && (sp.LastActiveAt == nil || ep.lastActiveAt.After(*sp.LastActiveAt)) {
+			t := ep.lastActiveAt
+			sp.LastActiveAt = &t
+		}
+	}
+
+	// 2. 解锁规则：前置剧集优先；否则顺序系列要求上一集已完成。已学过的剧集不再锁定
+	for i := range sp.Episodes {
+		ep := &sp.Episodes[i]
+		if ep.Status != model.EpisodeStatusNotStarted {
+			continue
+		}
+		if ep.PrerequisiteEpisodeID != nil {
+			if !completedByID[*ep.PrerequisiteEpisodeID] {
+				ep.Status = model.EpisodeStatusLocked
+				ep.LockedReason = "需要先完成前置剧集"
+			}
+			continue
+		}
+		if series.IsSequential && i > 0 && sp.Episodes[i-1].Status != model.EpisodeStatusCompleted {
+			ep.Status = model.EpisodeStatusLocked
+			ep.LockedReason = "需要先完成上一集"
+		}
+	}
+
+	// 3. 继续学习：优先最近在学的剧集，其次是第一集未开始且已解锁的剧集
+	var cont *EpisodeProgress
+	for i := range sp.Episodes {
+		ep := &sp.Episodes[i]
+		if ep.Status == model.EpisodeStatusInProgress && (cont == nil || ep.lastActiveAt.After(cont.lastActiveAt)) {
+			cont = ep
+		}
+	}
+	if cont == nil {
+		for i := range sp.Episodes {
+			if sp.Episodes[i].Status == model.EpisodeStatusNotStarted {
+				cont = &sp.Episodes[i]
+				break
+			}
+		}
+	}
+	if cont != nil {
+		c := *cont
+		sp.Continue = &c
+	}
+
+	if sp.TotalEpisodes > 0 {
+		sp.Progress = float64(sp.CompletedCount) / float64(sp.TotalEpisodes) * 100
+		sp.IsCompleted = sp.CompletedCount == sp.TotalEpisodes
+	}
+
+	if completion, err := s.seriesRepo.GetCompletion(userID, series.ID); err == nil {
+		sp.Completion = completion
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return sp, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"voicepaper/pkg/srs"
+)
+
+// 在线参数拟合的限制
+const (
+	OptimizeCooldown   = 24 * time.Hour   // 同一用户两次拟合的最小间隔
+	OptimizeTimeout    = 10 * time.Minute // 单次拟合的最长时间
+	OptimizeMaxRunning = 2                // 同时运行的拟合任务数，超出的排队等待
+)
+
+// 拟合任务状态
+const (
+	OptimizeJobQueued    = "queued"
+	OptimizeJobRunning   = "running"
+	OptimizeJobDone      = "done"
+	OptimizeJobFailed    = "failed"
+	OptimizeJobCancelled = "cancelled"
+)
+
+var (
+	ErrOptimizeTooSoon  = errors.New("参数拟合过于频繁")
+	ErrOptimizeNotFound = errors.New("没有进行中的参数拟合任务")
+)
+
+// OptimizeJob 后台参数拟合任务
+type OptimizeJob struct {
+	UserID     uint             `json:"user_id"`
+	Status     string           `json:"status"`
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Outcome    *OptimizeOutcome `json:"outcome,omitempty"`
+	Error      string           `json:"error,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// finished 任务是否已结束
+func (j *OptimizeJob) finished() bool {
+	return j.Status == OptimizeJobDone || j.Status == OptimizeJobFailed || j.Status == OptimizeJobCancelled
+}
+
+// optimizeJobs 每个用户最近一次的拟合任务（进程内），所有 SRSService 共用
+var (
+	optimizeJobsMu sync.Mutex
+	optimizeJobs   = make(map[uint]*OptimizeJob)
+	optimizeSlots  = make(chan struct{}, OptimizeMaxRunning)
+)
+
+// StartOptimize 在后台拟合用户的 FSRS 参数
+// 已有进行中的任务时直接返回该任务；距上次拟合不足 OptimizeCooldown 时返回 ErrOptimizeTooSoon。
+func (s *SRSService) StartOptimize(userID uint) (*OptimizeJob, error) {
+	optimizeJobsMu.Lock()
+	defer optimizeJobsMu.Unlock()
+
+	last := optimizeJobs[userID]
+	if last != nil && !last.finished() {
+		return last.snapshot(), nil
+	}
+
+	lastRun := time.Time{}
+	if last != nil {
+		lastRun = last.CreatedAt
+	}
+	params, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	if params != nil && params.OptimizedAt != nil && params.OptimizedAt.After(lastRun) {
+		lastRun = *params.OptimizedAt
+	}
+	if next := lastRun.Add(OptimizeCooldown); time.Now().Before(next) {
+		return nil, fmt.Errorf("%w，请在 %s 之后再试", ErrOptimizeTooSoon, next.Format("2006-01-02 15:04"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), OptimizeTimeout)
+	job := &OptimizeJob{UserID: userID, Status: OptimizeJobQueued, CreatedAt: time.Now(), cancel: cancel}
+	optimizeJobs[userID] = job
+	go s.runOptimize(ctx, job)
+	return job.snapshot(), nil
+}
+
+// OptimizeStatus 用户最近一次拟合任务的状态，没有任务时返回 nil
+func (s *SRSService) OptimizeStatus(userID uint) *OptimizeJob {
+	optimizeJobsMu.Lock()
+	defer optimizeJobsMu.Unlock()
+	if job := optimizeJobs[userID]; job != nil {
+		return job.snapshot()
+	}
+	return nil
+}
+
+// CancelOptimize 取消进行中的拟合任务
+func (s *SRSService) CancelOptimize(userID uint) error {
+	optimizeJobsMu.Lock()
+	defer optimizeJobsMu.Unlock()
+	job := optimizeJobs[userID]
+	if job == nil || job.finished() {
+		return ErrOptimizeNotFound
+	}
+	job.cancel()
+	return nil
+}
+
+func (s *SRSService) runOptimize(ctx context.Context, job *OptimizeJob) {
+	defer job.cancel()
+
+	// 排队等待空闲名额，排队期间也可以取消
+	select {
+	case optimizeSlots <- struct{}{}:
+		defer func() { <-optimizeSlots }()
+	case <-ctx.Done():
+		s.finishOptimize(job, nil, ctx.Err())
+		return
+	}
+
+	optimizeJobsMu.Lock()
+	now := time.Now()
+	job.Status = OptimizeJobRunning
+	job.StartedAt = &now
+	optimizeJobsMu.Unlock()
+
+	outcome, err := s.Optimize(ctx, job.UserID, srs.DefaultOptimizeOptions(), false)
+	s.finishOptimize(job, outcome, err)
+}
+
+func (s *SRSService) finishOptimize(job *OptimizeJob, outcome *OptimizeOutcome, err error) {
+	optimizeJobsMu.Lock()
+	defer optimizeJobsMu.Unlock()
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Outcome = outcome
+	switch {
+	case err == nil:
+		job.Status = OptimizeJobDone
+	case errors.Is(err, context.Canceled):
+		job.Status = OptimizeJobCancelled
+		job.Error = "已取消"
+	case errors.Is(err, context.DeadlineExceeded):
+		job.Status = OptimizeJobFailed
+		job.Error = fmt.Sprintf("拟合超时（超过 %v）", OptimizeTimeout)
+	default:
+		job.Status = OptimizeJobFailed
+		job.Error = err.Error()
+	}
+	if job.Status == OptimizeJobFailed {
+		log.Printf("⚠️ 用户 %d 的 FSRS 参数拟合失败: %s", job.UserID, job.Error)
+	}
+}
+
+// snapshot 复制任务状态（调用方持有 optimizeJobsMu），避免返回后被后台任务修改
+func (j *OptimizeJob) snapshot() *OptimizeJob {
+	c := *j
+	c.cancel = nil
+	return &c
+}
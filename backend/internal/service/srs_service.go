@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/srs"
+
+	"gorm.io/gorm"
+)
+
+// 每日复习上限默认值
+const (
+	DefaultNewCardsPerDay = 20
+	DefaultReviewsPerDay  = 200
+)
+
+// SRSService 间隔重复算法设置服务
+// 负责按用户选择调度器（SM-2 / FSRS），以及切换算法时按复习历史重放迁移卡片
+type SRSService struct {
+	db      *gorm.DB
+	srsRepo *repository.SRSRepository
+}
+
+func NewSRSService(db *gorm.DB) *SRSService {
+	return &SRSService{
+		db:      db,
+		srsRepo: repository.NewSRSRepository(db),
+	}
+}
+
+// SRSSettings 用户的算法设置
+type SRSSettings struct {
+	Algorithm        string     `json:"algorithm"`
+	Weights          []float64  `json:"weights"`
+	RequestRetention float64    `json:"request_retention"`
+	MaximumInterval  int        `json:"maximum_interval"`
+	IsDefaultWeights bool       `json:"is_default_weights"` // 是否使用 FSRS 默认参数
+	MigratedAt       *time.Time `json:"migrated_at,omitempty"`
+
+	NewCardsPerDay  int    `json:"new_cards_per_day"`
+	ReviewsPerDay   int    `json:"reviews_per_day"`
+	LearningSteps   string `json:"learning_steps"`
+	RelearningSteps string `json:"relearning_steps"`
+
+	OptimizedAt  *time.Time          `json:"optimized_at,omitempty"`
+	Optimization *srs.OptimizeResult `json:"optimization,omitempty"` // 最近一次参数拟合报告
+}
+
+// UpdateSRSSettingsRequest 更新算法设置请求
+// 所有字段可选，未传的字段保持原值
+type UpdateSRSSettingsRequest struct {
+	Algorithm        string    `json:"algorithm" binding:"omitempty,oneof=sm2 fsrs"`
+	Weights          []float64 `json:"weights"`
+	RequestRetention float64   `json:"request_retention"` // 0.7-0.99
+	MaximumInterval  int       `json:"maximum_interval"`
+
+	NewCardsPerDay  *int    `json:"new_cards_per_day" binding:"omitempty,min=0,max=9999"`
+	ReviewsPerDay   *int    `json:"reviews_per_day" binding:"omitempty,min=0,max=9999"`
+	LearningSteps   *string `json:"learning_steps"`   // 如 "1m,10m,1d"，空字符串表示不使用学习步骤
+	RelearningSteps *string `json:"relearning_steps"` // 同上
+}
+
+// MigrateResult 卡片迁移结果
+type MigrateResult struct {
+	Algorithm     string `json:"algorithm"`
+	MigratedCards int    `json:"migrated_cards"` // 按历史重放的卡片数
+	ReplayedLogs  int    `json:"replayed_logs"`  // 重放的复习记录数
+}
+
+// GetSettings 获取用户的算法设置（未设置时返回 SM-2 + FSRS 默认参数）
+func (s *SRSService) GetSettings(userID uint) (*SRSSettings, error) {
+	params, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toSRSSettings(params), nil
+}
+
+// SchedulerFor 获取用户使用的调度器，读取设置失败时回退为 SM-2
+func (s *SRSService) SchedulerFor(userID uint) srs.Scheduler {
+	params, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		log.Printf("⚠️ 获取用户 %d 的复习算法设置失败，使用 SM-2: %v", userID, err)
+		return srs.SM2{}
+	}
+	settings := toSRSSettings(params)
+	return settings.scheduler()
+}
+
+// UpdateSettings 更新算法设置
+// 切换算法或修改 FSRS 参数时，会用新调度器重放全部复习历史，重新计算每张卡片的记忆状态
+func (s *SRSService) UpdateSettings(userID uint, req *UpdateSRSSettingsRequest) (*SRSSettings, *MigrateResult, error) {
+	if len(req.Weights) > 0 && len(req.Weights) != srs.FSRSWeightCount {
+		return nil, nil, fmt.Errorf("FSRS 参数需要 %d 个", srs.FSRSWeightCount)
+	}
+	if req.RequestRetention != 0 && (req.RequestRetention < srs.MinRequestRetention || req.RequestRetention > srs.MaxRequestRetention) {
+		return nil, nil, fmt.Errorf("期望保持率需在 %.2f-%.2f 之间", srs.MinRequestRetention, srs.MaxRequestRetention)
+	}
+
+	for _, steps := range []*string{req.LearningSteps, req.RelearningSteps} {
+		if steps != nil {
+			if _, err := srs.ParseSteps(*steps); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	current, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := toSRSSettings(current)
+
+	params := newSRSParams(userID, before)
+	if current != nil {
+		*params = *current
+	}
+	if req.Algorithm != "" {
+		params.Algorithm = req.Algorithm
+	}
+	if len(req.Weights) > 0 {
+		data, _ := json.Marshal(req.Weights)
+		params.Weights = string(data)
+	}
+	if req.RequestRetention != 0 {
+		params.RequestRetention = req.RequestRetention
+	}
+	if req.MaximumInterval != 0 {
+		params.MaximumInterval = req.MaximumInterval
+	}
+	if req.NewCardsPerDay != nil {
+		params.NewCardsPerDay = *req.NewCardsPerDay
+	}
+	if req.ReviewsPerDay != nil {
+		params.ReviewsPerDay = *req.ReviewsPerDay
+	}
+	if req.LearningSteps != nil {
+		steps, _ := srs.ParseSteps(*req.LearningSteps)
+		params.LearningSteps = srs.FormatSteps(steps)
+	}
+	if req.RelearningSteps != nil {
+		steps, _ := srs.ParseSteps(*req.RelearningSteps)
+		params.RelearningSteps = srs.FormatSteps(steps)
+	}
+	after := toSRSSettings(params)
+	params.RequestRetention = after.RequestRetention
+	params.MaximumInterval = after.MaximumInterval
+
+	var result *MigrateResult
+	if needsMigration(before, after) {
+		result, err = s.migrate(userID, after.scheduler())
+		if err != nil {
+			return nil, nil, fmt.Errorf("迁移卡片失败: %w", err)
+		}
+		now := time.Now()
+		params.MigratedAt = &now
+	}
+
+	if err := s.srsRepo.SaveParams(params); err != nil {
+		return nil, nil, err
+	}
+	return toSRSSettings(params), result, nil
+}
+
+// MigrateCards 用当前算法重放复习历史，重新计算用户所有卡片的记忆状态
+func (s *SRSService) MigrateCards(userID uint) (*MigrateResult, error) {
+	current, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings := toSRSSettings(current)
+
+	result, err := s.migrate(userID, settings.scheduler())
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		now := time.Now()
+		current.MigratedAt = &now
+		if err := s.srsRepo.SaveParams(current); err != nil {
+			log.Printf("⚠️ 记录用户 %d 的迁移时间失败: %v", userID, err)
+		}
+	}
+	return result, nil
+}
+
+// OptimizeOutcome 参数拟合结果
+type OptimizeOutcome struct {
+	UserID    uint                `json:"user_id"`
+	Result    *srs.OptimizeResult `json:"result"`
+	Applied   bool                `json:"applied"`             // 是否保存了新参数（拟合后损失下降才保存）
+	Migration *MigrateResult      `json:"migration,omitempty"` // 使用 FSRS 的用户保存新参数后会重新计算卡片
+}
+
+// Optimize 根据用户的复习历史拟合 FSRS 参数
+// 拟合后对数损失下降时保存新参数；用户当前使用 FSRS 时同时按新参数重放迁移卡片。
+// dryRun 为 true 时只计算不保存；ctx 取消时中止拟合。
+func (s *SRSService) Optimize(ctx context.Context, userID uint, opts srs.OptimizeOptions, dryRun bool) (*OptimizeOutcome, error) {
+	current, err := s.srsRepo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings := toSRSSettings(current)
+
+	reviews, err := s.srsRepo.GetReviewLogs(userID)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]srs.ReviewLog, 0, len(reviews))
+	for _, r := range reviews {
+		l := srs.ReviewLog{CardID: r.VocabularyID, Quality: r.Quality, At: r.CreatedAt}
+		if r.ResponseTimeMs != nil {
+			l.ResponseTimeMs = *r.ResponseTimeMs
+		}
+		logs = append(logs, l)
+	}
+
+	result, err := srs.Optimize(ctx, logs, settings.Weights, opts)
+	if err != nil {
+		return nil, err
+	}
+	outcome := &OptimizeOutcome{UserID: userID, Result: result}
+	if dryRun {
+		return outcome, nil
+	}
+
+	params := current
+	if params == nil {
+		params = newSRSParams(userID, settings)
+	}
+	if result.Improved {
+		data, _ := json.Marshal(result.Weights)
+		params.Weights = string(data)
+		outcome.Applied = true
+
+		if settings.Algorithm == srs.AlgorithmFSRS {
+			optimized := *settings
+			optimized.Weights = result.Weights
+			outcome.Migration, err = s.migrate(userID, optimized.scheduler())
+			if err != nil {
+				return nil, fmt.Errorf("迁移卡片失败: %w", err)
+			}
+			now := time.Now()
+			params.MigratedAt = &now
+		}
+	}
+
+	report, _ := json.Marshal(result)
+	now := time.Now()
+	params.OptimizedAt = &now
+	params.OptimizeReport = string(report)
+	if err := s.srsRepo.SaveParams(params); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 用户 %d 的 FSRS 参数拟合完成: samples=%d, log_loss %.4f -> %.4f, applied=%v",
+		userID, result.After.Samples, result.Before.LogLoss, result.After.LogLoss, outcome.Applied)
+	return outcome, nil
+}
+
+// migrate 按生词分组重放复习记录，没有复习记录的卡片保持不变
+// scheduler 需要与复习时使用的一致（SRSSettings.scheduler，含学习步骤），否则学习中的卡片会被直接按长期间隔安排
+func (s *SRSService) migrate(userID uint, scheduler srs.Scheduler) (*MigrateResult, error) {
+	logs, err := s.srsRepo.GetReviewLogs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[uint][]srs.Review)
+	for _, l := range logs {
+		grouped[l.VocabularyID] = append(grouped[l.VocabularyID], srs.Review{Quality: l.Quality, At: l.CreatedAt})
+	}
+
+	states := make(map[uint]repository.CardState, len(grouped))
+	for vocabID, reviews := range grouped {
+		card := srs.Replay(scheduler, reviews)
+		states[vocabID] = repository.CardState{
+			EaseFactor:   card.EaseFactor,
+			IntervalDays: card.IntervalDays,
+			Repetitions:  card.Repetitions,
+			MasteryLevel: card.MasteryLevel,
+			Stability:    card.Stability,
+			Difficulty:   card.Difficulty,
+			Lapses:       card.Lapses,
+			State:        srs.StateOf(card),
+			Step:         card.Step,
+			LastReviewAt: card.LastReviewAt,
+			NextReviewAt: card.NextReviewAt,
+		}
+	}
+
+	if err := s.srsRepo.UpdateCardStates(userID, states); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 用户 %d 的卡片已按 %s 重放迁移: %d 张卡片, %d 条复习记录", userID, scheduler.Name(), len(states), len(logs))
+	return &MigrateResult{
+		Algorithm:     scheduler.Name(),
+		MigratedCards: len(states),
+		ReplayedLogs:  len(logs),
+	}, nil
+}
+
+func needsMigration(before, after *SRSSettings) bool {
+	if before.Algorithm != after.Algorithm {
+		return true
+	}
+	if after.Algorithm != srs.AlgorithmFSRS {
+		return false
+	}
+	if before.RequestRetention != after.RequestRetention || before.MaximumInterval != after.MaximumInterval {
+		return true
+	}
+	for i := range after.Weights {
+		if before.Weights[i] != after.Weights[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// newSRSParams 用当前生效的设置创建一条设置记录（用户首次保存设置时）
+func newSRSParams(userID uint, settings *SRSSettings) *model.UserSRSParams {
+	return &model.UserSRSParams{
+		UserID:           userID,
+		Algorithm:        settings.Algorithm,
+		RequestRetention: settings.RequestRetention,
+		MaximumInterval:  settings.MaximumInterval,
+		NewCardsPerDay:   settings.NewCardsPerDay,
+		ReviewsPerDay:    settings.ReviewsPerDay,
+		LearningSteps:    settings.LearningSteps,
+		RelearningSteps:  settings.RelearningSteps,
+	}
+}
+
+func toSRSSettings(params *model.UserSRSParams) *SRSSettings {
+	defaults := srs.DefaultFSRSParams()
+	settings := &SRSSettings{
+		Algorithm:        srs.AlgorithmSM2,
+		Weights:          defaults.Weights,
+		RequestRetention: defaults.RequestRetention,
+		MaximumInterval:  defaults.MaximumInterval,
+		IsDefaultWeights: true,
+		NewCardsPerDay:   DefaultNewCardsPerDay,
+		ReviewsPerDay:    DefaultReviewsPerDay,
+		LearningSteps:    srs.FormatSteps(srs.DefaultLearningSteps),
+		RelearningSteps:  srs.FormatSteps(srs.DefaultRelearningSteps),
+	}
+	if params == nil {
+		return settings
+	}
+
+	// 上限允许为 0（当天不学新卡片）；步骤允许为空（不使用学习步骤）
+	settings.NewCardsPerDay = params.NewCardsPerDay
+	settings.ReviewsPerDay = params.ReviewsPerDay
+	settings.LearningSteps = params.LearningSteps
+	settings.RelearningSteps = params.RelearningSteps
+
+	if params.Algorithm == srs.AlgorithmFSRS {
+		settings.Algorithm = srs.AlgorithmFSRS
+	}
+	var weights []float64
+	if params.Weights != "" && json.Unmarshal([]byte(params.Weights), &weights) == nil && len(weights) == srs.FSRSWeightCount {
+		settings.Weights = weights
+		settings.IsDefaultWeights = false
+	}
+	normalized := srs.FSRSParams{
+		Weights:          settings.Weights,
+		RequestRetention: params.RequestRetention,
+		MaximumInterval:  params.MaximumInterval,
+	}.Normalize()
+	settings.RequestRetention = normalized.RequestRetention
+	settings.MaximumInterval = normalized.MaximumInterval
+	settings.MigratedAt = params.MigratedAt
+	settings.OptimizedAt = params.OptimizedAt
+	if params.OptimizeReport != "" {
+		var report srs.OptimizeResult
+		if json.Unmarshal([]byte(params.OptimizeReport), &report) == nil {
+			settings.Optimization = &report
+		}
+	}
+	return settings
+}
+
+// scheduler 按设置创建调度器（长期算法 + 学习步骤）
+func (st *SRSSettings) scheduler() srs.Scheduler {
+	learning, err := srs.ParseSteps(st.LearningSteps)
+	if err != nil {
+		learning = srs.DefaultLearningSteps
+	}
+	relearning, err := srs.ParseSteps(st.RelearningSteps)
+	if err != nil {
+		relearning = srs.DefaultRelearningSteps
+	}
+	return srs.WithSteps(srs.New(st.Algorithm, st.fsrsParams()), learning, relearning)
+}
+
+func (st *SRSSettings) fsrsParams() srs.FSRSParams {
+	return srs.FSRSParams{
+		Weights:          st.Weights,
+		RequestRetention: st.RequestRetention,
+		MaximumInterval:  st.MaximumInterval,
+	}
+}
+
+// cardFromVocabulary 生词转换为调度器使用的卡片
+func cardFromVocabulary(v *model.Vocabulary) srs.Card {
+	return srs.Card{
+		EaseFactor:   v.EaseFactor,
+		IntervalDays: v.IntervalDays,
+		Repetitions:  v.Repetitions,
+		MasteryLevel: v.MasteryLevel,
+		Stability:    v.Stability,
+		Difficulty:   v.Difficulty,
+		Lapses:       v.Lapses,
+		State:        v.SrsState,
+		Step:         v.LearningStep,
+		LastReviewAt: v.LastReviewAt,
+		NextReviewAt: v.NextReviewAt,
+	}
+}
+
+// applyCardToVocabulary 把调度结果写回生词
+func applyCardToVocabulary(v *model.Vocabulary, card srs.Card) {
+	v.EaseFactor = card.EaseFactor
+	v.IntervalDays = card.IntervalDays
+	v.Repetitions = card.Repetitions
+	v.MasteryLevel = card.MasteryLevel
+	v.Stability = card.Stability
+	v.Difficulty = card.Difficulty
+	v.Lapses = card.Lapses
+	v.SrsState = srs.StateOf(card)
+	v.LearningStep = card.Step
+	v.LastReviewAt = card.LastReviewAt
+	v.NextReviewAt = card.NextReviewAt
+}
@@ -0,0 +1,471 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StreakFreezePrice    = 300 // 冻结卡价格（积分）
+	StreakFreezeMaxOwned = 2   // 冻结卡最多持有数量
+
+	streakFreezeMinDays = 3   // 中断的连续学习至少达到该天数才自动使用冻结卡
+	streakDefaultDays   = 365 // 默认统计最近多少天的连续学习
+	streakMaxDays       = 730
+	streakRecentDays    = 7   // 概览中返回最近几天自动使用的冻结卡
+	streakSweepBatch    = 200 // 后台任务每批处理的用户数
+)
+
+// 连续学习中某一天的状态
+const (
+	streakDayActive = "active" // 完成了学习
+)
+
+// 连续学习错误
+var (
+	ErrInvalidStreakKind  = errors.New("无效的连续学习类型")
+	ErrStreakFreezeLimit  = fmt.Errorf("冻结卡最多持有 %d 张", StreakFreezeMaxOwned)
+	ErrStreakNotBroken    = errors.New("该日期已完成或已保护，无需修复")
+	ErrInvalidRepairDate  = fmt.Errorf("只能修复最近 %d 天内（不含今天）的日期，格式为 YYYY-MM-DD", MakeupCardMaxDays)
+	ErrNoMakeupCards      = errors.New("补签卡不足，请先购买")
+	ErrNotEnoughPoints    = errors.New("积分不足")
+	ErrInvalidFreezeCount = errors.New("购买数量无效")
+)
+
+// StreakService 连续学习服务
+// 连续学习可以按签到、完成当天复习、学习时长、默写等类型统计，学习日期直接从各业务表查询。
+// 冻结卡：某天没有任何类型的学习时由后台任务自动使用（FreezeBrokenStreaks），当天所有类型的连续学习都不中断；
+// 补签卡：手动修复最近几天某一种连续学习的中断（签到类型即原有的补签）。
+// 注意：签到积分奖励使用的 ContinuousCheckIns 仍按签到记录计算，冻结卡不影响签到奖励。
+type StreakService struct {
+	db              *gorm.DB
+	repo            *repository.StreakRepository
+	userPointsRepo  *repository.UserPointsRepository
+	pointRecordRepo *repository.PointRecordRepository
+	checkInService  *CheckInService
+	calendar        *CalendarService
+}
+
+func NewStreakService(db *gorm.DB, calendar *CalendarService) *StreakService {
+	return &StreakService{
+		db:              db,
+		repo:            repository.NewStreakRepository(db),
+		userPointsRepo:  repository.NewUserPointsRepository(db),
+		pointRecordRepo: repository.NewPointRecordRepository(db),
+		checkInService:  NewCheckInService(db, calendar),
+		calendar:        calendar,
+	}
+}
+
+// StreakSegment 一段连续学习
+type StreakSegment struct {
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Days       int    `json:"days"`
+	FrozenDays int    `json:"frozen_days"` // 其中使用冻结卡或补签卡保护的天数
+}
+
+// StreakView 某种连续学习的状态
+type StreakView struct {
+	Kind        string          `json:"kind"`
+	Name        string          `json:"name"`
+	Current     int             `json:"current"`      // 当前连续天数（今天还没完成时算到昨天）
+	Longest     int             `json:"longest"`      // 统计范围内最长连续天数
+	ActiveToday bool            `json:"active_today"` // 今天已完成
+	AtRisk      bool            `json:"at_risk"`      // 今天还没完成，过了今天将中断（有冻结卡时自动使用）
+	LastActive  string          `json:"last_active,omitempty"`
+	Segments    []StreakSegment `json:"segments,omitempty"` // 连续学习历史（最近的在前），只在详情中返回
+}
+
+// StreakOverview 所有类型的连续学习
+type StreakOverview struct {
+	Streaks        []StreakView `json:"streaks"`
+	StreakFreezes  int          `json:"streak_freezes"`
+	MakeupCards    int          `json:"makeup_cards"`
+	FreezePrice    int          `json:"freeze_price"`
+	FreezeMaxOwned int          `json:"freeze_max_owned"`
+	RecentFrozen   []string     `json:"recent_frozen,omitempty"` // 最近几天自动使用冻结卡保护的日期
+}
+
+// GetOverview 获取所有类型的连续学习
+func (s *StreakService) GetOverview(userID uint) (*StreakOverview, error) {
+	overview := &StreakOverview{
+		Streaks:        make([]StreakView, 0, len(model.StreakKinds)),
+		FreezePrice:    StreakFreezePrice,
+		FreezeMaxOwned: StreakFreezeMaxOwned,
+	}
+	for _, kind := range model.StreakKinds {
+		view, err := s.get(userID, kind, streakDefaultDays, false)
+		if err != nil {
+			return nil, err
+		}
+		overview.Streaks = append(overview.Streaks, *view)
+	}
+	if points, err := s.userPointsRepo.GetByUserID(userID); err == nil {
+		overview.StreakFreezes = points.StreakFreezes
+		overview.MakeupCards = points.MakeupCards
+	}
+	since := s.calendar.Today(userID).AddDate(0, 0, -streakRecentDays)
+	protections, err := s.repo.ListProtections(userID, model.StreakAll, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range protections {
+		if p.Source == model.StreakProtectFreeze {
+			overview.RecentFrozen = append(overview.RecentFrozen, p.ProtectDate.Format("2006-01-02"))
+		}
+	}
+	return overview, nil
+}
+
+// GetStreak 获取某种连续学习及最近 days 天内的历史
+func (s *StreakService) GetStreak(userID uint, kind string, days int) (*StreakView, error) {
+	if model.StreakKindNames[kind] == "" {
+		return nil, ErrInvalidStreakKind
+	}
+	if days <= 0 {
+		days = streakDefaultDays
+	}
+	if days > streakMaxDays {
+		days = streakMaxDays
+	}
+	return s.get(userID, kind, days, true)
+}
+
+// BuyFreeze 用积分购买冻结卡
+func (s *StreakService) BuyFreeze(userID uint, count int) (map[string]interface{}, error) {
+	if count <= 0 {
+		count = 1
+	}
+	if count > StreakFreezeMaxOwned {
+		return nil, ErrInvalidFreezeCount
+	}
+
+	userPoints, err := s.userPointsRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	if userPoints.StreakFreezes+count > StreakFreezeMaxOwned {
+		return nil, ErrStreakFreezeLimit
+	}
+	totalCost := StreakFreezePrice * count
+	if userPoints.CurrentPoints < totalCost {
+		return nil, fmt.Errorf("%w，需要%d积分，当前只有%d积分", ErrNotEnoughPoints, totalCost, userPoints.CurrentPoints)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		latest, err := s.userPointsRepo.DeductPoints(tx, userID, totalCost)
+		if err != nil {
+			return fmt.Errorf("扣除积分失败: %w", err)
+		}
+		added, err := s.userPointsRepo.AddStreakFreezes(tx, userID, count, StreakFreezeMaxOwned)
+		if err != nil {
+			return fmt.Errorf("增加冻结卡失败: %w", err)
+		}
+		if !added {
+			return ErrStreakFreezeLimit
+		}
+		return s.pointRecordRepo.Create(tx, &model.PointRecord{
+			UserID:        userID,
+			Points:        -totalCost,
+			Type:          "streak_freeze_purchase",
+			Description:   fmt.Sprintf("购买%d张冻结卡", count),
+			BalanceBefore: userPoints.CurrentPoints,
+			BalanceAfter:  latest.CurrentPoints,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latestUserPoints, err := s.userPointsRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success":        true,
+		"message":        fmt.Sprintf("成功购买%d张冻结卡", count),
+		"cards_bought":   count,
+		"points_spent":   totalCost,
+		"streak_freezes": latestUserPoints.StreakFreezes,
+		"current_points": latestUserPoints.CurrentPoints,
+	}, nil
+}
+
+// Repair 使用补签卡修复某种连续学习在某天的中断
+func (s *StreakService) Repair(userID uint, kind, dateStr string) (map[string]interface{}, error) {
+	if model.StreakKindNames[kind] == "" {
+		return nil, ErrInvalidStreakKind
+	}
+
+	// 签到直接补签签到记录（同时更新签到统计）
+	if kind == model.StreakCheckIn {
+		result, err := s.checkInService.UseMakeupCard(userID, dateStr)
+		if err != nil {
+			return nil, err
+		}
+		if view, err := s.get(userID, kind, streakDefaultDays, false); err == nil {
+			result["streak"] = view
+		}
+		return result, nil
+	}
+
+	today := s.calendar.Today(userID)
+	date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+	if err != nil {
+		return nil, ErrInvalidRepairDate
+	}
+	if diff := daysBetween(date, today); diff < 1 || diff > MakeupCardMaxDays {
+		return nil, ErrInvalidRepairDate
+	}
+	marks, err := s.dayMarks(userID, kind, date)
+	if err != nil {
+		return nil, err
+	}
+	if marks[dateStr] != "" {
+		return nil, ErrStreakNotBroken
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userPointsRepo.DeductMakeupCards(tx, userID, 1); err != nil {
+			if errors.Is(err, gorm.ErrInvalidValue) || errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoMakeupCards
+			}
+			return fmt.Errorf("扣除补签卡失败: %w", err)
+		}
+		created, err := s.repo.CreateProtection(tx, &model.StreakProtection{
+			UserID:      userID,
+			Kind:        kind,
+			ProtectDate: date,
+			Source:      model.StreakProtectRepair,
+		})
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrStreakNotBroken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.get(userID, kind, streakDefaultDays, false)
+	if err != nil {
+		return nil, err
+	}
+	makeupCards := 0
+	if points, err := s.userPointsRepo.GetByUserID(userID); err == nil {
+		makeupCards = points.MakeupCards
+	}
+	return map[string]interface{}{
+		"success":      true,
+		"message":      fmt.Sprintf("成功修复 %s 的%s", dateStr, model.StreakKindNames[kind]),
+		"repair_date":  dateStr,
+		"makeup_cards": makeupCards,
+		"streak":       view,
+	}, nil
+}
+
+// FreezeBrokenStreaks 为持有冻结卡的用户自动使用冻结卡（后台任务定时执行）
+func (s *StreakService) FreezeBrokenStreaks(now time.Time) error {
+	var lastID uint
+	for {
+		userIDs, err := s.userPointsRepo.ListUserIDsWithStreakFreezes(lastID, streakSweepBatch)
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if _, err := s.autoFreeze(userID); err != nil {
+				log.Printf("⚠️ 自动使用冻结卡失败 (user=%d): %v", userID, err)
+			}
+		}
+		if len(userIDs) < streakSweepBatch {
+			return nil
+		}
+		lastID = userIDs[len(userIDs)-1]
+	}
+}
+
+// autoFreeze 自动使用冻结卡：从昨天往前连续几天没有任何类型的学习，中断前至少学习了 streakFreezeMinDays 天，
+// 且冻结卡足够覆盖这几天时，为这几天各使用一张冻结卡。
+// 冻结卡保护当天所有类型，所以只要某天完成了任何一种学习就不使用（那一天只中断了部分类型，可以用补签卡修复）。
+// 返回本次保护的日期
+func (s *StreakService) autoFreeze(userID uint) ([]string, error) {
+	points, err := s.userPointsRepo.GetByUserID(userID)
+	if err != nil || points.StreakFreezes <= 0 {
+		return nil, err
+	}
+	yesterday := s.calendar.Today(userID).AddDate(0, 0, -1)
+	since := yesterday.AddDate(0, 0, -(StreakFreezeMaxOwned + streakFreezeMinDays))
+	days, err := s.activeDays(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从昨天往前数中断的天数，再数中断前的连续天数
+	var gap []time.Time
+	day := yesterday
+	for ; !day.Before(since) && !days[day.Format("2006-01-02")]; day = day.AddDate(0, 0, -1) {
+		gap = append(gap, day)
+	}
+	if len(gap) == 0 || len(gap) > points.StreakFreezes {
+		return nil, nil
+	}
+	run := 0
+	for ; !day.Before(since) && days[day.Format("2006-01-02")]; day = day.AddDate(0, 0, -1) {
+		run++
+	}
+	if run < streakFreezeMinDays {
+		return nil, nil
+	}
+
+	var frozen []string
+	for _, date := range gap {
+		used, err := s.useFreeze(userID, date)
+		if err != nil {
+			return frozen, err
+		}
+		if used {
+			frozen = append(frozen, date.Format("2006-01-02"))
+			log.Printf("✅ 已自动使用冻结卡 (user=%d, date=%s)", userID, date.Format("2006-01-02"))
+		}
+	}
+	return frozen, nil
+}
+
+// activeDays since 之后完成了任何一种学习、或已使用冻结卡的日期
+func (s *StreakService) activeDays(userID uint, since time.Time) (map[string]bool, error) {
+	loc := s.calendar.Location(userID)
+	days := make(map[string]bool)
+	for _, kind := range model.StreakKinds {
+		dates, err := s.repo.ActiveDates(userID, kind, since, loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dates {
+			days[d.Format("2006-01-02")] = true
+		}
+	}
+	protections, err := s.repo.ListProtections(userID, model.StreakAll, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range protections {
+		days[p.ProtectDate.Format("2006-01-02")] = true
+	}
+	return days, nil
+}
+
+// useFreeze 为某天使用一张冻结卡（当天已冻结或没有冻结卡时返回 false）
+func (s *StreakService) useFreeze(userID uint, date time.Time) (bool, error) {
+	used := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		created, err := s.repo.CreateProtection(tx, &model.StreakProtection{
+			UserID:      userID,
+			Kind:        model.StreakAll,
+			ProtectDate: date,
+			Source:      model.StreakProtectFreeze,
+		})
+		if err != nil || !created {
+			return err
+		}
+		deducted, err := s.userPointsRepo.DeductStreakFreeze(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !deducted {
+			return ErrStreakFreezeLimit // 回滚保护记录
+		}
+		used = true
+		return nil
+	})
+	if errors.Is(err, ErrStreakFreezeLimit) {
+		return false, nil
+	}
+	return used, err
+}
+
+// dayMarks 某种连续学习在 since 之后每天的状态：active、freeze、repair，没有学习的日期不在结果中
+func (s *StreakService) dayMarks(userID uint, kind string, since time.Time) (map[string]string, error) {
+	dates, err := s.repo.ActiveDates(userID, kind, since, s.calendar.Location(userID))
+	if err != nil {
+		return nil, err
+	}
+	protections, err := s.repo.ListProtections(userID, kind, since)
+	if err != nil {
+		return nil, err
+	}
+	marks := make(map[string]string, len(dates)+len(protections))
+	for _, d := range dates {
+		marks[d.Format("2006-01-02")] = streakDayActive
+	}
+	for _, p := range protections {
+		if date := p.ProtectDate.Format("2006-01-02"); marks[date] == "" {
+			marks[date] = p.Source
+		}
+	}
+	return marks, nil
+}
+
+// get 计算最近 days 天内的连续学习
+func (s *StreakService) get(userID uint, kind string, days int, withSegments bool) (*StreakView, error) {
+	today := s.calendar.Today(userID)
+	since := today.AddDate(0, 0, -(days - 1))
+	marks, err := s.dayMarks(userID, kind, since)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &StreakView{Kind: kind, Name: model.StreakKindNames[kind]}
+	var segments []StreakSegment
+	var current *StreakSegment
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		mark := marks[date]
+		if mark == "" {
+			if !day.Equal(today) {
+				current = nil // 今天还没完成不算中断
+			}
+			continue
+		}
+		if mark == streakDayActive {
+			view.LastActive = date
+		}
+		if current == nil {
+			segments = append(segments, StreakSegment{Start: date})
+			current = &segments[len(segments)-1]
+		}
+		current.End = date
+		current.Days++
+		if mark != streakDayActive {
+			current.FrozenDays++
+		}
+	}
+
+	for _, seg := range segments {
+		if seg.Days > view.Longest {
+			view.Longest = seg.Days
+		}
+	}
+	view.ActiveToday = marks[today.Format("2006-01-02")] == streakDayActive
+	if n := len(segments); n > 0 && segments[n-1].End >= today.AddDate(0, 0, -1).Format("2006-01-02") {
+		view.Current = segments[n-1].Days
+	}
+	view.AtRisk = view.Current > 0 && !view.ActiveToday
+
+	if withSegments {
+		view.Segments = make([]StreakSegment, 0, len(segments))
+		for i := len(segments) - 1; i >= 0; i-- {
+			view.Segments = append(view.Segments, segments[i])
+		}
+	}
+	return view, nil
+}
@@ -1,8 +1,15 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 
@@ -14,21 +21,24 @@ type TitleService struct {
 	db             *gorm.DB
 	titleRepo      *repository.TitleRepository
 	userPointsRepo *repository.UserPointsRepository
-	vocabularyRepo *repository.VocabularyRepository
+	achievements   *AchievementService
+	calendar       *CalendarService
 }
 
-func NewTitleService(db *gorm.DB) *TitleService {
+func NewTitleService(db *gorm.DB, calendar *CalendarService) *TitleService {
 	return &TitleService{
 		db:             db,
 		titleRepo:      repository.NewTitleRepository(db),
 		userPointsRepo: repository.NewUserPointsRepository(db),
-		vocabularyRepo: repository.NewVocabularyRepository(),
+		achievements:   NewAchievementService(db, calendar),
+		calendar:       calendar,
 	}
 }
 
-// GetAllTitles 获取所有称号配置
+// GetAllTitles 获取所有称号配置（不含隐藏称号）
 func (s *TitleService) GetAllTitles() ([]model.TitleConfig, error) {
-	return s.titleRepo.GetAllTitleConfigs()
+	configs, err := s.titleRepo.GetAllTitleConfigs()
+	return visibleTitles(configs), err
 }
 
 // GetUserTitles 获取用户已获得的称号
@@ -38,9 +48,8 @@ func (s *TitleService) GetUserTitles(userID uint) ([]model.UserTitle, error) {
 
 // GetTitleProgress 获取用户的称号进度
 func (s *TitleService) GetTitleProgress(userID uint) ([]map[string]interface{}, error) {
-	// 1. 获取用户积分信息
-	userPoints, err := s.userPointsRepo.GetByUserID(userID)
-	if err != nil {
+	// 1. 确认用户有积分记录
+	if _, err := s.userPointsRepo.GetByUserID(userID); err != nil {
 		return nil, err
 	}
 
@@ -50,7 +59,12 @@ func (s *TitleService) GetTitleProgress(userID uint) ([]map[string]interface{},
 		return nil, err
 	}
 
-	// 3. 获取用户已拥有的称号
+	// 3. 补发已满足条件但还未获得的称号（例如事件之外达成的条件）
+	if _, err := s.achievements.CheckAll(userID); err != nil {
+		log.Printf("⚠️ 检查称号失败 (user=%d): %v", userID, err)
+	}
+
+	// 4. 获取用户已拥有的称号
 	userTitles, err := s.titleRepo.GetUserTitles(userID)
 	if err != nil {
 		return nil, err
@@ -69,72 +83,20 @@ func (s *TitleService) GetTitleProgress(userID uint) ([]map[string]interface{},
 	// 自动为老用户颁发"新手上路"称号（如果还没有的话）
 	s.ensureBeginnerTitle(userID, ownedTitles, allTitles)
 
-	// 4. 计算每个称号的进度
+	// 5. 计算每个称号的进度（自定义条件的称号没有进度）
+	progressByTitle, err := s.achievements.Progress(userID)
+	if err != nil {
+		log.Printf("⚠️ 计算称号进度失败 (user=%d): %v", userID, err)
+	}
+	tierProgress := buildTierProgress(allTitles, progressByTitle, ownedTitles)
+
+	now := s.calendar.Now()
 	var progressList []map[string]interface{}
-	for _, title := range allTitles {
+	for i := range allTitles {
+		title := &allTitles[i]
 		owned := ownedTitles[title.ID]
-
-		var currentValue int
-		var progress float64
-
-		// 根据条件类型获取当前进度
-		switch title.ConditionType {
-		case model.TitleConditionArticlesRead:
-			currentValue = userPoints.TotalArticlesRead
-		case model.TitleConditionDictationsCompleted:
-			currentValue = userPoints.TotalDictationsCompleted
-		case model.TitleConditionTotalPoints:
-			currentValue = userPoints.TotalPoints
-		case model.TitleConditionContinuousCheckIns:
-			currentValue = userPoints.ContinuousCheckIns
-		case model.TitleConditionVocabularyCount:
-			// 获取生词本收藏数
-			if stats, err := s.vocabularyRepo.GetStats(userID); err == nil {
-				currentValue = int(stats.Total)
-			}
-		case model.TitleConditionReviewCount:
-			// 获取复习次数 - 从生词本统计复习总次数
-			var reviewCount int64
-			s.db.Model(&model.VocabularyReview{}).Where("user_id = ?", userID).Count(&reviewCount)
-			currentValue = int(reviewCount)
-		case model.TitleConditionTotalDuration:
-			// 获取学习时长(分钟)
-			currentValue = int(userPoints.TotalDurationMinutes)
-		case model.TitleConditionPerfectStreak:
-			// 连续全对次数 - 需要从默写记录中获取，暂设为0
-			currentValue = 0 // TODO: 从默写记录中统计
-		case model.TitleConditionCustom:
-			currentValue = 0 // 自定义条件需要特殊处理
-		}
-
-		// 计算进度百分比
-		if title.ConditionValue > 0 {
-			progress = float64(currentValue) / float64(title.ConditionValue) * 100
-			if progress > 100 {
-				progress = 100
-			}
-		}
-
-		// 自动解锁：如果满足条件但还未拥有，自动授予称号
-		// 注意：beginner称号通过 ensureBeginnerTitle 单独处理
-		if !owned && title.ConditionType != model.TitleConditionCustom && currentValue >= title.ConditionValue {
-			userTitle := &model.UserTitle{
-				UserID:        userID,
-				TitleConfigID: title.ID,
-				AwardedAt:     time.Now(),
-				IsEquipped:    false,
-			}
-			if err := s.titleRepo.CreateUserTitle(s.db, userTitle); err == nil {
-				owned = true // 更新状态为已拥有
-			}
-		}
-
-		// 检查是否是刚颁发的beginner称号
-		if title.TitleKey == "beginner" && ownedTitles[title.ID] {
-			owned = true
-		}
-
-		progressList = append(progressList, map[string]interface{}{
+		progress := progressByTitle[title.ID]
+		item := map[string]interface{}{
 			"title_id":              title.ID,
 			"title_key":             title.TitleKey,
 			"title_name":            title.TitleName,
@@ -144,17 +106,104 @@ func (s *TitleService) GetTitleProgress(userID uint) ([]map[string]interface{},
 			"condition_type":        title.ConditionType,
 			"condition_value":       title.ConditionValue,
 			"condition_description": title.ConditionDescription,
-			"current_value":         currentValue,
-			"progress":              progress,
+			"current_value":         progress.CurrentValue,
+			"progress":              progress.Percent,
 			"owned":                 owned,
 			"is_equipped":           equippedTitles[title.ID],
 			"rarity":                title.Rarity,
-		})
+			"is_hidden":             title.IsHidden,
+			"available":             title.AvailableAt(now),
+			"start_at":              title.StartAt,
+			"end_at":                title.EndAt,
+			"reward_points":         title.RewardPoints,
+			"reward_makeup_cards":   title.RewardMakeupCards,
+			"reward_streak_freezes": title.RewardStreakFreezes,
+		}
+		if title.ConditionType == model.TitleConditionCompound {
+			if rule, err := ParseTitleRule(title); err == nil {
+				item["condition_rule"] = rule
+			}
+		}
+		if tp := tierProgress[title.FamilyKey]; tp != nil {
+			item["tier"] = title.Tier
+			item["tier_progress"] = tp
+		}
+
+		// 隐藏称号获得前只显示占位信息
+		if title.IsHidden && !owned {
+			item["title_name"] = "？？？"
+			item["title_icon"] = "❓"
+			item["description"] = "隐藏称号，达成条件后揭晓"
+			item["condition_value"] = 0
+			item["condition_description"] = ""
+			item["current_value"] = 0
+			item["progress"] = 0
+			delete(item, "condition_rule")
+		}
+		progressList = append(progressList, item)
 	}
 
 	return progressList, nil
 }
 
+// TitleTierStep 称号系列中的一个等级
+type TitleTierStep struct {
+	Tier      model.TitleTier `json:"tier"`
+	TitleID   uint            `json:"title_id"`
+	TitleName string          `json:"title_name"`
+	Value     int             `json:"value"` // 达到该等级需要的数值
+	Owned     bool            `json:"owned"`
+}
+
+// TitleTierProgress 称号系列的等级进度（各等级共用一个进度条）
+type TitleTierProgress struct {
+	FamilyKey    string          `json:"family_key"`
+	CurrentValue int             `json:"current_value"`
+	CurrentTier  model.TitleTier `json:"current_tier,omitempty"` // 已获得的最高等级
+	NextTier     model.TitleTier `json:"next_tier,omitempty"`    // 下一个未获得的等级，全部获得时为空
+	NextValue    int             `json:"next_value,omitempty"`
+	Progress     float64         `json:"progress"` // 到下一等级的进度（0-100），全部获得时为 100
+	Tiers        []TitleTierStep `json:"tiers"`    // 按等级从低到高
+}
+
+// buildTierProgress 按称号系列汇总等级进度（familyKey -> 进度），隐藏称号不参与
+func buildTierProgress(titles []model.TitleConfig, progress map[uint]TitleProgress, owned map[uint]bool) map[string]*TitleTierProgress {
+	families := make(map[string][]model.TitleConfig)
+	for _, t := range titles {
+		if t.FamilyKey != "" && t.Tier != "" && !t.IsHidden {
+			families[t.FamilyKey] = append(families[t.FamilyKey], t)
+		}
+	}
+
+	result := make(map[string]*TitleTierProgress, len(families))
+	for key, members := range families {
+		sort.Slice(members, func(i, j int) bool {
+			return model.TitleTierOrder[members[i].Tier] < model.TitleTierOrder[members[j].Tier]
+		})
+		tp := &TitleTierProgress{FamilyKey: key, Progress: 100, Tiers: make([]TitleTierStep, 0, len(members))}
+		for _, m := range members {
+			if v := progress[m.ID].CurrentValue; v > tp.CurrentValue {
+				tp.CurrentValue = v
+			}
+			step := TitleTierStep{Tier: m.Tier, TitleID: m.ID, TitleName: m.TitleName, Value: m.ConditionValue, Owned: owned[m.ID]}
+			tp.Tiers = append(tp.Tiers, step)
+			if step.Owned {
+				tp.CurrentTier = m.Tier
+			} else if tp.NextTier == "" {
+				tp.NextTier, tp.NextValue = m.Tier, m.ConditionValue
+			}
+		}
+		if tp.NextTier != "" && tp.NextValue > 0 {
+			tp.Progress = float64(tp.CurrentValue) / float64(tp.NextValue) * 100
+			if tp.Progress > 100 {
+				tp.Progress = 100
+			}
+		}
+		result[key] = tp
+	}
+	return result
+}
+
 // EquipTitle 佩戴称号
 func (s *TitleService) EquipTitle(userID, titleConfigID uint) error {
 	// 1. 检查用户是否拥有该称号
@@ -180,9 +229,21 @@ func (s *TitleService) GetEquippedTitle(userID uint) (*model.UserTitle, error) {
 	return s.titleRepo.GetEquippedTitle(userID)
 }
 
-// GetTitlesByCategory 根据分类获取称号
+// GetTitlesByCategory 根据分类获取称号（不含隐藏称号）
 func (s *TitleService) GetTitlesByCategory(category model.TitleCategory) ([]model.TitleConfig, error) {
-	return s.titleRepo.GetTitleConfigsByCategory(category)
+	configs, err := s.titleRepo.GetTitleConfigsByCategory(category)
+	return visibleTitles(configs), err
+}
+
+// visibleTitles 过滤掉隐藏称号（获得前不公开）
+func visibleTitles(configs []model.TitleConfig) []model.TitleConfig {
+	visible := make([]model.TitleConfig, 0, len(configs))
+	for _, cfg := range configs {
+		if !cfg.IsHidden {
+			visible = append(visible, cfg)
+		}
+	}
+	return visible
 }
 
 // ensureBeginnerTitle 确保用户拥有"新手上路"称号
@@ -210,10 +271,253 @@ func (s *TitleService) ensureBeginnerTitle(userID uint, ownedTitles map[uint]boo
 	userTitle := &model.UserTitle{
 		UserID:        userID,
 		TitleConfigID: beginnerTitle.ID,
-		AwardedAt:     time.Now(),
+		AwardedAt:     s.calendar.Now(),
 		IsEquipped:    false,
 	}
 	if err := s.titleRepo.CreateUserTitle(s.db, userTitle); err == nil {
 		ownedTitles[beginnerTitle.ID] = true // 更新map，让后续逻辑知道已拥有
 	}
 }
+
+// ============ 管理后台 ============
+
+var (
+	ErrInvalidTitleConfig  = errors.New("无效的称号配置")
+	ErrTitleConfigNotFound = errors.New("称号不存在")
+	ErrTitleKeyExists      = errors.New("称号标识已存在")
+)
+
+// titleKeyPattern 称号标识只允许小写字母、数字和下划线
+var titleKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// TitleConfigRequest 创建/更新称号配置的请求
+type TitleConfigRequest struct {
+	TitleKey             string                   `json:"title_key"`
+	TitleName            string                   `json:"title_name"`
+	TitleIcon            string                   `json:"title_icon"`
+	Description          string                   `json:"description"`
+	Category             model.TitleCategory      `json:"category"`
+	ConditionType        model.TitleConditionType `json:"condition_type"`
+	ConditionValue       int                      `json:"condition_value"`
+	ConditionDescription string                   `json:"condition_description"`
+	ConditionRule        *model.TitleRule         `json:"condition_rule"` // 条件类型为 compound 时必填
+	Rarity               model.TitleRarity        `json:"rarity"`
+	SortOrder            int                      `json:"sort_order"`
+	IsActive             *bool                    `json:"is_active"` // 不传时默认启用
+	IsHidden             bool                     `json:"is_hidden"`
+	FamilyKey            string                   `json:"family_key"`
+	Tier                 model.TitleTier          `json:"tier"`
+	StartAt              *time.Time               `json:"start_at"`
+	EndAt                *time.Time               `json:"end_at"`
+	RewardPoints         int                      `json:"reward_points"`
+	RewardMakeupCards    int                      `json:"reward_makeup_cards"`
+	RewardStreakFreezes  int                      `json:"reward_streak_freezes"`
+}
+
+// ListTitleConfigs 获取所有称号配置（含未启用和隐藏的）
+func (s *TitleService) ListTitleConfigs() ([]model.TitleConfig, error) {
+	return s.titleRepo.ListTitleConfigs()
+}
+
+// CreateTitleConfig 创建称号配置
+func (s *TitleService) CreateTitleConfig(req *TitleConfigRequest) (*model.TitleConfig, error) {
+	config := &model.TitleConfig{IsActive: true}
+	if err := s.applyTitleConfig(config, req); err != nil {
+		return nil, err
+	}
+	if err := s.titleRepo.CreateTitleConfig(config); err != nil {
+		return nil, err
+	}
+	InvalidateTitleConfigs()
+	log.Printf("✅ 创建称号配置: %s (id=%d)", config.TitleKey, config.ID)
+	return config, nil
+}
+
+// UpdateTitleConfig 更新称号配置
+func (s *TitleService) UpdateTitleConfig(id uint, req *TitleConfigRequest) (*model.TitleConfig, error) {
+	config, err := s.getTitleConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyTitleConfig(config, req); err != nil {
+		return nil, err
+	}
+	if err := s.titleRepo.UpdateTitleConfig(config); err != nil {
+		return nil, err
+	}
+	InvalidateTitleConfigs()
+	log.Printf("✅ 更新称号配置: %s (id=%d)", config.TitleKey, config.ID)
+	return config, nil
+}
+
+// DeleteTitleConfig 删除称号配置（用户已获得的称号保留）
+func (s *TitleService) DeleteTitleConfig(id uint) error {
+	config, err := s.getTitleConfig(id)
+	if err != nil {
+		return err
+	}
+	if err := s.titleRepo.DeleteTitleConfig(id); err != nil {
+		return err
+	}
+	InvalidateTitleConfigs()
+	log.Printf("✅ 删除称号配置: %s (id=%d)", config.TitleKey, config.ID)
+	return nil
+}
+
+func (s *TitleService) getTitleConfig(id uint) (*model.TitleConfig, error) {
+	config, err := s.titleRepo.GetTitleConfigByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTitleConfigNotFound
+	}
+	return config, err
+}
+
+// applyTitleConfig 校验请求并写入 config（config.ID 为 0 表示新建）
+func (s *TitleService) applyTitleConfig(config *model.TitleConfig, req *TitleConfigRequest) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidTitleConfig, fmt.Sprintf(format, args...))
+	}
+
+	req.TitleKey = strings.TrimSpace(req.TitleKey)
+	req.TitleName = strings.TrimSpace(req.TitleName)
+	req.FamilyKey = strings.TrimSpace(req.FamilyKey)
+	if !titleKeyPattern.MatchString(req.TitleKey) {
+		return invalid("称号标识只能包含小写字母、数字和下划线（最多50个字符）")
+	}
+	if req.TitleName == "" || utf8.RuneCountInString(req.TitleName) > 100 {
+		return invalid("称号名称不能为空且不超过100个字符")
+	}
+	switch req.Category {
+	case model.TitleCategoryReading, model.TitleCategoryDictation, model.TitleCategoryCheckIn,
+		model.TitleCategoryPoints, model.TitleCategorySpecial, model.TitleCategoryVocabulary,
+		model.TitleCategoryReview, model.TitleCategoryDuration, model.TitleCategoryAchievement:
+	default:
+		return invalid("无效的称号分类 %s", req.Category)
+	}
+	if req.Rarity == "" {
+		req.Rarity = model.TitleRarityCommon
+	}
+	switch req.Rarity {
+	case model.TitleRarityCommon, model.TitleRarityRare, model.TitleRarityEpic, model.TitleRarityLegendary:
+	default:
+		return invalid("无效的稀有度 %s", req.Rarity)
+	}
+
+	// 获取条件
+	conditionRule := ""
+	var rule model.TitleRule
+	switch req.ConditionType {
+	case model.TitleConditionCustom:
+		if req.ConditionRule != nil {
+			return invalid("自定义条件不需要 condition_rule")
+		}
+	case model.TitleConditionCompound:
+		if req.ConditionRule == nil {
+			return invalid("组合条件需要提供 condition_rule")
+		}
+		rule = *req.ConditionRule
+		if err := ValidateTitleRule(rule); err != nil {
+			return err
+		}
+		data, err := json.Marshal(req.ConditionRule)
+		if err != nil {
+			return err
+		}
+		conditionRule = string(data)
+	default:
+		rule = model.TitleRule{Metric: req.ConditionType, Value: req.ConditionValue}
+		if err := ValidateTitleRule(rule); err != nil {
+			return err
+		}
+		if req.ConditionValue <= 0 {
+			return invalid("条件数值必须大于0")
+		}
+		if req.ConditionRule != nil {
+			return invalid("单一指标条件不需要 condition_rule")
+		}
+	}
+
+	// 系列等级
+	if (req.FamilyKey == "") != (req.Tier == "") {
+		return invalid("称号系列和等级需要同时设置")
+	}
+	if req.FamilyKey != "" {
+		if !titleKeyPattern.MatchString(req.FamilyKey) {
+			return invalid("称号系列只能包含小写字母、数字和下划线（最多50个字符）")
+		}
+		if _, ok := model.TitleTierOrder[req.Tier]; !ok {
+			return invalid("无效的等级 %s", req.Tier)
+		}
+		if req.ConditionType == model.TitleConditionCustom || req.ConditionType == model.TitleConditionCompound {
+			return invalid("系列称号需要使用单一指标条件")
+		}
+		siblings, err := s.titleRepo.GetTitleConfigsByFamily(req.FamilyKey)
+		if err != nil {
+			return err
+		}
+		order := model.TitleTierOrder[req.Tier]
+		for _, sib := range siblings {
+			if sib.ID == config.ID {
+				continue
+			}
+			if sib.ConditionType != req.ConditionType {
+				return invalid("同一系列的称号需要使用相同的条件类型（%s）", sib.ConditionType)
+			}
+			sibOrder := model.TitleTierOrder[sib.Tier]
+			switch {
+			case sibOrder == order:
+				return invalid("系列中已有 %s 等级的称号（%s）", req.Tier, sib.TitleKey)
+			case sibOrder < order && sib.ConditionValue >= req.ConditionValue,
+				sibOrder > order && sib.ConditionValue <= req.ConditionValue:
+				return invalid("系列中等级越高条件数值需要越大（%s 等级为 %d）", sib.Tier, sib.ConditionValue)
+			}
+		}
+	}
+
+	// 有效期和奖励
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return invalid("结束时间需要晚于开始时间")
+	}
+	// 总积分、连续签到、连续全对和生词数只有当前值，无法只统计有效期内的部分
+	if req.StartAt != nil && req.ConditionType != model.TitleConditionCustom && !ruleOnlyWindowMetrics(rule) {
+		return invalid("限时称号只能使用阅读、默写、复习、签到次数和学习时长条件")
+	}
+	if req.RewardPoints < 0 || req.RewardMakeupCards < 0 || req.RewardStreakFreezes < 0 {
+		return invalid("奖励数量不能为负数")
+	}
+	if req.RewardStreakFreezes > StreakFreezeMaxOwned {
+		return invalid("奖励冻结卡不能超过持有上限 %d 张", StreakFreezeMaxOwned)
+	}
+
+	exists, err := s.titleRepo.TitleKeyExists(req.TitleKey, config.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrTitleKeyExists
+	}
+
+	config.TitleKey = req.TitleKey
+	config.TitleName = req.TitleName
+	config.TitleIcon = req.TitleIcon
+	config.Description = req.Description
+	config.Category = req.Category
+	config.ConditionType = req.ConditionType
+	config.ConditionValue = req.ConditionValue
+	config.ConditionDescription = req.ConditionDescription
+	config.ConditionRule = conditionRule
+	config.Rarity = req.Rarity
+	config.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		config.IsActive = *req.IsActive
+	}
+	config.IsHidden = req.IsHidden
+	config.FamilyKey = req.FamilyKey
+	config.Tier = req.Tier
+	config.StartAt = req.StartAt
+	config.EndAt = req.EndAt
+	config.RewardPoints = req.RewardPoints
+	config.RewardMakeupCards = req.RewardMakeupCards
+	config.RewardStreakFreezes = req.RewardStreakFreezes
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"voicepaper/internal/model"
+)
+
+func TestApplyTitleConfigRejectsSeasonalLifetimeMetrics(t *testing.T) {
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	windowed := model.TitleRule{Metric: model.TitleConditionReviewCount, Value: 100}
+
+	tests := []struct {
+		name          string
+		conditionType model.TitleConditionType
+		rule          *model.TitleRule
+	}{
+		{"总积分", model.TitleConditionTotalPoints, nil},
+		{"连续签到", model.TitleConditionContinuousCheckIns, nil},
+		{"连续全对", model.TitleConditionPerfectStreak, nil},
+		{"生词数", model.TitleConditionVocabularyCount, nil},
+		{"组合条件中的生词数", model.TitleConditionCompound, &model.TitleRule{Op: model.TitleRuleAnd, Rules: []model.TitleRule{
+			windowed,
+			{Metric: model.TitleConditionVocabularyCount, Value: 50},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &TitleConfigRequest{
+				TitleKey:       "winter_" + string(tt.conditionType),
+				TitleName:      "冬季限定",
+				Category:       model.TitleCategorySpecial,
+				ConditionType:  tt.conditionType,
+				ConditionValue: 10,
+				ConditionRule:  tt.rule,
+				StartAt:        &start,
+				EndAt:          &end,
+			}
+			err := (&TitleService{}).applyTitleConfig(&model.TitleConfig{}, req)
+			if !errors.Is(err, ErrInvalidTitleConfig) || !strings.Contains(err.Error(), "限时称号") {
+				t.Errorf("err = %v, want 限时称号条件无效", err)
+			}
+		})
+	}
+}
+
+func TestRuleOnlyWindowMetrics(t *testing.T) {
+	tests := []struct {
+		name string
+		rule model.TitleRule
+		want bool
+	}{
+		{"复习次数", model.TitleRule{Metric: model.TitleConditionReviewCount}, true},
+		{"学习时长", model.TitleRule{Metric: model.TitleConditionTotalDuration}, true},
+		{"总积分", model.TitleRule{Metric: model.TitleConditionTotalPoints}, false},
+		{"组合条件", model.TitleRule{Op: model.TitleRuleOr, Rules: []model.TitleRule{
+			{Metric: model.TitleConditionArticlesRead},
+			{Op: model.TitleRuleAnd, Rules: []model.TitleRule{{Metric: model.TitleConditionCheckIns}, {Metric: model.TitleConditionDictationsCompleted}}},
+		}}, true},
+		{"嵌套的连续签到", model.TitleRule{Op: model.TitleRuleOr, Rules: []model.TitleRule{
+			{Metric: model.TitleConditionArticlesRead},
+			{Op: model.TitleRuleAnd, Rules: []model.TitleRule{{Metric: model.TitleConditionContinuousCheckIns}}},
+		}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleOnlyWindowMetrics(tt.rule); got != tt.want {
+				t.Errorf("ruleOnlyWindowMetrics = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
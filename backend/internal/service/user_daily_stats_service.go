@@ -1,7 +1,6 @@
 package service
 
 import (
-	"time"
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 
@@ -12,53 +11,56 @@ type UserDailyStatsService struct {
 	db        *gorm.DB
 	statsRepo *repository.UserDailyStatsRepository
 	vocabRepo *repository.VocabularyRepository
+	calendar  *CalendarService
 }
 
-func NewUserDailyStatsService(db *gorm.DB) *UserDailyStatsService {
+func NewUserDailyStatsService(db *gorm.DB, calendar *CalendarService) *UserDailyStatsService {
 	return &UserDailyStatsService{
 		db:        db,
 		statsRepo: repository.NewUserDailyStatsRepository(db),
 		vocabRepo: repository.NewVocabularyRepository(),
+		calendar:  calendar,
 	}
 }
 
-// SyncDuration 同步今日时长
+// SyncDuration 同步今日时长（按用户时区的今天）
 func (s *UserDailyStatsService) SyncDuration(userID uint, durationSeconds int) error {
-	return s.statsRepo.UpdateDuration(userID, durationSeconds)
+	return s.statsRepo.UpdateDuration(userID, s.calendar.Today(userID), durationSeconds)
 }
 
 // RecordNewWord 记录新学单词
 func (s *UserDailyStatsService) RecordNewWord(userID uint) error {
-	return s.statsRepo.IncrementNewWords(userID, 1)
+	return s.statsRepo.IncrementNewWords(userID, s.calendar.Today(userID), 1)
 }
 
 // RecordReview 记录复习（包含正确率统计）
 func (s *UserDailyStatsService) RecordReview(userID uint, isCorrect bool) error {
 	// 增加复习数
-	err := s.statsRepo.IncrementReviewedWords(userID, 1)
+	today := s.calendar.Today(userID)
+	err := s.statsRepo.IncrementReviewedWords(userID, today, 1)
 	if err != nil {
 		return err
 	}
 
 	// 记录正确率
-	return s.statsRepo.RecordAttempt(userID, isCorrect)
+	return s.statsRepo.RecordAttempt(userID, today, isCorrect)
 }
 
 // GetTodayStats 获取今日统计
 func (s *UserDailyStatsService) GetTodayStats(userID uint) (*model.UserDailyStats, error) {
-	return s.statsRepo.GetTodayStats(userID)
+	return s.statsRepo.GetOrCreateStats(userID, s.calendar.Today(userID))
 }
 
 // GetTodayStatsWithVocabulary 获取今日统计（合并生词本数据）
 func (s *UserDailyStatsService) GetTodayStatsWithVocabulary(userID uint) (map[string]interface{}, error) {
 	// 获取基础统计
-	stats, err := s.statsRepo.GetTodayStats(userID)
+	today := s.calendar.Today(userID)
+	stats, err := s.statsRepo.GetOrCreateStats(userID, today)
 	if err != nil {
 		return nil, err
 	}
 
 	// 获取生词本今日统计
-	today := time.Now()
 	vocabStats, err := s.vocabRepo.GetOrCreateDailyStats(userID, today)
 	if err != nil {
 		vocabStats = &model.VocabularyDailyStats{}
@@ -78,12 +80,8 @@ func (s *UserDailyStatsService) GetTodayStatsWithVocabulary(userID uint) (map[st
 
 // GetStatsForDays 获取最近N天的统计
 func (s *UserDailyStatsService) GetStatsForDays(userID uint, days int) ([]model.UserDailyStats, error) {
-	endDate := time.Now()
+	endDate := s.calendar.Today(userID)
 	startDate := endDate.AddDate(0, 0, -days+1)
 
-	// 规范化为每天的开始时间
-	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
-	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
-
 	return s.statsRepo.GetStatsRange(userID, startDate, endDate)
 }
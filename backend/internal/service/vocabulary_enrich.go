@@ -0,0 +1,212 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/morph"
+
+	"gorm.io/gorm"
+)
+
+// ==================== 词形还原 ====================
+
+var (
+	morphAnalyzer     *morph.Analyzer
+	morphAnalyzerOnce sync.Once
+)
+
+// getMorphAnalyzer 惰性初始化词形还原器，词表和不规则变化从单词书（vp_wordbook）加载
+func getMorphAnalyzer() *morph.Analyzer {
+	morphAnalyzerOnce.Do(func() {
+		morphAnalyzer = morph.New()
+		if repository.DB == nil {
+			return
+		}
+		entries, err := repository.NewWordbookRepository(repository.DB).ListWordForms()
+		if err != nil {
+			log.Printf("⚠️ 加载单词书词表失败，词形还原只使用内置规则: %v", err)
+			return
+		}
+		for _, e := range entries {
+			morphAnalyzer.AddLemmas(e.Word)
+			if err := morphAnalyzer.AddForms(e.Word, e.WordForms); err != nil {
+				log.Printf("⚠️ 解析单词变形失败 (%s): %v", e.Word, err)
+			}
+		}
+		log.Printf("✅ 词形还原词表加载完成: %d 个单词", len(entries))
+	})
+	return morphAnalyzer
+}
+
+// vocabularyLemma 生词的原形，只对单个单词计算，短语和句子返回空字符串
+func vocabularyLemma(vocabType model.VocabularyType, content string) string {
+	content = strings.TrimSpace(content)
+	if vocabType != model.VocabularyTypeWord || content == "" || strings.ContainsAny(content, " \t\n") {
+		return ""
+	}
+	return truncateRunes(getMorphAnalyzer().Lemma(content), 100)
+}
+
+// ==================== 从单词书词典补全生词 ====================
+
+// morphemeItem 词根词缀（与前端 AddVocabularyPopup 提交的 morphemes 格式一致）
+type morphemeItem struct {
+	Type     string `json:"type"` // prefix/root/suffix
+	Morpheme string `json:"morpheme"`
+	Meaning  string `json:"meaning"`
+	Origin   string `json:"origin,omitempty"`
+}
+
+// VocabularyEnricher 按单词书词典（vp_wordbook）补全生词的音标、释义、例句和词根词缀
+type VocabularyEnricher struct {
+	wordbookRepo *repository.WordbookRepository
+}
+
+// NewVocabularyEnricher 创建生词补全器
+func NewVocabularyEnricher(db *gorm.DB) *VocabularyEnricher {
+	return &VocabularyEnricher{
+		wordbookRepo: repository.NewWordbookRepository(db),
+	}
+}
+
+// Lookup 查找单词在词典中的词条，单词本身没有时按原形（running -> run、went -> go）查找
+// 找不到时返回 gorm.ErrRecordNotFound
+func (e *VocabularyEnricher) Lookup(word string) (*model.Wordbook, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" || strings.ContainsAny(word, " \t\n") {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// 依次尝试：单词本身、还原出的原形、其他规则候选（词表未加载全时兜底）
+	candidates := []string{word}
+	if lemma := getMorphAnalyzer().Lemma(word); lemma != word {
+		candidates = append(candidates, lemma)
+	}
+	for _, c := range morph.Candidates(word)[1:] {
+		if c != candidates[len(candidates)-1] {
+			candidates = append(candidates, c)
+		}
+	}
+
+	entries, err := e.wordbookRepo.FindByWords(candidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		var best *model.Wordbook
+		for i := range entries {
+			if !strings.EqualFold(entries[i].Word, candidate) {
+				continue
+			}
+			if best == nil || wordbookRichness(&entries[i]) > wordbookRichness(best) {
+				best = &entries[i]
+			}
+		}
+		if best != nil {
+			return best, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Enrich 用词典数据填充生词的空字段并关联单词书词条，只处理单词；已有内容不会被覆盖
+// 词典中的助记图片等其余信息可通过 WordbookID 获取
+func (e *VocabularyEnricher) Enrich(vocab *model.Vocabulary) (bool, error) {
+	if vocab.Type != model.VocabularyTypeWord {
+		return false, nil
+	}
+
+	entry, err := e.Lookup(vocab.Content)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	fill := func(dst *string, src string) {
+		if strings.TrimSpace(*dst) == "" && src != "" {
+			*dst = src
+		}
+	}
+	fill(&vocab.Phonetic, entry.Phonetic)
+	fill(&vocab.Meaning, entry.Meaning)
+	fill(&vocab.Example, entry.Example)
+	fill(&vocab.ExampleTranslation, entry.ExampleTranslation)
+	fill(&vocab.Morphemes, wordbookMorphemes(entry))
+	if entry.MemoryTips != "" {
+		fill(&vocab.Note, "记忆技巧："+entry.MemoryTips)
+	}
+
+	if vocab.WordbookID == nil {
+		id := entry.ID
+		vocab.WordbookID = &id
+		vocab.WordbookType = entry.WordType
+	}
+	return true, nil
+}
+
+// wordbookMorphemes 把词条的词缀（Affix/AffixAnalysis）和词根（Root/RootAnalysis）转换为 morphemes JSON
+// 词缀按连字符位置区分前缀（re-）和后缀（-tion），按前缀、词根、后缀排列
+func wordbookMorphemes(w *model.Wordbook) string {
+	var prefixes, roots, suffixes []morphemeItem
+	for _, m := range morph.ParseAffixes(w.Affix, morph.KindPrefix) {
+		item := morphemeItem{Type: string(m.Kind), Morpheme: m.String(), Meaning: truncateRunes(w.AffixAnalysis, 100)}
+		switch m.Kind {
+		case morph.KindSuffix:
+			suffixes = append(suffixes, item)
+		case morph.KindRoot:
+			roots = append(roots, item)
+		default:
+			prefixes = append(prefixes, item)
+		}
+	}
+	for _, m := range morph.ParseAffixes(w.Root, morph.KindRoot) {
+		roots = append(roots, morphemeItem{Type: string(m.Kind), Morpheme: m.String(), Meaning: truncateRunes(w.RootAnalysis, 100)})
+	}
+
+	items := append(append(prefixes, roots...), suffixes...)
+	if len(items) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(items)
+	return string(data)
+}
+
+// wordbookRichness 同一单词有多条词条时优先选择内容更完整的
+func wordbookRichness(w *model.Wordbook) int {
+	score := 0
+	for _, s := range []string{w.Phonetic, w.Meaning, w.Example, w.RootAnalysis, w.WordForms, w.MemoryTips, w.ImageURL} {
+		if s != "" {
+			score++
+		}
+	}
+	return score
+}
+
+// BackfillVocabularyLemmas 为还没有原形的单词补全 lemma 字段，返回更新的条数
+func BackfillVocabularyLemmas(db *gorm.DB) (int, error) {
+	updated := 0
+	var batch []model.Vocabulary
+	err := db.Select("id", "type", "content").
+		Where("type = ? AND (lemma IS NULL OR lemma = '')", model.VocabularyTypeWord).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, v := range batch {
+				lemma := vocabularyLemma(v.Type, v.Content)
+				if lemma == "" {
+					continue
+				}
+				if err := db.Model(&model.Vocabulary{}).Where("id = ?", v.ID).Update("lemma", lemma).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
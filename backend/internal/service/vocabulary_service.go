@@ -1,10 +1,11 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
-	"time"
+	"log"
+	"strings"
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
 
@@ -16,14 +17,22 @@ type VocabularyService struct {
 	repo           *repository.VocabularyRepository
 	db             *gorm.DB
 	userPointsRepo *repository.UserPointsRepository
+	srsService     *SRSService
+	mistakeService *MistakeService
+	enricher       *VocabularyEnricher
+	calendar       *CalendarService
 }
 
 // NewVocabularyService 创建生词本服务实例
-func NewVocabularyService() *VocabularyService {
+func NewVocabularyService(calendar *CalendarService) *VocabularyService {
 	return &VocabularyService{
 		repo:           repository.NewVocabularyRepository(),
 		db:             repository.DB,
 		userPointsRepo: repository.NewUserPointsRepository(repository.DB),
+		srsService:     NewSRSService(repository.DB),
+		mistakeService: NewMistakeService(repository.DB),
+		enricher:       NewVocabularyEnricher(repository.DB),
+		calendar:       calendar,
 	}
 }
 
@@ -41,18 +50,24 @@ type AddVocabularyRequest struct {
 	ExampleTranslation string `json:"example_translation"`
 	Context            string `json:"context"`
 	Note               string `json:"note"`
-	Source             string `json:"source"` // 来源：article/manual（默认），由前端传递或后端自动判断
+	Morphemes          string `json:"morphemes"` // 词根词缀分析结果，JSON格式
+	Source             string `json:"source"`    // 来源：article/manual（默认），由前端传递或后端自动判断
 }
 
 // AddVocabulary 添加生词
-func (s *VocabularyService) AddVocabulary(userID uint, req *AddVocabularyRequest) (*model.Vocabulary, error) {
-	// 检查是否已存在
-	existing, err := s.repo.GetByUserIDAndContent(userID, req.Content)
-	if err == nil && existing != nil {
-		return nil, errors.New("该生词已在生词本中")
-	}
+// 同一单词的其他形式（如 running 与 run）已在生词本中时仍然添加，通过 related 返回该生词供前端提示；
+// 词形还原只是推测（found 既可能是 find 的过去式，也可能是独立的单词），不能据此拒绝添加
+func (s *VocabularyService) AddVocabulary(userID uint, req *AddVocabularyRequest) (vocab *model.Vocabulary, related *model.Vocabulary, err error) {
+	lemma := vocabularyLemma(model.VocabularyType(req.Type), req.Content)
+	existing, err := s.repo.GetByUserIDAndContent(userID, req.Content, lemma)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
+		return nil, nil, err
+	}
+	if err == nil && existing != nil {
+		if strings.EqualFold(existing.Content, req.Content) {
+			return nil, nil, errors.New("该生词已在生词本中")
+		}
+		related = existing
 	}
 
 	// 确定来源
@@ -65,19 +80,21 @@ func (s *VocabularyService) AddVocabulary(userID uint, req *AddVocabularyRequest
 	}
 
 	// 新词默认下次复习时间为立即（表示新词待学习）
-	now := time.Now()
-	vocab := &model.Vocabulary{
+	now := s.calendar.Now()
+	vocab = &model.Vocabulary{
 		UserID:             userID,
 		ArticleID:          req.ArticleID,
 		SentenceID:         req.SentenceID,
 		Type:               model.VocabularyType(req.Type),
 		Content:            req.Content,
+		Lemma:              lemma,
 		Phonetic:           req.Phonetic,
 		Meaning:            req.Meaning,
 		Example:            req.Example,
 		ExampleTranslation: req.ExampleTranslation,
 		Context:            req.Context,
 		Note:               req.Note,
+		Morphemes:          req.Morphemes,
 		Source:             source, // 来源标识
 		MasteryLevel:       0,
 		EaseFactor:         2.5,
@@ -86,14 +103,19 @@ func (s *VocabularyService) AddVocabulary(userID uint, req *AddVocabularyRequest
 		NextReviewAt:       &now, // 新词立即可复习
 	}
 
+	// 用单词书词典补全前端未提供的音标、释义、例句和词根词缀（失败不影响添加）
+	if _, err := s.enricher.Enrich(vocab); err != nil {
+		log.Printf("⚠️ 从词典补全生词失败 (%s): %v", vocab.Content, err)
+	}
+
 	if err := s.repo.Create(vocab); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 更新每日统计
 	s.updateDailyStatsNewWord(userID)
 
-	return vocab, nil
+	return vocab, related, nil
 }
 
 // GetVocabulary 获取单个生词
@@ -169,6 +191,7 @@ func (s *VocabularyService) DeleteVocabulary(id uint, userID uint) error {
 
 // ListVocabulary 获取生词列表
 func (s *VocabularyService) ListVocabulary(userID uint, params *repository.VocabularyListParams) ([]model.Vocabulary, int64, error) {
+	params.Now = s.calendar.LocalNow(userID)
 	return s.repo.ListByUserID(userID, params)
 }
 
@@ -195,28 +218,31 @@ func (s *VocabularyService) BatchDelete(userID uint, ids []uint) error {
 
 // ==================== 复习功能 (SM-2算法) ====================
 
-// GetTodayReviewList 获取今日待复习列表
-func (s *VocabularyService) GetTodayReviewList(userID uint, limit int) ([]model.Vocabulary, error) {
-	return s.repo.GetTodayReviewList(userID, limit)
+// GetTodayReviewList 获取今日待复习列表，filter 不为空时只复习符合条件的生词
+func (s *VocabularyService) GetTodayReviewList(userID uint, limit int, filter *repository.VocabularyFilter) ([]model.Vocabulary, error) {
+	return s.repo.GetTodayReviewList(userID, limit, filter, s.calendar.LocalNow(userID))
 }
 
 // SubmitReviewRequest 提交复习结果请求
+// 客户端自评（POST /vocabulary/:id/review 和复习会话）只能是 card；
+// 拼写、选择、完形填空、听音选义由服务端判分，经 /vocabulary/exercises/:id/answer 提交
 type SubmitReviewRequest struct {
-	VocabularyID   uint   `json:"vocabulary_id"`                                                     // 从URL参数获取
-	Quality        int    `json:"quality" binding:"min=0,max=5"`                                     // SM-2评分 0-5
-	ReviewType     string `json:"review_type" binding:"omitempty,oneof=card spell choice dictation"` // 可选
+	VocabularyID   uint   `json:"vocabulary_id"`                              // 从URL参数获取
+	Quality        int    `json:"quality" binding:"min=0,max=5"`              // SM-2评分 0-5
+	ReviewType     string `json:"review_type" binding:"omitempty,oneof=card"` // 可选，服务端判分的练习由 ExerciseService 填写
 	ResponseTimeMs *int   `json:"response_time_ms"`
 	UserAnswer     string `json:"user_answer"`
 }
 
 // SubmitReviewResult 提交复习结果返回
 type SubmitReviewResult struct {
-	Vocabulary   *model.Vocabulary `json:"vocabulary"`
-	PointsEarned int               `json:"points_earned"` // 本次获得的积分
-	TotalPoints  int               `json:"total_points"`  // 当前总积分
+	Vocabulary   *model.Vocabulary   `json:"vocabulary"`
+	PointsEarned int                 `json:"points_earned"` // 本次获得的积分
+	TotalPoints  int                 `json:"total_points"`  // 当前总积分
+	NewTitles    []model.TitleConfig `json:"new_titles"`    // 本次新获得的称号
 }
 
-// SubmitReview 提交复习结果（SM-2算法核心）
+// SubmitReview 提交复习结果（调度算法见 pkg/srs）
 func (s *VocabularyService) SubmitReview(userID uint, req *SubmitReviewRequest) (*SubmitReviewResult, error) {
 	vocab, err := s.GetVocabulary(req.VocabularyID, userID)
 	if err != nil {
@@ -227,8 +253,11 @@ func (s *VocabularyService) SubmitReview(userID uint, req *SubmitReviewRequest)
 	prevInterval := vocab.IntervalDays
 	prevEaseFactor := vocab.EaseFactor
 
-	// 应用SM-2算法
-	s.applySM2Algorithm(vocab, req.Quality)
+	// 按用户选择的算法（SM-2 / FSRS）计算下次复习时间
+	now := s.calendar.Now()
+	card := cardFromVocabulary(vocab)
+	s.srsService.SchedulerFor(userID).Schedule(&card, req.Quality, now)
+	applyCardToVocabulary(vocab, card)
 
 	// 更新统计
 	vocab.ReviewCount++
@@ -238,9 +267,6 @@ func (s *VocabularyService) SubmitReview(userID uint, req *SubmitReviewRequest)
 		vocab.WrongCount++
 	}
 
-	now := time.Now()
-	vocab.LastReviewAt = &now
-
 	// 保存更新
 	if err := s.repo.Update(vocab); err != nil {
 		return nil, err
@@ -269,16 +295,28 @@ func (s *VocabularyService) SubmitReview(userID uint, req *SubmitReviewRequest)
 	}
 	s.repo.CreateReview(review)
 
+	// 复习失败记入错题本，答对则标记已解决
+	if req.Quality >= 3 {
+		if err := s.mistakeService.Resolve(userID, model.MistakeItemVocabulary, vocab.ID); err != nil {
+			log.Printf("⚠️ 更新错题状态失败: %v", err)
+		}
+	} else if err := s.mistakeService.RecordReview(userID, vocab, reviewType, req.UserAnswer); err != nil {
+		log.Printf("⚠️ 记录错题失败: %v", err)
+	}
+
 	// 更新每日统计
 	s.updateDailyStatsReview(userID, req.Quality >= 3, vocab.MasteryLevel >= 4)
 
 	// 添加复习积分：忘记+1分，模糊+2分，认识+3分
 	pointsEarned, totalPoints := s.addReviewPoints(userID, req.Quality, vocab.Content)
 
+	event := Events().Publish(Event{Kind: EventReviewSubmitted, UserID: userID})
+
 	return &SubmitReviewResult{
 		Vocabulary:   vocab,
 		PointsEarned: pointsEarned,
 		TotalPoints:  totalPoints,
+		NewTitles:    event.UnlockedTitles,
 	}, nil
 }
 
@@ -346,71 +384,22 @@ func (s *VocabularyService) addReviewPoints(userID uint, quality int, wordConten
 	return points, totalPoints
 }
 
-// applySM2Algorithm 应用SM-2间隔重复算法
-// quality: 0-5 的评分
-// 0 - 完全忘记
-// 1 - 错误，看到答案后想起来
-// 2 - 错误，答案感觉熟悉
-// 3 - 正确，很费力
-// 4 - 正确，有些犹豫
-// 5 - 正确，非常轻松
-func (s *VocabularyService) applySM2Algorithm(vocab *model.Vocabulary, quality int) {
-	// 更新易度因子 (EF)
-	// EF' = EF + (0.1 - (5 - q) * (0.08 + (5 - q) * 0.02))
-	ef := vocab.EaseFactor + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
-	if ef < 1.3 {
-		ef = 1.3 // 最小值
-	}
-	vocab.EaseFactor = math.Round(ef*100) / 100
-
-	if quality < 3 {
-		// 回答错误，重置
-		vocab.Repetitions = 0
-		vocab.IntervalDays = 0
-		vocab.MasteryLevel = max(0, vocab.MasteryLevel-1) // 降级
-	} else {
-		// 回答正确
-		vocab.Repetitions++
-
-		// 计算新的间隔
-		switch vocab.Repetitions {
-		case 1:
-			vocab.IntervalDays = 1
-		case 2:
-			vocab.IntervalDays = 6
-		default:
-			vocab.IntervalDays = int(math.Round(float64(vocab.IntervalDays) * vocab.EaseFactor))
-		}
-
-		// 更新掌握等级
-		if vocab.Repetitions >= 5 && quality >= 4 {
-			vocab.MasteryLevel = 5
-		} else if vocab.Repetitions >= 3 {
-			vocab.MasteryLevel = min(4, vocab.MasteryLevel+1)
-		} else if vocab.Repetitions >= 1 {
-			vocab.MasteryLevel = min(2, vocab.MasteryLevel+1)
-		}
-	}
-
-	// 计算下次复习时间
-	if vocab.IntervalDays == 0 {
-		// 立即复习（1分钟后），方便用户快速重新学习
-		next := time.Now().Add(1 * time.Minute)
-		vocab.NextReviewAt = &next
-	} else {
-		next := time.Now().AddDate(0, 0, vocab.IntervalDays)
-		vocab.NextReviewAt = &next
-	}
-}
-
 // ==================== 文件夹管理 ====================
 
+// 智能文件夹错误
+var (
+	ErrSmartFolderReadOnly = errors.New("智能文件夹的内容由筛选条件决定，不能手动添加或移除生词")
+	ErrInvalidFilterFolder = errors.New("筛选条件中的文件夹不存在或不是普通文件夹")
+)
+
 // CreateFolderRequest 创建文件夹请求
+// Filter 不为空时创建智能文件夹，内容按筛选条件实时计算
 type CreateFolderRequest struct {
-	Name        string `json:"name" binding:"required,max=100"`
-	Description string `json:"description"`
-	Color       string `json:"color"`
-	Icon        string `json:"icon"`
+	Name        string                       `json:"name" binding:"required,max=100"`
+	Description string                       `json:"description"`
+	Color       string                       `json:"color"`
+	Icon        string                       `json:"icon"`
+	Filter      *repository.VocabularyFilter `json:"filter"`
 }
 
 // CreateFolder 创建文件夹
@@ -425,6 +414,13 @@ func (s *VocabularyService) CreateFolder(userID uint, req *CreateFolderRequest)
 	if folder.Color == "" {
 		folder.Color = "#3b82f6"
 	}
+	if req.Filter != nil {
+		filter, err := s.encodeFolderFilter(userID, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		folder.Filter = filter
+	}
 	if err := s.repo.CreateFolder(folder); err != nil {
 		return nil, err
 	}
@@ -436,22 +432,26 @@ func (s *VocabularyService) ListFolders(userID uint) ([]model.VocabularyFolder,
 	return s.repo.ListFolders(userID)
 }
 
-// UpdateFolder 更新文件夹
-func (s *VocabularyService) UpdateFolder(id uint, userID uint, req *CreateFolderRequest) (*model.VocabularyFolder, error) {
+// GetFolder 获取用户的文件夹
+func (s *VocabularyService) GetFolder(id uint, userID uint) (*model.VocabularyFolder, error) {
 	folders, err := s.repo.ListFolders(userID)
 	if err != nil {
 		return nil, err
 	}
-
-	var folder *model.VocabularyFolder
 	for i := range folders {
 		if folders[i].ID == id {
-			folder = &folders[i]
-			break
+			return &folders[i], nil
 		}
 	}
-	if folder == nil {
-		return nil, errors.New("文件夹不存在")
+	return nil, errors.New("文件夹不存在")
+}
+
+// UpdateFolder 更新文件夹
+// 智能文件夹可以修改筛选条件；普通文件夹和智能文件夹之间不能互相转换
+func (s *VocabularyService) UpdateFolder(id uint, userID uint, req *CreateFolderRequest) (*model.VocabularyFolder, error) {
+	folder, err := s.GetFolder(id, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	folder.Name = req.Name
@@ -460,6 +460,13 @@ func (s *VocabularyService) UpdateFolder(id uint, userID uint, req *CreateFolder
 		folder.Color = req.Color
 	}
 	folder.Icon = req.Icon
+	if folder.IsSmart() && req.Filter != nil {
+		filter, err := s.encodeFolderFilter(userID, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		folder.Filter = filter
+	}
 
 	if err := s.repo.UpdateFolder(folder); err != nil {
 		return nil, err
@@ -469,29 +476,23 @@ func (s *VocabularyService) UpdateFolder(id uint, userID uint, req *CreateFolder
 
 // DeleteFolder 删除文件夹
 func (s *VocabularyService) DeleteFolder(id uint, userID uint) error {
-	folders, err := s.repo.ListFolders(userID)
-	if err != nil {
+	if _, err := s.GetFolder(id, userID); err != nil {
 		return err
 	}
-
-	found := false
-	for _, f := range folders {
-		if f.ID == id {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return errors.New("文件夹不存在")
-	}
-
 	return s.repo.DeleteFolder(id)
 }
 
 // AddToFolder 添加生词到文件夹
 func (s *VocabularyService) AddToFolder(folderID, vocabID, userID uint) error {
+	folder, err := s.GetFolder(folderID, userID)
+	if err != nil {
+		return err
+	}
+	if folder.IsSmart() {
+		return ErrSmartFolderReadOnly
+	}
 	// 验证权限
-	_, err := s.GetVocabulary(vocabID, userID)
+	_, err = s.GetVocabulary(vocabID, userID)
 	if err != nil {
 		return err
 	}
@@ -503,15 +504,64 @@ func (s *VocabularyService) RemoveFromFolder(folderID, vocabID uint) error {
 	return s.repo.RemoveFromFolder(folderID, vocabID)
 }
 
-// ListByFolder 获取文件夹中的生词
-func (s *VocabularyService) ListByFolder(folderID uint, limit, offset int) ([]model.Vocabulary, int64, error) {
-	return s.repo.ListByFolder(folderID, limit, offset)
+// ListByFolder 获取文件夹中的生词，智能文件夹按筛选条件实时计算
+func (s *VocabularyService) ListByFolder(folderID, userID uint, limit, offset int) ([]model.Vocabulary, int64, error) {
+	folder, err := s.GetFolder(folderID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !folder.IsSmart() {
+		return s.repo.ListByFolder(folderID, limit, offset)
+	}
+
+	filter, err := repository.ParseVocabularyFilter(folder.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListByUserID(userID, &repository.VocabularyListParams{
+		Filter: filter,
+		Limit:  limit,
+		Offset: offset,
+		Now:    s.calendar.LocalNow(userID),
+	})
+}
+
+// FolderFilter 文件夹对应的生词筛选条件（用作复习范围）
+// 智能文件夹返回保存的筛选条件，普通文件夹返回「属于该文件夹」
+func (s *VocabularyService) FolderFilter(folderID, userID uint) (*repository.VocabularyFilter, error) {
+	folder, err := s.GetFolder(folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if folder.IsSmart() {
+		return repository.ParseVocabularyFilter(folder.Filter)
+	}
+	return &repository.VocabularyFilter{FolderID: &folder.ID}, nil
+}
+
+// encodeFolderFilter 校验智能文件夹的筛选条件并编码为 JSON
+// 条件中引用的文件夹必须是该用户的普通文件夹，避免智能文件夹互相嵌套
+func (s *VocabularyService) encodeFolderFilter(userID uint, filter *repository.VocabularyFilter) (string, error) {
+	if err := filter.Validate(); err != nil {
+		return "", err
+	}
+	if filter.FolderID != nil {
+		folder, err := s.GetFolder(*filter.FolderID, userID)
+		if err != nil || folder.IsSmart() {
+			return "", ErrInvalidFilterFolder
+		}
+	}
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
 }
 
 // ==================== 辅助方法 ====================
 
 func (s *VocabularyService) updateDailyStatsNewWord(userID uint) {
-	stats, err := s.repo.GetOrCreateDailyStats(userID, time.Now())
+	stats, err := s.repo.GetOrCreateDailyStats(userID, s.calendar.Today(userID))
 	if err != nil {
 		return
 	}
@@ -520,7 +570,7 @@ func (s *VocabularyService) updateDailyStatsNewWord(userID uint) {
 }
 
 func (s *VocabularyService) updateDailyStatsReview(userID uint, isCorrect bool, isMastered bool) {
-	stats, err := s.repo.GetOrCreateDailyStats(userID, time.Now())
+	stats, err := s.repo.GetOrCreateDailyStats(userID, s.calendar.Today(userID))
 	if err != nil {
 		return
 	}
@@ -538,7 +588,7 @@ func (s *VocabularyService) updateDailyStatsReview(userID uint, isCorrect bool,
 
 // GetDailyStats 获取每日统计
 func (s *VocabularyService) GetDailyStats(userID uint, days int) ([]model.VocabularyDailyStats, error) {
-	endDate := time.Now()
+	endDate := s.calendar.Today(userID)
 	startDate := endDate.AddDate(0, 0, -days)
 	return s.repo.GetDailyStatsRange(userID, startDate, endDate)
 }
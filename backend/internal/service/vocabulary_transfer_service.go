@@ -0,0 +1,900 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/internal/storage"
+	"voicepaper/pkg/apkg"
+
+	"gorm.io/gorm"
+)
+
+// 导入导出格式
+const (
+	TransferFormatCSV  = "csv"
+	TransferFormatJSON = "json"
+	TransferFormatAPKG = "apkg"
+)
+
+// 导入导出限制
+const (
+	maxImportItems  = 10000 // 单次导入的最大条数
+	maxExportMedia  = 2000  // 导出 .apkg 时最多附带的发音文件数
+	importErrorsMax = 20    // 导入结果中最多返回的错误信息条数
+	folderTagPrefix = "folder::"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedTransferFormat = errors.New("不支持的格式，仅支持 csv、json、apkg")
+	ErrFolderNotFound            = errors.New("文件夹不存在")
+	ErrTooManyImportItems        = fmt.Errorf("单次最多导入 %d 条", maxImportItems)
+)
+
+// csvColumns 导出 CSV 的列（也是导入时的默认列名和 JSON 字段名）
+var csvColumns = []string{
+	"type", "content", "phonetic", "meaning", "example", "example_translation",
+	"context", "note", "morphemes", "tags", "folders", "is_starred",
+	"srs_state", "mastery_level", "ease_factor", "interval_days", "repetitions",
+	"stability", "difficulty", "lapses", "review_count", "correct_count", "wrong_count",
+	"next_review_at", "last_review_at", "created_at",
+}
+
+// importAliases 导入时各字段可识别的其他列名（CSV 表头或 Anki 字段名，不区分大小写）
+var importAliases = map[string][]string{
+	"content":             {"front", "word", "expression", "单词", "生词", "正面"},
+	"meaning":             {"back", "definition", "translation", "释义", "背面"},
+	"phonetic":            {"pronunciation", "ipa", "音标"},
+	"example":             {"sentence", "例句"},
+	"example_translation": {"sentence_translation", "例句翻译"},
+	"note":                {"notes", "备注"},
+	"interval_days":       {"interval", "ivl"},
+	"ease_factor":         {"ease", "factor"},
+}
+
+// VocabularyTransferService 生词本导入导出服务（CSV / JSON / Anki .apkg）
+type VocabularyTransferService struct {
+	db            *gorm.DB
+	repo          *repository.VocabularyRepository
+	storage       storage.Storage
+	pronunciation *PronunciationService
+	calendar      *CalendarService
+}
+
+// NewVocabularyTransferService 创建导入导出服务实例，st 用于导出 .apkg 时附带单词发音，可以为 nil
+func NewVocabularyTransferService(db *gorm.DB, st storage.Storage, calendar *CalendarService) *VocabularyTransferService {
+	return &VocabularyTransferService{
+		db:            db,
+		repo:          repository.NewVocabularyRepository(),
+		storage:       st,
+		pronunciation: NewPronunciationService(db, st),
+		calendar:      calendar,
+	}
+}
+
+// VocabularyExportItem 导出的一条生词（JSON 格式）
+type VocabularyExportItem struct {
+	Type               string     `json:"type"`
+	Content            string     `json:"content"`
+	Phonetic           string     `json:"phonetic,omitempty"`
+	Meaning            string     `json:"meaning,omitempty"`
+	Example            string     `json:"example,omitempty"`
+	ExampleTranslation string     `json:"example_translation,omitempty"`
+	Context            string     `json:"context,omitempty"`
+	Note               string     `json:"note,omitempty"`
+	Morphemes          string     `json:"morphemes,omitempty"`
+	Tags               []string   `json:"tags,omitempty"`
+	Folders            []string   `json:"folders,omitempty"`
+	IsStarred          bool       `json:"is_starred"`
+	SrsState           string     `json:"srs_state"`
+	MasteryLevel       int        `json:"mastery_level"`
+	EaseFactor         float64    `json:"ease_factor"`
+	IntervalDays       int        `json:"interval_days"`
+	Repetitions        int        `json:"repetitions"`
+	Stability          float64    `json:"stability,omitempty"`
+	Difficulty         float64    `json:"difficulty,omitempty"`
+	Lapses             int        `json:"lapses"`
+	ReviewCount        int        `json:"review_count"`
+	CorrectCount       int        `json:"correct_count"`
+	WrongCount         int        `json:"wrong_count"`
+	NextReviewAt       *time.Time `json:"next_review_at,omitempty"`
+	LastReviewAt       *time.Time `json:"last_review_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// vocabularyExportFile JSON 导出文件
+type vocabularyExportFile struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Items      []VocabularyExportItem `json:"items"`
+}
+
+// ExportFile 导出结果
+type ExportFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Count       int
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Format   string            // csv/json/apkg
+	Mapping  map[string]string // 生词字段 -> 源列名（CSV 表头或 Anki 字段名），CSV 也可以用列序号
+	FolderID *uint             // 导入后放入的文件夹
+	WithSRS  bool              // 是否沿用源数据中的复习间隔和易度因子
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Total          int             `json:"total"`
+	Imported       int             `json:"imported"`
+	Duplicates     int             `json:"duplicates"`
+	Skipped        int             `json:"skipped"`
+	FoldersCreated int             `json:"folders_created"`
+	Errors         []string        `json:"errors"`
+	Related        []ImportRelated `json:"related"` // 已有同一单词其他形式的生词（仍然导入），最多 importErrorsMax 条
+}
+
+// ImportRelated 导入的生词与已有生词可能是同一单词的不同形式（如 running 与 run），供前端提示
+type ImportRelated struct {
+	Content string `json:"content"`
+	Related string `json:"related"`
+}
+
+// ==================== 导出 ====================
+
+// Export 导出生词本，folderID 不为空时只导出该文件夹（智能文件夹按筛选条件）；withMedia 仅对 apkg 生效
+func (s *VocabularyTransferService) Export(userID uint, format string, folderID *uint, withMedia bool) (*ExportFile, error) {
+	deck := "VoicePaper"
+	var folder *model.VocabularyFolder
+	if folderID != nil {
+		var err error
+		folder, err = s.getFolder(userID, *folderID)
+		if err != nil {
+			return nil, err
+		}
+		deck += "::" + folder.Name
+	}
+
+	vocabs, err := s.repo.ListForExport(userID, folder, s.calendar.LocalNow(userID))
+	if err != nil {
+		return nil, err
+	}
+	folderNames, err := s.repo.GetFolderNames(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]VocabularyExportItem, len(vocabs))
+	for i := range vocabs {
+		items[i] = exportItem(&vocabs[i], folderNames[vocabs[i].ID])
+	}
+
+	name := "voicepaper-vocabulary-" + time.Now().Format("20060102")
+	file := &ExportFile{Count: len(items)}
+	switch format {
+	case TransferFormatJSON:
+		file.Filename = name + ".json"
+		file.ContentType = "application/json"
+		file.Data, err = json.MarshalIndent(vocabularyExportFile{Version: 1, ExportedAt: time.Now(), Items: items}, "", "  ")
+	case TransferFormatCSV:
+		file.Filename = name + ".csv"
+		file.ContentType = "text/csv; charset=utf-8"
+		file.Data, err = encodeCSV(items)
+	case TransferFormatAPKG:
+		file.Filename = name + ".apkg"
+		file.ContentType = "application/octet-stream"
+		file.Data, err = s.encodeAPKG(deck, items, withMedia)
+	default:
+		return nil, ErrUnsupportedTransferFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func exportItem(v *model.Vocabulary, folders []string) VocabularyExportItem {
+	return VocabularyExportItem{
+		Type:               string(v.Type),
+		Content:            v.Content,
+		Phonetic:           v.Phonetic,
+		Meaning:            v.Meaning,
+		Example:            v.Example,
+		ExampleTranslation: v.ExampleTranslation,
+		Context:            v.Context,
+		Note:               v.Note,
+		Morphemes:          v.Morphemes,
+		Tags:               parseTags(v.Tags),
+		Folders:            folders,
+		IsStarred:          v.IsStarred,
+		SrsState:           v.SrsState,
+		MasteryLevel:       v.MasteryLevel,
+		EaseFactor:         v.EaseFactor,
+		IntervalDays:       v.IntervalDays,
+		Repetitions:        v.Repetitions,
+		Stability:          v.Stability,
+		Difficulty:         v.Difficulty,
+		Lapses:             v.Lapses,
+		ReviewCount:        v.ReviewCount,
+		CorrectCount:       v.CorrectCount,
+		WrongCount:         v.WrongCount,
+		NextReviewAt:       v.NextReviewAt,
+		LastReviewAt:       v.LastReviewAt,
+		CreatedAt:          v.CreatedAt,
+	}
+}
+
+// encodeCSV 导出 CSV（带 UTF-8 BOM，方便 Excel 直接打开），多值字段用分号分隔
+func encodeCSV(items []VocabularyExportItem) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvColumns); err != nil {
+		return nil, err
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, it := range items {
+		record := []string{
+			it.Type, it.Content, it.Phonetic, it.Meaning, it.Example, it.ExampleTranslation,
+			it.Context, it.Note, it.Morphemes, strings.Join(it.Tags, ";"), strings.Join(it.Folders, ";"),
+			strconv.FormatBool(it.IsStarred),
+			it.SrsState, strconv.Itoa(it.MasteryLevel),
+			strconv.FormatFloat(it.EaseFactor, 'f', 2, 64), strconv.Itoa(it.IntervalDays), strconv.Itoa(it.Repetitions),
+			strconv.FormatFloat(it.Stability, 'f', 4, 64), strconv.FormatFloat(it.Difficulty, 'f', 4, 64),
+			strconv.Itoa(it.Lapses), strconv.Itoa(it.ReviewCount), strconv.Itoa(it.CorrectCount), strconv.Itoa(it.WrongCount),
+			formatTime(it.NextReviewAt), formatTime(it.LastReviewAt), it.CreatedAt.Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// apkgFields 导出 .apkg 时笔记的字段（导入时按同名字段识别）
+var apkgFields = []string{"Front", "Back", "Phonetic", "Example", "ExampleTranslation", "Context", "Morphemes", "Note", "Audio"}
+
+const apkgCSS = `.card { font-family: arial; font-size: 20px; text-align: center; color: black; background-color: white; }
+.phonetic { color: #666; }
+.example { font-size: 16px; color: #333; margin-top: 12px; }
+.translation { font-size: 14px; color: #888; }`
+
+// encodeAPKG 导出 Anki 牌组包，单词和短语附带已生成的发音
+func (s *VocabularyTransferService) encodeAPKG(deck string, items []VocabularyExportItem, withMedia bool) ([]byte, error) {
+	pkg := &apkg.Package{
+		Deck:        deck,
+		Description: "从 VoicePaper 生词本导出",
+		Model: apkg.Model{
+			Name:   "VoicePaper Vocabulary",
+			Fields: apkgFields,
+			QFmt:   `{{Front}}{{#Phonetic}}<div class="phonetic">{{Phonetic}}</div>{{/Phonetic}}{{Audio}}`,
+			AFmt: `{{FrontSide}}<hr id=answer>{{Back}}` +
+				`{{#Example}}<div class="example">{{Example}}</div>{{/Example}}` +
+				`{{#ExampleTranslation}}<div class="translation">{{ExampleTranslation}}</div>{{/ExampleTranslation}}` +
+				`{{#Note}}<div class="translation">{{Note}}</div>{{/Note}}`,
+			CSS: apkgCSS,
+		},
+		Media: make(map[string][]byte),
+	}
+
+	ctx := context.Background()
+	for _, it := range items {
+		audio := ""
+		if withMedia && s.storage != nil && it.Type != string(model.VocabularyTypeSentence) && len(pkg.Media) < maxExportMedia {
+			p, ok := s.pronunciation.ReadyPath(it.Content, model.PronunciationWord)
+			if !ok {
+				p = legacyWordAudioPath(it.Content)
+			}
+			name := path.Base(p)
+			if _, ok := pkg.Media[name]; ok {
+				audio = "[sound:" + name + "]"
+			} else if exists, err := s.storage.Exists(ctx, p); err == nil && exists {
+				if data, err := s.storage.Get(ctx, p); err == nil {
+					pkg.Media[name] = data
+					audio = "[sound:" + name + "]"
+				} else {
+					log.Printf("⚠️ 读取单词发音失败 (%s): %v", it.Content, err)
+				}
+			}
+		}
+
+		tags := append([]string{}, it.Tags...)
+		for _, f := range it.Folders {
+			tags = append(tags, folderTagPrefix+f)
+		}
+
+		card := apkg.Card{
+			State:        it.SrsState,
+			IntervalDays: it.IntervalDays,
+			EaseFactor:   it.EaseFactor,
+			Reps:         it.ReviewCount,
+			Lapses:       it.Lapses,
+			Due:          it.NextReviewAt,
+		}
+		if it.ReviewCount == 0 {
+			card.State = apkg.StateNew
+		}
+
+		pkg.Notes = append(pkg.Notes, apkg.Note{
+			Fields: []string{
+				escapeField(it.Content), escapeField(it.Meaning), escapeField(it.Phonetic),
+				escapeField(it.Example), escapeField(it.ExampleTranslation), escapeField(it.Context),
+				escapeField(it.Morphemes), escapeField(it.Note), audio,
+			},
+			Tags: tags,
+			Card: card,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := apkg.Write(&buf, pkg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeField Anki 字段是 HTML，需要转义特殊字符并把换行转为 <br>
+func escapeField(s string) string {
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// ==================== 导入 ====================
+
+// importRecord 从任意格式解析出的一条待导入生词
+type importRecord struct {
+	item VocabularyExportItem
+	srs  bool // 源数据是否带有复习状态
+}
+
+// Import 导入生词本
+func (s *VocabularyTransferService) Import(userID uint, data []byte, opts *ImportOptions) (*ImportResult, error) {
+	if opts.FolderID != nil {
+		folder, err := s.getFolder(userID, *opts.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		if folder.IsSmart() {
+			return nil, ErrSmartFolderReadOnly
+		}
+	}
+
+	var records []importRecord
+	var err error
+	switch opts.Format {
+	case TransferFormatCSV:
+		records, err = parseCSVImport(data, opts.Mapping)
+	case TransferFormatJSON:
+		records, err = parseJSONImport(data)
+	case TransferFormatAPKG:
+		records, err = parseAPKGImport(data, opts.Mapping)
+	default:
+		return nil, ErrUnsupportedTransferFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > maxImportItems {
+		return nil, ErrTooManyImportItems
+	}
+
+	result := &ImportResult{Total: len(records), Errors: []string{}, Related: []ImportRelated{}}
+	addError := func(format string, args ...interface{}) {
+		if len(result.Errors) < importErrorsMax {
+			result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+		}
+	}
+
+	// 文件夹名称 -> ID，不存在的按需创建
+	folders, err := s.repo.ListFolders(userID)
+	if err != nil {
+		return nil, err
+	}
+	folderIDs := make(map[string]uint, len(folders))
+	for _, f := range folders {
+		if f.IsSmart() {
+			folderIDs[f.Name] = 0 // 智能文件夹的内容由筛选条件决定，同名的不加入
+			continue
+		}
+		folderIDs[f.Name] = f.ID
+	}
+
+	seen := make(map[string]bool, len(records))
+	seenLemmas := make(map[string]string, len(records)) // 词形 -> 文件中第一次出现的内容
+	now := time.Now()
+	for i, rec := range records {
+		it := rec.item
+		it.Content = strings.TrimSpace(it.Content)
+		if it.Content == "" {
+			result.Skipped++
+			addError("第 %d 条：内容为空", i+1)
+			continue
+		}
+		if len([]rune(it.Content)) > 500 {
+			result.Skipped++
+			addError("第 %d 条：内容超过 500 字", i+1)
+			continue
+		}
+
+		vocab := importedVocabulary(userID, &it, rec.srs && opts.WithSRS, now)
+		vocab.Lemma = vocabularyLemma(vocab.Type, vocab.Content)
+
+		// 去重：只跳过内容相同的（文件内重复或生词本中已存在）；
+		// 同一单词的其他形式与 AddVocabulary 一样仍然导入，只在结果中提示（词形还原只是推测）
+		key := strings.ToLower(vocab.Content)
+		if seen[key] {
+			result.Duplicates++
+			continue
+		}
+		seen[key] = true
+		related := ""
+		if existing, err := s.repo.GetByUserIDAndContent(userID, vocab.Content, vocab.Lemma); err == nil {
+			if strings.EqualFold(existing.Content, vocab.Content) {
+				result.Duplicates++
+				continue
+			}
+			related = existing.Content
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if vocab.Lemma != "" {
+			if first, ok := seenLemmas[vocab.Lemma]; ok && related == "" {
+				related = first
+			} else if !ok {
+				seenLemmas[vocab.Lemma] = vocab.Content
+			}
+		}
+
+		if err := s.repo.Create(vocab); err != nil {
+			result.Skipped++
+			addError("第 %d 条（%s）：%v", i+1, it.Content, err)
+			continue
+		}
+		result.Imported++
+		if related != "" && len(result.Related) < importErrorsMax {
+			result.Related = append(result.Related, ImportRelated{Content: vocab.Content, Related: related})
+		}
+
+		targetFolders := make(map[uint]bool)
+		if opts.FolderID != nil {
+			targetFolders[*opts.FolderID] = true
+		}
+		for _, name := range it.Folders {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			id, ok := folderIDs[name]
+			if ok && id == 0 {
+				continue
+			}
+			if !ok {
+				folder := &model.VocabularyFolder{UserID: userID, Name: truncateRunes(name, 100), Color: "#3b82f6"}
+				if err := s.repo.CreateFolder(folder); err != nil {
+					addError("创建文件夹「%s」失败：%v", name, err)
+					continue
+				}
+				id = folder.ID
+				folderIDs[name] = id
+				result.FoldersCreated++
+			}
+			targetFolders[id] = true
+		}
+		for folderID := range targetFolders {
+			if err := s.repo.AddToFolder(folderID, vocab.ID); err != nil {
+				addError("第 %d 条（%s）加入文件夹失败：%v", i+1, it.Content, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// importedVocabulary 根据导入记录创建生词
+// withSRS 为 true 时沿用间隔和易度因子（写入 SM-2 字段），否则作为新词立即可学
+func importedVocabulary(userID uint, it *VocabularyExportItem, withSRS bool, now time.Time) *model.Vocabulary {
+	vocabType := model.VocabularyType(it.Type)
+	switch vocabType {
+	case model.VocabularyTypeWord, model.VocabularyTypePhrase, model.VocabularyTypeSentence:
+	default:
+		vocabType = guessVocabularyType(it.Content)
+	}
+
+	tags := ""
+	if len(it.Tags) > 0 {
+		encoded, _ := json.Marshal(it.Tags)
+		tags = truncateRunes(string(encoded), 500)
+	}
+
+	vocab := &model.Vocabulary{
+		UserID:             userID,
+		Source:             "import",
+		Type:               vocabType,
+		Content:            it.Content,
+		Phonetic:           truncateRunes(it.Phonetic, 100),
+		Meaning:            it.Meaning,
+		Example:            it.Example,
+		ExampleTranslation: it.ExampleTranslation,
+		Context:            it.Context,
+		Note:               it.Note,
+		Morphemes:          it.Morphemes,
+		Tags:               tags,
+		IsStarred:          it.IsStarred,
+		EaseFactor:         2.5,
+		SrsState:           "new",
+		NextReviewAt:       &now,
+	}
+	if !withSRS || it.IntervalDays <= 0 {
+		return vocab
+	}
+
+	ease := it.EaseFactor
+	if ease > 10 {
+		ease /= 1000 // Anki 的 factor 为千分比
+	}
+	if ease < 1.3 {
+		ease = 2.5
+	}
+
+	next := it.NextReviewAt
+	if next == nil {
+		base := now
+		if it.LastReviewAt != nil {
+			base = *it.LastReviewAt
+		}
+		t := base.AddDate(0, 0, it.IntervalDays)
+		next = &t
+	}
+
+	repetitions := it.Repetitions
+	if repetitions <= 0 {
+		// 按 SM-2 的间隔推算连续正确次数：1 天 -> 1，6 天以内 -> 2，更长 -> 3
+		switch {
+		case it.IntervalDays <= 1:
+			repetitions = 1
+		case it.IntervalDays <= 6:
+			repetitions = 2
+		default:
+			repetitions = 3
+		}
+	}
+
+	mastery := it.MasteryLevel
+	if mastery <= 0 {
+		switch {
+		case it.IntervalDays >= 60:
+			mastery = 5
+		case it.IntervalDays >= 21:
+			mastery = 4
+		case it.IntervalDays >= 7:
+			mastery = 3
+		default:
+			mastery = 2
+		}
+	}
+
+	vocab.EaseFactor = ease
+	vocab.IntervalDays = it.IntervalDays
+	vocab.Repetitions = repetitions
+	vocab.MasteryLevel = mastery
+	vocab.Stability = it.Stability
+	vocab.Difficulty = it.Difficulty
+	vocab.Lapses = it.Lapses
+	vocab.ReviewCount = it.ReviewCount
+	vocab.CorrectCount = it.CorrectCount
+	vocab.WrongCount = it.WrongCount
+	vocab.NextReviewAt = next
+	vocab.LastReviewAt = it.LastReviewAt
+	vocab.SrsState = "review"
+	return vocab
+}
+
+// parseCSVImport 解析 CSV，第一行为表头
+func parseCSVImport(data []byte, mapping map[string]string) ([]importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("CSV 文件为空")
+		}
+		return nil, err
+	}
+
+	columns := resolveColumns(header, mapping)
+	if _, ok := columns["content"]; !ok {
+		return nil, errors.New("CSV 中找不到内容列，请通过 mapping 指定 content 对应的列")
+	}
+	_, hasInterval := columns["interval_days"]
+
+	var records []importRecord
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) >= maxImportItems {
+			return nil, ErrTooManyImportItems
+		}
+
+		get := func(field string) string {
+			if idx, ok := columns[field]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+		records = append(records, importRecord{item: VocabularyExportItem{
+			Type:               get("type"),
+			Content:            get("content"),
+			Phonetic:           get("phonetic"),
+			Meaning:            get("meaning"),
+			Example:            get("example"),
+			ExampleTranslation: get("example_translation"),
+			Context:            get("context"),
+			Note:               get("note"),
+			Morphemes:          get("morphemes"),
+			Tags:               splitList(get("tags")),
+			Folders:            splitList(get("folders")),
+			IsStarred:          parseBool(get("is_starred")),
+			MasteryLevel:       atoi(get("mastery_level")),
+			EaseFactor:         atof(get("ease_factor")),
+			IntervalDays:       atoi(get("interval_days")),
+			Repetitions:        atoi(get("repetitions")),
+			Stability:          atof(get("stability")),
+			Difficulty:         atof(get("difficulty")),
+			Lapses:             atoi(get("lapses")),
+			ReviewCount:        atoi(get("review_count")),
+			CorrectCount:       atoi(get("correct_count")),
+			WrongCount:         atoi(get("wrong_count")),
+			NextReviewAt:       parseTime(get("next_review_at")),
+			LastReviewAt:       parseTime(get("last_review_at")),
+		}, srs: hasInterval})
+	}
+	return records, nil
+}
+
+// resolveColumns 确定每个生词字段对应的 CSV 列序号
+// 优先使用 mapping（列名或从 0 开始的列序号），其次是同名列和别名
+func resolveColumns(header []string, mapping map[string]string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, ok := index[key]; !ok {
+			index[key] = i
+		}
+	}
+
+	columns := make(map[string]int)
+	for _, field := range csvColumns {
+		if src, ok := mapping[field]; ok {
+			if idx, ok := index[strings.ToLower(strings.TrimSpace(src))]; ok {
+				columns[field] = idx
+			} else if n, err := strconv.Atoi(src); err == nil && n >= 0 && n < len(header) {
+				columns[field] = n
+			}
+			continue
+		}
+		if idx, ok := index[field]; ok {
+			columns[field] = idx
+			continue
+		}
+		for _, alias := range importAliases[field] {
+			if idx, ok := index[alias]; ok {
+				columns[field] = idx
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// parseJSONImport 解析本系统导出的 JSON（也接受直接的数组）
+func parseJSONImport(data []byte) ([]importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var items []VocabularyExportItem
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+	} else {
+		var file vocabularyExportFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+		items = file.Items
+	}
+	if len(items) > maxImportItems {
+		return nil, ErrTooManyImportItems
+	}
+
+	records := make([]importRecord, len(items))
+	for i, it := range items {
+		records[i] = importRecord{item: it, srs: true}
+	}
+	return records, nil
+}
+
+// parseAPKGImport 解析 Anki 牌组包，默认按字段名识别（本系统导出的字段或 Front/Back），
+// 识别不到时第一个字段作为内容、第二个字段作为释义
+func parseAPKGImport(data []byte, mapping map[string]string) ([]importRecord, error) {
+	notes, err := apkg.Read(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) > maxImportItems {
+		return nil, ErrTooManyImportItems
+	}
+
+	fieldNames := map[string]string{
+		"content": "Front", "meaning": "Back", "phonetic": "Phonetic", "example": "Example",
+		"example_translation": "ExampleTranslation", "context": "Context", "morphemes": "Morphemes", "note": "Note",
+	}
+	records := make([]importRecord, 0, len(notes))
+	for i := range notes {
+		n := &notes[i]
+		get := func(field string) string {
+			if src, ok := mapping[field]; ok {
+				return apkg.StripHTML(n.Field(src))
+			}
+			if v := n.Field(fieldNames[field]); v != "" {
+				return apkg.StripHTML(v)
+			}
+			for _, alias := range importAliases[field] {
+				if v := n.Field(alias); v != "" {
+					return apkg.StripHTML(v)
+				}
+			}
+			return ""
+		}
+
+		content, meaning := get("content"), get("meaning")
+		if content == "" && len(n.Fields) > 0 {
+			content = apkg.StripHTML(n.Fields[0])
+		}
+		if meaning == "" && len(n.Fields) > 1 && n.Field("Back") == "" {
+			meaning = apkg.StripHTML(n.Fields[1])
+		}
+
+		var tags, folders []string
+		for _, t := range n.Tags {
+			if strings.HasPrefix(t, folderTagPrefix) {
+				folders = append(folders, strings.ReplaceAll(strings.TrimPrefix(t, folderTagPrefix), "_", " "))
+			} else if !strings.EqualFold(t, "leech") && !strings.EqualFold(t, "marked") {
+				tags = append(tags, t)
+			}
+		}
+
+		records = append(records, importRecord{item: VocabularyExportItem{
+			Content:            content,
+			Meaning:            meaning,
+			Phonetic:           get("phonetic"),
+			Example:            get("example"),
+			ExampleTranslation: get("example_translation"),
+			Context:            get("context"),
+			Note:               get("note"),
+			Morphemes:          get("morphemes"),
+			Tags:               tags,
+			Folders:            folders,
+			IsStarred:          hasTag(n.Tags, "marked"),
+			SrsState:           n.Card.State,
+			EaseFactor:         n.Card.EaseFactor,
+			IntervalDays:       n.Card.IntervalDays,
+			Lapses:             n.Card.Lapses,
+			ReviewCount:        n.Card.Reps,
+			NextReviewAt:       n.Card.Due,
+		}, srs: n.Card.State == apkg.StateReview})
+	}
+	return records, nil
+}
+
+// getFolder 获取用户的文件夹
+func (s *VocabularyTransferService) getFolder(userID, folderID uint) (*model.VocabularyFolder, error) {
+	var folder model.VocabularyFolder
+	err := s.db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// parseTags 解析生词的标签（JSON 数组，兼容逗号分隔）
+func parseTags(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(raw), &tags); err == nil {
+		return tags
+	}
+	return splitList(strings.ReplaceAll(raw, ",", ";"))
+}
+
+// guessVocabularyType 未指定类型时按内容推断：含空格的短内容为短语，长内容或带句末标点的为句子
+func guessVocabularyType(content string) model.VocabularyType {
+	words := strings.Fields(content)
+	switch {
+	case len(words) <= 1:
+		return model.VocabularyTypeWord
+	case len(words) > 6 || strings.ContainsAny(content[len(content)-1:], ".?!。？！"):
+		return model.VocabularyTypeSentence
+	default:
+		return model.VocabularyTypePhrase
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(strings.ToLower(s))
+	return b || s == "1" || s == "是"
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atof(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
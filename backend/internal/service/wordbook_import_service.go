@@ -0,0 +1,616 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// 单词书内容导入模式：已有词条的字段如何处理
+const (
+	WordbookImportFill      = "fill"      // 只补充已有词条中为空的字段
+	WordbookImportOverwrite = "overwrite" // 用导入的非空字段覆盖已有词条
+)
+
+// maxWordbookImportItems 单个导入文件的最大条数
+const maxWordbookImportItems = 50000
+
+var (
+	ErrWordbookInfoMissing      = errors.New("单词书不存在，请指定名称以创建单词书信息")
+	ErrWordbookImportCustomBook = errors.New("不能向用户自建的单词书导入内容")
+	ErrInvalidWordbookImport    = errors.New("导入文件格式错误")
+)
+
+// WordbookImportEntry 导入的一条词条，字段与 vp_wordbook 一致
+// JSON 列（examples_json、phrases、word_forms）在 JSON 文件中可以是 JSON 值，也可以是 JSON 字符串；CSV 中为 JSON 文本
+type WordbookImportEntry struct {
+	Word               string          `json:"word"`
+	Phonetic           string          `json:"phonetic"`
+	Frequency          uint            `json:"frequency"`
+	Difficulty         uint8           `json:"difficulty"`
+	Source             string          `json:"source"`
+	Meaning            string          `json:"meaning"`
+	MeaningAnalysis    string          `json:"meaning_analysis"`
+	Example            string          `json:"example"`
+	ExampleTranslation string          `json:"example_translation"`
+	ExamplesJSON       json.RawMessage `json:"examples_json"`
+	Phrases            json.RawMessage `json:"phrases"`
+	Root               string          `json:"root"`
+	RootAnalysis       string          `json:"root_analysis"`
+	Affix              string          `json:"affix"`
+	AffixAnalysis      string          `json:"affix_analysis"`
+	Etymology          string          `json:"etymology"`
+	CulturalBackground string          `json:"cultural_background"`
+	WordForms          json.RawMessage `json:"word_forms"`
+	MemoryTips         string          `json:"memory_tips"`
+	StoryEn            string          `json:"story_en"`
+	StoryCn            string          `json:"story_cn"`
+	DrawExplain        string          `json:"draw_explain"`
+	DrawPrompt         string          `json:"draw_prompt"`
+	ImageURL           string          `json:"image_url"`
+	AnalysisFull       string          `json:"analysis_full"`
+
+	line     int   // 在文件中的位置（CSV 为行号，JSON 为第几条），用于报告
+	parseErr error // CSV 中无法解析的数值列
+}
+
+// WordbookImportOptions 导入选项
+type WordbookImportOptions struct {
+	Book        string // 单词书类型，如 cet4
+	Name        string // 单词书不存在时用于创建单词书信息
+	Category    string
+	Description string
+	Mode        string // fill（默认）/ overwrite
+	RepairLinks bool   // 删除单词书中的悬空关联和重复关联
+	DryRun      bool   // 只生成报告，不写入数据库
+}
+
+// WordbookImportIssue 未通过校验的条目
+type WordbookImportIssue struct {
+	Line   int    `json:"line"`
+	Word   string `json:"word"`
+	Reason string `json:"reason"`
+}
+
+// WordbookWordDiff 已有词条的字段变化
+type WordbookWordDiff struct {
+	ID     uint     `json:"id"`
+	Word   string   `json:"word"`
+	Fields []string `json:"fields"`
+}
+
+// WordbookHeadwordConflict 词典中同一个单词有多条词条（导入时关联 ID 最小的一条）
+type WordbookHeadwordConflict struct {
+	Word string `json:"word"`
+	IDs  []uint `json:"ids"`
+}
+
+// WordbookImportReport 导入报告（dry-run 时为将要执行的变更）
+type WordbookImportReport struct {
+	Book            string                         `json:"book"`
+	DryRun          bool                           `json:"dry_run"`
+	BookCreated     bool                           `json:"book_created"`
+	Total           int                            `json:"total"`
+	Invalid         []WordbookImportIssue          `json:"invalid"`
+	DuplicateInFile []WordbookImportIssue          `json:"duplicate_in_file"`
+	Created         []string                       `json:"created"`   // 新建的词条
+	Updated         []WordbookWordDiff             `json:"updated"`   // 更新了字段的已有词条
+	Unchanged       int                            `json:"unchanged"` // 已有且无需更新的词条
+	Conflicts       []WordbookHeadwordConflict     `json:"conflicts"`
+	Linked          int                            `json:"linked"`         // 新加入单词书的单词
+	AlreadyLinked   int                            `json:"already_linked"` // 已在单词书中的单词
+	LinkIssues      *repository.WordbookLinkIssues `json:"link_issues"`
+	LinksRepaired   int                            `json:"links_repaired"`
+	WordCountBefore int64                          `json:"word_count_before"`
+	WordCountAfter  int64                          `json:"word_count_after"`
+}
+
+// WordbookImportService 单词书内容导入服务
+// 按单词（不区分大小写）在词典中去重：已有词条直接关联到单词书并按模式补充字段，没有的新建词条
+type WordbookImportService struct {
+	db *gorm.DB
+}
+
+func NewWordbookImportService(db *gorm.DB) *WordbookImportService {
+	return &WordbookImportService{db: db}
+}
+
+// ParseWordbookImport 解析导入文件，format 为 json 或 csv
+// JSON 为词条数组；CSV 第一行为表头，列名与 JSON 字段名相同
+func ParseWordbookImport(data []byte, format string) ([]WordbookImportEntry, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var entries []WordbookImportEntry
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWordbookImport, err)
+		}
+		for i := range entries {
+			entries[i].line = i + 1
+		}
+	case "csv":
+		var err error
+		if entries, err = parseWordbookCSV(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedTransferFormat
+	}
+	if len(entries) > maxWordbookImportItems {
+		return nil, fmt.Errorf("%w: 单个文件最多 %d 条", ErrInvalidWordbookImport, maxWordbookImportItems)
+	}
+	return entries, nil
+}
+
+func parseWordbookCSV(data []byte) ([]WordbookImportEntry, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: CSV 文件为空", ErrInvalidWordbookImport)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWordbookImport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := columns["word"]; !ok {
+		return nil, fmt.Errorf("%w: CSV 缺少 word 列", ErrInvalidWordbookImport)
+	}
+
+	var entries []WordbookImportEntry
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWordbookImport, err)
+		}
+		line, _ := r.FieldPos(0)
+		get := func(field string) string {
+			if idx, ok := columns[field]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+		raw := func(field string) json.RawMessage {
+			if v := get(field); v != "" {
+				return json.RawMessage(v)
+			}
+			return nil
+		}
+		var parseErr error
+		number := func(field string, bits int) uint64 {
+			v := get(field)
+			if v == "" {
+				return 0
+			}
+			n, err := strconv.ParseUint(v, 10, bits)
+			if err != nil && parseErr == nil {
+				parseErr = fmt.Errorf("%s 不是合法的数字: %s", field, v)
+			}
+			return n
+		}
+		frequency := number("frequency", 32)
+		difficulty := number("difficulty", 8)
+		entries = append(entries, WordbookImportEntry{
+			Word:               get("word"),
+			Phonetic:           get("phonetic"),
+			Frequency:          uint(frequency),
+			Difficulty:         uint8(difficulty),
+			Source:             get("source"),
+			Meaning:            get("meaning"),
+			MeaningAnalysis:    get("meaning_analysis"),
+			Example:            get("example"),
+			ExampleTranslation: get("example_translation"),
+			ExamplesJSON:       raw("examples_json"),
+			Phrases:            raw("phrases"),
+			Root:               get("root"),
+			RootAnalysis:       get("root_analysis"),
+			Affix:              get("affix"),
+			AffixAnalysis:      get("affix_analysis"),
+			Etymology:          get("etymology"),
+			CulturalBackground: get("cultural_background"),
+			WordForms:          raw("word_forms"),
+			MemoryTips:         get("memory_tips"),
+			StoryEn:            get("story_en"),
+			StoryCn:            get("story_cn"),
+			DrawExplain:        get("draw_explain"),
+			DrawPrompt:         get("draw_prompt"),
+			ImageURL:           get("image_url"),
+			AnalysisFull:       get("analysis_full"),
+			line:               line,
+			parseErr:           parseErr,
+		})
+	}
+	return entries, nil
+}
+
+// Import 校验并导入词条，生成差异报告；DryRun 时不写入数据库
+func (s *WordbookImportService) Import(entries []WordbookImportEntry, opts WordbookImportOptions) (*WordbookImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = WordbookImportFill
+	}
+	if opts.Mode != WordbookImportFill && opts.Mode != WordbookImportOverwrite {
+		return nil, fmt.Errorf("未知的导入模式: %s", opts.Mode)
+	}
+
+	report := &WordbookImportReport{
+		Book:            opts.Book,
+		DryRun:          opts.DryRun,
+		Total:           len(entries),
+		Invalid:         []WordbookImportIssue{},
+		DuplicateInFile: []WordbookImportIssue{},
+		Created:         []string{},
+		Updated:         []WordbookWordDiff{},
+		Conflicts:       []WordbookHeadwordConflict{},
+	}
+
+	repo := repository.NewWordbookRepository(s.db)
+	info, err := repo.GetWordbookInfo(opts.Book)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if opts.Name == "" {
+			return nil, ErrWordbookInfoMissing
+		}
+		info = &model.WordbookInfo{
+			Type:        opts.Book,
+			Name:        opts.Name,
+			Description: opts.Description,
+			Category:    opts.Category,
+			Visibility:  model.WordbookVisibilityPublic,
+			IsActive:    true,
+		}
+		report.BookCreated = true
+	case err != nil:
+		return nil, err
+	case info.IsCustom():
+		return nil, ErrWordbookImportCustomBook
+	}
+
+	// 1. 校验并在文件内去重
+	words := make([]model.Wordbook, 0, len(entries))
+	index := make(map[string]int, len(entries))
+	for _, e := range entries {
+		w, err := e.toWordbook()
+		if err != nil {
+			report.Invalid = append(report.Invalid, WordbookImportIssue{Line: e.line, Word: e.Word, Reason: err.Error()})
+			continue
+		}
+		key := strings.ToLower(w.Word)
+		if first, ok := index[key]; ok {
+			report.DuplicateInFile = append(report.DuplicateInFile, WordbookImportIssue{
+				Line:   e.line,
+				Word:   w.Word,
+				Reason: fmt.Sprintf("与第 %d 条重复，已忽略", entries[first].line),
+			})
+			continue
+		}
+		index[key] = len(words)
+		words = append(words, w)
+	}
+
+	// 2. 在词典中按单词查找已有词条
+	existing := make(map[string][]model.Wordbook, len(words))
+	for start := 0; start < len(words); start += 500 {
+		end := start + 500
+		if end > len(words) {
+			end = len(words)
+		}
+		headwords := make([]string, 0, end-start)
+		for _, w := range words[start:end] {
+			headwords = append(headwords, w.Word)
+		}
+		found, err := repo.FindByWords(headwords)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			key := strings.ToLower(f.Word)
+			existing[key] = append(existing[key], f)
+		}
+	}
+
+	linkedIDs, err := repo.GetBookWordIDs(opts.Book)
+	if err != nil {
+		return nil, err
+	}
+	linked := make(map[uint]bool, len(linkedIDs))
+	for _, id := range linkedIDs {
+		linked[id] = true
+	}
+
+	// 3. 生成变更：新建、更新、关联
+	var creates []model.Wordbook
+	type wordUpdate struct {
+		id     uint
+		fields map[string]interface{}
+	}
+	var updates []wordUpdate
+	var linkIDs []uint
+	for _, w := range words {
+		matches := existing[strings.ToLower(w.Word)]
+		if len(matches) == 0 {
+			w.WordType = opts.Book
+			if w.Difficulty == 0 {
+				w.Difficulty = 3
+			}
+			creates = append(creates, w)
+			report.Created = append(report.Created, w.Word)
+			report.Linked++
+			continue
+		}
+		if len(matches) > 1 {
+			conflict := WordbookHeadwordConflict{Word: w.Word}
+			for _, m := range matches {
+				conflict.IDs = append(conflict.IDs, m.ID)
+			}
+			report.Conflicts = append(report.Conflicts, conflict)
+		}
+
+		current := matches[0]
+		fields, changed := wordbookChanges(&current, &w, opts.Mode)
+		if len(changed) > 0 {
+			report.Updated = append(report.Updated, WordbookWordDiff{ID: current.ID, Word: current.Word, Fields: changed})
+			updates = append(updates, wordUpdate{id: current.ID, fields: fields})
+		} else {
+			report.Unchanged++
+		}
+		if linked[current.ID] {
+			report.AlreadyLinked++
+		} else {
+			linked[current.ID] = true
+			linkIDs = append(linkIDs, current.ID)
+			report.Linked++
+		}
+	}
+
+	report.LinkIssues, err = repo.FindLinkIssues(opts.Book)
+	if err != nil {
+		return nil, err
+	}
+	report.WordCountBefore, err = repo.CountBookWords(opts.Book)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		report.WordCountAfter = report.WordCountBefore + int64(report.Linked)
+		return report, nil
+	}
+
+	// 4. 写入
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewWordbookRepository(tx)
+		if report.BookCreated {
+			if err := txRepo.SaveWordbookInfo(info); err != nil {
+				return err
+			}
+		}
+		if err := txRepo.CreateWords(creates); err != nil {
+			return err
+		}
+		for _, w := range creates {
+			linkIDs = append(linkIDs, w.ID)
+		}
+		for _, u := range updates {
+			if err := txRepo.UpdateWordFields(u.id, u.fields); err != nil {
+				return err
+			}
+		}
+		if _, err := txRepo.AddWordsToBook(opts.Book, linkIDs); err != nil {
+			return err
+		}
+		if opts.RepairLinks {
+			ids := append(append([]uint{}, report.LinkIssues.Orphaned...), report.LinkIssues.Duplicated...)
+			if err := txRepo.DeleteLinks(ids); err != nil {
+				return err
+			}
+			report.LinksRepaired = len(ids)
+		}
+		report.WordCountAfter, err = txRepo.UpdateWordCount(opts.Book)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// toWordbook 校验条目并转换为词条
+func (e *WordbookImportEntry) toWordbook() (model.Wordbook, error) {
+	w := model.Wordbook{
+		Word:               strings.TrimSpace(e.Word),
+		Phonetic:           strings.TrimSpace(e.Phonetic),
+		Frequency:          e.Frequency,
+		Difficulty:         e.Difficulty,
+		Source:             strings.TrimSpace(e.Source),
+		Meaning:            e.Meaning,
+		MeaningAnalysis:    e.MeaningAnalysis,
+		Example:            e.Example,
+		ExampleTranslation: e.ExampleTranslation,
+		Root:               e.Root,
+		RootAnalysis:       e.RootAnalysis,
+		Affix:              e.Affix,
+		AffixAnalysis:      e.AffixAnalysis,
+		Etymology:          e.Etymology,
+		CulturalBackground: e.CulturalBackground,
+		MemoryTips:         e.MemoryTips,
+		StoryEn:            e.StoryEn,
+		StoryCn:            e.StoryCn,
+		DrawExplain:        e.DrawExplain,
+		DrawPrompt:         e.DrawPrompt,
+		ImageURL:           e.ImageURL,
+		AnalysisFull:       e.AnalysisFull,
+	}
+	if e.parseErr != nil {
+		return w, e.parseErr
+	}
+	if w.Word == "" {
+		return w, errors.New("单词为空")
+	}
+	if w.Difficulty > 5 {
+		return w, fmt.Errorf("difficulty 应在 1-5 之间: %d", w.Difficulty)
+	}
+	if w.Source == model.WordbookSourceCustom {
+		return w, fmt.Errorf("source 不能为 %s（自建单词书保留）", model.WordbookSourceCustom)
+	}
+	for _, c := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"word", w.Word, 100},
+		{"phonetic", w.Phonetic, 100},
+		{"source", w.Source, 50},
+		{"root", w.Root, 100},
+		{"affix", w.Affix, 100},
+		{"draw_prompt", w.DrawPrompt, 500},
+		{"image_url", w.ImageURL, 500},
+	} {
+		if utf8.RuneCountInString(c.value) > c.max {
+			return w, fmt.Errorf("%s 超过 %d 个字符", c.name, c.max)
+		}
+	}
+
+	var err error
+	if w.ExamplesJSON, err = normalizeJSONColumn("examples_json", e.ExamplesJSON, validateJSONArray); err != nil {
+		return w, err
+	}
+	if w.Phrases, err = normalizeJSONColumn("phrases", e.Phrases, validateJSONArray); err != nil {
+		return w, err
+	}
+	if w.WordForms, err = normalizeJSONColumn("word_forms", e.WordForms, validateWordForms); err != nil {
+		return w, err
+	}
+	return w, nil
+}
+
+// normalizeJSONColumn 把 JSON 列的值规整为紧凑的 JSON 文本并校验结构，null 和空值返回空字符串
+// 值本身是 JSON 字符串时按其内容解析（兼容把 JSON 列导出为字符串的数据）
+func normalizeJSONColumn(name string, raw json.RawMessage, validate func(interface{}) error) (string, error) {
+	text := bytes.TrimSpace(raw)
+	if len(text) > 0 && text[0] == '"' {
+		var s string
+		if err := json.Unmarshal(text, &s); err != nil {
+			return "", fmt.Errorf("%s 不是合法的 JSON: %v", name, err)
+		}
+		text = bytes.TrimSpace([]byte(s))
+	}
+	if len(text) == 0 || string(text) == "null" {
+		return "", nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(text, &value); err != nil {
+		return "", fmt.Errorf("%s 不是合法的 JSON: %v", name, err)
+	}
+	if err := validate(value); err != nil {
+		return "", fmt.Errorf("%s %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, text); err != nil {
+		return "", fmt.Errorf("%s 不是合法的 JSON: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// validateJSONArray examples_json、phrases 应为数组
+func validateJSONArray(v interface{}) error {
+	if _, ok := v.([]interface{}); !ok {
+		return errors.New("应为 JSON 数组")
+	}
+	return nil
+}
+
+// validateWordForms word_forms 应为对象，值为字符串或字符串数组（与词形还原词表的解析一致）
+func validateWordForms(v interface{}) error {
+	forms, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("应为 JSON 对象")
+	}
+	for key, value := range forms {
+		switch val := value.(type) {
+		case string:
+		case []interface{}:
+			for _, item := range val {
+				if _, ok := item.(string); !ok {
+					return fmt.Errorf("的 %s 应为字符串数组", key)
+				}
+			}
+		default:
+			return fmt.Errorf("的 %s 应为字符串或字符串数组", key)
+		}
+	}
+	return nil
+}
+
+// wordbookChanges 计算已有词条需要更新的字段（列名 -> 新值）和变化的字段名
+// fill 模式只写入已有词条中为空的字段，overwrite 模式写入所有与导入值不同的非空字段
+func wordbookChanges(current, incoming *model.Wordbook, mode string) (map[string]interface{}, []string) {
+	fields := make(map[string]interface{})
+	var changed []string
+	set := func(column string, cur, next interface{}, empty func(interface{}) bool, equal func(a, b interface{}) bool) {
+		if empty(next) || equal(cur, next) {
+			return
+		}
+		if mode == WordbookImportFill && !empty(cur) {
+			return
+		}
+		fields[column] = next
+		changed = append(changed, column)
+	}
+	emptyString := func(v interface{}) bool { return strings.TrimSpace(v.(string)) == "" }
+	equalString := func(a, b interface{}) bool { return a.(string) == b.(string) }
+	emptyNumber := func(v interface{}) bool { return reflect.ValueOf(v).IsZero() }
+	equalNumber := func(a, b interface{}) bool { return a == b }
+
+	set("phonetic", current.Phonetic, incoming.Phonetic, emptyString, equalString)
+	set("frequency", current.Frequency, incoming.Frequency, emptyNumber, equalNumber)
+	set("difficulty", current.Difficulty, incoming.Difficulty, emptyNumber, equalNumber)
+	set("source", current.Source, incoming.Source, emptyString, equalString)
+	set("meaning", current.Meaning, incoming.Meaning, emptyString, equalString)
+	set("meaning_analysis", current.MeaningAnalysis, incoming.MeaningAnalysis, emptyString, equalString)
+	set("example", current.Example, incoming.Example, emptyString, equalString)
+	set("example_translation", current.ExampleTranslation, incoming.ExampleTranslation, emptyString, equalString)
+	set("examples_json", current.ExamplesJSON, incoming.ExamplesJSON, emptyString, equalJSON)
+	set("phrases", current.Phrases, incoming.Phrases, emptyString, equalJSON)
+	set("root", current.Root, incoming.Root, emptyString, equalString)
+	set("root_analysis", current.RootAnalysis, incoming.RootAnalysis, emptyString, equalString)
+	set("affix", current.Affix, incoming.Affix, emptyString, equalString)
+	set("affix_analysis", current.AffixAnalysis, incoming.AffixAnalysis, emptyString, equalString)
+	set("etymology", current.Etymology, incoming.Etymology, emptyString, equalString)
+	set("cultural_background", current.CulturalBackground, incoming.CulturalBackground, emptyString, equalString)
+	set("word_forms", current.WordForms, incoming.WordForms, emptyString, equalJSON)
+	set("memory_tips", current.MemoryTips, incoming.MemoryTips, emptyString, equalString)
+	set("story_en", current.StoryEn, incoming.StoryEn, emptyString, equalString)
+	set("story_cn", current.StoryCn, incoming.StoryCn, emptyString, equalString)
+	set("draw_explain", current.DrawExplain, incoming.DrawExplain, emptyString, equalString)
+	set("draw_prompt", current.DrawPrompt, incoming.DrawPrompt, emptyString, equalString)
+	set("image_url", current.ImageURL, incoming.ImageURL, emptyString, equalString)
+	set("analysis_full", current.AnalysisFull, incoming.AnalysisFull, emptyString, equalString)
+	return fields, changed
+}
+
+// equalJSON 按内容比较两段 JSON（数据库返回的 JSON 格式可能与导入文件不同）
+func equalJSON(a, b interface{}) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a.(string)), &va) != nil || json.Unmarshal([]byte(b.(string)), &vb) != nil {
+		return a.(string) == b.(string)
+	}
+	return reflect.DeepEqual(va, vb)
+}
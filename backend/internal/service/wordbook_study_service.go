@@ -0,0 +1,347 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"voicepaper/internal/model"
+	"voicepaper/internal/repository"
+	"voicepaper/pkg/srs"
+
+	"gorm.io/gorm"
+)
+
+// maxWordbookNewPerDay 每日新词数上限（背完日期很近时也不超过该值）
+const maxWordbookNewPerDay = 500
+
+// 单词书学习错误
+var (
+	ErrWordNotInWordbook  = errors.New("该单词不在这本单词书中")
+	ErrInvalidTargetDate  = errors.New("背完日期格式应为 YYYY-MM-DD，且不能早于今天")
+	ErrInvalidStudyAnswer = errors.New("无效的评分，仅支持 forget、fuzzy、know")
+)
+
+// WordbookStudyService 单词书学习服务
+// 单词书中学过的每个单词都是一张间隔重复卡片（vp_wordbook_cards），与生词本使用同一个调度器。
+// 每日计划 = 到期复习 + 按学习序列（顺序/乱序）取的新词；设置了背完日期时，每日新词数按剩余单词和剩余天数计算。
+// 复习间隔达到 model.WordbookMasteredInterval 天的单词视为已掌握，单词书进度按掌握数计算。
+type WordbookStudyService struct {
+	wordbookRepo *repository.WordbookRepository
+	cardRepo     *repository.WordbookCardRepository
+	srsService   *SRSService
+	calendar     *CalendarService
+}
+
+func NewWordbookStudyService(db *gorm.DB, calendar *CalendarService) *WordbookStudyService {
+	return &WordbookStudyService{
+		wordbookRepo: repository.NewWordbookRepository(db),
+		cardRepo:     repository.NewWordbookCardRepository(db),
+		srsService:   NewSRSService(db),
+		calendar:     calendar,
+	}
+}
+
+// WordbookPlanItem 计划中的一个单词
+type WordbookPlanItem struct {
+	Word model.Wordbook      `json:"word"`
+	Card *model.WordbookCard `json:"card,omitempty"` // 新词为空
+	Kind string              `json:"kind"`           // new/review
+}
+
+// WordbookStudyProgress 单词书学习进度（按记忆状态，而不只是学习位置）
+type WordbookStudyProgress struct {
+	TotalWords      int     `json:"total_words"`
+	Learned         int64   `json:"learned"`          // 学过的单词
+	Learning        int64   `json:"learning"`         // 学习/重学中
+	Review          int64   `json:"review"`           // 已毕业，按间隔复习
+	Mastered        int64   `json:"mastered"`         // 已掌握
+	Remaining       int     `json:"remaining"`        // 还没学过的单词
+	MasteredPercent float64 `json:"mastered_percent"` // 掌握进度（0-100）
+}
+
+// WordbookStudyPlan 今日学习计划
+type WordbookStudyPlan struct {
+	WordType        string                 `json:"word_type"`
+	Reviews         []WordbookPlanItem     `json:"reviews"`   // 今日到期的复习
+	NewWords        []WordbookPlanItem     `json:"new_words"` // 今日还要学的新词
+	NewPerDay       int                    `json:"new_per_day"`
+	NewStudiedToday int                    `json:"new_studied_today"`
+	DueCount        int64                  `json:"due_count"` // 今日到期的复习总数（可能超过每日复习上限）
+	TargetDate      *time.Time             `json:"target_date,omitempty"`
+	DaysLeft        int                    `json:"days_left,omitempty"` // 距背完日期的天数（含今天）
+	Progress        *WordbookStudyProgress `json:"progress"`
+}
+
+// UpdateWordbookPlanRequest 更新学习计划请求
+type UpdateWordbookPlanRequest struct {
+	TargetDate *string `json:"target_date"`                                   // YYYY-MM-DD，空字符串表示取消
+	NewPerDay  *int    `json:"new_per_day" binding:"omitempty,min=0,max=500"` // 未设置背完日期时的每日新词数，0 表示使用复习设置
+}
+
+// WordbookAnswerResult 提交学习结果返回
+type WordbookAnswerResult struct {
+	Card         *model.WordbookCard `json:"card"`
+	IsNew        bool                `json:"is_new"`        // 是否第一次学习该单词
+	JustMastered bool                `json:"just_mastered"` // 本次刚达到掌握
+}
+
+// GetPlan 获取今日学习计划
+func (s *WordbookStudyService) GetPlan(userID uint, wordType string) (*WordbookStudyPlan, error) {
+	order, err := s.wordbookRepo.GetOrCreateUserOrder(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.srsService.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.calendar.Now()
+	today := s.calendar.Today(userID)
+	studiedToday, err := s.cardRepo.CountCreatedSince(userID, wordType, s.calendar.DayStart(userID, today))
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &WordbookStudyPlan{
+		WordType:        wordType,
+		NewStudiedToday: int(studiedToday),
+		TargetDate:      order.TargetDate,
+	}
+
+	plan.Progress, err = s.progress(userID, wordType, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每日新词数：按背完日期平均分配（今天已学的也算在今天的份额内），否则使用设置值
+	remaining := plan.Progress.Remaining
+	switch {
+	case order.TargetDate != nil:
+		plan.DaysLeft = daysBetween(today, s.calendar.Date(*order.TargetDate)) + 1
+		if plan.DaysLeft < 1 {
+			plan.DaysLeft = 1
+		}
+		plan.NewPerDay = (remaining + plan.NewStudiedToday + plan.DaysLeft - 1) / plan.DaysLeft
+	case order.NewPerDay > 0:
+		plan.NewPerDay = order.NewPerDay
+	default:
+		plan.NewPerDay = settings.NewCardsPerDay
+	}
+	if plan.NewPerDay > maxWordbookNewPerDay {
+		plan.NewPerDay = maxWordbookNewPerDay
+	}
+
+	// 新词：按学习序列取还没学过的单词
+	newWords := []model.Wordbook{}
+	if limit := plan.NewPerDay - plan.NewStudiedToday; limit > 0 {
+		newWords, err = s.wordbookRepo.GetUnlearnedWords(order, 0, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 复习：今天内到期的复习卡片，学习/重学步骤中的卡片只取马上到期的
+	cards, err := s.cardRepo.GetDue(userID, wordType, s.calendar.TomorrowStart(userID), 0)
+	if err != nil {
+		return nil, err
+	}
+	reviewCards := make([]model.WordbookCard, 0, len(cards))
+	for _, card := range cards {
+		if card.SrsState != srs.StateReview && card.NextReviewAt.After(now.Add(learnAheadLimit)) {
+			continue
+		}
+		plan.DueCount++
+		if len(reviewCards) < settings.ReviewsPerDay {
+			reviewCards = append(reviewCards, card)
+		}
+	}
+
+	reviewIDs := make([]uint, len(reviewCards))
+	for i, card := range reviewCards {
+		reviewIDs[i] = card.WordID
+	}
+	words, err := s.wordbookRepo.GetWordsByIDs(reviewIDs)
+	if err != nil {
+		return nil, err
+	}
+	wordMap := make(map[uint]model.Wordbook, len(words))
+	for _, w := range words {
+		wordMap[w.ID] = w
+	}
+
+	plan.Reviews = make([]WordbookPlanItem, 0, len(reviewCards))
+	for i := range reviewCards {
+		if w, ok := wordMap[reviewCards[i].WordID]; ok {
+			plan.Reviews = append(plan.Reviews, WordbookPlanItem{Word: w, Card: &reviewCards[i], Kind: model.ReviewItemReview})
+		}
+	}
+	plan.NewWords = make([]WordbookPlanItem, 0, len(newWords))
+	for _, w := range newWords {
+		plan.NewWords = append(plan.NewWords, WordbookPlanItem{Word: w, Kind: model.ReviewItemNew})
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan 设置背完日期和每日新词数
+func (s *WordbookStudyService) UpdatePlan(userID uint, wordType string, req *UpdateWordbookPlanRequest) (*WordbookStudyPlan, error) {
+	order, err := s.wordbookRepo.GetOrCreateUserOrder(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+
+	targetDate := order.TargetDate
+	if req.TargetDate != nil {
+		targetDate = nil
+		if *req.TargetDate != "" {
+			date, err := time.ParseInLocation("2006-01-02", *req.TargetDate, time.Local)
+			if err != nil || date.Before(s.calendar.Today(userID)) {
+				return nil, ErrInvalidTargetDate
+			}
+			targetDate = &date
+		}
+	}
+	newPerDay := order.NewPerDay
+	if req.NewPerDay != nil {
+		newPerDay = *req.NewPerDay
+	}
+
+	if err := s.wordbookRepo.UpdateUserOrderPlan(userID, wordType, targetDate, newPerDay); err != nil {
+		return nil, err
+	}
+	return s.GetPlan(userID, wordType)
+}
+
+// Answer 提交一个单词的学习结果（forget/fuzzy/know），第一次学习时创建卡片
+func (s *WordbookStudyService) Answer(userID uint, wordType string, wordID uint, answer string) (*WordbookAnswerResult, error) {
+	inBook, err := s.wordbookRepo.InBook(wordID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	if !inBook {
+		return nil, ErrWordNotInWordbook
+	}
+
+	result := &WordbookAnswerResult{}
+	card, err := s.cardRepo.Get(userID, wordType, wordID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		card = &model.WordbookCard{
+			UserID:     userID,
+			WordType:   wordType,
+			WordID:     wordID,
+			EaseFactor: srs.DefaultEaseFactor,
+			SrsState:   srs.StateNew,
+		}
+		result.IsNew = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	quality, ok := wordbookQuality(answer, srs.StateOf(cardFromWordbookCard(card)) == srs.StateNew)
+	if !ok {
+		return nil, ErrInvalidStudyAnswer
+	}
+
+	now := s.calendar.Now()
+	srsCard := cardFromWordbookCard(card)
+	s.srsService.SchedulerFor(userID).Schedule(&srsCard, quality, now)
+	applyCardToWordbookCard(card, srsCard)
+	card.ReviewCount++
+
+	// 间隔达到阈值视为掌握，遗忘（回到重学）后取消
+	switch {
+	case card.SrsState == srs.StateReview && card.IntervalDays >= model.WordbookMasteredInterval:
+		if card.MasteredAt == nil {
+			card.MasteredAt = &now
+			result.JustMastered = true
+		}
+	case card.SrsState != srs.StateReview:
+		card.MasteredAt = nil
+	}
+
+	if err := s.cardRepo.Save(card); err != nil {
+		return nil, err
+	}
+	result.Card = card
+	return result, nil
+}
+
+// GetProgress 获取单词书学习进度
+func (s *WordbookStudyService) GetProgress(userID uint, wordType string) (*WordbookStudyProgress, error) {
+	order, err := s.wordbookRepo.GetOrCreateUserOrder(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(userID, wordType, order)
+}
+
+func (s *WordbookStudyService) progress(userID uint, wordType string, order *model.WordbookUserOrder) (*WordbookStudyProgress, error) {
+	stats, err := s.cardRepo.GetStats(userID, wordType)
+	if err != nil {
+		return nil, err
+	}
+	progress := &WordbookStudyProgress{
+		TotalWords: order.TotalWords,
+		Learned:    stats.Learned,
+		Learning:   stats.Learning,
+		Review:     stats.Review,
+		Mastered:   stats.Mastered,
+		Remaining:  order.TotalWords - int(stats.Learned),
+	}
+	if progress.Remaining < 0 {
+		progress.Remaining = 0 // 从单词书移除的单词仍保留卡片
+	}
+	if order.TotalWords > 0 {
+		progress.MasteredPercent = float64(stats.Mastered) * 100 / float64(order.TotalWords)
+	}
+	return progress, nil
+}
+
+// wordbookQuality 单词书的三档评分转换为 SM-2 评分
+// 新词选「认识」直接毕业（评分 5），复习时为正常记住（评分 4）
+func wordbookQuality(answer string, isNew bool) (int, bool) {
+	switch answer {
+	case "forget":
+		return 1, true
+	case "fuzzy":
+		return 3, true
+	case "know":
+		if isNew {
+			return 5, true
+		}
+		return 4, true
+	}
+	return 0, false
+}
+
+// cardFromWordbookCard 单词书卡片转换为调度器使用的卡片
+func cardFromWordbookCard(c *model.WordbookCard) srs.Card {
+	return srs.Card{
+		EaseFactor:   c.EaseFactor,
+		IntervalDays: c.IntervalDays,
+		Repetitions:  c.Repetitions,
+		MasteryLevel: c.MasteryLevel,
+		Stability:    c.Stability,
+		Difficulty:   c.Difficulty,
+		Lapses:       c.Lapses,
+		State:        c.SrsState,
+		Step:         c.LearningStep,
+		LastReviewAt: c.LastReviewAt,
+		NextReviewAt: c.NextReviewAt,
+	}
+}
+
+// applyCardToWordbookCard 把调度结果写回单词书卡片
+func applyCardToWordbookCard(c *model.WordbookCard, card srs.Card) {
+	c.EaseFactor = card.EaseFactor
+	c.IntervalDays = card.IntervalDays
+	c.Repetitions = card.Repetitions
+	c.MasteryLevel = card.MasteryLevel
+	c.Stability = card.Stability
+	c.Difficulty = card.Difficulty
+	c.Lapses = card.Lapses
+	c.SrsState = srs.StateOf(card)
+	c.LearningStep = card.Step
+	c.LastReviewAt = card.LastReviewAt
+	c.NextReviewAt = card.NextReviewAt
+}
@@ -0,0 +1,123 @@
+// Package apkg Anki 牌组包（.apkg）的生成和解析
+//
+// .apkg 是一个 zip 文件，包含 collection.anki2（SQLite 数据库，Anki 2.1 旧版 schema 11）、
+// media（JSON，媒体文件编号 -> 文件名）以及按编号命名的媒体文件。
+// 数据库通过 pkg/sqlite 纯 Go 生成和读取。只支持「兼容旧版本」格式，
+// 新版 Anki 默认导出的 collection.anki21b（zstd 压缩）无法解析。
+package apkg
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 卡片状态（与 pkg/srs 的学习阶段一致）
+const (
+	StateNew        = "new"
+	StateLearning   = "learning"
+	StateReview     = "review"
+	StateRelearning = "relearning"
+)
+
+// 错误定义
+var (
+	ErrNoCollection      = errors.New("牌组包中没有 collection 数据库")
+	ErrUnsupportedFormat = errors.New("不支持新版 Anki 牌组格式，请导出时勾选「兼容旧版本 Anki」")
+)
+
+// fieldSeparator 笔记各字段之间的分隔符
+const fieldSeparator = "\x1f"
+
+// Model 笔记类型（字段和卡片模板）
+type Model struct {
+	Name   string
+	Fields []string
+	QFmt   string // 正面模板，如 {{Front}}
+	AFmt   string // 背面模板
+	CSS    string
+}
+
+// Card 卡片的学习状态
+type Card struct {
+	State        string     // new/learning/review/relearning
+	IntervalDays int        // 复习间隔（天）
+	EaseFactor   float64    // 易度因子，如 2.5
+	Reps         int        // 复习次数
+	Lapses       int        // 遗忘次数
+	Due          *time.Time // 下次复习时间，新卡片为 nil
+}
+
+// Note 一条笔记（对应一张卡片）
+type Note struct {
+	GUID   string   // 为空时根据第一个字段生成
+	Fields []string // 与 Model.Fields 一一对应
+	Tags   []string
+	Card   Card
+}
+
+// Package 要导出的牌组
+type Package struct {
+	Deck        string // 牌组名，用 :: 表示子牌组
+	Description string
+	Model       Model
+	Notes       []Note
+	Media       map[string][]byte // 文件名 -> 内容，字段中用 [sound:文件名] 引用
+}
+
+// ImportedNote 从牌组包中解析出的笔记
+type ImportedNote struct {
+	Model      string
+	FieldNames []string
+	Fields     []string
+	Tags       []string
+	Deck       string
+	Card       Card
+}
+
+// Field 按字段名（不区分大小写）获取字段值，不存在时返回空字符串
+func (n *ImportedNote) Field(name string) string {
+	for i, f := range n.FieldNames {
+		if strings.EqualFold(f, name) && i < len(n.Fields) {
+			return n.Fields[i]
+		}
+	}
+	return ""
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	soundPattern = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	brPattern    = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+)
+
+// StripHTML 把字段内容转为纯文本：换行标签转为换行，去掉其余标签和 [sound:] 引用，反转义实体
+func StripHTML(s string) string {
+	s = soundPattern.ReplaceAllString(s, "")
+	s = brPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(strings.ReplaceAll(s, "\u00a0", " "))
+}
+
+// checksum Anki 的字段校验和：纯文本 SHA1 的前 8 位十六进制
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(StripHTML(field)))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+// guidFor 根据内容生成稳定的 GUID，重复导入同一牌组时 Anki 会识别为同一条笔记
+func guidFor(field string) string {
+	sum := sha1.Sum([]byte("voicepaper:" + field))
+	return hex.EncodeToString(sum[:8])
+}
+
+// dayStart 当天零点
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
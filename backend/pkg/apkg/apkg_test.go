@@ -0,0 +1,223 @@
+package apkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func samplePackage(now time.Time) *Package {
+	reviewDue := dayStart(now).AddDate(0, 0, 5)
+	overdue := dayStart(now).AddDate(0, 0, -3)
+	learningDue := time.Unix(now.Add(10*time.Minute).Unix(), 0)
+
+	pkg := &Package{
+		Deck:        "VoicePaper::生词本",
+		Description: "测试牌组",
+		Model: Model{
+			Name:   "VoicePaper Word",
+			Fields: []string{"Front", "Back", "Audio"},
+			QFmt:   "{{Front}}",
+			AFmt:   "{{FrontSide}}<hr id=answer>{{Back}}",
+		},
+		Media: map[string][]byte{"hello.mp3": []byte("ID3 fake audio")},
+		Notes: []Note{
+			{Fields: []string{"hello", "你好<br>喂", "[sound:hello.mp3]"}, Tags: []string{"greeting", "two words"}},
+			{Fields: []string{"apple", "苹果", ""}, Card: Card{State: StateReview, IntervalDays: 12, EaseFactor: 2.5, Reps: 4, Lapses: 1, Due: &reviewDue}},
+			{Fields: []string{"late", "迟的", ""}, Card: Card{State: StateReview, IntervalDays: 3, EaseFactor: 2.3, Reps: 2, Due: &overdue}},
+			{Fields: []string{"learn", "学习", ""}, Card: Card{State: StateLearning, EaseFactor: 2.5, Reps: 1, Due: &learningDue}},
+			{Fields: []string{"forget", "忘记", ""}, Card: Card{State: StateRelearning, EaseFactor: 2.1, Reps: 6, Lapses: 2, Due: &learningDue}},
+		},
+	}
+	// 足够多的笔记让 notes/cards 表跨越多个页面，长字段产生溢出页
+	for i := 0; i < 1500; i++ {
+		pkg.Notes = append(pkg.Notes, Note{
+			Fields: []string{fmt.Sprintf("word%04d", i), strings.Repeat("释义", 1+i%2000), ""},
+			Tags:   []string{"bulk"},
+		})
+	}
+	return pkg
+}
+
+func writePackage(t *testing.T, pkg *Package) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, pkg); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	pkg := samplePackage(time.Now())
+	notes, err := Read(writePackage(t, pkg))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(notes) != len(pkg.Notes) {
+		t.Fatalf("读出 %d 条笔记, want %d", len(notes), len(pkg.Notes))
+	}
+
+	for i, want := range pkg.Notes {
+		got := notes[i]
+		if got.Model != pkg.Model.Name || got.Deck != pkg.Deck {
+			t.Errorf("笔记 %d: model/deck = %q/%q", i, got.Model, got.Deck)
+		}
+		if !reflect.DeepEqual(got.FieldNames, pkg.Model.Fields) {
+			t.Errorf("笔记 %d: 字段名 = %v, want %v", i, got.FieldNames, pkg.Model.Fields)
+		}
+		if !reflect.DeepEqual(got.Fields, want.Fields) {
+			t.Errorf("笔记 %d: 字段 = %q, want %q", i, got.Fields, want.Fields)
+		}
+		checkCard(t, i, got.Card, want.Card)
+	}
+
+	first := notes[0]
+	if !reflect.DeepEqual(first.Tags, []string{"greeting", "two_words"}) {
+		t.Errorf("标签 = %v, want [greeting two_words]", first.Tags)
+	}
+	if got := first.Field("back"); got != "你好<br>喂" {
+		t.Errorf(`Field("back") = %q`, got)
+	}
+	if got := first.Field("missing"); got != "" {
+		t.Errorf(`Field("missing") = %q, want ""`, got)
+	}
+}
+
+// checkCard 比较学习状态，时间只比较到秒（复习卡片比较到天）
+func checkCard(t *testing.T, i int, got, want Card) {
+	t.Helper()
+	if want.State == "" {
+		want.State = StateNew
+	}
+	if got.State != want.State || got.Reps != want.Reps || got.Lapses != want.Lapses {
+		t.Errorf("笔记 %d: 卡片 = %+v, want %+v", i, got, want)
+		return
+	}
+	if want.State == StateNew {
+		if got.Due != nil {
+			t.Errorf("笔记 %d: 新卡片的到期时间 = %v, want nil", i, got.Due)
+		}
+		return
+	}
+	if got.EaseFactor != want.EaseFactor {
+		t.Errorf("笔记 %d: EaseFactor = %v, want %v", i, got.EaseFactor, want.EaseFactor)
+	}
+	if want.State == StateReview && got.IntervalDays != want.IntervalDays {
+		t.Errorf("笔记 %d: IntervalDays = %d, want %d", i, got.IntervalDays, want.IntervalDays)
+	}
+	wantDue := *want.Due
+	if want.State == StateReview {
+		wantDue = dayStart(wantDue)
+	}
+	if got.Due == nil || !got.Due.Equal(wantDue) {
+		t.Errorf("笔记 %d: Due = %v, want %v", i, got.Due, wantDue)
+	}
+}
+
+func TestWriteMedia(t *testing.T) {
+	data := writePackage(t, samplePackage(time.Now()))
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var index map[string]string
+	if err := json.Unmarshal(files["media"], &index); err != nil {
+		t.Fatalf("media 解析失败: %v", err)
+	}
+	if !reflect.DeepEqual(index, map[string]string{"0": "hello.mp3"}) {
+		t.Errorf("media = %v", index)
+	}
+	if string(files["0"]) != "ID3 fake audio" {
+		t.Errorf("媒体文件内容 = %q", files["0"])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	zipWith := func(name string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create(name)
+		f.Write([]byte("data"))
+		zw.Close()
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"新版格式", zipWith("collection.anki21b"), ErrUnsupportedFormat},
+		{"没有数据库", zipWith("media"), ErrNoCollection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(tt.data); err != tt.want {
+				t.Errorf("Read err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := Read([]byte("not a zip")); err == nil {
+		t.Error("非 zip 文件应该报错")
+	}
+}
+
+// TestCollectionWithSQLite3 用真正的 SQLite 打开生成的 collection.anki2，没有安装 sqlite3 命令行时跳过
+func TestCollectionWithSQLite3(t *testing.T) {
+	bin, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("未安装 sqlite3 命令行工具")
+	}
+	now := time.Now()
+	pkg := samplePackage(now)
+	collection, err := buildCollection(pkg, now)
+	if err != nil {
+		t.Fatalf("buildCollection: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	if err := os.WriteFile(path, collection, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"PRAGMA integrity_check", "ok"},
+		{"SELECT ver, count(*) FROM col", "11|1"},
+		{"SELECT count(*) FROM notes", fmt.Sprint(len(pkg.Notes))},
+		{"SELECT count(*) FROM cards c JOIN notes n ON n.id = c.nid", fmt.Sprint(len(pkg.Notes))},
+		{"SELECT sfld, '[' || tags || ']' FROM notes ORDER BY id LIMIT 1", "hello|[ greeting two_words ]"},
+		{"SELECT type, queue, ivl, factor, reps, lapses FROM cards WHERE nid = (SELECT id FROM notes WHERE sfld = 'apple')", "2|2|12|2500|4|1"},
+		{"SELECT max(length(flds)) FROM notes", "3010"},
+		{"SELECT count(*) FROM revlog", "0"},
+	}
+	for _, tt := range tests {
+		out, err := exec.Command(bin, "-readonly", path, tt.query).CombinedOutput()
+		if err != nil {
+			t.Fatalf("sqlite3 %q: %v\n%s", tt.query, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("sqlite3 %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
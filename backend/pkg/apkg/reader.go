@@ -0,0 +1,220 @@
+package apkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"voicepaper/pkg/sqlite"
+)
+
+// maxCollectionSize 允许解压的 collection 数据库大小上限
+const maxCollectionSize = 200 << 20
+
+// Read 解析 .apkg 文件，返回其中的所有笔记（每条笔记取第一张卡片的学习状态）
+func Read(data []byte) ([]ImportedNote, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	var file *zip.File
+	for _, name := range []string{"collection.anki21", "collection.anki2"} {
+		if f, ok := files[name]; ok {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		if _, ok := files["collection.anki21b"]; ok {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, ErrNoCollection
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	raw, err := io.ReadAll(io.LimitReader(rc, maxCollectionSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxCollectionSize {
+		return nil, sqlite.ErrCorrupt
+	}
+
+	db, err := sqlite.Open(raw)
+	if err != nil {
+		return nil, err
+	}
+	return readCollection(db)
+}
+
+func readCollection(db *sqlite.DB) ([]ImportedNote, error) {
+	colRows, err := db.ReadTable("col")
+	if err != nil {
+		return nil, err
+	}
+	if len(colRows) == 0 || len(colRows[0].Values) < 11 {
+		return nil, sqlite.ErrCorrupt
+	}
+	col := colRows[0].Values
+	crt := time.Unix(toInt(col[1]), 0)
+
+	// 笔记类型：id -> 名称和字段名（按 ord 排序）
+	var rawModels map[string]struct {
+		Name string `json:"name"`
+		Flds []struct {
+			Name string `json:"name"`
+			Ord  int    `json:"ord"`
+		} `json:"flds"`
+	}
+	_ = json.Unmarshal([]byte(toString(col[9])), &rawModels)
+	type modelInfo struct {
+		name   string
+		fields []string
+	}
+	models := make(map[int64]modelInfo, len(rawModels))
+	for id, m := range rawModels {
+		mid, _ := strconv.ParseInt(id, 10, 64)
+		fields := make([]string, len(m.Flds))
+		for i, f := range m.Flds {
+			if f.Ord >= 0 && f.Ord < len(fields) {
+				fields[f.Ord] = f.Name
+			} else {
+				fields[i] = f.Name
+			}
+		}
+		models[mid] = modelInfo{name: m.Name, fields: fields}
+	}
+
+	var rawDecks map[string]struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal([]byte(toString(col[10])), &rawDecks)
+	decks := make(map[int64]string, len(rawDecks))
+	for id, d := range rawDecks {
+		did, _ := strconv.ParseInt(id, 10, 64)
+		decks[did] = d.Name
+	}
+
+	// 每条笔记取 ord 最小的卡片
+	cardRows, err := db.ReadTable("cards")
+	if err != nil {
+		return nil, err
+	}
+	type cardInfo struct {
+		ord  int64
+		deck int64
+		card Card
+	}
+	cards := make(map[int64]cardInfo, len(cardRows))
+	for _, r := range cardRows {
+		v := r.Values
+		if len(v) < 13 {
+			continue
+		}
+		nid, ord := toInt(v[1]), toInt(v[3])
+		if existing, ok := cards[nid]; ok && existing.ord <= ord {
+			continue
+		}
+		cards[nid] = cardInfo{
+			ord:  ord,
+			deck: toInt(v[2]),
+			card: cardFromAnki(toInt(v[6]), toInt(v[7]), toInt(v[8]), toInt(v[9]), toInt(v[10]), toInt(v[11]), toInt(v[12]), crt),
+		}
+	}
+
+	noteRows, err := db.ReadTable("notes")
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]ImportedNote, 0, len(noteRows))
+	for _, r := range noteRows {
+		v := r.Values
+		if len(v) < 7 {
+			continue
+		}
+		model := models[toInt(v[2])]
+		info := cards[r.RowID]
+		notes = append(notes, ImportedNote{
+			Model:      model.name,
+			FieldNames: model.fields,
+			Fields:     strings.Split(toString(v[6]), fieldSeparator),
+			Tags:       strings.Fields(toString(v[5])),
+			Deck:       decks[info.deck],
+			Card:       info.card,
+		})
+	}
+	return notes, nil
+}
+
+// cardFromAnki 把 Anki 的卡片字段转换为学习状态
+func cardFromAnki(typ, queue, due, ivl, factor, reps, lapses int64, crt time.Time) Card {
+	c := Card{
+		State:        StateNew,
+		IntervalDays: int(ivl),
+		EaseFactor:   float64(factor) / 1000,
+		Reps:         int(reps),
+		Lapses:       int(lapses),
+	}
+	if ivl < 0 {
+		// 负数表示学习阶段的秒数
+		c.IntervalDays = 0
+	}
+
+	switch typ {
+	case 2:
+		c.State = StateReview
+		at := dayStart(crt).AddDate(0, 0, int(due))
+		c.Due = &at
+	case 1, 3:
+		c.State = StateLearning
+		if typ == 3 {
+			c.State = StateRelearning
+		}
+		if queue == 1 {
+			at := time.Unix(due, 0)
+			c.Due = &at
+		} else {
+			// 跨天学习（queue 3）的 due 为天数
+			at := dayStart(crt).AddDate(0, 0, int(due))
+			c.Due = &at
+		}
+	}
+	return c
+}
+
+func toInt(v interface{}) int64 {
+	switch val := v.(type) {
+	case int64:
+		return val
+	case float64:
+		return int64(val)
+	case string:
+		n, _ := strconv.ParseInt(val, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	}
+	return ""
+}
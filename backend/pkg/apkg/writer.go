@@ -0,0 +1,297 @@
+package apkg
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"voicepaper/pkg/sqlite"
+)
+
+// Anki 2.1 旧版 schema（11）的表定义
+const (
+	schemaCol = `CREATE TABLE col (
+    id              integer primary key,
+    crt             integer not null,
+    mod             integer not null,
+    scm             integer not null,
+    ver             integer not null,
+    dty             integer not null,
+    usn             integer not null,
+    ls              integer not null,
+    conf            text not null,
+    models          text not null,
+    decks           text not null,
+    dconf           text not null,
+    tags            text not null
+)`
+	schemaNotes = `CREATE TABLE notes (
+    id              integer primary key,
+    guid            text not null,
+    mid             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    tags            text not null,
+    flds            text not null,
+    sfld            integer not null,
+    csum            integer not null,
+    flags           integer not null,
+    data            text not null
+)`
+	schemaCards = `CREATE TABLE cards (
+    id              integer primary key,
+    nid             integer not null,
+    did             integer not null,
+    ord             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    type            integer not null,
+    queue           integer not null,
+    due             integer not null,
+    ivl             integer not null,
+    factor          integer not null,
+    reps            integer not null,
+    lapses          integer not null,
+    left            integer not null,
+    odue            integer not null,
+    odid            integer not null,
+    flags           integer not null,
+    data            text not null
+)`
+	schemaRevlog = `CREATE TABLE revlog (
+    id              integer primary key,
+    cid             integer not null,
+    usn             integer not null,
+    ease            integer not null,
+    ivl             integer not null,
+    lastIvl         integer not null,
+    factor          integer not null,
+    time            integer not null,
+    type            integer not null
+)`
+	schemaGraves = `CREATE TABLE graves (
+    usn             integer not null,
+    oid             integer not null,
+    type            integer not null
+)`
+)
+
+// Write 生成 .apkg 文件写入 w
+func Write(w io.Writer, pkg *Package) error {
+	now := time.Now()
+	collection, err := buildCollection(pkg, now)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create("collection.anki2")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(collection); err != nil {
+		return err
+	}
+
+	// 媒体文件按编号存放，media 文件记录编号 -> 文件名
+	names := make([]string, 0, len(pkg.Media))
+	for name := range pkg.Media {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	index := make(map[string]string, len(names))
+	for i, name := range names {
+		key := strconv.Itoa(i)
+		index[key] = name
+		f, err := zw.Create(key)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(pkg.Media[name]); err != nil {
+			return err
+		}
+	}
+	mediaJSON, _ := json.Marshal(index)
+	f, err = zw.Create("media")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(mediaJSON); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// buildCollection 生成 collection.anki2 数据库
+func buildCollection(pkg *Package, now time.Time) ([]byte, error) {
+	base := now.UnixMilli()
+	modelID := base
+	deckID := base + 1
+	mod := now.Unix()
+
+	// 复习卡片的 due 是相对 crt 的天数，crt 取最早到期日和今天中较早的一天，保证 due 不为负
+	crt := dayStart(now)
+	for _, n := range pkg.Notes {
+		if n.Card.Due != nil && n.Card.Due.Before(crt) {
+			crt = dayStart(*n.Card.Due)
+		}
+	}
+
+	var notes, cards []sqlite.Row
+	newPos := int64(0)
+	for i, n := range pkg.Notes {
+		noteID := base + int64(i)
+		fields := make([]string, len(pkg.Model.Fields))
+		copy(fields, n.Fields)
+		guid := n.GUID
+		if guid == "" {
+			guid = guidFor(fields[0])
+		}
+
+		notes = append(notes, sqlite.Row{
+			RowID: noteID,
+			Values: []interface{}{
+				nil, guid, modelID, mod, int64(-1),
+				joinTags(n.Tags),
+				strings.Join(fields, fieldSeparator),
+				StripHTML(fields[0]),
+				checksum(fields[0]),
+				int64(0), "",
+			},
+		})
+
+		typ, queue, due, factor, left := cardSchedule(n.Card, crt, &newPos)
+		cards = append(cards, sqlite.Row{
+			RowID: noteID,
+			Values: []interface{}{
+				nil, noteID, deckID, int64(0), mod, int64(-1),
+				typ, queue, due,
+				int64(n.Card.IntervalDays), factor,
+				int64(n.Card.Reps), int64(n.Card.Lapses), left,
+				int64(0), int64(0), int64(0), "",
+			},
+		})
+	}
+
+	models, decks, dconf, conf := collectionJSON(pkg, modelID, deckID, mod)
+	col := sqlite.Row{
+		RowID: 1,
+		Values: []interface{}{
+			nil, crt.Unix(), now.UnixMilli(), now.UnixMilli(), int64(11), int64(0), int64(0), int64(0),
+			conf, models, decks, dconf, "{}",
+		},
+	}
+
+	return sqlite.Build([]sqlite.Table{
+		{Name: "col", SQL: schemaCol, Rows: []sqlite.Row{col}},
+		{Name: "notes", SQL: schemaNotes, Rows: notes},
+		{Name: "cards", SQL: schemaCards, Rows: cards},
+		{Name: "revlog", SQL: schemaRevlog},
+		{Name: "graves", SQL: schemaGraves},
+	})
+}
+
+// cardSchedule 把学习状态转换为 Anki 的 type/queue/due/factor/left
+func cardSchedule(c Card, crt time.Time, newPos *int64) (typ, queue, due, factor, left int64) {
+	factor = int64(c.EaseFactor*1000 + 0.5)
+	switch {
+	case c.State == StateReview && c.Due != nil && c.IntervalDays > 0:
+		days := int64(dayStart(*c.Due).Sub(crt).Hours() / 24)
+		return 2, 2, days, factor, 0
+	case (c.State == StateLearning || c.State == StateRelearning) && c.Due != nil:
+		typ = 1
+		if c.State == StateRelearning {
+			typ = 3
+		}
+		return typ, 1, c.Due.Unix(), factor, 1001
+	default:
+		*newPos++
+		return 0, 0, *newPos, 0, 0
+	}
+}
+
+// collectionJSON 生成 col 表中的 models/decks/dconf/conf
+func collectionJSON(pkg *Package, modelID, deckID, mod int64) (models, decks, dconf, conf string) {
+	flds := make([]map[string]interface{}, len(pkg.Model.Fields))
+	for i, name := range pkg.Model.Fields {
+		flds[i] = map[string]interface{}{
+			"name": name, "ord": i, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+	model := map[string]interface{}{
+		"id":        modelID,
+		"name":      pkg.Model.Name,
+		"type":      0,
+		"mod":       mod,
+		"usn":       -1,
+		"sortf":     0,
+		"did":       deckID,
+		"flds":      flds,
+		"css":       pkg.Model.CSS,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []interface{}{},
+		"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+		"tmpls": []map[string]interface{}{{
+			"name": "Card 1", "ord": 0, "qfmt": pkg.Model.QFmt, "afmt": pkg.Model.AFmt,
+			"did": nil, "bqfmt": "", "bafmt": "",
+		}},
+	}
+
+	deck := func(id int64, name, desc string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": name, "desc": desc, "mod": mod, "usn": -1,
+			"lrnToday": []int{0, 0}, "revToday": []int{0, 0}, "newToday": []int{0, 0}, "timeToday": []int{0, 0},
+			"collapsed": false, "dyn": 0, "conf": 1, "extendNew": 10, "extendRev": 50,
+		}
+	}
+
+	deckConf := map[string]interface{}{
+		"id": 1, "name": "Default", "mod": 0, "usn": 0, "dyn": false,
+		"maxTaken": 60, "timer": 0, "autoplay": true, "replayq": true,
+		"new": map[string]interface{}{
+			"delays": []float64{1, 10}, "ints": []int{1, 4, 7}, "initialFactor": 2500,
+			"order": 1, "perDay": 20, "bury": false, "separate": true,
+		},
+		"rev": map[string]interface{}{
+			"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "maxIvl": 36500, "bury": false, "hardFactor": 1.2,
+		},
+		"lapse": map[string]interface{}{
+			"delays": []float64{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 1,
+		},
+	}
+
+	m, _ := json.Marshal(map[string]interface{}{strconv.FormatInt(modelID, 10): model})
+	d, _ := json.Marshal(map[string]interface{}{
+		"1":                           deck(1, "Default", ""),
+		strconv.FormatInt(deckID, 10): deck(deckID, pkg.Deck, pkg.Description),
+	})
+	dc, _ := json.Marshal(map[string]interface{}{"1": deckConf})
+	c, _ := json.Marshal(map[string]interface{}{
+		"nextPos": len(pkg.Notes) + 1, "estTimes": true, "activeDecks": []int64{deckID},
+		"sortType": "noteFld", "timeLim": 0, "sortBackwards": false, "addToCur": true,
+		"curDeck": deckID, "newSpread": 0, "dueCounts": true, "curModel": modelID, "collapseTime": 1200,
+	})
+	return string(m), string(d), string(dc), string(c)
+}
+
+// joinTags Anki 标签以空格分隔，首尾各留一个空格；标签内的空格替换为下划线
+func joinTags(tags []string) string {
+	cleaned := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(t), "_")
+		if t != "" {
+			cleaned = append(cleaned, t)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ""
+	}
+	return " " + strings.Join(cleaned, " ") + " "
+}
@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"sync"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据库，部署环境没有 zoneinfo 时也能加载用户时区
+)
+
+// Clock 时间来源，测试时可替换为 Fake
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// System 系统时钟
+var System Clock = systemClock{}
+
+// Fake 可手动设置的时钟（测试用）
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set 设置当前时间
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	f.now = now
+	f.mu.Unlock()
+}
+
+// Advance 时间前进 d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	f.mu.Unlock()
+}
+
+// Date t 在 loc 时区的日历日期，用服务器时区当天 0 点表示。
+// 数据库中的 DATE 字段（签到日期、统计日期等）都按服务器时区读写，日期统一用这种形式传递和比较
+func Date(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// StartOf 日历日期 date 在 loc 时区开始的时刻，用于按天统计时间戳字段（如 created_at）
+func StartOf(date time.Time, loc *time.Location) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// LoadLocation 加载 IANA 时区（如 Asia/Shanghai、America/New_York），无效时返回 false
+func LoadLocation(name string) (*time.Location, bool) {
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
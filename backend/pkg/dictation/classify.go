@@ -0,0 +1,116 @@
+package dictation
+
+import (
+	"strings"
+	"voicepaper/pkg/morph"
+)
+
+// Category 错误类型
+type Category string
+
+const (
+	CategorySpelling    Category = "spelling"     // 拼写错误
+	CategoryArticle     Category = "article"      // 冠词（a/an/the）漏写、多写或用错
+	CategoryPreposition Category = "preposition"  // 介词漏写、多写或用错
+	CategoryTense       Category = "tense"        // 时态（-ed/-ing、be/have/do 的不同形式）
+	CategoryNumber      Category = "number"       // 单复数、第三人称单数（-s/-es）
+	CategoryMissingWord Category = "missing_word" // 漏写单词
+	CategoryExtraWord   Category = "extra_word"   // 多写单词
+	CategoryWrongWord   Category = "wrong_word"   // 写成了别的单词
+)
+
+var articles = map[string]bool{"a": true, "an": true, "the": true}
+
+var prepositions = map[string]bool{
+	"about": true, "above": true, "across": true, "after": true, "against": true, "along": true,
+	"among": true, "around": true, "at": true, "before": true, "behind": true, "below": true,
+	"beneath": true, "beside": true, "between": true, "beyond": true, "by": true, "during": true,
+	"for": true, "from": true, "in": true, "inside": true, "into": true, "near": true, "of": true,
+	"off": true, "on": true, "onto": true, "out": true, "over": true, "since": true, "through": true,
+	"to": true, "toward": true, "towards": true, "under": true, "until": true, "up": true,
+	"upon": true, "with": true, "within": true, "without": true,
+}
+
+// lemmatizer 词形还原（内置的不规则变化表加规则猜测，不加载词表）
+var lemmatizer = morph.New()
+
+// modals 情态动词的过去形式，不属于屈折变化，单独对应到原形
+var modals = map[string]string{"would": "will", "could": "can", "should": "shall", "might": "may"}
+
+// auxiliaries 助动词的各个形式（is/are、has/have、does/do）互相替换归入时态，不算单复数
+var auxiliaries = map[string]bool{"be": true, "have": true, "do": true}
+
+// Classify 根据逐词判分结果统计错误类型，返回 类型 -> 次数
+func Classify(r *Result) map[Category]int {
+	counts := make(map[Category]int)
+	for _, t := range r.Tokens {
+		if c, ok := classifyToken(t); ok {
+			counts[c]++
+		}
+	}
+	return counts
+}
+
+func classifyToken(t Token) (Category, bool) {
+	switch t.Type {
+	case TokenMisspelled:
+		// cat/cats、walk/walked 编辑距离很小，但属于语法错误而不是拼写错误
+		if c, ok := inflectionCategory(t.Expected, t.Actual); ok {
+			return c, true
+		}
+		return CategorySpelling, true
+	case TokenMissing:
+		return functionWordCategory(t.Expected, CategoryMissingWord), true
+	case TokenExtra:
+		return functionWordCategory(t.Actual, CategoryExtraWord), true
+	case TokenSubstitute:
+		if articles[t.Expected] && articles[t.Actual] {
+			return CategoryArticle, true
+		}
+		if prepositions[t.Expected] && prepositions[t.Actual] {
+			return CategoryPreposition, true
+		}
+		if c, ok := inflectionCategory(t.Expected, t.Actual); ok {
+			return c, true
+		}
+		return CategoryWrongWord, true
+	}
+	return "", false
+}
+
+// functionWordCategory 漏写/多写的是冠词或介词时归入对应类型，否则使用 fallback
+func functionWordCategory(word string, fallback Category) Category {
+	switch {
+	case articles[word]:
+		return CategoryArticle
+	case prepositions[word]:
+		return CategoryPreposition
+	default:
+		return fallback
+	}
+}
+
+// inflectionCategory 判断两个单词是否为同一个词的不同屈折形式
+func inflectionCategory(expected, actual string) (Category, bool) {
+	lemma := lemmaOf(expected)
+	if expected == actual || lemma == "" || lemma != lemmaOf(actual) {
+		return "", false
+	}
+	// 只在原形与 -s/-es 形式之间替换的视为单复数/主谓一致，其余（-ed/-ing、不规则变化）视为时态
+	if !auxiliaries[lemma] && (expected == lemma && isSForm(actual) || actual == lemma && isSForm(expected)) {
+		return CategoryNumber, true
+	}
+	return CategoryTense, true
+}
+
+func lemmaOf(w string) string {
+	if lemma, ok := modals[w]; ok {
+		return lemma
+	}
+	return lemmatizer.Lemma(w)
+}
+
+// isSForm 是否为 -s/-es 形式（cats、boxes、studies、wolves）
+func isSForm(w string) bool {
+	return strings.HasSuffix(w, "s")
+}
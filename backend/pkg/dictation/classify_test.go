@@ -0,0 +1,74 @@
+package dictation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     map[Category]int
+	}{
+		{"全部正确", "I'm fine", "I am fine", map[Category]int{}},
+		{"冠词用错", "the cat sat", "a cat sat", map[Category]int{CategoryArticle: 1}},
+		{"漏写冠词", "I saw a dog", "I saw dog", map[Category]int{CategoryArticle: 1}},
+		{"介词用错", "in the park", "at the park", map[Category]int{CategoryPreposition: 1}},
+		{"漏写介词", "I want to go", "I want go", map[Category]int{CategoryPreposition: 1}},
+		{"时态", "she walks home", "she walked home", map[Category]int{CategoryTense: 1}},
+		{"不规则变化", "he is here", "he are here", map[Category]int{CategoryTense: 1}},
+		{"情态动词", "it would rain", "it will rain", map[Category]int{CategoryTense: 1}},
+		// 编辑距离在容忍范围内，但属于单复数而不是拼写错误
+		{"单复数", "two cats", "two cat", map[Category]int{CategoryNumber: 1}},
+		{"拼写错误", "beautiful day", "beutiful day", map[Category]int{CategorySpelling: 1}},
+		{"写成别的单词", "green apple", "green orange", map[Category]int{CategoryWrongWord: 1}},
+		{"漏写单词", "quick brown fox", "quick fox", map[Category]int{CategoryMissingWord: 1}},
+		{"多写单词", "go home", "go home now", map[Category]int{CategoryExtraWord: 1}},
+		{"多种错误", "the boys play in the garden", "a boy play at garden", map[Category]int{
+			CategoryArticle: 2, CategoryNumber: 1, CategoryPreposition: 1,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(Grade(tt.expected, tt.actual)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Classify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInflectionCategory(t *testing.T) {
+	tests := []struct {
+		expected, actual string
+		want             Category
+		ok               bool
+	}{
+		{"cat", "cats", CategoryNumber, true},
+		{"boxes", "box", CategoryNumber, true},
+		{"study", "studies", CategoryNumber, true},
+		{"wolf", "wolves", CategoryNumber, true},
+		{"walk", "walked", CategoryTense, true},
+		{"walks", "walking", CategoryTense, true},
+		{"stop", "stopped", CategoryTense, true},
+		{"go", "went", CategoryTense, true},
+		// 助动词的人称变化归入时态
+		{"do", "does", CategoryTense, true},
+		{"have", "has", CategoryTense, true},
+		{"could", "can", CategoryTense, true},
+		// 本身常作独立单词的形式不还原
+		{"find", "found", "", false},
+		{"see", "saw", "", false},
+		{"cat", "cap", "", false},
+		{"walk", "walk", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected+"/"+tt.actual, func(t *testing.T) {
+			got, ok := inflectionCategory(tt.expected, tt.actual)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("inflectionCategory = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
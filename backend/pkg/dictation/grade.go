@@ -0,0 +1,200 @@
+// Package dictation 默写答案判分
+//
+// 把用户答案与标准文本做单词级对齐：先统一大小写、标点和缩写，再用 LCS 找出
+// 完全相同的单词，两段相同单词之间的删除和新增按顺序配对，编辑距离足够小的
+// 视为拼写错误，否则视为替换。返回逐词的差异结果，供前端渲染和服务端计分。
+package dictation
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"voicepaper/pkg/textdiff"
+)
+
+// TokenType 逐词判分结果类型
+type TokenType string
+
+const (
+	TokenCorrect    TokenType = "correct"    // 正确
+	TokenMisspelled TokenType = "misspelled" // 拼写错误（编辑距离在容忍范围内）
+	TokenSubstitute TokenType = "substitute" // 写成了别的单词
+	TokenMissing    TokenType = "missing"    // 漏写（只在标准答案中）
+	TokenExtra      TokenType = "extra"      // 多写（只在用户答案中）
+)
+
+// 计分规则
+const (
+	misspelledCredit = 0.5 // 拼写错误按半个单词计分
+)
+
+// Token 一个单词的判分结果
+// Expected 为标准答案中的单词，Actual 为用户写的单词，不存在时为空
+type Token struct {
+	Type     TokenType `json:"type"`
+	Expected string    `json:"expected,omitempty"`
+	Actual   string    `json:"actual,omitempty"`
+}
+
+// Result 判分结果
+type Result struct {
+	Tokens     []Token `json:"tokens"`
+	Correct    int     `json:"correct"`
+	Misspelled int     `json:"misspelled"`
+	Substitute int     `json:"substitute"`
+	Missing    int     `json:"missing"`
+	Extra      int     `json:"extra"`
+	Score      int     `json:"score"`      // 得分 0-100
+	IsCorrect  bool    `json:"is_correct"` // 所有单词都正确
+}
+
+// contractions 常见缩写展开（先匹配完整单词，再匹配后缀）
+var contractions = map[string][]string{
+	"won't":   {"will", "not"},
+	"can't":   {"can", "not"},
+	"shan't":  {"shall", "not"},
+	"ain't":   {"am", "not"},
+	"let's":   {"let", "us"},
+	"i'm":     {"i", "am"},
+	"it's":    {"it", "is"},
+	"that's":  {"that", "is"},
+	"what's":  {"what", "is"},
+	"there's": {"there", "is"},
+	"here's":  {"here", "is"},
+	"he's":    {"he", "is"},
+	"she's":   {"she", "is"},
+	"who's":   {"who", "is"},
+	"where's": {"where", "is"},
+}
+
+var contractionSuffixes = []struct {
+	suffix string
+	word   string
+}{
+	{"n't", "not"},
+	{"'re", "are"},
+	{"'ve", "have"},
+	{"'ll", "will"},
+	{"'d", "would"},
+	{"'m", "am"},
+}
+
+// Normalize 把文本切分为归一化的单词序列：
+// 转小写，统一弯引号，展开缩写，连字符和斜杠拆成两个单词，去掉其余标点
+func Normalize(text string) []string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("’", "'", "‘", "'", "`", "'", "-", " ", "–", " ", "—", " ", "/", " ").Replace(text)
+
+	words := make([]string, 0, len(text)/5+1)
+	for _, raw := range strings.Fields(text) {
+		w := strings.TrimFunc(raw, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if w == "" {
+			continue
+		}
+		words = append(words, expand(w)...)
+	}
+	return words
+}
+
+// expand 展开单个单词中的缩写，并去掉剩余的非字母数字字符
+func expand(w string) []string {
+	if parts, ok := contractions[w]; ok {
+		return parts
+	}
+	for _, c := range contractionSuffixes {
+		if strings.HasSuffix(w, c.suffix) && len(w) > len(c.suffix) {
+			return append(expand(strings.TrimSuffix(w, c.suffix)), c.word)
+		}
+	}
+	// 所有格 's 与标准答案统一去掉
+	w = strings.TrimSuffix(w, "'s")
+	w = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, w)
+	if w == "" {
+		return nil
+	}
+	return []string{w}
+}
+
+// MaxTypos 单词允许的拼写错误（编辑距离）上限：
+// 3 个字母以内不容错，4-7 个字母容忍 1 处，更长的容忍 2 处
+func MaxTypos(word string) int {
+	n := len([]rune(word))
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Grade 对比标准答案 expected 与用户答案 actual，返回逐词判分结果
+func Grade(expected, actual string) *Result {
+	exp, act := Normalize(expected), Normalize(actual)
+	result := &Result{Tokens: make([]Token, 0, len(exp)+len(act))}
+
+	var deletes, inserts []string
+	flush := func() {
+		k := 0
+		for ; k < len(deletes) && k < len(inserts); k++ {
+			typ := TokenSubstitute
+			if d := textdiff.EditDistance(deletes[k], inserts[k]); d > 0 && d <= MaxTypos(deletes[k]) {
+				typ = TokenMisspelled
+			}
+			result.add(Token{Type: typ, Expected: deletes[k], Actual: inserts[k]})
+		}
+		for ; k < len(deletes); k++ {
+			result.add(Token{Type: TokenMissing, Expected: deletes[k]})
+		}
+		for ; k < len(inserts); k++ {
+			result.add(Token{Type: TokenExtra, Actual: inserts[k]})
+		}
+		deletes, inserts = deletes[:0], inserts[:0]
+	}
+
+	for _, op := range textdiff.Diff(exp, act) {
+		switch op.Type {
+		case textdiff.OpEqual:
+			flush()
+			result.add(Token{Type: TokenCorrect, Expected: op.Text, Actual: op.Text})
+		case textdiff.OpDelete:
+			deletes = append(deletes, op.Text)
+		case textdiff.OpInsert:
+			inserts = append(inserts, op.Text)
+		}
+	}
+	flush()
+
+	// 得分 = (正确 + 拼写错误×0.5) / (标准答案单词数 + 多写单词数)
+	total := len(exp) + result.Extra
+	if total > 0 {
+		credit := float64(result.Correct) + float64(result.Misspelled)*misspelledCredit
+		result.Score = int(math.Round(credit / float64(total) * 100))
+	}
+	result.IsCorrect = len(exp) > 0 && result.Correct == len(exp) && result.Extra == 0
+	return result
+}
+
+func (r *Result) add(t Token) {
+	r.Tokens = append(r.Tokens, t)
+	switch t.Type {
+	case TokenCorrect:
+		r.Correct++
+	case TokenMisspelled:
+		r.Misspelled++
+	case TokenSubstitute:
+		r.Substitute++
+	case TokenMissing:
+		r.Missing++
+	case TokenExtra:
+		r.Extra++
+	}
+}
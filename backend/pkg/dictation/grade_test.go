@@ -0,0 +1,142 @@
+package dictation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"  ...  ", []string{}},
+		{"I'm here.", []string{"i", "am", "here"}},
+		{"I am here", []string{"i", "am", "here"}},
+		{"Don't WORRY!", []string{"do", "not", "worry"}},
+		{"won't", []string{"will", "not"}},
+		{"We're sure they'll come", []string{"we", "are", "sure", "they", "will", "come"}},
+		// 弯引号与直引号相同
+		{"It’s John’s ‘book’", []string{"it", "is", "john", "book"}},
+		{"well-known and/or famous", []string{"well", "known", "and", "or", "famous"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Normalize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxTypos(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"", 0},
+		{"cat", 0},
+		{"word", 1},
+		{"café", 1}, // 按字符计算长度
+		{"example", 1},
+		{"elephant", 2},
+		{"beautiful", 2},
+	}
+	for _, tt := range tests {
+		if got := MaxTypos(tt.word); got != tt.want {
+			t.Errorf("MaxTypos(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func correct(w string) Token { return Token{Type: TokenCorrect, Expected: w, Actual: w} }
+
+func TestGrade(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		tokens    []Token
+		score     int
+		isCorrect bool
+	}{
+		{
+			name: "缩写与展开形式相同", expected: "I'm fine.", actual: "I am fine",
+			tokens: []Token{correct("i"), correct("am"), correct("fine")}, score: 100, isCorrect: true,
+		},
+		{
+			name: "弯引号", expected: "It’s John’s book", actual: "it's john's book",
+			tokens: []Token{correct("it"), correct("is"), correct("john"), correct("book")}, score: 100, isCorrect: true,
+		},
+		{
+			name: "3 个字母以内不容错", expected: "cat", actual: "cap",
+			tokens: []Token{{Type: TokenSubstitute, Expected: "cat", Actual: "cap"}},
+		},
+		{
+			name: "4-7 个字母容忍 1 处", expected: "house", actual: "hause",
+			tokens: []Token{{Type: TokenMisspelled, Expected: "house", Actual: "hause"}}, score: 50,
+		},
+		{
+			name: "4-7 个字母错 2 处算替换", expected: "world", actual: "wrd",
+			tokens: []Token{{Type: TokenSubstitute, Expected: "world", Actual: "wrd"}},
+		},
+		{
+			name: "8 个字母以上容忍 2 处", expected: "beautiful", actual: "beutifull",
+			tokens: []Token{{Type: TokenMisspelled, Expected: "beautiful", Actual: "beutifull"}}, score: 50,
+		},
+		{
+			// 两段相同单词之间的删除和新增按顺序配对，多写的单独列出
+			name: "替换与多写", expected: "the big dog runs", actual: "a big cat runs fast",
+			tokens: []Token{
+				{Type: TokenSubstitute, Expected: "the", Actual: "a"},
+				correct("big"),
+				{Type: TokenSubstitute, Expected: "dog", Actual: "cat"},
+				correct("runs"),
+				{Type: TokenExtra, Actual: "fast"},
+			},
+			score: 40,
+		},
+		{
+			name: "漏写与多写不配对", expected: "I want to go home", actual: "I want go home now",
+			tokens: []Token{
+				correct("i"), correct("want"),
+				{Type: TokenMissing, Expected: "to"},
+				correct("go"), correct("home"),
+				{Type: TokenExtra, Actual: "now"},
+			},
+			score: 67,
+		},
+		{
+			name: "删除多于新增", expected: "red green blue", actual: "grey",
+			tokens: []Token{
+				{Type: TokenSubstitute, Expected: "red", Actual: "grey"},
+				{Type: TokenMissing, Expected: "green"},
+				{Type: TokenMissing, Expected: "blue"},
+			},
+		},
+		{name: "都为空", expected: "", actual: "", tokens: []Token{}},
+		{
+			name: "没有作答", expected: "hello world", actual: "  ",
+			tokens: []Token{{Type: TokenMissing, Expected: "hello"}, {Type: TokenMissing, Expected: "world"}},
+		},
+		{
+			name: "标准答案为空", expected: "", actual: "hi",
+			tokens: []Token{{Type: TokenExtra, Actual: "hi"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Grade(tt.expected, tt.actual)
+			if !reflect.DeepEqual(r.Tokens, tt.tokens) {
+				t.Errorf("Tokens = %+v, want %+v", r.Tokens, tt.tokens)
+			}
+			if r.Score != tt.score || r.IsCorrect != tt.isCorrect {
+				t.Errorf("Score/IsCorrect = %d/%v, want %d/%v", r.Score, r.IsCorrect, tt.score, tt.isCorrect)
+			}
+			if n := r.Correct + r.Misspelled + r.Substitute + r.Missing + r.Extra; n != len(r.Tokens) {
+				t.Errorf("各类计数之和 = %d, want %d", n, len(r.Tokens))
+			}
+		})
+	}
+}
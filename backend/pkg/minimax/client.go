@@ -17,10 +17,18 @@ import (
 
 // GenerateSpeech 是对外的统一接口，处理所有异步轮询逻辑，直接返回音频二进制数据
 func GenerateSpeech(text string) ([]byte, error) {
+	return GenerateSpeechWithVoice(text, "")
+}
+
+// GenerateSpeechWithVoice 使用指定音色生成语音，voiceID 为空时使用配置中的音色
+func GenerateSpeechWithVoice(text, voiceID string) ([]byte, error) {
 	cfg := config.GetConfig()
+	if voiceID == "" {
+		voiceID = cfg.TTS.VoiceID
+	}
 
 	// 1. 发起请求
-	taskID, err := initiateTask(text, cfg)
+	taskID, err := initiateTask(text, voiceID, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -102,12 +110,12 @@ type RetrieveResponse struct {
 	} `json:"file"`
 }
 
-func initiateTask(text string, cfg *config.Config) (int64, error) {
+func initiateTask(text, voiceID string, cfg *config.Config) (int64, error) {
 	reqBody := T2ARequest{
 		Model: cfg.TTS.Model,
 		Text:  text,
 		VoiceSetting: VoiceSetting{
-			VoiceID: cfg.TTS.VoiceID,
+			VoiceID: voiceID,
 			Speed:   cfg.TTS.Speed,
 			Vol:     cfg.TTS.Volume,
 			Pitch:   cfg.TTS.Pitch,
@@ -0,0 +1,125 @@
+package morph
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kind 构词成分类型（与前端词根词缀分析的 position 一致）
+type Kind string
+
+const (
+	KindPrefix Kind = "prefix" // 前缀
+	KindRoot   Kind = "root"   // 词根
+	KindSuffix Kind = "suffix" // 后缀
+)
+
+// Morpheme 一个构词成分
+type Morpheme struct {
+	Kind Kind   `json:"type"`
+	Text string `json:"morpheme"` // 不带连字符，如 re、spect、tion
+}
+
+// String 按单词书 Root/Affix 字段的写法输出：前缀 re-、后缀 -tion、词根不带连字符
+func (m Morpheme) String() string {
+	switch m.Kind {
+	case KindPrefix:
+		return m.Text + "-"
+	case KindSuffix:
+		return "-" + m.Text
+	}
+	return m.Text
+}
+
+// minRootLen 拆分后词根至少保留的字母数
+const minRootLen = 3
+
+// prefixes 常见前缀
+var prefixes = []string{
+	"anti", "auto", "bi", "co", "com", "con", "contra", "counter", "de", "dis", "en", "em",
+	"ex", "extra", "fore", "hyper", "il", "im", "in", "inter", "intra", "ir", "micro", "mid",
+	"mis", "mono", "multi", "non", "over", "post", "pre", "pro", "re", "semi", "sub",
+	"super", "tele", "trans", "tri", "ultra", "un", "under", "uni",
+}
+
+// suffixes 常见后缀（含屈折后缀以外的派生后缀）
+var suffixes = []string{
+	"able", "ible", "al", "ial", "ance", "ence", "ant", "ent", "ary", "ery", "ory", "ate",
+	"dom", "ee", "en", "er", "or", "ess", "ful", "hood", "ic", "ical", "ify", "fy", "ion",
+	"tion", "sion", "ation", "ish", "ism", "ist", "ity", "ty", "ive", "ize", "ise", "less",
+	"ly", "ment", "ness", "ous", "ious", "eous", "ship", "some", "ure", "ward", "wise",
+}
+
+func init() {
+	// 按长度从长到短匹配，inter 优先于 in，ation 优先于 ion
+	byLength := func(list []string) {
+		sort.SliceStable(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+	}
+	byLength(prefixes)
+	byLength(suffixes)
+}
+
+// Split 按常见前缀和后缀粗略拆分单词（各最多一个），词根至少保留 3 个字母
+// 例如 unhappiness -> un- happi -ness；没有可拆的词缀时只返回词根
+func Split(word string) []Morpheme {
+	w := normalize(word)
+	if !isWord(w) {
+		return nil
+	}
+
+	var prefix, suffix string
+	for _, p := range prefixes {
+		if strings.HasPrefix(w, p) && len(w)-len(p) >= minRootLen {
+			prefix = p
+			break
+		}
+	}
+	rest := strings.TrimPrefix(w, prefix)
+	for _, s := range suffixes {
+		if strings.HasSuffix(rest, s) && len(rest)-len(s) >= minRootLen {
+			suffix = s
+			break
+		}
+	}
+	root := strings.TrimSuffix(rest, suffix)
+
+	var parts []Morpheme
+	if prefix != "" {
+		parts = append(parts, Morpheme{Kind: KindPrefix, Text: prefix})
+	}
+	parts = append(parts, Morpheme{Kind: KindRoot, Text: root})
+	if suffix != "" {
+		parts = append(parts, Morpheme{Kind: KindSuffix, Text: suffix})
+	}
+	return parts
+}
+
+// ParseAffixes 解析单词书 Affix/Root 字段，如 "re-, -tion"、"pre- + -able"、"spect"
+// 按连字符位置判断类型：re- 为前缀，-tion 为后缀，-spect- 和不带连字符的为 defaultKind
+func ParseAffixes(field string, defaultKind Kind) []Morpheme {
+	var parts []Morpheme
+	for _, token := range strings.FieldsFunc(field, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",，、;；+/|", r)
+	}) {
+		token = strings.TrimSpace(token)
+		lead := strings.HasPrefix(token, "-")
+		trail := strings.HasSuffix(token, "-")
+		text := strings.ToLower(strings.Trim(token, "-"))
+		if text == "" {
+			continue
+		}
+
+		kind := defaultKind
+		switch {
+		case trail && !lead:
+			kind = KindPrefix
+		case lead && !trail:
+			kind = KindSuffix
+		case lead && trail:
+			kind = KindRoot
+		}
+		parts = append(parts, Morpheme{Kind: kind, Text: text})
+	}
+	return parts
+}
@@ -0,0 +1,75 @@
+package morph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		word string
+		want []Morpheme
+	}{
+		{"unhappiness", []Morpheme{{KindPrefix, "un"}, {KindRoot, "happi"}, {KindSuffix, "ness"}}},
+		// 长的词缀优先：inter 而不是 in，词根保留 nation
+		{"international", []Morpheme{{KindPrefix, "inter"}, {KindRoot, "nation"}, {KindSuffix, "al"}}},
+		{"information", []Morpheme{{KindPrefix, "in"}, {KindRoot, "form"}, {KindSuffix, "ation"}}},
+		{"respect", []Morpheme{{KindPrefix, "re"}, {KindRoot, "spect"}}},
+		{"Teacher", []Morpheme{{KindRoot, "teach"}, {KindSuffix, "er"}}},
+		// 词根不足 3 个字母时不拆
+		{"redo", []Morpheme{{KindRoot, "redo"}}},
+		{"undo", []Morpheme{{KindRoot, "undo"}}},
+		{"cat", []Morpheme{{KindRoot, "cat"}}},
+		// 不是单个英文单词
+		{"ice cream", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := Split(tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q) = %v, want %v", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAffixes(t *testing.T) {
+	tests := []struct {
+		field       string
+		defaultKind Kind
+		want        []Morpheme
+	}{
+		{"re-, -tion", KindRoot, []Morpheme{{KindPrefix, "re"}, {KindSuffix, "tion"}}},
+		{"pre- + -able", KindRoot, []Morpheme{{KindPrefix, "pre"}, {KindSuffix, "able"}}},
+		{"Dis-；-ment", KindRoot, []Morpheme{{KindPrefix, "dis"}, {KindSuffix, "ment"}}},
+		{"spect", KindRoot, []Morpheme{{KindRoot, "spect"}}},
+		// 两端都有连字符的是词根，不带连字符的按 defaultKind
+		{"-spect-", KindPrefix, []Morpheme{{KindRoot, "spect"}}},
+		{"bio / geo", KindPrefix, []Morpheme{{KindPrefix, "bio"}, {KindPrefix, "geo"}}},
+		{"", KindRoot, nil},
+		{" - , -- ", KindRoot, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := ParseAffixes(tt.field, tt.defaultKind); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAffixes(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMorphemeString(t *testing.T) {
+	tests := []struct {
+		m    Morpheme
+		want string
+	}{
+		{Morpheme{KindPrefix, "re"}, "re-"},
+		{Morpheme{KindSuffix, "tion"}, "-tion"},
+		{Morpheme{KindRoot, "spect"}, "spect"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package morph
+
+// irregularForms 内置的不规则变化：原形 -> 各个形式
+// 本身常作独立单词使用的形式不收录（found、felt、saw、rose、better、more、ground、left、media 等），
+// 避免把不同的单词还原成同一个
+var irregularForms = map[string][]string{
+	// 助动词和系动词
+	"be":   {"am", "is", "are", "was", "were", "been", "being"},
+	"have": {"has", "had", "having"},
+	"do":   {"does", "did", "done", "doing"},
+
+	// 不规则动词
+	"arise":      {"arose", "arisen"},
+	"awake":      {"awoke", "awoken"},
+	"bear":       {"borne"},
+	"beat":       {"beaten"},
+	"become":     {"became"},
+	"begin":      {"began", "begun", "beginning"},
+	"bend":       {"bent"},
+	"bet":        {"betting"},
+	"bite":       {"bitten"},
+	"bleed":      {"bled"},
+	"blow":       {"blew", "blown"},
+	"break":      {"broke", "broken"},
+	"breed":      {"bred"},
+	"bring":      {"brought"},
+	"broadcast":  {"broadcasting"},
+	"build":      {"built"},
+	"burn":       {"burnt"},
+	"buy":        {"bought"},
+	"catch":      {"caught"},
+	"choose":     {"chose", "chosen"},
+	"cling":      {"clung"},
+	"come":       {"came"},
+	"creep":      {"crept"},
+	"cut":        {"cutting"},
+	"deal":       {"dealt"},
+	"die":        {"dying"},
+	"dig":        {"dug", "digging"},
+	"draw":       {"drew", "drawn"},
+	"dream":      {"dreamt"},
+	"drink":      {"drank", "drunk"},
+	"drive":      {"drove", "driven"},
+	"eat":        {"ate", "eaten"},
+	"fall":       {"fell", "fallen"},
+	"feed":       {"fed"},
+	"fight":      {"fought"},
+	"flee":       {"fled"},
+	"fly":        {"flew", "flown", "flies"},
+	"forbid":     {"forbade", "forbidden"},
+	"forget":     {"forgot", "forgotten", "forgetting"},
+	"forgive":    {"forgave", "forgiven"},
+	"freeze":     {"froze", "frozen"},
+	"get":        {"got", "gotten", "getting"},
+	"give":       {"gave", "given"},
+	"go":         {"goes", "went", "gone", "going"},
+	"grow":       {"grew", "grown"},
+	"hang":       {"hung"},
+	"hear":       {"heard"},
+	"hide":       {"hid", "hidden"},
+	"hit":        {"hitting"},
+	"hold":       {"held"},
+	"hurt":       {"hurting"},
+	"keep":       {"kept"},
+	"kneel":      {"knelt"},
+	"know":       {"knew", "known"},
+	"lay":        {"laid"},
+	"lead":       {"led"},
+	"lean":       {"leant"},
+	"leap":       {"leapt"},
+	"learn":      {"learnt"},
+	"lend":       {"lent"},
+	"let":        {"letting"},
+	"lie":        {"lay", "lain", "lying"},
+	"light":      {"lit"},
+	"lose":       {"lost"},
+	"make":       {"made"},
+	"mean":       {"meant"},
+	"meet":       {"met"},
+	"mistake":    {"mistook", "mistaken"},
+	"overcome":   {"overcame"},
+	"pay":        {"paid"},
+	"put":        {"putting"},
+	"quit":       {"quitting"},
+	"read":       {"reading"},
+	"ride":       {"rode", "ridden"},
+	"ring":       {"rang"},
+	"rise":       {"risen"},
+	"run":        {"ran", "running"},
+	"say":        {"said", "says"},
+	"see":        {"seen", "sees"},
+	"seek":       {"sought"},
+	"sell":       {"sold"},
+	"send":       {"sent"},
+	"set":        {"setting"},
+	"shake":      {"shook", "shaken"},
+	"shine":      {"shone"},
+	"show":       {"shown"},
+	"shrink":     {"shrank", "shrunk"},
+	"shut":       {"shutting"},
+	"sing":       {"sang", "sung"},
+	"sink":       {"sank", "sunk"},
+	"sit":        {"sat", "sitting"},
+	"sleep":      {"slept"},
+	"slide":      {"slid"},
+	"speak":      {"spoken"},
+	"speed":      {"sped"},
+	"spend":      {"spent"},
+	"spin":       {"spun", "spinning"},
+	"split":      {"splitting"},
+	"spread":     {"spreading"},
+	"spring":     {"sprang", "sprung"},
+	"stand":      {"stood"},
+	"steal":      {"stolen"},
+	"stick":      {"stuck"},
+	"sting":      {"stung"},
+	"strike":     {"struck", "stricken"},
+	"strive":     {"strove", "striven"},
+	"swear":      {"swore", "sworn"},
+	"sweep":      {"swept"},
+	"swim":       {"swam", "swum", "swimming"},
+	"swing":      {"swung"},
+	"take":       {"took", "taken"},
+	"teach":      {"taught"},
+	"tear":       {"tore", "torn"},
+	"tell":       {"told"},
+	"think":      {"thought"},
+	"throw":      {"threw", "thrown"},
+	"understand": {"understood"},
+	"tie":        {"tying"},
+	"undertake":  {"undertook", "undertaken"},
+	"upset":      {"upsetting"},
+	"wake":       {"woke", "woken"},
+	"wear":       {"wore", "worn"},
+	"weave":      {"wove", "woven"},
+	"weep":       {"wept"},
+	"win":        {"won", "winning"},
+	"withdraw":   {"withdrew", "withdrawn"},
+	"write":      {"wrote", "written"},
+
+	// 不规则名词复数
+	"analysis":   {"analyses"},
+	"child":      {"children"},
+	"crisis":     {"crises"},
+	"criterion":  {"criteria"},
+	"foot":       {"feet"},
+	"goose":      {"geese"},
+	"hypothesis": {"hypotheses"},
+	"man":        {"men"},
+	"mouse":      {"mice"},
+	"ox":         {"oxen"},
+	"person":     {"people"},
+	"phenomenon": {"phenomena"},
+	"thesis":     {"theses"},
+	"tooth":      {"teeth"},
+	"woman":      {"women"},
+	"knife":      {"knives"},
+	"wife":       {"wives"},
+	"leaf":       {"leaves"},
+	"half":       {"halves"},
+	"self":       {"selves"},
+	"shelf":      {"shelves"},
+	"wolf":       {"wolves"},
+	"thief":      {"thieves"},
+	"potato":     {"potatoes"},
+	"tomato":     {"tomatoes"},
+	"hero":       {"heroes"},
+
+	// 不规则比较级和最高级
+	"bad": {"worse", "worst"},
+	"far": {"farther", "farthest"},
+}
+
+// noStrip 以 -s/-ed/-ing 结尾但本身就是原形的常见词，猜测时不还原
+var noStrip = map[string]bool{
+	"news": true, "series": true, "species": true, "means": true, "always": true, "perhaps": true,
+	"whereas": true, "sometimes": true, "towards": true, "afterwards": true, "physics": true,
+	"mathematics": true, "economics": true, "politics": true, "lens": true, "gas": true, "bus": true,
+	"yes": true, "this": true, "thus": true, "plus": true, "minus": true, "chaos": true, "bias": true,
+	"canvas": true, "atlas": true, "alias": true, "diabetes": true, "measles": true, "clothes": true,
+	"during": true, "nothing": true, "something": true, "anything": true, "everything": true,
+	"morning": true, "evening": true, "ceiling": true, "building": true, "feeling": true,
+	"meeting": true, "wedding": true, "pudding": true, "king": true, "thing": true, "sibling": true,
+	"interesting": true, "exciting": true, "boring": true, "amazing": true,
+	"bed": true, "red": true, "hundred": true, "sacred": true, "wicked": true, "naked": true,
+	"rugged": true, "ragged": true, "beloved": true, "learned": true, "aged": true, "kindred": true,
+	"hers": true, "ours": true, "yours": true, "theirs": true, "besides": true,
+}
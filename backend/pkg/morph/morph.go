@@ -0,0 +1,356 @@
+// Package morph 英语词形还原和构词分析
+//
+// 把单词的屈折形式还原为原形（running -> run、went -> go、studies -> study），
+// 用于词典查询、生词去重、已知词覆盖率和关键词提取。还原分三步：
+// 不规则变化表（内置常见词，并可从单词书的 word_forms 补充）、
+// 按规则生成候选原形并用词表验证、词表中找不到时按保守的规则猜测。
+// 另外提供与单词书 Root/Affix 字段格式一致的词根词缀拆分。纯 Go 实现，不依赖数据库。
+package morph
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Analyzer 词形还原器
+// 通过 AddLemmas/AddForms 加载词表后只读，可以被多个 goroutine 同时使用
+type Analyzer struct {
+	irregular map[string]string // 不规则变化形式 -> 原形
+	lexicon   map[string]bool   // 已知的原形
+}
+
+// New 创建词形还原器，内置常见的不规则动词、名词和形容词
+func New() *Analyzer {
+	a := &Analyzer{
+		irregular: make(map[string]string, len(irregularForms)*4),
+		lexicon:   make(map[string]bool),
+	}
+	for lemma, forms := range irregularForms {
+		for _, f := range forms {
+			// lay 既是 lie 的过去式也是原形，本身是原形的不记录
+			if _, isLemma := irregularForms[f]; !isLemma {
+				a.irregular[f] = lemma
+			}
+		}
+	}
+	return a
+}
+
+// AddLemmas 把单词加入词表（词表中的单词视为原形）
+func (a *Analyzer) AddLemmas(words ...string) {
+	for _, w := range words {
+		if w = normalize(w); w != "" {
+			a.lexicon[w] = true
+		}
+	}
+}
+
+// AddForms 加载单词书的 word_forms JSON，如 {"past":"went","past_participle":"gone"}
+// 值也可以是数组或用逗号、斜杠分隔的多个形式；与原形相同或符合规则变化的形式同样记录，查表更快
+func (a *Analyzer) AddForms(lemma, formsJSON string) error {
+	lemma = normalize(lemma)
+	if lemma == "" || strings.TrimSpace(formsJSON) == "" {
+		return nil
+	}
+	a.lexicon[lemma] = true
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(formsJSON), &raw); err != nil {
+		return err
+	}
+	for _, v := range raw {
+		var values []string
+		switch val := v.(type) {
+		case string:
+			values = []string{val}
+		case []interface{}:
+			for _, item := range val {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+		for _, value := range values {
+			for _, form := range strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || r == '/' || r == ';' || r == '，' || r == '、'
+			}) {
+				// 形式中带空格的（如 more beautiful）不是单个单词，跳过
+				if form = normalize(form); form != "" && form != lemma && !strings.Contains(form, " ") {
+					if _, exists := a.irregular[form]; !exists {
+						a.irregular[form] = lemma
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Known 单词（原形）是否在词表中
+func (a *Analyzer) Known(word string) bool {
+	return a.lexicon[normalize(word)]
+}
+
+// Lemma 返回单词的原形；不是单个英文单词时原样返回（小写）
+// 词表中同时有单词本身和去掉 -s/-ed/-ing 的原形时取原形（used -> use、united -> unite），
+// -er/-est 只在单词本身不在词表中时才还原（number、corner 不是 numb、corn 的比较级）
+func (a *Analyzer) Lemma(word string) string {
+	w := normalize(word)
+	if !isWord(w) {
+		return w
+	}
+	if lemma, ok := a.irregular[w]; ok {
+		return lemma
+	}
+	if len(a.lexicon) > 0 {
+		if !noStrip[w] {
+			for _, c := range newCandidates(w).inflections(w).list[1:] {
+				if a.lexicon[c] {
+					return c
+				}
+			}
+		}
+		if a.lexicon[w] {
+			return w
+		}
+		for _, c := range newCandidates(w).comparatives(w).list[1:] {
+			if a.lexicon[c] {
+				return c
+			}
+		}
+	}
+	return guess(w)
+}
+
+// Lemmas 依次还原多个单词
+func (a *Analyzer) Lemmas(words []string) []string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = a.Lemma(w)
+	}
+	return out
+}
+
+// SameLemma 两个单词是否为同一个词的不同形式
+func (a *Analyzer) SameLemma(x, y string) bool {
+	return a.Lemma(x) == a.Lemma(y)
+}
+
+// Candidates 按屈折变化规则推测可能的原形，按可能性排序，第一个是单词本身（小写）
+// 例如 running -> [running run runne runn]；结果需要用词表验证，不包含不规则变化
+func Candidates(word string) []string {
+	w := normalize(word)
+	c := newCandidates(w)
+	if !isWord(w) {
+		return c.list
+	}
+	return c.inflections(w).comparatives(w).list
+}
+
+// candidates 去重的候选原形列表
+type candidates struct {
+	list []string
+	seen map[string]bool
+}
+
+func newCandidates(w string) *candidates {
+	return &candidates{list: []string{w}, seen: map[string]bool{w: true}}
+}
+
+func (c *candidates) add(s string) {
+	if len(s) >= 2 && !c.seen[s] {
+		c.seen[s] = true
+		c.list = append(c.list, s)
+	}
+}
+
+// addStem 去掉 -ed/-ing/-er/-est 后的词干：双写辅音还原（runn -> run）优先，
+// 其次补回 e（hop -> hope、unit -> unite：原形是不带 e 的重读闭音节时词尾会双写），最后是词干本身；
+// 以 ng 结尾的词干本身优先（sing-ing 不是 singe），没有元音的不是词干（sh-ed、str-ing）
+func (c *candidates) addStem(stem string) {
+	if undoubled, ok := undouble(stem); ok {
+		c.add(undoubled)
+	}
+	if !strings.ContainsAny(stem, "aeiouy") {
+		return
+	}
+	if strings.HasSuffix(stem, "ng") {
+		c.add(stem)
+		c.add(stem + "e")
+		return
+	}
+	c.add(stem + "e")
+	c.add(stem)
+}
+
+// inflections 名词复数、动词第三人称单数、过去式和现在分词的候选原形
+func (c *candidates) inflections(w string) *candidates {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		c.add(strings.TrimSuffix(w, "ies") + "y")
+	case strings.HasSuffix(w, "ves") && len(w) > 4:
+		c.add(strings.TrimSuffix(w, "ves") + "f")
+		c.add(strings.TrimSuffix(w, "ves") + "fe")
+		c.add(strings.TrimSuffix(w, "s"))
+	case strings.HasSuffix(w, "es") && len(w) > 3:
+		c.add(strings.TrimSuffix(w, "s"))
+		c.add(strings.TrimSuffix(w, "es"))
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && len(w) > 3:
+		c.add(strings.TrimSuffix(w, "s"))
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ied") && len(w) > 4:
+		c.add(strings.TrimSuffix(w, "ied") + "y")
+	case strings.HasSuffix(w, "eed") && len(w) > 4:
+		c.add(strings.TrimSuffix(w, "d"))
+	case strings.HasSuffix(w, "ed") && len(w) > 3:
+		c.addStem(strings.TrimSuffix(w, "ed"))
+	case strings.HasSuffix(w, "ying") && len(w) > 5:
+		c.add(strings.TrimSuffix(w, "ing"))
+	case strings.HasSuffix(w, "ing") && len(w) > 4:
+		c.addStem(strings.TrimSuffix(w, "ing"))
+	}
+	return c
+}
+
+// comparatives 比较级和最高级的候选原形
+func (c *candidates) comparatives(w string) *candidates {
+	switch {
+	case strings.HasSuffix(w, "iest") && len(w) > 5:
+		c.add(strings.TrimSuffix(w, "iest") + "y")
+	case strings.HasSuffix(w, "ier") && len(w) > 4:
+		c.add(strings.TrimSuffix(w, "ier") + "y")
+	case strings.HasSuffix(w, "est") && len(w) > 5:
+		c.addStem(strings.TrimSuffix(w, "est"))
+	case strings.HasSuffix(w, "er") && len(w) > 4:
+		c.addStem(strings.TrimSuffix(w, "er"))
+	}
+	return c
+}
+
+// guess 词表中找不到时按规则猜测原形
+// 只处理 -s/-es/-ies、-ed、-ing，不处理 -er/-est（teacher、dinner 等名词很常见）；
+// 宁可不还原也不要把两个不同的词还原成同一个
+func guess(w string) string {
+	if len(w) <= 3 || noStrip[w] {
+		return w
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "ves"):
+		return w
+	case hasAnySuffix(w, "sses", "shes", "ches", "xes", "zzes", "oes"):
+		return strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "s"):
+		if hasAnySuffix(w, "ss", "us", "is", "ous", "ics") {
+			return w
+		}
+		return strings.TrimSuffix(w, "s")
+	case strings.HasSuffix(w, "ied") && len(w) > 4:
+		return strings.TrimSuffix(w, "ied") + "y"
+	case strings.HasSuffix(w, "eed"):
+		if len(w) > 5 {
+			return strings.TrimSuffix(w, "d") // agreed -> agree，need/feed/seed 不变
+		}
+		return w
+	case strings.HasSuffix(w, "ed") && len(w) > 3:
+		return guessStem(strings.TrimSuffix(w, "ed"), w)
+	case strings.HasSuffix(w, "ying") && len(w) > 5:
+		return strings.TrimSuffix(w, "ing")
+	case strings.HasSuffix(w, "ing") && len(w) > 4:
+		return guessStem(strings.TrimSuffix(w, "ing"), w)
+	}
+	return w
+}
+
+// guessStem 根据去掉 -ed/-ing 后的词干猜测原形，词干不像单词（没有元音）时返回原词
+func guessStem(stem, word string) string {
+	if !strings.ContainsAny(stem, "aeiouy") {
+		return word // string、spring 中的 str/spr
+	}
+	if undoubled, ok := undouble(stem); ok {
+		return undoubled
+	}
+	if needsE(stem) {
+		return stem + "e"
+	}
+	return stem
+}
+
+// undouble 词干末尾双写的辅音还原（stopp -> stop），ll/ss/zz/ff 等常见的本身双写不处理；
+// 三个字母的词干（add、err、egg）本身就是单词
+func undouble(stem string) (string, bool) {
+	n := len(stem)
+	if n >= 4 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeioulszfwxy", rune(stem[n-1])) {
+		return stem[:n-1], true
+	}
+	return "", false
+}
+
+// needsE 去掉词尾后原形是否更可能以 e 结尾
+// 只剩两个字母的词干补 e（us-ed -> use、ow-ing -> owe）；短的单音节「辅音-元音-辅音」词干没有双写（hop-ing 会写成 hopping），说明原形为 hope；
+// 以 v/z/c、s、dg、rg、辅音+at 结尾的词干在英语中很少单独成词（lov、caus、produc、judg、relat）
+func needsE(stem string) bool {
+	n := len(stem)
+	if n < 2 {
+		return false
+	}
+	if n == 2 {
+		return true
+	}
+	last := stem[n-1]
+	if (n == 3 || (n == 4 && !isVowel(stem[0]))) && !isVowel(stem[n-3]) && isVowel(stem[n-2]) &&
+		!isVowel(last) && !strings.ContainsRune("wxy", rune(last)) {
+		return true
+	}
+	switch {
+	case strings.ContainsRune("vzc", rune(last)):
+		return !strings.HasSuffix(stem, "zz")
+	case last == 's':
+		// focus、bonus 等以 us 结尾的较长词干本身是单词
+		return stem[n-2] != 's' && (n <= 4 || !strings.HasSuffix(stem, "us"))
+	case hasAnySuffix(stem, "dg", "rg", "creat"):
+		return true
+	case strings.HasSuffix(stem, "at") && n >= 4:
+		return !isVowel(stem[n-3])
+	}
+	return false
+}
+
+// normalize 小写并去掉首尾空白、所有格和弯引号
+func normalize(word string) string {
+	w := strings.ToLower(strings.TrimSpace(word))
+	w = strings.ReplaceAll(w, "’", "'")
+	w = strings.TrimSuffix(w, "'s")
+	return strings.Trim(w, "'")
+}
+
+// isWord 是否为单个英文单词（只含字母、连字符和撇号）
+func isWord(w string) bool {
+	if w == "" {
+		return false
+	}
+	for i := 0; i < len(w); i++ {
+		c := w[i]
+		if !(c >= 'a' && c <= 'z') && c != '-' && c != '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package morph
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lexicon 测试用的小词表，模拟从单词书加载的原形
+var lexicon = []string{
+	"use", "us", "unite", "unit", "visit", "hope", "hop", "run", "sing", "singe", "change",
+	"study", "agree", "number", "numb", "corner", "corn", "late", "later", "new", "news",
+	"found", "find", "feel", "see", "good", "better", "more", "many", "add", "ad", "she",
+	"owe", "ice", "box", "wolf", "glass", "want", "paste", "past", "big", "happy",
+}
+
+func TestLemmaWithLexicon(t *testing.T) {
+	a := New()
+	a.AddLemmas(lexicon...)
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		// 规则变化用词表验证
+		{"uses", "use"},
+		{"used", "use"},
+		{"using", "use"},
+		{"united", "unite"},
+		{"unites", "unite"},
+		{"units", "unit"},
+		{"visited", "visit"},
+		{"hoped", "hope"},
+		{"hopped", "hop"},
+		{"running", "run"},
+		{"singing", "sing"},
+		{"changing", "change"},
+		{"studies", "study"},
+		{"agreed", "agree"},
+		{"owed", "owe"},
+		{"iced", "ice"},
+		{"boxes", "box"},
+		{"wolves", "wolf"},
+		{"glasses", "glass"},
+		{"wanted", "want"},
+		{"pasted", "paste"},
+		{"bigger", "big"},
+		{"happiest", "happy"},
+		// 单词本身在词表中时不按 -er 还原
+		{"number", "number"},
+		{"corner", "corner"},
+		{"later", "later"},
+		// 本身是原形的词
+		{"news", "news"},
+		{"shed", "shed"},
+		{"added", "add"},
+		// 不规则变化
+		{"went", "go"},
+		{"children", "child"},
+		{"worse", "bad"},
+		// 同时是独立单词的形式不还原
+		{"found", "found"},
+		{"felt", "felt"},
+		{"saw", "saw"},
+		{"better", "better"},
+		{"best", "best"},
+		{"more", "more"},
+		// 大小写、所有格和非单词
+		{"Running", "run"},
+		{"study's", "study"},
+		{"ice cream", "ice cream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := a.Lemma(tt.word); got != tt.want {
+				t.Errorf("Lemma(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLemmaGuess(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"uses", "use"},
+		{"used", "use"},
+		{"using", "use"},
+		{"owed", "owe"},
+		{"died", "die"},
+		{"studies", "study"},
+		{"watches", "watch"},
+		{"stopped", "stop"},
+		{"hoping", "hope"},
+		{"created", "create"},
+		{"loved", "love"},
+		{"judging", "judge"},
+		{"added", "add"},
+		{"agreed", "agree"},
+		{"need", "need"},
+		{"shed", "shed"},
+		{"bring", "bring"},
+		{"string", "string"},
+		{"status", "status"},
+		{"famous", "famous"},
+		{"news", "news"},
+		{"hers", "hers"},
+		{"building", "building"},
+		{"teacher", "teacher"},
+		{"found", "found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := a.Lemma(tt.word); got != tt.want {
+				t.Errorf("Lemma(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddForms(t *testing.T) {
+	a := New()
+	if err := a.AddForms("arise", `{"past":"arose","past_participle":["arisen"],"third":"arises / arising"}`); err != nil {
+		t.Fatalf("AddForms: %v", err)
+	}
+	if err := a.AddForms("beautiful", `{"comparative":"more beautiful"}`); err != nil {
+		t.Fatalf("AddForms: %v", err)
+	}
+	if err := a.AddForms("x", `not json`); err == nil {
+		t.Error("无效的 JSON 应该报错")
+	}
+
+	for word, want := range map[string]string{"arose": "arise", "arising": "arise", "arises": "arise", "more": "more"} {
+		if got := a.Lemma(word); got != want {
+			t.Errorf("Lemma(%q) = %q, want %q", word, got, want)
+		}
+	}
+	if !a.Known("arise") || a.Known("arose") {
+		t.Error("AddForms 只应把原形加入词表")
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"running", []string{"running", "run", "runne", "runn"}},
+		{"used", []string{"used", "use", "us"}},
+		{"singing", []string{"singing", "sing", "singe"}},
+		{"shed", []string{"shed"}},
+		{"studies", []string{"studies", "study"}},
+		{"bigger", []string{"bigger", "big", "bigge", "bigg"}},
+		{"ice cream", []string{"ice cream"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := Candidates(tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Candidates(%q) = %v, want %v", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameLemma(t *testing.T) {
+	a := New()
+	a.AddLemmas(lexicon...)
+
+	tests := []struct {
+		x, y string
+		want bool
+	}{
+		{"used", "uses", true},
+		{"running", "ran", true},
+		{"found", "find", false},
+		{"saw", "see", false},
+		{"better", "good", false},
+		{"united", "unit", false},
+	}
+	for _, tt := range tests {
+		if got := a.SameLemma(tt.x, tt.y); got != tt.want {
+			t.Errorf("SameLemma(%q, %q) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Package recommend 文章推荐打分引擎
+//
+// 纯 Go 实现，不依赖数据库：调用方负责把阅读历史、候选文章、待复习单词等数据
+// 组装成本包的结构体，引擎只做打分和排序，并给出可解释的分项得分，方便用固定数据做单元测试。
+package recommend
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights 各信号的权重
+type Weights struct {
+	Category   float64 `json:"category"`   // 分类偏好
+	Difficulty float64 `json:"difficulty"` // 难度匹配
+	Vocabulary float64 `json:"vocabulary"` // 与待复习生词的重合度
+	Recency    float64 `json:"recency"`    // 新鲜度
+	Topic      float64 `json:"topic"`      // 与订阅话题的匹配度
+}
+
+// DefaultWeights 默认权重（总和为1）
+var DefaultWeights = Weights{
+	Category:   0.30,
+	Difficulty: 0.20,
+	Vocabulary: 0.20,
+	Recency:    0.10,
+	Topic:      0.20,
+}
+
+const (
+	// DefaultLevel 没有阅读历史时假定的用户水平（难度 0-5）
+	DefaultLevel = 2.5
+	// MaxDifficulty 难度上限，与 vp_words.level 一致
+	MaxDifficulty = 5.0
+	// LevelStretch 推荐难度略高于用户当前水平（i+1 可理解输入）
+	LevelStretch = 0.3
+	// RecencyHalfLifeDays 新鲜度半衰期（天）
+	RecencyHalfLifeDays = 14.0
+	// HistoryHalfLifeDays 阅读历史权重半衰期（天），越久远的阅读对偏好的影响越小
+	HistoryHalfLifeDays = 30.0
+	// VocabularySaturation 命中多少个待复习单词后重合度得分接近饱和
+	VocabularySaturation = 3.0
+	// TopicSaturation 命中多少个订阅话题后话题得分接近饱和
+	TopicSaturation = 1.5
+)
+
+// ReadingSignal 一条阅读历史（来自 vp_reading_progress）
+type ReadingSignal struct {
+	ArticleID   uint
+	CategoryID  *uint
+	Difficulty  float64 // 该文章的难度（0-5），未知时为0
+	Progress    float64 // 阅读进度 0-100
+	IsCompleted bool
+	LastReadAt  time.Time
+}
+
+// Profile 用户画像
+type Profile struct {
+	CategoryAffinity map[uint]float64 // 分类ID -> 偏好度（0-1，已归一化）
+	Level            float64          // 用户水平（0-5）
+	DueWords         map[string]bool  // 待复习单词（小写）
+	SubscribedTags   map[uint]bool    // 订阅的话题标签ID
+	Now              time.Time
+}
+
+// Candidate 候选文章
+type Candidate struct {
+	ArticleID   uint
+	CategoryID  *uint
+	Difficulty  float64  // 文章难度（0-5）
+	Words       []string // 文章单词（用于和待复习生词求交集）
+	TagIDs      []uint   // 文章标签ID（用于和订阅话题求交集）
+	PublishedAt time.Time
+}
+
+// Breakdown 可解释的分项得分
+// 每个分项都在 0-1 之间，Total 为加权和
+type Breakdown struct {
+	CategoryAffinity  float64  `json:"category_affinity"`
+	DifficultyFit     float64  `json:"difficulty_fit"`
+	VocabularyOverlap float64  `json:"vocabulary_overlap"`
+	Recency           float64  `json:"recency"`
+	TopicMatch        float64  `json:"topic_match"`
+	Total             float64  `json:"total"`
+	MatchedWords      []string `json:"matched_words,omitempty"` // 命中的待复习单词
+	MatchedTags       []uint   `json:"matched_tags,omitempty"`  // 命中的订阅话题
+	Reasons           []string `json:"reasons,omitempty"`       // 推荐理由
+}
+
+// Result 推荐结果
+type Result struct {
+	ArticleID uint      `json:"article_id"`
+	Score     float64   `json:"score"`
+	Breakdown Breakdown `json:"breakdown"`
+}
+
+// BuildProfile 根据阅读历史、待复习单词和订阅话题构建用户画像
+func BuildProfile(history []ReadingSignal, dueWords []string, subscribedTags []uint, now time.Time) Profile {
+	affinity := make(map[uint]float64)
+	var maxAffinity float64
+	var levelSum, levelWeight float64
+
+	for _, h := range history {
+		w := engagement(h) * decay(now.Sub(h.LastReadAt), HistoryHalfLifeDays)
+		if h.CategoryID != nil {
+			affinity[*h.CategoryID] += w
+			if affinity[*h.CategoryID] > maxAffinity {
+				maxAffinity = affinity[*h.CategoryID]
+			}
+		}
+		// 只有读完或读了大半的文章才用来估计水平
+		if h.Difficulty > 0 && (h.IsCompleted || h.Progress >= 50) {
+			levelSum += h.Difficulty * w
+			levelWeight += w
+		}
+	}
+
+	if maxAffinity > 0 {
+		for k, v := range affinity {
+			affinity[k] = v / maxAffinity
+		}
+	}
+
+	level := DefaultLevel
+	if levelWeight > 0 {
+		level = levelSum / levelWeight
+	}
+
+	due := make(map[string]bool, len(dueWords))
+	for _, w := range dueWords {
+		if w = normalizeWord(w); w != "" {
+			due[w] = true
+		}
+	}
+
+	tags := make(map[uint]bool, len(subscribedTags))
+	for _, id := range subscribedTags {
+		tags[id] = true
+	}
+
+	return Profile{
+		CategoryAffinity: affinity,
+		Level:            level,
+		DueWords:         due,
+		SubscribedTags:   tags,
+		Now:              now,
+	}
+}
+
+// Score 为单篇候选文章打分
+func Score(p Profile, c Candidate, w Weights) Result {
+	var b Breakdown
+
+	// 1. 分类偏好
+	if c.CategoryID != nil {
+		b.CategoryAffinity = p.CategoryAffinity[*c.CategoryID]
+	}
+
+	// 2. 难度匹配：目标难度为用户水平 + LevelStretch，偏差越大得分越低
+	if c.Difficulty > 0 {
+		target := math.Min(p.Level+LevelStretch, MaxDifficulty)
+		b.DifficultyFit = clamp01(1 - math.Abs(c.Difficulty-target)/MaxDifficulty*2)
+	} else {
+		b.DifficultyFit = 0.5 // 难度未知时给中性分
+	}
+
+	// 3. 待复习生词重合度
+	seen := make(map[string]bool)
+	for _, word := range c.Words {
+		word = normalizeWord(word)
+		if p.DueWords[word] && !seen[word] {
+			seen[word] = true
+			b.MatchedWords = append(b.MatchedWords, word)
+		}
+	}
+	sort.Strings(b.MatchedWords)
+	b.VocabularyOverlap = 1 - math.Exp(-float64(len(b.MatchedWords))/VocabularySaturation)
+
+	// 4. 新鲜度
+	if !c.PublishedAt.IsZero() {
+		b.Recency = decay(p.Now.Sub(c.PublishedAt), RecencyHalfLifeDays)
+	}
+
+	// 5. 订阅话题
+	seenTags := make(map[uint]bool)
+	for _, id := range c.TagIDs {
+		if p.SubscribedTags[id] && !seenTags[id] {
+			seenTags[id] = true
+			b.MatchedTags = append(b.MatchedTags, id)
+		}
+	}
+	sort.Slice(b.MatchedTags, func(i, j int) bool { return b.MatchedTags[i] < b.MatchedTags[j] })
+	b.TopicMatch = 1 - math.Exp(-float64(len(b.MatchedTags))/TopicSaturation)
+
+	b.Total = w.Category*b.CategoryAffinity +
+		w.Difficulty*b.DifficultyFit +
+		w.Vocabulary*b.VocabularyOverlap +
+		w.Recency*b.Recency +
+		w.Topic*b.TopicMatch
+	b.Total = round4(b.Total)
+	b.CategoryAffinity = round4(b.CategoryAffinity)
+	b.DifficultyFit = round4(b.DifficultyFit)
+	b.VocabularyOverlap = round4(b.VocabularyOverlap)
+	b.Recency = round4(b.Recency)
+	b.TopicMatch = round4(b.TopicMatch)
+	b.Reasons = reasons(b)
+
+	return Result{ArticleID: c.ArticleID, Score: b.Total, Breakdown: b}
+}
+
+// Rank 为候选文章打分并按得分降序返回前 limit 篇（limit<=0 表示全部）
+// 得分相同时按发布时间倒序、文章ID倒序，保证结果稳定
+func Rank(p Profile, candidates []Candidate, w Weights, limit int) []Result {
+	results := make([]Result, 0, len(candidates))
+	published := make(map[uint]time.Time, len(candidates))
+	for _, c := range candidates {
+		results = append(results, Score(p, c, w))
+		published[c.ArticleID] = c.PublishedAt
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		pi, pj := published[results[i].ArticleID], published[results[j].ArticleID]
+		if !pi.Equal(pj) {
+			return pi.After(pj)
+		}
+		return results[i].ArticleID > results[j].ArticleID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// engagement 单条阅读记录的投入度（0-1）
+func engagement(h ReadingSignal) float64 {
+	if h.IsCompleted {
+		return 1
+	}
+	return clamp01(h.Progress / 100)
+}
+
+// decay 按半衰期计算时间衰减系数
+func decay(age time.Duration, halfLifeDays float64) float64 {
+	days := age.Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return math.Exp(-math.Ln2 * days / halfLifeDays)
+}
+
+func reasons(b Breakdown) []string {
+	var r []string
+	if b.CategoryAffinity >= 0.5 {
+		r = append(r, "你常读的分类")
+	}
+	if b.DifficultyFit >= 0.8 {
+		r = append(r, "难度适合你当前水平")
+	}
+	if len(b.MatchedTags) > 0 {
+		r = append(r, "你订阅的话题")
+	}
+	if len(b.MatchedWords) > 0 {
+		r = append(r, "包含你待复习的单词")
+	}
+	if b.Recency >= 0.8 {
+		r = append(r, "最新发布")
+	}
+	return r
+}
+
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimSpace(w))
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
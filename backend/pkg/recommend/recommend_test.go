@@ -0,0 +1,198 @@
+package recommend
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fixture testdata 中的一组推荐场景：阅读历史、待复习单词、订阅话题、候选文章及期望结果
+type fixture struct {
+	Now     time.Time `json:"now"`
+	History []struct {
+		ArticleID   uint      `json:"article_id"`
+		CategoryID  *uint     `json:"category_id"`
+		Difficulty  float64   `json:"difficulty"`
+		Progress    float64   `json:"progress"`
+		IsCompleted bool      `json:"is_completed"`
+		LastReadAt  time.Time `json:"last_read_at"`
+	} `json:"history"`
+	DueWords       []string `json:"due_words"`
+	SubscribedTags []uint   `json:"subscribed_tags"`
+	Candidates     []struct {
+		ArticleID   uint      `json:"article_id"`
+		CategoryID  *uint     `json:"category_id"`
+		Difficulty  float64   `json:"difficulty"`
+		Words       []string  `json:"words"`
+		TagIDs      []uint    `json:"tag_ids"`
+		PublishedAt time.Time `json:"published_at"`
+	} `json:"candidates"`
+	Expected struct {
+		Level            float64            `json:"level"`
+		CategoryAffinity map[string]float64 `json:"category_affinity"`
+		Ranking          []uint             `json:"ranking"`
+		Top              Breakdown          `json:"top"`
+	} `json:"expected"`
+}
+
+func loadFixture(t *testing.T, name string) fixture {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取 fixture 失败: %v", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("解析 fixture 失败: %v", err)
+	}
+	return f
+}
+
+func (f fixture) signals() []ReadingSignal {
+	history := make([]ReadingSignal, 0, len(f.History))
+	for _, h := range f.History {
+		history = append(history, ReadingSignal{
+			ArticleID:   h.ArticleID,
+			CategoryID:  h.CategoryID,
+			Difficulty:  h.Difficulty,
+			Progress:    h.Progress,
+			IsCompleted: h.IsCompleted,
+			LastReadAt:  h.LastReadAt,
+		})
+	}
+	return history
+}
+
+func (f fixture) candidates() []Candidate {
+	candidates := make([]Candidate, 0, len(f.Candidates))
+	for _, c := range f.Candidates {
+		candidates = append(candidates, Candidate{
+			ArticleID:   c.ArticleID,
+			CategoryID:  c.CategoryID,
+			Difficulty:  c.Difficulty,
+			Words:       c.Words,
+			TagIDs:      c.TagIDs,
+			PublishedAt: c.PublishedAt,
+		})
+	}
+	return candidates
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestFixtures(t *testing.T) {
+	for _, name := range []string{"basic.json"} {
+		t.Run(name, func(t *testing.T) {
+			f := loadFixture(t, name)
+			profile := BuildProfile(f.signals(), f.DueWords, f.SubscribedTags, f.Now)
+
+			if !almostEqual(profile.Level, f.Expected.Level) {
+				t.Errorf("Level = %v, want %v", profile.Level, f.Expected.Level)
+			}
+			for key, want := range f.Expected.CategoryAffinity {
+				id, _ := strconv.Atoi(key)
+				if got := profile.CategoryAffinity[uint(id)]; !almostEqual(got, want) {
+					t.Errorf("CategoryAffinity[%d] = %v, want %v", id, got, want)
+				}
+			}
+
+			results := Rank(profile, f.candidates(), DefaultWeights, 0)
+			var ranking []uint
+			for _, r := range results {
+				ranking = append(ranking, r.ArticleID)
+			}
+			if !reflect.DeepEqual(ranking, f.Expected.Ranking) {
+				t.Fatalf("ranking = %v, want %v", ranking, f.Expected.Ranking)
+			}
+
+			got, want := results[0].Breakdown, f.Expected.Top
+			checks := []struct {
+				name      string
+				got, want float64
+			}{
+				{"category_affinity", got.CategoryAffinity, want.CategoryAffinity},
+				{"difficulty_fit", got.DifficultyFit, want.DifficultyFit},
+				{"vocabulary_overlap", got.VocabularyOverlap, want.VocabularyOverlap},
+				{"recency", got.Recency, want.Recency},
+				{"topic_match", got.TopicMatch, want.TopicMatch},
+			}
+			for _, c := range checks {
+				if !almostEqual(c.got, c.want) {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+			if !reflect.DeepEqual(got.MatchedWords, want.MatchedWords) {
+				t.Errorf("matched_words = %v, want %v", got.MatchedWords, want.MatchedWords)
+			}
+			if !reflect.DeepEqual(got.MatchedTags, want.MatchedTags) {
+				t.Errorf("matched_tags = %v, want %v", got.MatchedTags, want.MatchedTags)
+			}
+			if !reflect.DeepEqual(got.Reasons, want.Reasons) {
+				t.Errorf("reasons = %v, want %v", got.Reasons, want.Reasons)
+			}
+		})
+	}
+}
+
+func TestBuildProfileWithoutHistory(t *testing.T) {
+	p := BuildProfile(nil, nil, nil, time.Now())
+	if p.Level != DefaultLevel {
+		t.Errorf("Level = %v, want %v", p.Level, DefaultLevel)
+	}
+	if len(p.CategoryAffinity) != 0 {
+		t.Errorf("CategoryAffinity = %v, want empty", p.CategoryAffinity)
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	profile := Profile{Level: 2, Now: now}
+
+	tests := []struct {
+		name       string
+		candidate  Candidate
+		difficulty float64
+		recency    float64
+	}{
+		{"难度未知给中性分", Candidate{ArticleID: 1}, 0.5, 0},
+		{"难度正好", Candidate{ArticleID: 2, Difficulty: 2.3}, 1, 0},
+		{"难度过高", Candidate{ArticleID: 3, Difficulty: 5}, 0, 0},
+		{"刚发布", Candidate{ArticleID: 4, PublishedAt: now}, 0.5, 1},
+		{"半衰期", Candidate{ArticleID: 5, PublishedAt: now.AddDate(0, 0, -14)}, 0.5, 0.5},
+		{"未来发布按刚发布计算", Candidate{ArticleID: 6, PublishedAt: now.Add(time.Hour)}, 0.5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Score(profile, tt.candidate, DefaultWeights).Breakdown
+			if !almostEqual(b.DifficultyFit, tt.difficulty) {
+				t.Errorf("DifficultyFit = %v, want %v", b.DifficultyFit, tt.difficulty)
+			}
+			if !almostEqual(b.Recency, tt.recency) {
+				t.Errorf("Recency = %v, want %v", b.Recency, tt.recency)
+			}
+		})
+	}
+}
+
+func TestRankTieBreakAndLimit(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	profile := Profile{Level: DefaultLevel, Now: now}
+	// 得分相同时按文章ID倒序，并只返回前 limit 篇
+	published := now.AddDate(0, 0, -7)
+	candidates := []Candidate{
+		{ArticleID: 1, PublishedAt: published},
+		{ArticleID: 3, PublishedAt: published},
+		{ArticleID: 2, PublishedAt: published},
+	}
+	results := Rank(profile, candidates, DefaultWeights, 2)
+	if len(results) != 2 || results[0].ArticleID != 3 || results[1].ArticleID != 2 {
+		t.Fatalf("Rank = %+v, want articles [3 2]", results)
+	}
+}
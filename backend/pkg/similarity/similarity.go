@@ -0,0 +1,187 @@
+// Package similarity 基于 TF-IDF 余弦相似度的文章相关度计算
+//
+// 纯 Go 实现，不依赖数据库：调用方把每篇文章的正文和重点单词组装成 Document，
+// 本包负责分词、计算 TF-IDF 向量，并为每篇文章找出最相似的若干篇文章。
+package similarity
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// KeyWordWeight 重点单词（vp_words）在词频中额外计入的次数
+	KeyWordWeight = 3
+	// MinTokenLength 参与计算的最短单词长度
+	MinTokenLength = 3
+	// MaxSharedTerms 每对文章最多返回的共同关键词数
+	MaxSharedTerms = 5
+)
+
+// stopWords 常见英文停用词（不参与相似度计算）
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		the and for are but not you all any can had her was one our out has have
+		his how its may new now old see two way who did get got him let put say
+		she too use that with this from they will would there their what when which
+		about after again also been before being could does doing down each few
+		into just more most much must only other over same should some such than
+		then them these those through under until very were where while whom why
+		your yours ours because between during further here once own both off upon
+		said says like make made many even ever every still yet within without
+		onto per via among across against along around behind below beside beyond
+	`) {
+		stopWords[w] = true
+	}
+}
+
+// Document 一篇参与计算的文章
+type Document struct {
+	ID       uint
+	Text     string   // 正文（标题 + 句子）
+	KeyWords []string // 重点单词
+}
+
+// Neighbor 相似文章
+type Neighbor struct {
+	ID          uint     `json:"id"`
+	Score       float64  `json:"score"`        // 余弦相似度（0-1）
+	SharedTerms []string `json:"shared_terms"` // 贡献最大的共同词
+}
+
+// Tokenize 把文本切分成小写单词，过滤停用词、数字和过短的词
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "'")
+		if i := strings.Index(f, "'"); i >= 0 {
+			f = f[:i] // 去掉 's、'll 等缩写后缀
+		}
+		if len(f) < MinTokenLength || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+// Vectorize 计算所有文档的 TF-IDF 稀疏向量（文章ID -> 单词 -> 权重）
+// TF 使用对数平滑（1+log(tf)），IDF 使用 log((1+N)/(1+df))+1，向量做 L2 归一化
+func Vectorize(docs []Document) map[uint]map[string]float64 {
+	termFreqs := make(map[uint]map[string]int, len(docs))
+	docFreq := make(map[string]int)
+
+	for _, d := range docs {
+		tf := make(map[string]int)
+		for _, t := range Tokenize(d.Text) {
+			tf[t]++
+		}
+		for _, kw := range d.KeyWords {
+			for _, t := range Tokenize(kw) {
+				tf[t] += KeyWordWeight
+			}
+		}
+		termFreqs[d.ID] = tf
+		for t := range tf {
+			docFreq[t]++
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make(map[uint]map[string]float64, len(docs))
+	for id, tf := range termFreqs {
+		v := make(map[string]float64, len(tf))
+		var norm float64
+		for t, c := range tf {
+			w := (1 + math.Log(float64(c))) * (math.Log((1+n)/(1+float64(docFreq[t]))) + 1)
+			v[t] = w
+			norm += w * w
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for t := range v {
+				v[t] /= norm
+			}
+		}
+		vectors[id] = v
+	}
+	return vectors
+}
+
+// Cosine 计算两个已归一化向量的余弦相似度，并返回贡献最大的共同词
+func Cosine(a, b map[string]float64) (float64, []string) {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	type contrib struct {
+		term  string
+		value float64
+	}
+	var score float64
+	var shared []contrib
+	for t, wa := range a {
+		if wb, ok := b[t]; ok {
+			score += wa * wb
+			shared = append(shared, contrib{t, wa * wb})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].value != shared[j].value {
+			return shared[i].value > shared[j].value
+		}
+		return shared[i].term < shared[j].term
+	})
+	if len(shared) > MaxSharedTerms {
+		shared = shared[:MaxSharedTerms]
+	}
+	terms := make([]string, 0, len(shared))
+	for _, s := range shared {
+		terms = append(terms, s.term)
+	}
+	return math.Min(score, 1), terms
+}
+
+// TopK 为每篇文章找出相似度最高的 k 篇文章（得分低于 minScore 的忽略）
+// 结果按得分降序，得分相同时按文章ID升序
+func TopK(docs []Document, k int, minScore float64) map[uint][]Neighbor {
+	vectors := Vectorize(docs)
+	ids := make([]uint, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	result := make(map[uint][]Neighbor, len(ids))
+	for i := 0; i < len(ids); i++ {
+		for j := i + 1; j < len(ids); j++ {
+			score, terms := Cosine(vectors[ids[i]], vectors[ids[j]])
+			if score <= 0 || score < minScore {
+				continue
+			}
+			score = math.Round(score*10000) / 10000
+			result[ids[i]] = append(result[ids[i]], Neighbor{ID: ids[j], Score: score, SharedTerms: terms})
+			result[ids[j]] = append(result[ids[j]], Neighbor{ID: ids[i], Score: score, SharedTerms: terms})
+		}
+	}
+
+	for id, list := range result {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].ID < list[j].ID
+		})
+		if k > 0 && len(list) > k {
+			list = list[:k]
+		}
+		result[id] = list
+	}
+	return result
+}
@@ -0,0 +1,184 @@
+package similarity
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// fixture testdata 中的一组文章及期望的相似文章
+type fixture struct {
+	K         int     `json:"k"`
+	MinScore  float64 `json:"min_score"`
+	Documents []struct {
+		ID       uint     `json:"id"`
+		Text     string   `json:"text"`
+		KeyWords []string `json:"key_words"`
+	} `json:"documents"`
+	Expected map[string][]Neighbor `json:"expected"`
+}
+
+func (f fixture) documents() []Document {
+	docs := make([]Document, 0, len(f.Documents))
+	for _, d := range f.Documents {
+		docs = append(docs, Document{ID: d.ID, Text: d.Text, KeyWords: d.KeyWords})
+	}
+	return docs
+}
+
+func loadFixture(t *testing.T, name string) fixture {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取 fixture 失败: %v", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("解析 fixture 失败: %v", err)
+	}
+	return f
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestFixtures(t *testing.T) {
+	for _, name := range []string{"articles.json"} {
+		t.Run(name, func(t *testing.T) {
+			f := loadFixture(t, name)
+			result := TopK(f.documents(), f.K, f.MinScore)
+
+			if len(result) != len(f.Expected) {
+				t.Errorf("有相似文章的文章数 = %d, want %d", len(result), len(f.Expected))
+			}
+			for key, want := range f.Expected {
+				id, _ := strconv.Atoi(key)
+				got := result[uint(id)]
+				if len(got) != len(want) {
+					t.Errorf("文章 %d: 相似文章 = %+v, want %+v", id, got, want)
+					continue
+				}
+				for i := range want {
+					if got[i].ID != want[i].ID || !almostEqual(got[i].Score, want[i].Score) {
+						t.Errorf("文章 %d 第 %d 篇 = %d (%.4f), want %d (%.4f)", id, i, got[i].ID, got[i].Score, want[i].ID, want[i].Score)
+					}
+					if !reflect.DeepEqual(got[i].SharedTerms, want[i].SharedTerms) {
+						t.Errorf("文章 %d 第 %d 篇共同词 = %v, want %v", id, i, got[i].SharedTerms, want[i].SharedTerms)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"The Economy's growth, 2024!", []string{"economy", "growth"}},
+		{"Don't STOP; it's OK", []string{"don", "stop"}},
+		{"'quoted' words", []string{"quoted", "words"}},
+		{"they would have been there", []string{}},
+		{"well-being co2 x-ray", []string{"well", "ray"}},
+		{"café naïve", []string{"café", "naïve"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVectorize(t *testing.T) {
+	// N=2：apple 只出现在文章 1（idf = ln(3/2)+1），banana 两篇都有（idf = 1）
+	// 文章 1：apple tf=2 → (1+ln2)·1.4055 = 2.3797，banana = 1，归一化后 0.9219 / 0.3874
+	vectors := Vectorize([]Document{
+		{ID: 1, Text: "apple apple banana"},
+		{ID: 2, Text: "banana cherry"},
+	})
+	tests := []struct {
+		id   uint
+		term string
+		want float64
+	}{
+		{1, "apple", 0.9219},
+		{1, "banana", 0.3874},
+		{2, "banana", 0.5797},
+		{2, "cherry", 0.8148},
+	}
+	for _, tt := range tests {
+		if got := vectors[tt.id][tt.term]; !almostEqual(got, tt.want) {
+			t.Errorf("文章 %d 的 %s 权重 = %.4f, want %.4f", tt.id, tt.term, got, tt.want)
+		}
+	}
+	if score, terms := Cosine(vectors[1], vectors[2]); !almostEqual(score, 0.2246) || !reflect.DeepEqual(terms, []string{"banana"}) {
+		t.Errorf("Cosine = %.4f %v, want 0.2246 [banana]", score, terms)
+	}
+}
+
+func TestVectorizeKeyWords(t *testing.T) {
+	// 重点单词额外计入 KeyWordWeight 次，权重高于只在正文出现一次的词
+	vectors := Vectorize([]Document{
+		{ID: 1, Text: "market report", KeyWords: []string{"Market"}},
+		{ID: 2, Text: "weather report"},
+	})
+	v := vectors[1]
+	if v["market"] <= v["report"] {
+		t.Errorf("重点单词权重 %.4f 应大于普通单词 %.4f", v["market"], v["report"])
+	}
+	var norm float64
+	for _, w := range v {
+		norm += w * w
+	}
+	if !almostEqual(norm, 1) {
+		t.Errorf("向量长度平方 = %.4f, want 1", norm)
+	}
+}
+
+func TestCosineSharedTerms(t *testing.T) {
+	a := map[string]float64{"a": 0.5, "b": 0.5, "c": 0.4, "d": 0.3, "e": 0.2, "f": 0.1, "g": 0.1}
+	b := map[string]float64{"a": 0.1, "b": 0.1, "c": 0.5, "d": 0.5, "e": 0.5, "f": 0.5, "g": 0.5, "h": 0.9}
+	// 贡献：c=0.20 d=0.15 e=0.10 a=b=f=g=0.05，相同时按单词排序，最多 MaxSharedTerms 个
+	score, terms := Cosine(a, b)
+	if !almostEqual(score, 0.65) {
+		t.Errorf("score = %.4f, want 0.65", score)
+	}
+	if want := []string{"c", "d", "e", "a", "b"}; !reflect.DeepEqual(terms, want) {
+		t.Errorf("terms = %v, want %v", terms, want)
+	}
+	if score, terms := Cosine(a, map[string]float64{"z": 1}); score != 0 || len(terms) != 0 {
+		t.Errorf("没有共同词时 Cosine = %v %v, want 0 []", score, terms)
+	}
+}
+
+func TestTopKTieBreak(t *testing.T) {
+	// 文章 2、3 与文章 1 的相似度相同，按文章ID升序；k=0 不限制数量
+	docs := []Document{
+		{ID: 3, Text: "river bank"},
+		{ID: 1, Text: "river"},
+		{ID: 2, Text: "river bank"},
+	}
+	result := TopK(docs, 0, 0)
+	var ids []uint
+	for _, n := range result[1] {
+		ids = append(ids, n.ID)
+	}
+	if !reflect.DeepEqual(ids, []uint{2, 3}) {
+		t.Errorf("文章 1 的相似文章 = %v, want [2 3]", ids)
+	}
+	if len(result[2]) != 2 || result[2][0].ID != 3 || result[2][0].Score != 1 {
+		t.Errorf("文章 2 的相似文章 = %+v, want 文章 3 排第一且得分为 1", result[2])
+	}
+	if got := TopK(nil, 5, 0); len(got) != 0 {
+		t.Errorf("TopK(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+// DB 只读打开的数据库文件（整个文件在内存中）
+type DB struct {
+	data     []byte
+	pageSize int
+	usable   int
+}
+
+// Open 解析数据库文件头
+func Open(data []byte) (*DB, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte("SQLite format 3\x00")) {
+		return nil, ErrNotSQLite
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 || len(data) < pageSize {
+		return nil, ErrCorrupt
+	}
+	return &DB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+	}, nil
+}
+
+// Tables 返回所有表名及其 CREATE 语句
+func (db *DB) Tables() (map[string]string, error) {
+	tables := make(map[string]string)
+	err := db.scan(1, func(_ int64, values []interface{}) error {
+		if len(values) >= 5 && values[0] == "table" {
+			name, _ := values[1].(string)
+			sql, _ := values[4].(string)
+			tables[name] = sql
+		}
+		return nil
+	})
+	return tables, err
+}
+
+// ReadTable 按 rowid 顺序读取整张表（表名不区分大小写）
+func (db *DB) ReadTable(name string) ([]Row, error) {
+	root := 0
+	err := db.scan(1, func(_ int64, values []interface{}) error {
+		if len(values) >= 4 && values[0] == "table" {
+			if n, ok := values[1].(string); ok && strings.EqualFold(n, name) {
+				if page, ok := values[3].(int64); ok {
+					root = int(page)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == 0 {
+		return nil, ErrTableNotFound
+	}
+
+	var rows []Row
+	err = db.scan(root, func(rowID int64, values []interface{}) error {
+		rows = append(rows, Row{RowID: rowID, Values: values})
+		return nil
+	})
+	return rows, err
+}
+
+func (db *DB) page(no int) ([]byte, error) {
+	start := (no - 1) * db.pageSize
+	if no < 1 || start+db.pageSize > len(db.data) {
+		return nil, ErrCorrupt
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// scan 深度优先遍历表 B 树
+func (db *DB) scan(root int, fn func(rowID int64, values []interface{}) error) error {
+	return db.scanPage(root, fn, 0)
+}
+
+func (db *DB) scanPage(no int, fn func(int64, []interface{}) error, depth int) error {
+	if depth > 64 {
+		return ErrCorrupt
+	}
+	page, err := db.page(no)
+	if err != nil {
+		return err
+	}
+	offset := 0
+	if no == 1 {
+		offset = headerSize
+	}
+	if offset+leafHeaderSize > len(page) {
+		return ErrCorrupt
+	}
+
+	kind := page[offset]
+	count := int(binary.BigEndian.Uint16(page[offset+3:]))
+	hdrLen := leafHeaderSize
+	if kind == pageInterTable {
+		hdrLen = interHeaderLen
+	}
+	if offset+hdrLen+count*2 > len(page) {
+		return ErrCorrupt
+	}
+
+	for i := 0; i < count; i++ {
+		ptr := int(binary.BigEndian.Uint16(page[offset+hdrLen+i*2:]))
+		if ptr >= len(page) {
+			return ErrCorrupt
+		}
+		c := page[ptr:]
+
+		switch kind {
+		case pageInterTable:
+			if len(c) < 4 {
+				return ErrCorrupt
+			}
+			child := int(binary.BigEndian.Uint32(c))
+			if err := db.scanPage(child, fn, depth+1); err != nil {
+				return err
+			}
+		case pageLeafTable:
+			payloadLen, n := readVarint(c)
+			if n == 0 {
+				return ErrCorrupt
+			}
+			rowID, m := readVarint(c[n:])
+			if m == 0 {
+				return ErrCorrupt
+			}
+			payload, err := db.payload(c[n+m:], int(payloadLen))
+			if err != nil {
+				return err
+			}
+			values, err := decodeRecord(payload)
+			if err != nil {
+				return err
+			}
+			if err := fn(int64(rowID), values); err != nil {
+				return err
+			}
+		default:
+			return ErrCorrupt
+		}
+	}
+
+	if kind == pageInterTable {
+		right := int(binary.BigEndian.Uint32(page[offset+8:]))
+		return db.scanPage(right, fn, depth+1)
+	}
+	return nil
+}
+
+// payload 读取单元格负载，超出本页部分沿溢出页链表读取
+func (db *DB) payload(c []byte, size int) ([]byte, error) {
+	local := db.localPayload(size)
+	if local == size {
+		if len(c) < size {
+			return nil, ErrCorrupt
+		}
+		return c[:size], nil
+	}
+	if len(c) < local+4 {
+		return nil, ErrCorrupt
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, c[:local]...)
+	next := int(binary.BigEndian.Uint32(c[local:]))
+	for len(out) < size {
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		n := size - len(out)
+		if n > db.usable-4 {
+			n = db.usable - 4
+		}
+		out = append(out, page[4:4+n]...)
+		next = int(binary.BigEndian.Uint32(page))
+	}
+	return out, nil
+}
+
+// localPayload 与写入时的计算相同，但使用文件实际的页大小
+func (db *DB) localPayload(payload int) int {
+	maxLocal := db.usable - 35
+	if payload <= maxLocal {
+		return payload
+	}
+	minLocal := (db.usable-12)*32/255 - 23
+	k := minLocal + (payload-minLocal)%(db.usable-4)
+	if k <= maxLocal {
+		return k
+	}
+	return minLocal
+}
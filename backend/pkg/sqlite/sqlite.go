@@ -0,0 +1,277 @@
+// Package sqlite 纯 Go 实现的 SQLite 数据库文件读写（只支持最基本的表）
+//
+// 用于生成和解析 Anki 牌组包（.apkg）中的 collection 数据库，不依赖 cgo 和 SQLite 驱动。
+// 写入：一次性生成整个数据库文件，只包含普通的 rowid 表（不生成索引），
+// 所有表的定义需要放在第 1 页的 sqlite_master 中。
+// 读取：遍历 sqlite_master 找到表的根页，按 rowid 顺序读取表 B 树中的所有记录，
+// 支持溢出页，不支持 WAL 模式和 WITHOUT ROWID 表。
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// 文件格式常量
+const (
+	PageSize = 4096
+
+	headerSize     = 100
+	pageLeafTable  = 0x0D
+	pageInterTable = 0x05
+	leafHeaderSize = 8
+	interHeaderLen = 12
+)
+
+// 错误定义
+var (
+	ErrNotSQLite     = errors.New("不是 SQLite 数据库文件")
+	ErrCorrupt       = errors.New("SQLite 数据库文件已损坏")
+	ErrTableNotFound = errors.New("表不存在")
+	ErrSchemaTooBig  = errors.New("表定义过多，sqlite_master 超出第 1 页")
+)
+
+// Row 一行记录
+// Values 中的元素只能是 nil、int64、float64、string 或 []byte；
+// 声明为 INTEGER PRIMARY KEY 的列是 rowid 的别名，写入时对应位置应为 nil
+type Row struct {
+	RowID  int64
+	Values []interface{}
+}
+
+// ==================== varint ====================
+
+// putVarint 按 SQLite 的大端变长整数格式编码（1-9 字节）
+func putVarint(buf []byte, v uint64) int {
+	if v > 0x00ffffffffffffff {
+		// 9 字节：前 8 字节各 7 位，最后一个字节 8 位
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return 9
+	}
+
+	var tmp [9]byte
+	n := 0
+	for {
+		tmp[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	tmp[0] &= 0x7f
+	for i := 0; i < n; i++ {
+		buf[i] = tmp[n-1-i]
+	}
+	return n
+}
+
+func varintLen(v uint64) int {
+	var buf [9]byte
+	return putVarint(buf[:], v)
+}
+
+func appendVarint(dst []byte, v uint64) []byte {
+	var buf [9]byte
+	n := putVarint(buf[:], v)
+	return append(dst, buf[:n]...)
+}
+
+// readVarint 解码变长整数，返回值和占用的字节数（数据不足时返回 0 字节）
+func readVarint(buf []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(buf) {
+			return 0, 0
+		}
+		v = v<<7 | uint64(buf[i]&0x7f)
+		if buf[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(buf) < 9 {
+		return 0, 0
+	}
+	return v<<8 | uint64(buf[8]), 9
+}
+
+// ==================== 记录格式 ====================
+
+// encodeRecord 把一行的各列编码为 SQLite 记录格式（头部 + 数据）
+func encodeRecord(values []interface{}) ([]byte, error) {
+	types := make([]uint64, len(values))
+	var body []byte
+
+	for i, v := range values {
+		switch val := v.(type) {
+		case nil:
+			types[i] = 0
+		case int:
+			types[i], body = encodeInt(int64(val), body)
+		case int64:
+			types[i], body = encodeInt(val, body)
+		case bool:
+			if val {
+				types[i] = 9
+			} else {
+				types[i] = 8
+			}
+		case float64:
+			types[i] = 7
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(val))
+		case string:
+			types[i] = uint64(len(val))*2 + 13
+			body = append(body, val...)
+		case []byte:
+			types[i] = uint64(len(val))*2 + 12
+			body = append(body, val...)
+		default:
+			return nil, errors.New("不支持的列类型")
+		}
+	}
+
+	headerLen := 0
+	for _, t := range types {
+		headerLen += varintLen(t)
+	}
+	// 头部长度本身也计入头部
+	total := headerLen + varintLen(uint64(headerLen+1))
+	if varintLen(uint64(total)) != varintLen(uint64(headerLen+1)) {
+		total = headerLen + varintLen(uint64(total))
+	}
+
+	record := make([]byte, 0, total+len(body))
+	record = appendVarint(record, uint64(total))
+	for _, t := range types {
+		record = appendVarint(record, t)
+	}
+	return append(record, body...), nil
+}
+
+// encodeInt 选择能容纳该整数的最小序列类型
+func encodeInt(v int64, body []byte) (uint64, []byte) {
+	switch {
+	case v == 0:
+		return 8, body
+	case v == 1:
+		return 9, body
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1, append(body, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2, binary.BigEndian.AppendUint16(body, uint16(v))
+	case v >= -1<<23 && v < 1<<23:
+		return 3, append(body, byte(v>>16), byte(v>>8), byte(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4, binary.BigEndian.AppendUint32(body, uint32(v))
+	case v >= -1<<47 && v < 1<<47:
+		return 5, append(body, byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	default:
+		return 6, binary.BigEndian.AppendUint64(body, uint64(v))
+	}
+}
+
+// decodeRecord 解析记录格式，整数统一返回 int64
+func decodeRecord(record []byte) ([]interface{}, error) {
+	headerLen, n := readVarint(record)
+	if n == 0 || headerLen > uint64(len(record)) {
+		return nil, ErrCorrupt
+	}
+
+	var types []uint64
+	for pos := n; pos < int(headerLen); {
+		t, m := readVarint(record[pos:int(headerLen)])
+		if m == 0 {
+			return nil, ErrCorrupt
+		}
+		types = append(types, t)
+		pos += m
+	}
+
+	values := make([]interface{}, len(types))
+	body := record[headerLen:]
+	for i, t := range types {
+		size := serialSize(t)
+		if size > len(body) {
+			return nil, ErrCorrupt
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case t == 0:
+			values[i] = nil
+		case t >= 1 && t <= 6:
+			values[i] = decodeInt(data)
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(data))
+		case t == 8:
+			values[i] = int64(0)
+		case t == 9:
+			values[i] = int64(1)
+		case t >= 12 && t%2 == 0:
+			values[i] = append([]byte(nil), data...)
+		case t >= 13:
+			values[i] = string(data)
+		default:
+			return nil, ErrCorrupt
+		}
+	}
+	return values, nil
+}
+
+func serialSize(t uint64) int {
+	switch t {
+	case 0, 8, 9, 10, 11:
+		return 0
+	case 1:
+		return 1
+	case 2:
+		return 2
+	case 3:
+		return 3
+	case 4:
+		return 4
+	case 5:
+		return 6
+	case 6, 7:
+		return 8
+	}
+	if t%2 == 0 {
+		return int(t-12) / 2
+	}
+	return int(t-13) / 2
+}
+
+func decodeInt(data []byte) int64 {
+	var v int64
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range data {
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+// ==================== 溢出页计算 ====================
+
+// localPayload 表叶子页单元格中直接存储的负载字节数，其余部分放到溢出页
+func localPayload(payload int) int {
+	usable := PageSize
+	maxLocal := usable - 35
+	if payload <= maxLocal {
+		return payload
+	}
+	minLocal := (usable-12)*32/255 - 23
+	k := minLocal + (payload-minLocal)%(usable-4)
+	if k <= maxLocal {
+		return k
+	}
+	return minLocal
+}
@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sampleTables 覆盖各种值类型、大量记录（内部页）和超长记录（溢出页）的测试数据
+func sampleTables() []Table {
+	words := Table{
+		Name: "words",
+		SQL:  "CREATE TABLE words (id integer primary key, word text not null, freq integer, score real, audio blob, note text)",
+	}
+	for i := 1; i <= 20000; i++ {
+		var note interface{}
+		if i%3 == 0 {
+			note = fmt.Sprintf("note-%d", i)
+		}
+		words.Rows = append(words.Rows, Row{
+			RowID:  int64(i),
+			Values: []interface{}{nil, fmt.Sprintf("word%05d", i), int64(i * 37), float64(i) / 8, []byte{byte(i), byte(i >> 8)}, note},
+		})
+	}
+
+	blobs := Table{
+		Name: "blobs",
+		SQL:  "CREATE TABLE blobs (id integer primary key, body text, data blob)",
+	}
+	for i, size := range []int{0, 100, PageSize - 50, PageSize * 3, PageSize*20 + 7} {
+		data := make([]byte, size)
+		for k := range data {
+			data[k] = byte(k * (i + 1))
+		}
+		blobs.Rows = append(blobs.Rows, Row{
+			RowID:  int64(i + 1),
+			Values: []interface{}{nil, strings.Repeat("长文本", size/9+1), data},
+		})
+	}
+
+	numbers := Table{
+		Name: "numbers",
+		SQL:  "CREATE TABLE numbers (id integer primary key, n integer, f real)",
+	}
+	for i, n := range []int64{0, 1, -1, 127, -128, 32767, 1 << 23, -(1 << 40), 1<<62 + 5, -1 << 63} {
+		numbers.Rows = append(numbers.Rows, Row{
+			RowID:  int64(i + 1),
+			Values: []interface{}{nil, n, float64(n) * 0.5},
+		})
+	}
+	return []Table{words, blobs, numbers}
+}
+
+func TestBuildRoundTrip(t *testing.T) {
+	tables := sampleTables()
+	data, err := Build(tables)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(data)%PageSize != 0 {
+		t.Fatalf("文件大小 %d 不是页大小的整数倍", len(data))
+	}
+
+	db, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	schema, err := db.Tables()
+	if err != nil {
+		t.Fatalf("Tables: %v", err)
+	}
+	for _, table := range tables {
+		if schema[table.Name] != table.SQL {
+			t.Errorf("表 %s 的定义 = %q, want %q", table.Name, schema[table.Name], table.SQL)
+		}
+		rows, err := db.ReadTable(table.Name)
+		if err != nil {
+			t.Fatalf("ReadTable(%s): %v", table.Name, err)
+		}
+		if len(rows) != len(table.Rows) {
+			t.Fatalf("表 %s 读出 %d 行, want %d", table.Name, len(rows), len(table.Rows))
+		}
+		for i, want := range table.Rows {
+			if !sameRow(rows[i], want) {
+				t.Fatalf("表 %s 第 %d 行 = %v, want %v", table.Name, i, rows[i], want)
+			}
+		}
+	}
+
+	if _, err := db.ReadTable("missing"); err != ErrTableNotFound {
+		t.Errorf("ReadTable(missing) err = %v, want ErrTableNotFound", err)
+	}
+}
+
+// sameRow 比较两行记录，长度为 0 的 BLOB 读出时可能为 nil
+func sameRow(a, b Row) bool {
+	if a.RowID != b.RowID || len(a.Values) != len(b.Values) {
+		return false
+	}
+	for i := range a.Values {
+		x, xok := a.Values[i].([]byte)
+		y, yok := b.Values[i].([]byte)
+		if xok && yok {
+			if !bytes.Equal(x, y) {
+				return false
+			}
+		} else if !reflect.DeepEqual(a.Values[i], b.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBuildRejectsDuplicateRowID(t *testing.T) {
+	_, err := Build([]Table{{
+		Name: "t",
+		SQL:  "CREATE TABLE t (id integer primary key)",
+		Rows: []Row{{RowID: 1, Values: []interface{}{nil}}, {RowID: 1, Values: []interface{}{nil}}},
+	}})
+	if err == nil {
+		t.Fatal("重复的 rowid 应该报错")
+	}
+}
+
+func TestOpenRejectsInvalidData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空文件", nil},
+		{"不是 SQLite", bytes.Repeat([]byte("x"), PageSize)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.data); err == nil {
+				t.Fatal("应该报错")
+			}
+		})
+	}
+}
+
+// TestBuildWithSQLite3 用真正的 SQLite 校验生成的文件，没有安装 sqlite3 命令行时跳过
+func TestBuildWithSQLite3(t *testing.T) {
+	bin, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("未安装 sqlite3 命令行工具")
+	}
+	data, err := Build(sampleTables())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"PRAGMA integrity_check", "ok"},
+		{"SELECT name FROM sqlite_master ORDER BY name", "blobs\nnumbers\nwords"},
+		{"SELECT count(*), sum(freq), max(id) FROM words", "20000|7400370000|20000"},
+		{"SELECT word, freq, score, hex(audio), note FROM words WHERE id = 4098", "word04098|151626|512.25|0210|note-4098"},
+		{"SELECT count(*) FROM words WHERE note IS NULL", "13334"},
+		{"SELECT id, length(data), length(body) FROM blobs ORDER BY id", "1|0|3\n2|100|36\n3|4046|1350\n4|12288|4098\n5|81927|27312"},
+		{"SELECT n FROM numbers ORDER BY id", "0\n1\n-1\n127\n-128\n32767\n8388608\n-1099511627776\n4611686018427387909\n-9223372036854775808"},
+	}
+	for _, tt := range tests {
+		out, err := exec.Command(bin, "-readonly", path, tt.query).CombinedOutput()
+		if err != nil {
+			t.Fatalf("sqlite3 %q: %v\n%s", tt.query, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("sqlite3 %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
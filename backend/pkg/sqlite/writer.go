@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Table 要写入的表
+type Table struct {
+	Name string
+	SQL  string // CREATE TABLE 语句，原样写入 sqlite_master
+	Rows []Row
+}
+
+// cell 一个 B 树单元格（已编码）
+type cell struct {
+	key  int64
+	data []byte
+}
+
+// writer 按页号顺序追加页面
+type writer struct {
+	pages [][]byte // pages[0] 为第 1 页
+}
+
+func (w *writer) alloc() int {
+	w.pages = append(w.pages, make([]byte, PageSize))
+	return len(w.pages)
+}
+
+func (w *writer) page(no int) []byte {
+	return w.pages[no-1]
+}
+
+// Build 生成包含给定表的数据库文件
+func Build(tables []Table) ([]byte, error) {
+	w := &writer{}
+	w.alloc() // 第 1 页：文件头 + sqlite_master
+
+	master := make([]Row, 0, len(tables))
+	for i, t := range tables {
+		rows := make([]Row, len(t.Rows))
+		copy(rows, t.Rows)
+		sort.SliceStable(rows, func(a, b int) bool { return rows[a].RowID < rows[b].RowID })
+		for k := 1; k < len(rows); k++ {
+			if rows[k].RowID == rows[k-1].RowID {
+				return nil, fmt.Errorf("表 %s 的 rowid %d 重复", t.Name, rows[k].RowID)
+			}
+		}
+
+		root, err := w.buildTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		master = append(master, Row{
+			RowID:  int64(i + 1),
+			Values: []interface{}{"table", t.Name, t.Name, int64(root), t.SQL},
+		})
+	}
+
+	// sqlite_master 必须以第 1 页为根，这里要求它只占一页
+	cells, err := w.leafCells(master)
+	if err != nil {
+		return nil, err
+	}
+	if !fitsLeaf(cells, headerSize) {
+		return nil, ErrSchemaTooBig
+	}
+	writeLeaf(w.page(1), headerSize, cells)
+	writeFileHeader(w.page(1), len(w.pages))
+
+	out := make([]byte, 0, len(w.pages)*PageSize)
+	for _, p := range w.pages {
+		out = append(out, p...)
+	}
+	return out, nil
+}
+
+// buildTable 写入一张表的 B 树，返回根页号
+func (w *writer) buildTable(rows []Row) (int, error) {
+	cells, err := w.leafCells(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// 叶子层：尽量装满每一页
+	type node struct {
+		page   int
+		maxKey int64
+	}
+	var level []node
+	start := 0
+	for {
+		end := start
+		for end < len(cells) && fitsLeaf(cells[start:end+1], 0) {
+			end++
+		}
+		no := w.alloc()
+		writeLeaf(w.page(no), 0, cells[start:end])
+		maxKey := int64(0)
+		if end > start {
+			maxKey = cells[end-1].key
+		}
+		level = append(level, node{page: no, maxKey: maxKey})
+		start = end
+		if start >= len(cells) {
+			break
+		}
+	}
+
+	// 内部层：每个单元格 = 左孩子页号 + 左孩子的最大 rowid，最后一个孩子放在右指针。
+	// 按最长单元格（4 字节页号 + 9 字节 varint）估算每页容量，并把孩子平均分配到各页，
+	// 避免出现只有右指针、没有单元格的内部页
+	const maxChildren = (PageSize-interHeaderLen)/(4+9+2) + 1
+	for len(level) > 1 {
+		groups := (len(level) + maxChildren - 1) / maxChildren
+		next := make([]node, 0, groups)
+		for g, start := 0, 0; g < groups; g++ {
+			end := start + (len(level)-start)/(groups-g)
+			children := level[start:end]
+			inner := make([]cell, 0, len(children)-1)
+			for _, child := range children[:len(children)-1] {
+				inner = append(inner, cell{key: child.maxKey, data: interiorCell(child.page, child.maxKey)})
+			}
+			right := children[len(children)-1]
+			no := w.alloc()
+			writeInterior(w.page(no), inner, right.page)
+			next = append(next, node{page: no, maxKey: right.maxKey})
+			start = end
+		}
+		level = next
+	}
+	return level[0].page, nil
+}
+
+// leafCells 把每行编码为表叶子单元格，负载过大时写入溢出页
+func (w *writer) leafCells(rows []Row) ([]cell, error) {
+	cells := make([]cell, 0, len(rows))
+	for _, r := range rows {
+		payload, err := encodeRecord(r.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		local := localPayload(len(payload))
+		data := appendVarint(nil, uint64(len(payload)))
+		data = appendVarint(data, uint64(r.RowID))
+		data = append(data, payload[:local]...)
+		if local < len(payload) {
+			first := w.writeOverflow(payload[local:])
+			data = binary.BigEndian.AppendUint32(data, uint32(first))
+		}
+		cells = append(cells, cell{key: r.RowID, data: data})
+	}
+	return cells, nil
+}
+
+// writeOverflow 把剩余负载写入溢出页链表，返回第一页页号
+func (w *writer) writeOverflow(rest []byte) int {
+	first := 0
+	prev := 0
+	for len(rest) > 0 {
+		no := w.alloc()
+		if prev == 0 {
+			first = no
+		} else {
+			binary.BigEndian.PutUint32(w.page(prev), uint32(no))
+		}
+		n := copy(w.page(no)[4:], rest)
+		rest = rest[n:]
+		prev = no
+	}
+	return first
+}
+
+func interiorCell(child int, key int64) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(child))
+	return appendVarint(data, uint64(key))
+}
+
+func cellsSize(cells []cell) int {
+	size := 0
+	for _, c := range cells {
+		size += len(c.data) + 2 // 单元格 + 单元格指针
+	}
+	return size
+}
+
+func fitsLeaf(cells []cell, offset int) bool {
+	return offset+leafHeaderSize+cellsSize(cells) <= PageSize
+}
+
+// writeLeaf 写入表叶子页，offset 为页头在页内的偏移（第 1 页为 100）
+func writeLeaf(page []byte, offset int, cells []cell) {
+	page[offset] = pageLeafTable
+	writeCells(page, offset, leafHeaderSize, cells)
+}
+
+// writeInterior 写入表内部页
+func writeInterior(page []byte, cells []cell, right int) {
+	page[0] = pageInterTable
+	binary.BigEndian.PutUint32(page[8:], uint32(right))
+	writeCells(page, 0, interHeaderLen, cells)
+}
+
+// writeCells 单元格从页尾向前存放，单元格指针数组紧跟页头
+func writeCells(page []byte, offset, hdrLen int, cells []cell) {
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	content := PageSize
+	ptr := offset + hdrLen
+	for _, c := range cells {
+		content -= len(c.data)
+		copy(page[content:], c.data)
+		binary.BigEndian.PutUint16(page[ptr:], uint16(content))
+		ptr += 2
+	}
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+// writeFileHeader 写入 100 字节的数据库文件头
+func writeFileHeader(page []byte, pageCount int) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], PageSize)
+	page[18] = 1                                             // 写版本：legacy（非 WAL）
+	page[19] = 1                                             // 读版本
+	page[20] = 0                                             // 每页保留字节
+	page[21] = 64                                            // 最大内嵌负载比例
+	page[22] = 32                                            // 最小内嵌负载比例
+	page[23] = 32                                            // 叶子负载比例
+	binary.BigEndian.PutUint32(page[24:], 1)                 // 文件修改计数
+	binary.BigEndian.PutUint32(page[28:], uint32(pageCount)) // 页数
+	binary.BigEndian.PutUint32(page[40:], 1)                 // schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4)                 // schema 格式
+	binary.BigEndian.PutUint32(page[56:], 1)                 // 文本编码 UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1)                 // version-valid-for
+	binary.BigEndian.PutUint32(page[96:], 3040001)           // SQLITE_VERSION_NUMBER
+}
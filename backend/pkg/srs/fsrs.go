@@ -0,0 +1,228 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// FSRS 遗忘曲线常数（FSRS-4.5）
+// R(t, S) = (1 + FACTOR * t / S) ^ DECAY，t = S 时 R = 0.9
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// FSRS 评分等级
+const (
+	GradeAgain = 1 // 忘记
+	GradeHard  = 2 // 困难
+	GradeGood  = 3 // 良好
+	GradeEasy  = 4 // 简单
+)
+
+// DefaultFSRSWeights FSRS-4.5 默认参数（17 个），来自官方在大规模复习数据上的训练结果
+var DefaultFSRSWeights = []float64{
+	0.4872, 1.4003, 3.7145, 13.8206,
+	5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461,
+	2.1072, 0.0793, 0.3246, 1.587,
+	0.2272, 2.8755,
+}
+
+// FSRS 参数默认值
+const (
+	DefaultRequestRetention = 0.9   // 期望记忆保持率
+	MinRequestRetention     = 0.7   // 期望记忆保持率下限（含）
+	MaxRequestRetention     = 0.99  // 期望记忆保持率上限（含）
+	DefaultMaximumInterval  = 36500 // 最大间隔（天）
+	FSRSWeightCount         = 17
+)
+
+// FSRSParams FSRS 参数（按用户保存）
+type FSRSParams struct {
+	Weights          []float64 `json:"weights"`
+	RequestRetention float64   `json:"request_retention"`
+	MaximumInterval  int       `json:"maximum_interval"`
+}
+
+// DefaultFSRSParams 默认 FSRS 参数
+func DefaultFSRSParams() FSRSParams {
+	weights := make([]float64, len(DefaultFSRSWeights))
+	copy(weights, DefaultFSRSWeights)
+	return FSRSParams{
+		Weights:          weights,
+		RequestRetention: DefaultRequestRetention,
+		MaximumInterval:  DefaultMaximumInterval,
+	}
+}
+
+// Normalize 补全缺失或非法的参数
+func (p FSRSParams) Normalize() FSRSParams {
+	if len(p.Weights) != FSRSWeightCount {
+		p.Weights = DefaultFSRSParams().Weights
+	}
+	if p.RequestRetention < MinRequestRetention || p.RequestRetention > MaxRequestRetention {
+		p.RequestRetention = DefaultRequestRetention
+	}
+	if p.MaximumInterval <= 0 {
+		p.MaximumInterval = DefaultMaximumInterval
+	}
+	return p
+}
+
+// FSRS Free Spaced Repetition Scheduler（FSRS-4.5）
+// 用稳定性（S）、难度（D）和可提取性（R）三个变量描述记忆状态：
+//   - S：R 从 100% 下降到 90% 所需的天数
+//   - D：1-10，越大越难以提升稳定性
+//   - R：距上次复习 t 天后回忆成功的概率
+type FSRS struct {
+	params FSRSParams
+}
+
+// NewFSRS 创建 FSRS 调度器
+func NewFSRS(params FSRSParams) *FSRS {
+	return &FSRS{params: params.Normalize()}
+}
+
+func (f *FSRS) Name() string { return AlgorithmFSRS }
+
+// Params 当前使用的参数
+func (f *FSRS) Params() FSRSParams {
+	return f.params
+}
+
+// QualityToGrade 把 0-5 评分映射为 FSRS 的四级评分
+// 0-2 忘记，3 困难，4 良好，5 简单
+func QualityToGrade(quality int) int {
+	switch {
+	case quality < 3:
+		return GradeAgain
+	case quality == 3:
+		return GradeHard
+	case quality == 4:
+		return GradeGood
+	default:
+		return GradeEasy
+	}
+}
+
+// Schedule 应用 FSRS 算法
+func (f *FSRS) Schedule(card *Card, quality int, now time.Time) {
+	w := f.params.Weights
+	grade := QualityToGrade(quality)
+
+	if card.Stability <= 0 {
+		// 首次学习
+		card.Stability = w[grade-1]
+		card.Difficulty = f.initDifficulty(grade)
+	} else {
+		r := f.Retrievability(*card, now)
+		card.Difficulty = f.nextDifficulty(card.Difficulty, grade)
+		if grade == GradeAgain {
+			card.Stability = math.Min(f.forgetStability(card.Difficulty, card.Stability, r), card.Stability)
+		} else {
+			card.Stability = f.recallStability(card.Difficulty, card.Stability, r, grade)
+		}
+	}
+
+	if grade == GradeAgain {
+		if card.Repetitions > 0 {
+			card.Lapses++
+		}
+		card.Repetitions = 0
+		card.IntervalDays = 0
+		card.MasteryLevel = maxInt(0, card.MasteryLevel-1)
+	} else {
+		card.Repetitions++
+		card.IntervalDays = f.nextInterval(card.Stability)
+		card.MasteryLevel = masteryByStability(card.Stability)
+	}
+
+	card.LastReviewAt = &now
+	card.NextReviewAt = nextReviewAt(now, card.IntervalDays)
+}
+
+// Retrievability 卡片在 now 时刻的可提取性（回忆成功概率）
+// 尚未按 FSRS 学习过的卡片返回 0
+func (f *FSRS) Retrievability(card Card, now time.Time) float64 {
+	if card.Stability <= 0 || card.LastReviewAt == nil {
+		return 0
+	}
+	elapsed := now.Sub(*card.LastReviewAt).Hours() / 24
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return forgettingCurve(elapsed, card.Stability)
+}
+
+func forgettingCurve(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+// nextInterval 在期望保持率下的复习间隔：R(I, S) = requestRetention
+func (f *FSRS) nextInterval(stability float64) int {
+	interval := stability / fsrsFactor * (math.Pow(f.params.RequestRetention, 1/fsrsDecay) - 1)
+	days := int(math.Round(interval))
+	if days < 1 {
+		days = 1
+	}
+	if days > f.params.MaximumInterval {
+		days = f.params.MaximumInterval
+	}
+	return days
+}
+
+// initDifficulty D0(G) = w4 - (G - 3) * w5
+func (f *FSRS) initDifficulty(grade int) float64 {
+	w := f.params.Weights
+	return clampDifficulty(w[4] - float64(grade-3)*w[5])
+}
+
+// nextDifficulty D' = w7 * D0(3) + (1 - w7) * (D - w6 * (G - 3))
+// 向“良好”的初始难度做均值回归，避免难度一路升高到 10 后无法回落
+func (f *FSRS) nextDifficulty(d float64, grade int) float64 {
+	w := f.params.Weights
+	next := d - w[6]*float64(grade-3)
+	return clampDifficulty(w[7]*f.initDifficulty(GradeGood) + (1-w[7])*next)
+}
+
+// recallStability 回忆成功后的新稳定性
+// S' = S * (e^w8 * (11 - D) * S^-w9 * (e^(w10 * (1 - R)) - 1) * hardPenalty * easyBonus + 1)
+func (f *FSRS) recallStability(d, s, r float64, grade int) float64 {
+	w := f.params.Weights
+	hardPenalty, easyBonus := 1.0, 1.0
+	if grade == GradeHard {
+		hardPenalty = w[15]
+	}
+	if grade == GradeEasy {
+		easyBonus = w[16]
+	}
+	return s * (math.Exp(w[8])*(11-d)*math.Pow(s, -w[9])*(math.Exp(w[10]*(1-r))-1)*hardPenalty*easyBonus + 1)
+}
+
+// forgetStability 遗忘后的新稳定性
+// S' = w11 * D^-w12 * ((S + 1)^w13 - 1) * e^(w14 * (1 - R))
+func (f *FSRS) forgetStability(d, s, r float64) float64 {
+	w := f.params.Weights
+	return w[11] * math.Pow(d, -w[12]) * (math.Pow(s+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
+
+// masteryByStability 根据稳定性换算掌握等级（与 SM-2 的 0-5 等级对齐，>=4 视为已掌握）
+func masteryByStability(stability float64) int {
+	switch {
+	case stability < 2:
+		return 1
+	case stability < 7:
+		return 2
+	case stability < 21:
+		return 3
+	case stability < 60:
+		return 4
+	default:
+		return 5
+	}
+}
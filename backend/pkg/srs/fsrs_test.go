@@ -0,0 +1,143 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFSRSParamsNormalizeRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention float64
+		want      float64
+	}{
+		{"未设置", 0, DefaultRequestRetention},
+		{"低于下限", 0.69, DefaultRequestRetention},
+		{"等于下限", MinRequestRetention, MinRequestRetention},
+		{"区间内", 0.85, 0.85},
+		{"等于上限", MaxRequestRetention, MaxRequestRetention},
+		{"高于上限", 0.995, DefaultRequestRetention},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := FSRSParams{RequestRetention: tt.retention}.Normalize()
+			if p.RequestRetention != tt.want {
+				t.Errorf("RequestRetention = %v, want %v", p.RequestRetention, tt.want)
+			}
+			if len(p.Weights) != FSRSWeightCount || p.MaximumInterval != DefaultMaximumInterval {
+				t.Errorf("缺失的参数没有补全: %+v", p)
+			}
+		})
+	}
+}
+
+func TestFSRSFirstReview(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	w := DefaultFSRSWeights
+
+	// 首次学习：S0 = w[G-1]，D0 = w4 - (G-3)·w5；保持率 0.9 时间隔等于稳定性
+	tests := []struct {
+		name       string
+		quality    int
+		stability  float64
+		difficulty float64
+		interval   int
+		reps       int
+	}{
+		{"忘记", 1, w[0], w[4] + 2*w[5], 0, 0},
+		{"困难", 3, w[1], w[4] + w[5], 1, 1},
+		{"良好", 4, w[2], w[4], 4, 1},
+		{"简单", 5, w[3], w[4] - w[5], 14, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := NewCard()
+			NewFSRS(DefaultFSRSParams()).Schedule(&card, tt.quality, now)
+			if !almostEqual(card.Stability, tt.stability) || !almostEqual(card.Difficulty, tt.difficulty) {
+				t.Errorf("S/D = %.4f/%.4f, want %.4f/%.4f", card.Stability, card.Difficulty, tt.stability, tt.difficulty)
+			}
+			if card.IntervalDays != tt.interval || card.Repetitions != tt.reps || card.Lapses != 0 {
+				t.Errorf("interval/reps/lapses = %d/%d/%d, want %d/%d/0", card.IntervalDays, card.Repetitions, card.Lapses, tt.interval, tt.reps)
+			}
+		})
+	}
+}
+
+func TestFSRSReviewSequence(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	f := NewFSRS(DefaultFSRSParams())
+	card := NewCard()
+	f.Schedule(&card, 4, now)
+
+	// 到期复习且答对：稳定性增长，“良好”不改变初始难度
+	prev := card
+	at := *card.NextReviewAt
+	if r := f.Retrievability(card, at); math.Abs(r-0.9) > 0.01 {
+		t.Errorf("到期时 R = %.4f, want ≈0.9", r)
+	}
+	f.Schedule(&card, 4, at)
+	if card.Stability <= prev.Stability || !almostEqual(card.Difficulty, prev.Difficulty) {
+		t.Errorf("答对后 S/D = %.4f/%.4f, 之前 %.4f/%.4f", card.Stability, card.Difficulty, prev.Stability, prev.Difficulty)
+	}
+	if card.IntervalDays <= prev.IntervalDays || card.Repetitions != 2 {
+		t.Errorf("答对后 interval/reps = %d/%d", card.IntervalDays, card.Repetitions)
+	}
+
+	// 遗忘：记一次遗忘，稳定性下降，难度上升，立即重新学习
+	prev = card
+	at = *card.NextReviewAt
+	f.Schedule(&card, 1, at)
+	if card.Lapses != 1 || card.Repetitions != 0 || card.IntervalDays != 0 {
+		t.Errorf("遗忘后 lapses/reps/interval = %d/%d/%d, want 1/0/0", card.Lapses, card.Repetitions, card.IntervalDays)
+	}
+	if card.Stability >= prev.Stability || card.Difficulty <= prev.Difficulty {
+		t.Errorf("遗忘后 S/D = %.4f/%.4f, 之前 %.4f/%.4f", card.Stability, card.Difficulty, prev.Stability, prev.Difficulty)
+	}
+	if want := at.Add(time.Minute); !card.NextReviewAt.Equal(want) {
+		t.Errorf("遗忘后 NextReviewAt = %v, want %v", card.NextReviewAt, want)
+	}
+}
+
+func TestFSRSRetentionAndBounds(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	interval := func(params FSRSParams, stability float64) int {
+		card := Card{Stability: stability, Difficulty: 5, Repetitions: 3, LastReviewAt: &now}
+		NewFSRS(params).Schedule(&card, 4, now.AddDate(0, 0, int(stability)))
+		return card.IntervalDays
+	}
+
+	// 期望保持率越高间隔越短
+	low, high := DefaultFSRSParams(), DefaultFSRSParams()
+	low.RequestRetention, high.RequestRetention = 0.8, 0.95
+	if a, b := interval(low, 30), interval(high, 30); a <= b {
+		t.Errorf("保持率 0.8 间隔 %d 应大于 0.95 的 %d", a, b)
+	}
+
+	// 不超过最大间隔
+	capped := DefaultFSRSParams()
+	capped.MaximumInterval = 100
+	if got := interval(capped, 5000); got != 100 {
+		t.Errorf("interval = %d, want 100", got)
+	}
+
+	// 难度限制在 1-10
+	card := NewCard()
+	f := NewFSRS(DefaultFSRSParams())
+	for i := 0; i < 30; i++ {
+		f.Schedule(&card, 0, now.AddDate(0, 0, i))
+	}
+	if card.Difficulty != 10 {
+		t.Errorf("Difficulty = %v, want 10", card.Difficulty)
+	}
+	for i := 0; i < 60; i++ {
+		f.Schedule(&card, 5, now.AddDate(0, 0, 30+i))
+	}
+	if card.Difficulty < 1 || card.MasteryLevel != 5 {
+		t.Errorf("Difficulty/MasteryLevel = %v/%d", card.Difficulty, card.MasteryLevel)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
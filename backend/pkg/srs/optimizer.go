@@ -0,0 +1,310 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// ReviewLog 用于参数拟合的一条复习记录
+type ReviewLog struct {
+	CardID         uint
+	Quality        int
+	At             time.Time
+	ResponseTimeMs int // 0 表示未记录
+}
+
+// OptimizeOptions 参数拟合选项
+type OptimizeOptions struct {
+	MinReviews      int           // 最少可用样本数，不足时不拟合
+	Iterations      int           // 迭代次数
+	LearningRate    float64       // 学习率（在归一化参数空间上）
+	Regularization  float64       // 向初始参数回归的正则强度，样本少时防止过拟合
+	MaxResponseTime time.Duration // 超过该作答时间的记录视为走神，不参与损失计算（仍参与状态重放）
+}
+
+// DefaultOptimizeOptions 默认拟合选项
+func DefaultOptimizeOptions() OptimizeOptions {
+	return OptimizeOptions{
+		MinReviews:      300,
+		Iterations:      200,
+		LearningRate:    0.02,
+		Regularization:  0.5,
+		MaxResponseTime: 5 * time.Minute,
+	}
+}
+
+// ErrNotEnoughReviews 复习记录不足，无法拟合
+var ErrNotEnoughReviews = errors.New("复习记录不足，无法拟合参数")
+
+// CalibrationBin 按预测保持率分桶的校准结果
+type CalibrationBin struct {
+	Predicted float64 `json:"predicted"` // 桶内平均预测保持率
+	Actual    float64 `json:"actual"`    // 桶内实际回忆成功率
+	Count     int     `json:"count"`
+}
+
+// Evaluation 参数在复习历史上的表现
+type Evaluation struct {
+	Samples            int              `json:"samples"`             // 参与评估的样本数（间隔 >= 1 天的复习）
+	LogLoss            float64          `json:"log_loss"`            // 对数损失，越小越好
+	RMSE               float64          `json:"rmse"`                // 分桶校准误差，越小越好
+	PredictedRetention float64          `json:"predicted_retention"` // 平均预测保持率
+	ActualRetention    float64          `json:"actual_retention"`    // 实际回忆成功率
+	Bins               []CalibrationBin `json:"bins"`
+}
+
+// OptimizeResult 参数拟合结果
+type OptimizeResult struct {
+	Weights    []float64  `json:"weights"`
+	Before     Evaluation `json:"before"` // 初始参数的表现
+	After      Evaluation `json:"after"`  // 拟合后参数的表现
+	Iterations int        `json:"iterations"`
+	Improved   bool       `json:"improved"` // 拟合后对数损失是否下降
+}
+
+// weightBounds FSRS-4.5 各参数的取值范围
+var weightBounds = [FSRSWeightCount][2]float64{
+	{0.01, 100}, {0.01, 100}, {0.01, 100}, {0.01, 100},
+	{1, 10}, {0.01, 5}, {0.01, 5}, {0, 0.75},
+	{0, 4.5}, {0, 0.8}, {0.01, 3.5},
+	{0.1, 5}, {0.01, 0.25}, {0.01, 0.9}, {0.01, 4},
+	{0, 1}, {1, 6},
+}
+
+// sample 一次可用于评估的复习：复习前的预测保持率和实际结果
+type sample struct {
+	predicted float64
+	recalled  bool
+}
+
+// Optimize 在复习历史上拟合 FSRS 参数
+// 对每张卡片按时间重放复习记录，用上一次复习后的稳定性预测本次回忆成功的概率，
+// 以对数损失为目标，在归一化参数空间上用 Adam + 中心差分梯度做有界优化。
+// 同一天内的重复复习（间隔 < 1 天）只参与状态重放，不计入损失。
+// 每次迭代前检查 ctx，取消或超时时返回 ctx.Err()。
+func Optimize(ctx context.Context, logs []ReviewLog, initial []float64, opts OptimizeOptions) (*OptimizeResult, error) {
+	if len(initial) != FSRSWeightCount {
+		initial = DefaultFSRSParams().Weights
+	}
+	sequences := groupLogs(logs)
+
+	before := evaluate(sequences, initial, opts.MaxResponseTime)
+	if before.Samples < opts.MinReviews {
+		return nil, ErrNotEnoughReviews
+	}
+
+	// 归一化到 [0, 1]，使各参数的步长可比
+	x := make([]float64, FSRSWeightCount)
+	x0 := make([]float64, FSRSWeightCount)
+	for i, w := range initial {
+		x[i] = toUnit(i, w)
+		x0[i] = x[i]
+	}
+
+	objective := func(x []float64) float64 {
+		loss := logLoss(sequences, fromUnitAll(x), opts.MaxResponseTime)
+		reg := 0.0
+		for i := range x {
+			d := x[i] - x0[i]
+			reg += d * d
+		}
+		return loss + opts.Regularization*reg/float64(before.Samples)
+	}
+
+	const (
+		beta1 = 0.9
+		beta2 = 0.999
+		eps   = 1e-8
+		h     = 1e-4
+	)
+	m := make([]float64, FSRSWeightCount)
+	v := make([]float64, FSRSWeightCount)
+	grad := make([]float64, FSRSWeightCount)
+	best := append([]float64(nil), x...)
+	bestLoss := objective(x)
+
+	for iter := 1; iter <= opts.Iterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := range x {
+			orig := x[i]
+			x[i] = math.Min(orig+h, 1)
+			up := objective(x)
+			hi := x[i]
+			x[i] = math.Max(orig-h, 0)
+			down := objective(x)
+			lo := x[i]
+			x[i] = orig
+			grad[i] = (up - down) / (hi - lo)
+		}
+
+		for i := range x {
+			m[i] = beta1*m[i] + (1-beta1)*grad[i]
+			v[i] = beta2*v[i] + (1-beta2)*grad[i]*grad[i]
+			mHat := m[i] / (1 - math.Pow(beta1, float64(iter)))
+			vHat := v[i] / (1 - math.Pow(beta2, float64(iter)))
+			x[i] = math.Min(math.Max(x[i]-opts.LearningRate*mHat/(math.Sqrt(vHat)+eps), 0), 1)
+		}
+
+		if loss := objective(x); loss < bestLoss {
+			bestLoss = loss
+			copy(best, x)
+		}
+	}
+
+	weights := fromUnitAll(best)
+	for i := range weights {
+		weights[i] = math.Round(weights[i]*10000) / 10000
+	}
+	after := evaluate(sequences, weights, opts.MaxResponseTime)
+
+	return &OptimizeResult{
+		Weights:    weights,
+		Before:     before,
+		After:      after,
+		Iterations: opts.Iterations,
+		Improved:   after.LogLoss < before.LogLoss,
+	}, nil
+}
+
+// Evaluate 评估一组参数在复习历史上的预测效果
+func Evaluate(logs []ReviewLog, weights []float64, maxResponseTime time.Duration) Evaluation {
+	if len(weights) != FSRSWeightCount {
+		weights = DefaultFSRSParams().Weights
+	}
+	return evaluate(groupLogs(logs), weights, maxResponseTime)
+}
+
+func evaluate(sequences [][]ReviewLog, weights []float64, maxResponseTime time.Duration) Evaluation {
+	samples := collectSamples(sequences, weights, maxResponseTime)
+	ev := Evaluation{Samples: len(samples)}
+	if len(samples) == 0 {
+		return ev
+	}
+
+	const binCount = 10
+	bins := make([]CalibrationBin, binCount)
+	var loss, predicted, actual float64
+	for _, s := range samples {
+		y := 0.0
+		if s.recalled {
+			y = 1
+		}
+		loss += crossEntropy(s.predicted, y)
+		predicted += s.predicted
+		actual += y
+
+		b := int(s.predicted * binCount)
+		if b >= binCount {
+			b = binCount - 1
+		}
+		bins[b].Predicted += s.predicted
+		bins[b].Actual += y
+		bins[b].Count++
+	}
+
+	n := float64(len(samples))
+	ev.LogLoss = round4(loss / n)
+	ev.PredictedRetention = round4(predicted / n)
+	ev.ActualRetention = round4(actual / n)
+
+	var sq float64
+	for _, b := range bins {
+		if b.Count == 0 {
+			continue
+		}
+		p, a := b.Predicted/float64(b.Count), b.Actual/float64(b.Count)
+		sq += (p - a) * (p - a) * float64(b.Count)
+		ev.Bins = append(ev.Bins, CalibrationBin{Predicted: round4(p), Actual: round4(a), Count: b.Count})
+	}
+	ev.RMSE = round4(math.Sqrt(sq / n))
+	return ev
+}
+
+func logLoss(sequences [][]ReviewLog, weights []float64, maxResponseTime time.Duration) float64 {
+	samples := collectSamples(sequences, weights, maxResponseTime)
+	if len(samples) == 0 {
+		return 0
+	}
+	var loss float64
+	for _, s := range samples {
+		y := 0.0
+		if s.recalled {
+			y = 1
+		}
+		loss += crossEntropy(s.predicted, y)
+	}
+	return loss / float64(len(samples))
+}
+
+// collectSamples 用给定参数重放每张卡片，收集每次复习前的预测保持率
+func collectSamples(sequences [][]ReviewLog, weights []float64, maxResponseTime time.Duration) []sample {
+	f := &FSRS{params: FSRSParams{Weights: weights, RequestRetention: DefaultRequestRetention, MaximumInterval: DefaultMaximumInterval}}
+	var samples []sample
+	for _, seq := range sequences {
+		card := NewCard()
+		for _, l := range seq {
+			if card.Stability > 0 && card.LastReviewAt != nil {
+				elapsed := l.At.Sub(*card.LastReviewAt).Hours() / 24
+				distracted := maxResponseTime > 0 && l.ResponseTimeMs > 0 &&
+					time.Duration(l.ResponseTimeMs)*time.Millisecond > maxResponseTime
+				if elapsed >= 1 && !distracted {
+					samples = append(samples, sample{
+						predicted: forgettingCurve(elapsed, card.Stability),
+						recalled:  IsCorrect(l.Quality),
+					})
+				}
+			}
+			f.Schedule(&card, l.Quality, l.At)
+		}
+	}
+	return samples
+}
+
+// groupLogs 按卡片分组并按时间排序（卡片顺序固定，保证结果可复现）
+func groupLogs(logs []ReviewLog) [][]ReviewLog {
+	byCard := make(map[uint][]ReviewLog)
+	var ids []uint
+	for _, l := range logs {
+		if _, ok := byCard[l.CardID]; !ok {
+			ids = append(ids, l.CardID)
+		}
+		byCard[l.CardID] = append(byCard[l.CardID], l)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	sequences := make([][]ReviewLog, 0, len(ids))
+	for _, id := range ids {
+		seq := byCard[id]
+		sort.SliceStable(seq, func(i, j int) bool { return seq[i].At.Before(seq[j].At) })
+		sequences = append(sequences, seq)
+	}
+	return sequences
+}
+
+func crossEntropy(p, y float64) float64 {
+	p = math.Min(math.Max(p, 1e-6), 1-1e-6)
+	return -(y*math.Log(p) + (1-y)*math.Log(1-p))
+}
+
+func toUnit(i int, w float64) float64 {
+	lo, hi := weightBounds[i][0], weightBounds[i][1]
+	return math.Min(math.Max((w-lo)/(hi-lo), 0), 1)
+}
+
+func fromUnitAll(x []float64) []float64 {
+	weights := make([]float64, len(x))
+	for i, v := range x {
+		lo, hi := weightBounds[i][0], weightBounds[i][1]
+		weights[i] = lo + v*(hi-lo)
+	}
+	return weights
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
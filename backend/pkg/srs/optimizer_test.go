@@ -0,0 +1,164 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// syntheticLogs 模拟一个记忆比默认参数假设的更好的学习者：
+// 按 truth 参数计算真实的稳定性，按默认参数安排复习间隔（再加上随机的提前或推迟），
+// 按真实的遗忘曲线随机决定是否回忆成功。随机数种子固定，结果可复现。
+func syntheticLogs(cards int, truth []float64) []ReviewLog {
+	rng := rand.New(rand.NewSource(32))
+	learner := NewFSRS(FSRSParams{Weights: truth})
+	scheduler := NewFSRS(DefaultFSRSParams())
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	var logs []ReviewLog
+	for id := 1; id <= cards; id++ {
+		at := start.Add(time.Duration(rng.Intn(30*24)) * time.Hour)
+		memory, planned := NewCard(), NewCard()
+		quality := 4
+		for n := 0; n < 8; n++ {
+			if n > 0 {
+				elapsed := at.Sub(*memory.LastReviewAt).Hours() / 24
+				quality = 1
+				if rng.Float64() < forgettingCurve(elapsed, memory.Stability) {
+					quality = 3 + rng.Intn(3)
+				}
+			}
+			logs = append(logs, ReviewLog{CardID: uint(id), Quality: quality, At: at, ResponseTimeMs: 2000 + rng.Intn(8000)})
+			learner.Schedule(&memory, quality, at)
+			scheduler.Schedule(&planned, quality, at)
+
+			days := planned.IntervalDays
+			if days == 0 {
+				days = 1
+			}
+			jitter := 0.5 + rng.Float64()*1.5
+			at = at.Add(time.Duration(float64(days)*jitter*24) * time.Hour)
+		}
+	}
+	return logs
+}
+
+func truthWeights() []float64 {
+	truth := DefaultFSRSParams().Weights
+	for i := 0; i < 4; i++ {
+		truth[i] *= 3 // 初始稳定性是默认值的 3 倍
+	}
+	truth[8] = 2.2 // 复习后稳定性增长更快
+	return truth
+}
+
+func TestOptimizeReducesLogLoss(t *testing.T) {
+	logs := syntheticLogs(100, truthWeights())
+	opts := DefaultOptimizeOptions()
+	opts.Iterations = 40
+
+	result, err := Optimize(context.Background(), logs, nil, opts)
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if result.Before.Samples < opts.MinReviews || result.After.Samples != result.Before.Samples {
+		t.Fatalf("样本数 before/after = %d/%d", result.Before.Samples, result.After.Samples)
+	}
+	if !result.Improved || result.After.LogLoss >= result.Before.LogLoss {
+		t.Errorf("对数损失 %.4f -> %.4f，应该下降", result.Before.LogLoss, result.After.LogLoss)
+	}
+	// 默认参数低估了记忆：拟合后预测保持率更接近实际
+	before := math.Abs(result.Before.PredictedRetention - result.Before.ActualRetention)
+	after := math.Abs(result.After.PredictedRetention - result.After.ActualRetention)
+	if after >= before {
+		t.Errorf("预测与实际保持率的差距 %.4f -> %.4f，应该缩小", before, after)
+	}
+	if result.Weights[2] <= DefaultFSRSWeights[2] {
+		t.Errorf("“良好”的初始稳定性 = %.4f，应大于默认值 %.4f", result.Weights[2], DefaultFSRSWeights[2])
+	}
+
+	if len(result.Weights) != FSRSWeightCount {
+		t.Fatalf("参数个数 = %d, want %d", len(result.Weights), FSRSWeightCount)
+	}
+	for i, w := range result.Weights {
+		if lo, hi := weightBounds[i][0], weightBounds[i][1]; w < lo || w > hi {
+			t.Errorf("w[%d] = %v 超出范围 [%v, %v]", i, w, lo, hi)
+		}
+	}
+
+	// 相同输入结果相同
+	again, err := Optimize(context.Background(), logs, nil, opts)
+	if err != nil || !reflect.DeepEqual(again.Weights, result.Weights) {
+		t.Errorf("两次拟合结果不同: %v / %v", again.Weights, result.Weights)
+	}
+}
+
+func TestOptimizeKeepsWeightsInBounds(t *testing.T) {
+	// 初始参数超出范围、学习率很大时，结果仍限制在范围内
+	initial := make([]float64, FSRSWeightCount)
+	for i := range initial {
+		initial[i] = weightBounds[i][1] * 2
+	}
+	opts := DefaultOptimizeOptions()
+	opts.Iterations = 10
+	opts.LearningRate = 5
+
+	result, err := Optimize(context.Background(), syntheticLogs(60, truthWeights()), initial, opts)
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	for i, w := range result.Weights {
+		if lo, hi := weightBounds[i][0], weightBounds[i][1]; w < lo || w > hi {
+			t.Errorf("w[%d] = %v 超出范围 [%v, %v]", i, w, lo, hi)
+		}
+	}
+}
+
+func TestOptimizeErrors(t *testing.T) {
+	logs := syntheticLogs(10, truthWeights())
+	if _, err := Optimize(context.Background(), logs, nil, DefaultOptimizeOptions()); !errors.Is(err, ErrNotEnoughReviews) {
+		t.Errorf("样本不足 err = %v, want ErrNotEnoughReviews", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts := DefaultOptimizeOptions()
+	opts.MinReviews = 1
+	if _, err := Optimize(ctx, logs, nil, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后 err = %v, want context.Canceled", err)
+	}
+}
+
+func TestEvaluateSamples(t *testing.T) {
+	day := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	logs := []ReviewLog{
+		{CardID: 1, Quality: 4, At: day},
+		{CardID: 1, Quality: 4, At: day.Add(10 * time.Minute)},                    // 同一天内，只重放
+		{CardID: 1, Quality: 1, At: day.AddDate(0, 0, 3)},                         // 样本
+		{CardID: 1, Quality: 4, At: day.AddDate(0, 0, 9), ResponseTimeMs: 600000}, // 走神，只重放
+		{CardID: 1, Quality: 5, At: day.AddDate(0, 0, 20)},                        // 样本
+		{CardID: 2, Quality: 4, At: day.AddDate(0, 0, 5)},                         // 乱序输入
+		{CardID: 2, Quality: 4, At: day},
+	}
+	ev := Evaluate(logs, nil, 5*time.Minute)
+	if ev.Samples != 3 {
+		t.Fatalf("Samples = %d, want 3", ev.Samples)
+	}
+	if ev.ActualRetention != 0.6667 {
+		t.Errorf("ActualRetention = %v, want 0.6667", ev.ActualRetention)
+	}
+	total := 0
+	for _, b := range ev.Bins {
+		total += b.Count
+	}
+	if total != ev.Samples || ev.LogLoss <= 0 {
+		t.Errorf("Bins = %+v, LogLoss = %v", ev.Bins, ev.LogLoss)
+	}
+	if empty := Evaluate(nil, nil, 0); empty.Samples != 0 || empty.LogLoss != 0 {
+		t.Errorf("Evaluate(nil) = %+v", empty)
+	}
+}
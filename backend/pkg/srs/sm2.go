@@ -0,0 +1,74 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// SM-2 参数
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// SM2 SuperMemo-2 间隔重复算法
+// quality: 0-5 的评分
+// 0 - 完全忘记
+// 1 - 错误，看到答案后想起来
+// 2 - 错误，答案感觉熟悉
+// 3 - 正确，很费力
+// 4 - 正确，有些犹豫
+// 5 - 正确，非常轻松
+type SM2 struct{}
+
+func (SM2) Name() string { return AlgorithmSM2 }
+
+// Schedule 应用 SM-2 算法
+func (SM2) Schedule(card *Card, quality int, now time.Time) {
+	if card.EaseFactor == 0 {
+		card.EaseFactor = DefaultEaseFactor
+	}
+
+	// 更新易度因子 (EF)
+	// EF' = EF + (0.1 - (5 - q) * (0.08 + (5 - q) * 0.02))
+	ef := card.EaseFactor + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
+	if ef < MinEaseFactor {
+		ef = MinEaseFactor // 最小值
+	}
+	card.EaseFactor = math.Round(ef*100) / 100
+
+	if quality < 3 {
+		// 回答错误，重置
+		if card.Repetitions > 0 {
+			card.Lapses++
+		}
+		card.Repetitions = 0
+		card.IntervalDays = 0
+		card.MasteryLevel = maxInt(0, card.MasteryLevel-1) // 降级
+	} else {
+		// 回答正确
+		card.Repetitions++
+
+		// 计算新的间隔
+		switch card.Repetitions {
+		case 1:
+			card.IntervalDays = 1
+		case 2:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+
+		// 更新掌握等级
+		if card.Repetitions >= 5 && quality >= 4 {
+			card.MasteryLevel = 5
+		} else if card.Repetitions >= 3 {
+			card.MasteryLevel = minInt(4, card.MasteryLevel+1)
+		} else if card.Repetitions >= 1 {
+			card.MasteryLevel = minInt(2, card.MasteryLevel+1)
+		}
+	}
+
+	card.LastReviewAt = &now
+	card.NextReviewAt = nextReviewAt(now, card.IntervalDays)
+}
@@ -0,0 +1,133 @@
+package srs
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// legacySM2 替换前生词本使用的 applySM2Algorithm（原样保留作对照，只改为操作 Card）
+func legacySM2(card *Card, quality int, now time.Time) {
+	ef := card.EaseFactor + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
+	if ef < 1.3 {
+		ef = 1.3
+	}
+	card.EaseFactor = math.Round(ef*100) / 100
+
+	if quality < 3 {
+		card.Repetitions = 0
+		card.IntervalDays = 0
+		card.MasteryLevel = maxInt(0, card.MasteryLevel-1)
+	} else {
+		card.Repetitions++
+		switch card.Repetitions {
+		case 1:
+			card.IntervalDays = 1
+		case 2:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+		if card.Repetitions >= 5 && quality >= 4 {
+			card.MasteryLevel = 5
+		} else if card.Repetitions >= 3 {
+			card.MasteryLevel = minInt(4, card.MasteryLevel+1)
+		} else if card.Repetitions >= 1 {
+			card.MasteryLevel = minInt(2, card.MasteryLevel+1)
+		}
+	}
+
+	if card.IntervalDays == 0 {
+		next := now.Add(time.Minute)
+		card.NextReviewAt = &next
+	} else {
+		next := now.AddDate(0, 0, card.IntervalDays)
+		card.NextReviewAt = &next
+	}
+}
+
+func TestSM2Schedule(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		qualities []int
+		interval  int
+		ef        float64
+		reps      int
+		mastery   int
+		lapses    int
+	}{
+		{"首次正确 1 天", []int{4}, 1, 2.5, 1, 1, 0},
+		{"第二次正确 6 天", []int{4, 4}, 6, 2.5, 2, 2, 0},
+		{"之后按 EF 倍增", []int{4, 4, 4}, 15, 2.5, 3, 3, 0},
+		{"评分 5 提高 EF", []int{5, 5, 5}, 17, 2.8, 3, 3, 0},
+		{"评分 3 降低 EF", []int{3, 3, 3}, 12, 2.08, 3, 3, 0},
+		{"连续 5 次且评分 4 以上满级", []int{4, 4, 4, 4, 4}, 95, 2.5, 5, 5, 0},
+		{"连续 5 次但评分 3 最高 4 级", []int{4, 4, 4, 4, 3}, 90, 2.36, 5, 4, 0},
+		{"答错重置并降级", []int{4, 4, 4, 1}, 0, 1.96, 0, 2, 1},
+		{"新卡片答错不算遗忘", []int{0}, 0, 1.7, 0, 0, 0},
+		{"EF 最低 1.3", []int{0, 0, 0, 0, 0}, 0, 1.3, 0, 0, 0},
+		{"遗忘后重新从 1 天开始", []int{4, 4, 0, 4}, 1, 1.7, 1, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := NewCard()
+			at := now
+			for _, q := range tt.qualities {
+				SM2{}.Schedule(&card, q, at)
+				at = at.AddDate(0, 0, card.IntervalDays)
+			}
+			if card.IntervalDays != tt.interval || card.Repetitions != tt.reps || card.MasteryLevel != tt.mastery || card.Lapses != tt.lapses {
+				t.Errorf("interval/reps/mastery/lapses = %d/%d/%d/%d, want %d/%d/%d/%d",
+					card.IntervalDays, card.Repetitions, card.MasteryLevel, card.Lapses, tt.interval, tt.reps, tt.mastery, tt.lapses)
+			}
+			if math.Abs(card.EaseFactor-tt.ef) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", card.EaseFactor, tt.ef)
+			}
+		})
+	}
+}
+
+func TestSM2NextReviewAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	card := NewCard()
+	SM2{}.Schedule(&card, 1, now)
+	if want := now.Add(time.Minute); !card.NextReviewAt.Equal(want) || !card.LastReviewAt.Equal(now) {
+		t.Errorf("答错后 NextReviewAt = %v, want %v", card.NextReviewAt, want)
+	}
+	SM2{}.Schedule(&card, 4, now)
+	if want := now.AddDate(0, 0, 1); !card.NextReviewAt.Equal(want) {
+		t.Errorf("答对后 NextReviewAt = %v, want %v", card.NextReviewAt, want)
+	}
+
+	// 没有记录易度因子的旧卡片按默认值计算
+	card = Card{}
+	SM2{}.Schedule(&card, 5, now)
+	if card.EaseFactor != 2.6 {
+		t.Errorf("EaseFactor = %v, want 2.6", card.EaseFactor)
+	}
+}
+
+// TestSM2MatchesLegacy 随机评分序列下与替换前的实现结果一致（新增的遗忘次数除外）
+func TestSM2MatchesLegacy(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 500; i++ {
+		got, want := NewCard(), NewCard()
+		at := now
+		count := 1 + rng.Intn(15)
+		for n := 0; n < count; n++ {
+			q := rng.Intn(6)
+			SM2{}.Schedule(&got, q, at)
+			legacySM2(&want, q, at)
+			if got.EaseFactor != want.EaseFactor || got.IntervalDays != want.IntervalDays ||
+				got.Repetitions != want.Repetitions || got.MasteryLevel != want.MasteryLevel ||
+				!got.NextReviewAt.Equal(*want.NextReviewAt) {
+				t.Fatalf("序列 %d 第 %d 次（评分 %d）: got %+v, want %+v", i, n, q, got, want)
+			}
+			at = at.Add(time.Duration(1+rng.Intn(240)) * time.Hour)
+		}
+	}
+}
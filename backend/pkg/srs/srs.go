@@ -0,0 +1,105 @@
+// Package srs 间隔重复调度算法
+//
+// 定义统一的 Scheduler 接口，目前提供 SM-2 和 FSRS 两种实现。
+// 调度器只负责根据评分计算卡片的下一次记忆状态，不涉及数据库和统计，
+// 生词本、单词书等模块把各自的模型转换成 Card 后调用即可。
+package srs
+
+import (
+	"sort"
+	"time"
+)
+
+// 算法名称
+const (
+	AlgorithmSM2  = "sm2"
+	AlgorithmFSRS = "fsrs"
+)
+
+// Card 卡片的记忆状态
+// SM-2 使用 EaseFactor/Repetitions，FSRS 使用 Stability/Difficulty，两组字段互不干扰，
+// 切换算法时另一组字段保持原值。
+type Card struct {
+	EaseFactor   float64    // SM-2 易度因子
+	IntervalDays int        // 当前复习间隔（天），0 表示需要立即重新学习
+	Repetitions  int        // 连续正确次数
+	MasteryLevel int        // 掌握等级 0-5
+	Stability    float64    // FSRS 记忆稳定性（天），0 表示尚未按 FSRS 学习过
+	Difficulty   float64    // FSRS 难度 1-10
+	Lapses       int        // 遗忘次数
+	State        string     // 学习阶段 new/learning/review/relearning，为空时根据复习记录推断
+	Step         int        // 当前所处的学习/重学步骤（下标）
+	LastReviewAt *time.Time // 上次复习时间
+	NextReviewAt *time.Time // 下次复习时间
+}
+
+// Scheduler 间隔重复调度器
+type Scheduler interface {
+	// Name 算法名称
+	Name() string
+	// Schedule 根据评分（0-5）更新卡片状态，now 为本次复习时间
+	Schedule(card *Card, quality int, now time.Time)
+}
+
+// NewCard 创建一张新卡片（默认易度因子 2.5）
+func NewCard() Card {
+	return Card{EaseFactor: DefaultEaseFactor}
+}
+
+// New 根据算法名称创建调度器，未知算法回退为 SM-2
+func New(algorithm string, params FSRSParams) Scheduler {
+	if algorithm == AlgorithmFSRS {
+		return NewFSRS(params)
+	}
+	return SM2{}
+}
+
+// Review 一条历史复习记录
+type Review struct {
+	Quality int
+	At      time.Time
+}
+
+// Replay 按时间顺序重放复习历史，从新卡片开始计算出当前的记忆状态
+// 用于切换算法时迁移已有卡片
+func Replay(s Scheduler, reviews []Review) Card {
+	sorted := make([]Review, len(reviews))
+	copy(sorted, reviews)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].At.Before(sorted[j].At) })
+
+	card := NewCard()
+	for _, r := range sorted {
+		s.Schedule(&card, r.Quality, r.At)
+	}
+	return card
+}
+
+// IsCorrect 评分是否视为回答正确（与 SM-2 一致，3 分及以上为正确）
+func IsCorrect(quality int) bool {
+	return quality >= 3
+}
+
+func nextReviewAt(now time.Time, intervalDays int) *time.Time {
+	var next time.Time
+	if intervalDays == 0 {
+		// 立即复习（1分钟后），方便用户快速重新学习
+		next = now.Add(1 * time.Minute)
+	} else {
+		next = now.AddDate(0, 0, intervalDays)
+	}
+	return &next
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,139 @@
+package srs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 卡片学习阶段
+const (
+	StateNew        = "new"        // 未学习
+	StateLearning   = "learning"   // 新卡片学习中（按学习步骤短间隔重复）
+	StateReview     = "review"     // 已毕业，按长期调度复习
+	StateRelearning = "relearning" // 复习遗忘后重新学习中
+)
+
+// 默认学习/重学步骤：1分钟、10分钟、1天
+var (
+	DefaultLearningSteps   = []time.Duration{time.Minute, 10 * time.Minute, 24 * time.Hour}
+	DefaultRelearningSteps = []time.Duration{time.Minute, 10 * time.Minute, 24 * time.Hour}
+)
+
+// StateOf 卡片当前所处的学习阶段
+// 旧数据没有记录阶段时，复习过的卡片视为 review，否则为 new
+func StateOf(card Card) string {
+	if card.State != "" {
+		return card.State
+	}
+	if card.Repetitions > 0 || card.LastReviewAt != nil {
+		return StateReview
+	}
+	return StateNew
+}
+
+// StepScheduler 在长期调度算法之外加入日内学习步骤
+//   - 新卡片：按学习步骤短间隔重复，走完全部步骤（或评分为 5）后毕业，交给长期算法计算首个间隔
+//   - 复习卡片：交给长期算法；遗忘时记录遗忘并进入重学步骤
+//   - 重学卡片：按重学步骤重复，走完后回到复习阶段
+//
+// 学习步骤中的复习不参与长期算法计算，避免一次学习被重复计入间隔。
+type StepScheduler struct {
+	Scheduler
+	LearningSteps   []time.Duration
+	RelearningSteps []time.Duration
+}
+
+// WithSteps 为调度器加上学习/重学步骤
+func WithSteps(s Scheduler, learning, relearning []time.Duration) *StepScheduler {
+	return &StepScheduler{Scheduler: s, LearningSteps: learning, RelearningSteps: relearning}
+}
+
+// Schedule 根据卡片阶段安排下次复习
+func (s *StepScheduler) Schedule(card *Card, quality int, now time.Time) {
+	switch StateOf(*card) {
+	case StateNew, StateLearning:
+		s.step(card, quality, now, s.LearningSteps, StateLearning)
+	case StateRelearning:
+		s.step(card, quality, now, s.RelearningSteps, StateRelearning)
+	default:
+		s.Scheduler.Schedule(card, quality, now)
+		card.Step = 0
+		if !IsCorrect(quality) && len(s.RelearningSteps) > 0 {
+			card.State = StateRelearning
+			next := now.Add(s.RelearningSteps[0])
+			card.NextReviewAt = &next
+		} else {
+			card.State = StateReview
+		}
+	}
+}
+
+func (s *StepScheduler) step(card *Card, quality int, now time.Time, steps []time.Duration, state string) {
+	if IsCorrect(quality) {
+		card.Step++
+	} else {
+		card.Step = 0
+	}
+
+	// 评分为 5 或走完全部步骤：毕业，交给长期算法
+	if len(steps) == 0 || quality >= 5 || card.Step >= len(steps) {
+		s.Scheduler.Schedule(card, quality, now)
+		card.State = StateReview
+		card.Step = 0
+		return
+	}
+
+	card.State = state
+	card.LastReviewAt = &now
+	next := now.Add(steps[card.Step])
+	card.NextReviewAt = &next
+}
+
+// ParseSteps 解析步骤配置，如 "1m,10m,1d"（支持 s/m/h/d 单位）
+func ParseSteps(text string) ([]time.Duration, error) {
+	var steps []time.Duration
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if len(part) < 2 {
+			return nil, fmt.Errorf("无效的步骤: %s", part)
+		}
+		n, err := strconv.Atoi(part[:len(part)-1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("无效的步骤: %s", part)
+		}
+		var unit time.Duration
+		switch part[len(part)-1] {
+		case 's':
+			unit = time.Second
+		case 'm':
+			unit = time.Minute
+		case 'h':
+			unit = time.Hour
+		case 'd':
+			unit = 24 * time.Hour
+		default:
+			return nil, fmt.Errorf("无效的步骤单位: %s", part)
+		}
+		steps = append(steps, time.Duration(n)*unit)
+	}
+	return steps, nil
+}
+
+// FormatSteps 把步骤格式化为配置字符串
+func FormatSteps(steps []time.Duration) string {
+	parts := make([]string, 0, len(steps))
+	for _, d := range steps {
+		switch {
+		case d%(24*time.Hour) == 0:
+			parts = append(parts, fmt.Sprintf("%dd", d/(24*time.Hour)))
+		case d%time.Hour == 0:
+			parts = append(parts, fmt.Sprintf("%dh", d/time.Hour))
+		case d%time.Minute == 0:
+			parts = append(parts, fmt.Sprintf("%dm", d/time.Minute))
+		default:
+			parts = append(parts, fmt.Sprintf("%ds", d/time.Second))
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,102 @@
+package srs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStepScheduler(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	s := WithSteps(SM2{}, DefaultLearningSteps, DefaultRelearningSteps)
+
+	type want struct {
+		state    string
+		step     int
+		next     time.Duration // 距本次复习的时间
+		interval int
+		lapses   int
+	}
+	tests := []struct {
+		name      string
+		qualities []int
+		want      want
+	}{
+		{"新卡片答对进入第二步", []int{4}, want{StateLearning, 1, 10 * time.Minute, 0, 0}},
+		{"新卡片答错回到第一步", []int{4, 1}, want{StateLearning, 0, time.Minute, 0, 0}},
+		{"走完全部步骤毕业", []int{4, 4, 4}, want{StateReview, 0, 24 * time.Hour, 1, 0}},
+		{"评分 5 直接毕业", []int{5}, want{StateReview, 0, 24 * time.Hour, 1, 0}},
+		{"毕业后按长期算法", []int{5, 4}, want{StateReview, 0, 6 * 24 * time.Hour, 6, 0}},
+		{"复习遗忘进入重学", []int{5, 4, 1}, want{StateRelearning, 0, time.Minute, 0, 1}},
+		{"重学走完回到复习", []int{5, 4, 1, 4, 4, 4}, want{StateReview, 0, 24 * time.Hour, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := NewCard()
+			at := now
+			for _, q := range tt.qualities {
+				at = at.Add(time.Hour)
+				if card.NextReviewAt != nil && card.NextReviewAt.After(at) {
+					at = *card.NextReviewAt
+				}
+				s.Schedule(&card, q, at)
+			}
+			got := want{card.State, card.Step, card.NextReviewAt.Sub(at), card.IntervalDays, card.Lapses}
+			if got != tt.want {
+				t.Errorf("state/step/next/interval/lapses = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayWithSteps(t *testing.T) {
+	day := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	reviews := []Review{
+		{Quality: 4, At: day.Add(2 * time.Minute)}, // 乱序输入按时间排序
+		{Quality: 4, At: day},
+	}
+
+	// 带学习步骤重放：两次答对后仍在学习第三步，与实时复习的结果一致
+	s := WithSteps(SM2{}, DefaultLearningSteps, DefaultRelearningSteps)
+	card := Replay(s, reviews)
+	live := NewCard()
+	s.Schedule(&live, 4, day)
+	s.Schedule(&live, 4, day.Add(2*time.Minute))
+	if !reflect.DeepEqual(card, live) {
+		t.Errorf("Replay = %+v, 实时复习 = %+v", card, live)
+	}
+	if card.State != StateLearning || card.Step != 2 || card.Repetitions != 0 {
+		t.Errorf("state/step/reps = %s/%d/%d, want learning/2/0", card.State, card.Step, card.Repetitions)
+	}
+
+	// 不带学习步骤重放会直接安排长期间隔
+	if plain := Replay(SM2{}, reviews); plain.IntervalDays != 6 || StateOf(plain) != StateReview {
+		t.Errorf("不带步骤 interval/state = %d/%s, want 6/review", plain.IntervalDays, StateOf(plain))
+	}
+}
+
+func TestParseSteps(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{"1m,10m,1d", DefaultLearningSteps, false},
+		{" 30s 2h ", []time.Duration{30 * time.Second, 2 * time.Hour}, false},
+		{"", nil, false},
+		{"10", nil, true},
+		{"0m", nil, true},
+		{"5w", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseSteps(tt.text)
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSteps(%q) = %v, %v", tt.text, got, err)
+			}
+		})
+	}
+	if got := FormatSteps([]time.Duration{30 * time.Second, time.Minute, 2 * time.Hour, 48 * time.Hour}); got != "30s,1m,2h,2d" {
+		t.Errorf("FormatSteps = %q", got)
+	}
+}
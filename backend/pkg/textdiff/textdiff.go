@@ -0,0 +1,237 @@
+// Package textdiff 通用文本差异比较
+//
+// 基于最长公共子序列（LCS）对两个字符串序列做差异比较，可用于按行比较 Markdown、
+// 按句子对齐文章修订版本，以及按单词比较默写答案。纯 Go 实现，不依赖数据库。
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// OpType 差异操作类型
+type OpType string
+
+const (
+	OpEqual  OpType = "equal"  // 相同
+	OpInsert OpType = "insert" // 新增（只在 B 中）
+	OpDelete OpType = "delete" // 删除（只在 A 中）
+)
+
+// Op 一条差异操作
+// AIndex/BIndex 为元素在原序列中的下标，不存在时为 -1
+type Op struct {
+	Type   OpType `json:"type"`
+	Text   string `json:"text"`
+	AIndex int    `json:"a_index"`
+	BIndex int    `json:"b_index"`
+}
+
+// Diff 比较两个序列，返回把 a 变成 b 的操作列表
+// 同一位置既有删除又有新增时，删除排在新增之前
+func Diff(a, b []string) []Op {
+	return DiffFunc(a, b, func(x, y string) bool { return x == y })
+}
+
+// DiffFunc 使用自定义相等函数比较两个序列（如忽略大小写、标点）
+func DiffFunc(a, b []string, equal func(x, y string) bool) []Op {
+	n, m := len(a), len(b)
+
+	// 去掉公共前后缀，减小 LCS 表的规模
+	prefix := 0
+	for prefix < n && prefix < m && equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(a[n-1-suffix], b[m-1-suffix]) {
+		suffix++
+	}
+
+	ops := make([]Op, 0, n+m)
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, Op{Type: OpEqual, Text: b[i], AIndex: i, BIndex: i})
+	}
+
+	as, bs := a[prefix:n-suffix], b[prefix:m-suffix]
+	rows, cols := len(as), len(bs)
+
+	// lcs[i][j] = as[i:] 与 bs[j:] 的最长公共子序列长度
+	lcs := make([][]int, rows+1)
+	for i := range lcs {
+		lcs[i] = make([]int, cols+1)
+	}
+	for i := rows - 1; i >= 0; i-- {
+		for j := cols - 1; j >= 0; j-- {
+			if equal(as[i], bs[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < rows || j < cols {
+		switch {
+		case i < rows && j < cols && equal(as[i], bs[j]):
+			ops = append(ops, Op{Type: OpEqual, Text: bs[j], AIndex: prefix + i, BIndex: prefix + j})
+			i++
+			j++
+		case i < rows && (j >= cols || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, Op{Type: OpDelete, Text: as[i], AIndex: prefix + i, BIndex: -1})
+			i++
+		default:
+			ops = append(ops, Op{Type: OpInsert, Text: bs[j], AIndex: -1, BIndex: prefix + j})
+			j++
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		ops = append(ops, Op{Type: OpEqual, Text: b[m-suffix+k], AIndex: n - suffix + k, BIndex: m - suffix + k})
+	}
+	return ops
+}
+
+// Lines 按行切分文本（统一换行符，保留空行）
+func Lines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Words 按空白切分单词
+func Words(text string) []string {
+	return strings.Fields(text)
+}
+
+// NormalizeWord 单词归一化：转小写并去掉首尾标点（用于忽略大小写和标点的比较）
+func NormalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// Similarity 两段文本的单词级相似度（0-1）：2*LCS / (len(a)+len(b))，忽略大小写和标点
+func Similarity(a, b string) float64 {
+	wa, wb := Words(a), Words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	same := 0
+	for _, op := range DiffFunc(wa, wb, func(x, y string) bool { return NormalizeWord(x) == NormalizeWord(y) }) {
+		if op.Type == OpEqual {
+			same++
+		}
+	}
+	return 2 * float64(same) / float64(len(wa)+len(wb))
+}
+
+// Stats 差异统计
+type Stats struct {
+	Equal   int `json:"equal"`
+	Inserts int `json:"inserts"`
+	Deletes int `json:"deletes"`
+}
+
+// Count 统计操作列表中各类操作的数量
+func Count(ops []Op) Stats {
+	var s Stats
+	for _, op := range ops {
+		switch op.Type {
+		case OpEqual:
+			s.Equal++
+		case OpInsert:
+			s.Inserts++
+		case OpDelete:
+			s.Deletes++
+		}
+	}
+	return s
+}
+
+// Pair 两个序列元素之间的对齐关系
+// AIndex 或 BIndex 为 -1 表示该元素在另一序列中没有对应（纯删除或纯新增）
+type Pair struct {
+	AIndex     int     `json:"a_index"`
+	BIndex     int     `json:"b_index"`
+	Similarity float64 `json:"similarity"` // 1 表示完全相同
+}
+
+// Align 对齐两个文本序列（如修订前后的句子列表）
+// 先用 LCS 找出完全相同的元素，再把两段相同元素之间被删除和新增的元素按顺序配对，
+// 相似度不低于 threshold 的视为同一元素被修改，否则视为删除 + 新增。
+func Align(a, b []string, threshold float64) []Pair {
+	ops := Diff(a, b)
+	pairs := make([]Pair, 0, len(ops))
+
+	var deletes, inserts []int
+	flush := func() {
+		k := 0
+		for ; k < len(deletes) && k < len(inserts); k++ {
+			sim := Similarity(a[deletes[k]], b[inserts[k]])
+			if sim >= threshold {
+				pairs = append(pairs, Pair{AIndex: deletes[k], BIndex: inserts[k], Similarity: sim})
+			} else {
+				pairs = append(pairs, Pair{AIndex: deletes[k], BIndex: -1})
+				pairs = append(pairs, Pair{AIndex: -1, BIndex: inserts[k]})
+			}
+		}
+		for ; k < len(deletes); k++ {
+			pairs = append(pairs, Pair{AIndex: deletes[k], BIndex: -1})
+		}
+		for ; k < len(inserts); k++ {
+			pairs = append(pairs, Pair{AIndex: -1, BIndex: inserts[k]})
+		}
+		deletes, inserts = deletes[:0], inserts[:0]
+	}
+
+	for _, op := range ops {
+		switch op.Type {
+		case OpEqual:
+			flush()
+			pairs = append(pairs, Pair{AIndex: op.AIndex, BIndex: op.BIndex, Similarity: 1})
+		case OpDelete:
+			deletes = append(deletes, op.AIndex)
+		case OpInsert:
+			inserts = append(inserts, op.BIndex)
+		}
+	}
+	flush()
+	return pairs
+}
+
+// EditDistance 两个字符串的编辑距离（Levenshtein，按字符计算）
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minOf(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func minOf(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,125 @@
+package textdiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []Op
+	}{
+		{"都为空", nil, nil, []Op{}},
+		{"只有新增", nil, []string{"a"}, []Op{{OpInsert, "a", -1, 0}}},
+		{"只有删除", []string{"a"}, nil, []Op{{OpDelete, "a", 0, -1}}},
+		{"相同", []string{"a", "b"}, []string{"a", "b"}, []Op{{OpEqual, "a", 0, 0}, {OpEqual, "b", 1, 1}}},
+		// 同一位置既有删除又有新增时，删除在前
+		{"替换", []string{"x"}, []string{"y"}, []Op{{OpDelete, "x", 0, -1}, {OpInsert, "y", -1, 0}}},
+		{"公共前缀", []string{"a", "b", "c"}, []string{"a", "c", "d"}, []Op{
+			{OpEqual, "a", 0, 0}, {OpDelete, "b", 1, -1}, {OpEqual, "c", 2, 1}, {OpInsert, "d", -1, 2},
+		}},
+		{"公共后缀", []string{"a", "b"}, []string{"c", "b"}, []Op{
+			{OpDelete, "a", 0, -1}, {OpInsert, "c", -1, 0}, {OpEqual, "b", 1, 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFunc(t *testing.T) {
+	// 忽略大小写和标点时相同，输出 b 中的写法
+	ops := DiffFunc([]string{"Hello,", "World"}, []string{"hello", "world!"}, func(x, y string) bool {
+		return NormalizeWord(x) == NormalizeWord(y)
+	})
+	want := []Op{{OpEqual, "hello", 0, 0}, {OpEqual, "world!", 1, 1}}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("DiffFunc = %+v, want %+v", ops, want)
+	}
+	if s := Count(ops); s != (Stats{Equal: 2}) {
+		t.Errorf("Count = %+v", s)
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\r\nb\n", []string{"a", "b"}},
+		{"a\n\nb", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		if got := Lines(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"Hello, world!", "hello world", 1},
+		{"a b c d", "a b x y", 0.5},
+		{"one", "", 0},
+		{"the cat sat", "the cat", 0.8},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	a := []string{"The cat sat on the mat.", "It was sunny.", "Birds sang."}
+	b := []string{"The cat sat on the mat.", "It was very sunny.", "Nothing else happened today."}
+	got := Align(a, b, 0.5)
+	want := []Pair{
+		{AIndex: 0, BIndex: 0, Similarity: 1},
+		{AIndex: 1, BIndex: 1, Similarity: 6.0 / 7}, // 修改：3 个相同单词，共 7 个
+		{AIndex: 2, BIndex: -1},                     // 相似度太低：删除 + 新增
+		{AIndex: -1, BIndex: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Align = %+v, want %+v", got, want)
+	}
+
+	// 删除和新增数量不同时，多出的部分单独成对
+	got = Align([]string{"x", "y"}, []string{"z"}, 0)
+	want = []Pair{{AIndex: 0, BIndex: 0}, {AIndex: 1, BIndex: -1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Align = %+v, want %+v", got, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"house", "hause", 1},
+		{"café", "cafe", 1}, // 按字符而不是字节计算
+		{"flaw", "lawn", 2},
+	}
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
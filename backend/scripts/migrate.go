@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"log"
+	"strings"
 	"voicepaper/config"
 	"voicepaper/internal/model"
 	"voicepaper/internal/repository"
+	"voicepaper/internal/service"
 )
 
 func main() {
@@ -119,5 +121,462 @@ func main() {
 		log.Println("⏭️  vp_articles.original_article_url 字段已存在")
 	}
 
+	// 6. 创建 vp_series 表
+	if !db.Migrator().HasTable("vp_series") {
+		if err := db.Migrator().CreateTable(&model.Series{}); err != nil {
+			log.Fatalf("❌ 创建 vp_series 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_series 表")
+	} else {
+		log.Println("⏭️  vp_series 表已存在")
+	}
+
+	// 7. 创建 vp_series_episodes 表
+	if !db.Migrator().HasTable("vp_series_episodes") {
+		if err := db.Migrator().CreateTable(&model.SeriesEpisode{}); err != nil {
+			log.Fatalf("❌ 创建 vp_series_episodes 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_series_episodes 表")
+	} else {
+		log.Println("⏭️  vp_series_episodes 表已存在")
+	}
+
+	// 8. 创建 vp_series_completions 表
+	if !db.Migrator().HasTable("vp_series_completions") {
+		if err := db.Migrator().CreateTable(&model.SeriesCompletion{}); err != nil {
+			log.Fatalf("❌ 创建 vp_series_completions 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_series_completions 表")
+	} else {
+		log.Println("⏭️  vp_series_completions 表已存在")
+	}
+
+	// 9. 创建 vp_tags 表
+	if !db.Migrator().HasTable("vp_tags") {
+		if err := db.Migrator().CreateTable(&model.Tag{}); err != nil {
+			log.Fatalf("❌ 创建 vp_tags 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_tags 表")
+	} else {
+		log.Println("⏭️  vp_tags 表已存在")
+	}
+
+	// 10. 创建 vp_article_tags 表
+	if !db.Migrator().HasTable("vp_article_tags") {
+		if err := db.Migrator().CreateTable(&model.ArticleTag{}); err != nil {
+			log.Fatalf("❌ 创建 vp_article_tags 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_article_tags 表")
+	} else {
+		log.Println("⏭️  vp_article_tags 表已存在")
+	}
+
+	// 11. 创建 vp_user_tag_subscriptions 表
+	if !db.Migrator().HasTable("vp_user_tag_subscriptions") {
+		if err := db.Migrator().CreateTable(&model.UserTagSubscription{}); err != nil {
+			log.Fatalf("❌ 创建 vp_user_tag_subscriptions 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_user_tag_subscriptions 表")
+	} else {
+		log.Println("⏭️  vp_user_tag_subscriptions 表已存在")
+	}
+
+	// 12. 创建 vp_article_similarities 表
+	if !db.Migrator().HasTable("vp_article_similarities") {
+		if err := db.Migrator().CreateTable(&model.ArticleSimilarity{}); err != nil {
+			log.Fatalf("❌ 创建 vp_article_similarities 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_article_similarities 表")
+	} else {
+		log.Println("⏭️  vp_article_similarities 表已存在")
+	}
+
+	// 13. 创建 vp_article_revisions 表
+	if !db.Migrator().HasTable("vp_article_revisions") {
+		if err := db.Migrator().CreateTable(&model.ArticleRevision{}); err != nil {
+			log.Fatalf("❌ 创建 vp_article_revisions 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_article_revisions 表")
+	} else {
+		log.Println("⏭️  vp_article_revisions 表已存在")
+	}
+
+	// 14. 创建 vp_sentence_remaps 表
+	if !db.Migrator().HasTable("vp_sentence_remaps") {
+		if err := db.Migrator().CreateTable(&model.SentenceRemap{}); err != nil {
+			log.Fatalf("❌ 创建 vp_sentence_remaps 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_sentence_remaps 表")
+	} else {
+		log.Println("⏭️  vp_sentence_remaps 表已存在")
+	}
+
+	// 15. 为 vp_vocabulary 表添加 FSRS 记忆状态字段
+	if !db.Migrator().HasColumn("vp_vocabulary", "stability") {
+		if err := db.Exec(`
+			ALTER TABLE vp_vocabulary
+			ADD COLUMN stability DOUBLE NOT NULL DEFAULT 0 COMMENT 'FSRS 记忆稳定性（天）' AFTER last_review_at,
+			ADD COLUMN difficulty DOUBLE NOT NULL DEFAULT 0 COMMENT 'FSRS 难度 1-10' AFTER stability,
+			ADD COLUMN lapses INT NOT NULL DEFAULT 0 COMMENT '遗忘次数' AFTER difficulty;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_vocabulary FSRS 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_vocabulary.stability/difficulty/lapses 字段")
+	} else {
+		log.Println("⏭️  vp_vocabulary FSRS 字段已存在")
+	}
+
+	// 16. 创建 vp_user_srs_params 表
+	if !db.Migrator().HasTable("vp_user_srs_params") {
+		if err := db.Migrator().CreateTable(&model.UserSRSParams{}); err != nil {
+			log.Fatalf("❌ 创建 vp_user_srs_params 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_user_srs_params 表")
+	} else {
+		log.Println("⏭️  vp_user_srs_params 表已存在")
+	}
+
+	// 17. 为 vp_user_srs_params 表添加参数拟合字段
+	if !db.Migrator().HasColumn("vp_user_srs_params", "optimized_at") {
+		if err := db.Exec(`
+			ALTER TABLE vp_user_srs_params
+			ADD COLUMN optimized_at DATETIME DEFAULT NULL COMMENT '最近一次参数拟合时间' AFTER migrated_at,
+			ADD COLUMN optimize_report TEXT COMMENT '最近一次拟合报告（JSON）' AFTER optimized_at;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_user_srs_params 拟合字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_user_srs_params.optimized_at/optimize_report 字段")
+	} else {
+		log.Println("⏭️  vp_user_srs_params 拟合字段已存在")
+	}
+
+	// 18. 为 vp_vocabulary 表添加学习阶段字段，已复习过的卡片标记为 review
+	if !db.Migrator().HasColumn("vp_vocabulary", "srs_state") {
+		if err := db.Exec(`
+			ALTER TABLE vp_vocabulary
+			ADD COLUMN srs_state VARCHAR(20) NOT NULL DEFAULT 'new' COMMENT '学习阶段：new/learning/review/relearning' AFTER lapses,
+			ADD COLUMN learning_step INT NOT NULL DEFAULT 0 COMMENT '当前学习/重学步骤' AFTER srs_state,
+			ADD INDEX idx_vp_vocabulary_srs_state (srs_state);
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_vocabulary 学习阶段字段失败: %v", err)
+		}
+		if err := db.Exec("UPDATE vp_vocabulary SET srs_state = 'review' WHERE repetitions > 0 OR last_review_at IS NOT NULL").Error; err != nil {
+			log.Fatalf("❌ 初始化 vp_vocabulary.srs_state 失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_vocabulary.srs_state/learning_step 字段")
+	} else {
+		log.Println("⏭️  vp_vocabulary 学习阶段字段已存在")
+	}
+
+	// 19. 为 vp_user_srs_params 表添加每日上限和学习步骤字段
+	if !db.Migrator().HasColumn("vp_user_srs_params", "new_cards_per_day") {
+		if err := db.Exec(`
+			ALTER TABLE vp_user_srs_params
+			ADD COLUMN new_cards_per_day INT NOT NULL DEFAULT 20 COMMENT '每日新卡片上限' AFTER migrated_at,
+			ADD COLUMN reviews_per_day INT NOT NULL DEFAULT 200 COMMENT '每日复习上限' AFTER new_cards_per_day,
+			ADD COLUMN learning_steps VARCHAR(100) NOT NULL DEFAULT '1m,10m,1d' COMMENT '学习步骤' AFTER reviews_per_day,
+			ADD COLUMN relearning_steps VARCHAR(100) NOT NULL DEFAULT '1m,10m,1d' COMMENT '重学步骤' AFTER learning_steps;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_user_srs_params 会话字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_user_srs_params.new_cards_per_day/reviews_per_day/learning_steps/relearning_steps 字段")
+	} else {
+		log.Println("⏭️  vp_user_srs_params 会话字段已存在")
+	}
+
+	// 20. 创建 vp_review_sessions 表
+	if !db.Migrator().HasTable("vp_review_sessions") {
+		if err := db.Migrator().CreateTable(&model.ReviewSession{}); err != nil {
+			log.Fatalf("❌ 创建 vp_review_sessions 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_review_sessions 表")
+	} else {
+		log.Println("⏭️  vp_review_sessions 表已存在")
+	}
+
+	// 21. 扩展 vp_vocabulary_reviews.review_type 枚举（完形填空、听音选义）
+	var reviewTypeColumn string
+	if err := db.Raw(`
+		SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'vp_vocabulary_reviews' AND COLUMN_NAME = 'review_type'
+	`).Scan(&reviewTypeColumn).Error; err != nil {
+		log.Fatalf("❌ 查询 vp_vocabulary_reviews.review_type 类型失败: %v", err)
+	}
+	if !strings.Contains(reviewTypeColumn, "'cloze'") || !strings.Contains(reviewTypeColumn, "'listening'") {
+		if err := db.Exec(`
+			ALTER TABLE vp_vocabulary_reviews
+			MODIFY COLUMN review_type ENUM('card','spell','choice','dictation','cloze','listening') NOT NULL DEFAULT 'card' COMMENT '复习方式';
+		`).Error; err != nil {
+			log.Fatalf("❌ 修改 vp_vocabulary_reviews.review_type 枚举失败: %v", err)
+		}
+		log.Println("✅ vp_vocabulary_reviews.review_type 枚举已更新")
+	} else {
+		log.Println("⏭️  vp_vocabulary_reviews.review_type 枚举已包含 cloze、listening")
+	}
+
+	// 22. 创建 vp_review_exercises 表
+	if !db.Migrator().HasTable("vp_review_exercises") {
+		if err := db.Migrator().CreateTable(&model.ReviewExercise{}); err != nil {
+			log.Fatalf("❌ 创建 vp_review_exercises 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_review_exercises 表")
+	} else {
+		log.Println("⏭️  vp_review_exercises 表已存在")
+	}
+
+	// 23. 创建 vp_mistakes 表
+	if !db.Migrator().HasTable("vp_mistakes") {
+		if err := db.Migrator().CreateTable(&model.Mistake{}); err != nil {
+			log.Fatalf("❌ 创建 vp_mistakes 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_mistakes 表")
+	} else {
+		log.Println("⏭️  vp_mistakes 表已存在")
+	}
+
+	// 24. 为 vp_vocabulary 表添加 lemma 字段（单词原形，用于去重），并为已有单词回填
+	if !db.Migrator().HasColumn("vp_vocabulary", "lemma") {
+		if err := db.Exec(`
+			ALTER TABLE vp_vocabulary
+			ADD COLUMN lemma VARCHAR(100) NOT NULL DEFAULT '' COMMENT '单词原形（用于去重）' AFTER content,
+			ADD INDEX idx_vp_vocabulary_user_lemma (user_id, lemma);
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_vocabulary.lemma 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_vocabulary.lemma 字段")
+	} else {
+		log.Println("⏭️  vp_vocabulary.lemma 字段已存在")
+	}
+	if n, err := service.BackfillVocabularyLemmas(db); err != nil {
+		log.Fatalf("❌ 回填 vp_vocabulary.lemma 失败: %v", err)
+	} else {
+		log.Printf("✅ vp_vocabulary.lemma 回填完成: %d 条", n)
+	}
+
+	// 25. 为 vp_vocabulary_folders 表添加 filter 字段（智能文件夹的筛选条件）
+	if !db.Migrator().HasColumn("vp_vocabulary_folders", "filter") {
+		if err := db.Exec(`
+			ALTER TABLE vp_vocabulary_folders
+			ADD COLUMN filter TEXT NULL COMMENT '智能文件夹筛选条件（JSON），为空表示普通文件夹' AFTER sort_order;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_vocabulary_folders.filter 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_vocabulary_folders.filter 字段")
+	} else {
+		log.Println("⏭️  vp_vocabulary_folders.filter 字段已存在")
+	}
+
+	// 26. 创建 vp_wordbook_cards 表（单词书间隔重复卡片），为 vp_wordbook_user_order 添加学习计划字段
+	if !db.Migrator().HasTable("vp_wordbook_cards") {
+		if err := db.Migrator().CreateTable(&model.WordbookCard{}); err != nil {
+			log.Fatalf("❌ 创建 vp_wordbook_cards 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_wordbook_cards 表")
+	} else {
+		log.Println("⏭️  vp_wordbook_cards 表已存在")
+	}
+	if !db.Migrator().HasColumn("vp_wordbook_user_order", "target_date") {
+		if err := db.Exec(`
+			ALTER TABLE vp_wordbook_user_order
+			ADD COLUMN target_date DATE NULL COMMENT '计划背完日期' AFTER total_words,
+			ADD COLUMN new_per_day INT NOT NULL DEFAULT 0 COMMENT '每日新词数，0 表示使用复习设置' AFTER target_date;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_wordbook_user_order 学习计划字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_wordbook_user_order 学习计划字段")
+	} else {
+		log.Println("⏭️  vp_wordbook_user_order 学习计划字段已存在")
+	}
+
+	// 27. 为 vp_wordbook_user_order 表添加 seed、version 字段（按种子计算的学习序列，旧序列在读取时迁移）
+	if !db.Migrator().HasColumn("vp_wordbook_user_order", "seed") {
+		if err := db.Exec(`
+			ALTER TABLE vp_wordbook_user_order
+			ADD COLUMN seed BIGINT NOT NULL DEFAULT 0 COMMENT '乱序排列的种子，顺序模式为 0' AFTER is_random,
+			ADD COLUMN version INT NOT NULL DEFAULT 0 COMMENT '序列格式版本：0 旧版 JSON 序列，1 按种子计算' AFTER seed;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_wordbook_user_order.seed 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_wordbook_user_order.seed、version 字段")
+	} else {
+		log.Println("⏭️  vp_wordbook_user_order.seed 字段已存在")
+	}
+
+	// 28. 为 vp_wordbook_info 表添加 owner_id、visibility、share_token 字段（用户自建单词书）
+	if !db.Migrator().HasColumn("vp_wordbook_info", "owner_id") {
+		if err := db.Exec(`
+			ALTER TABLE vp_wordbook_info
+			ADD COLUMN owner_id BIGINT UNSIGNED NULL COMMENT '创建者，系统单词书为 NULL' AFTER name,
+			ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public' COMMENT '可见范围：private/link/public' AFTER owner_id,
+			ADD COLUMN share_token VARCHAR(32) NOT NULL DEFAULT '' COMMENT '分享链接令牌' AFTER visibility,
+			ADD INDEX idx_vp_wordbook_info_owner_id (owner_id),
+			ADD INDEX idx_vp_wordbook_info_share_token (share_token);
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_wordbook_info 自建单词书字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_wordbook_info.owner_id、visibility、share_token 字段")
+	} else {
+		log.Println("⏭️  vp_wordbook_info.owner_id 字段已存在")
+	}
+
+	// 29. 创建 vp_pronunciations 表（TTS 生成的单词、例句发音缓存）
+	if !db.Migrator().HasTable("vp_pronunciations") {
+		if err := db.Migrator().CreateTable(&model.Pronunciation{}); err != nil {
+			log.Fatalf("❌ 创建 vp_pronunciations 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_pronunciations 表")
+	} else {
+		log.Println("⏭️  vp_pronunciations 表已存在")
+	}
+
+	// 30. 创建 vp_user_goals 表（学习目标）
+	if !db.Migrator().HasTable("vp_user_goals") {
+		if err := db.Migrator().CreateTable(&model.UserGoal{}); err != nil {
+			log.Fatalf("❌ 创建 vp_user_goals 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_user_goals 表")
+	} else {
+		log.Println("⏭️  vp_user_goals 表已存在")
+	}
+
+	// 31. 为 vp_user_points 表添加 streak_freezes 字段，创建 vp_user_streak_protections 表（连续学习冻结卡和修复）
+	if !db.Migrator().HasColumn("vp_user_points", "streak_freezes") {
+		if err := db.Exec(`
+			ALTER TABLE vp_user_points
+			ADD COLUMN streak_freezes INT NOT NULL DEFAULT 0 COMMENT '拥有的连续学习冻结卡数量' AFTER makeup_cards;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_user_points.streak_freezes 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_user_points.streak_freezes 字段")
+	} else {
+		log.Println("⏭️  vp_user_points.streak_freezes 字段已存在")
+	}
+	if !db.Migrator().HasTable("vp_user_streak_protections") {
+		if err := db.Migrator().CreateTable(&model.StreakProtection{}); err != nil {
+			log.Fatalf("❌ 创建 vp_user_streak_protections 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_user_streak_protections 表")
+	} else {
+		log.Println("⏭️  vp_user_streak_protections 表已存在")
+	}
+
+	// 32. 为 vp_users 表添加 timezone 字段（用户时区，决定签到、每日统计、连续学习等按哪天计算）
+	if !db.Migrator().HasColumn("vp_users", "timezone") {
+		if err := db.Exec(`
+			ALTER TABLE vp_users
+			ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '' COMMENT '用户时区（IANA 名称），为空时使用服务器时区' AFTER bio;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_users.timezone 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_users.timezone 字段")
+	} else {
+		log.Println("⏭️  vp_users.timezone 字段已存在")
+	}
+
+	// 33. 成就引擎：vp_user_points 添加复习次数和连续默写全对字段，vp_title_configs 添加组合条件字段，创建 vp_user_achievement_counters 表
+	if !db.Migrator().HasColumn("vp_user_points", "total_reviews") {
+		if err := db.Exec(`
+			ALTER TABLE vp_user_points
+			ADD COLUMN total_reviews INT NOT NULL DEFAULT 0 COMMENT '累计复习次数' AFTER total_duration_minutes,
+			ADD COLUMN perfect_streak INT NOT NULL DEFAULT 0 COMMENT '当前连续默写全对次数' AFTER total_reviews,
+			ADD COLUMN max_perfect_streak INT NOT NULL DEFAULT 0 COMMENT '最大连续默写全对次数' AFTER perfect_streak;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_user_points 成就字段失败: %v", err)
+		}
+		if err := db.Exec(`
+			UPDATE vp_user_points p
+			JOIN (SELECT user_id, COUNT(*) AS total FROM vp_vocabulary_reviews GROUP BY user_id) r ON r.user_id = p.user_id
+			SET p.total_reviews = r.total;
+		`).Error; err != nil {
+			log.Fatalf("❌ 初始化 vp_user_points.total_reviews 失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_user_points.total_reviews/perfect_streak/max_perfect_streak 字段")
+	} else {
+		log.Println("⏭️  vp_user_points 成就字段已存在")
+	}
+	if !db.Migrator().HasColumn("vp_title_configs", "condition_rule") {
+		if err := db.Exec(`
+			ALTER TABLE vp_title_configs
+			ADD COLUMN condition_rule TEXT NULL COMMENT '组合条件（JSON），condition_type 为 compound 时使用' AFTER condition_description;
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_title_configs.condition_rule 字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_title_configs.condition_rule 字段")
+	} else {
+		log.Println("⏭️  vp_title_configs.condition_rule 字段已存在")
+	}
+	if !db.Migrator().HasTable("vp_user_achievement_counters") {
+		if err := db.Migrator().CreateTable(&model.UserAchievementCounter{}); err != nil {
+			log.Fatalf("❌ 创建 vp_user_achievement_counters 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_user_achievement_counters 表")
+	} else {
+		log.Println("⏭️  vp_user_achievement_counters 表已存在")
+	}
+
+	// 34. 称号系列、限时称号、隐藏称号和解锁奖励：vp_title_configs 添加相关字段
+	if !db.Migrator().HasColumn("vp_title_configs", "family_key") {
+		if err := db.Exec(`
+			ALTER TABLE vp_title_configs
+			ADD COLUMN is_hidden TINYINT(1) NOT NULL DEFAULT 0 COMMENT '隐藏称号：获得前不显示名称、描述和条件' AFTER is_active,
+			ADD COLUMN family_key VARCHAR(50) NULL COMMENT '称号系列' AFTER is_hidden,
+			ADD COLUMN tier VARCHAR(20) NULL COMMENT '等级：bronze/silver/gold' AFTER family_key,
+			ADD COLUMN start_at DATETIME(3) NULL COMMENT '限时称号开始时间' AFTER tier,
+			ADD COLUMN end_at DATETIME(3) NULL COMMENT '限时称号结束时间' AFTER start_at,
+			ADD COLUMN reward_points INT NOT NULL DEFAULT 0 COMMENT '解锁奖励积分' AFTER end_at,
+			ADD COLUMN reward_makeup_cards INT NOT NULL DEFAULT 0 COMMENT '解锁奖励补签卡' AFTER reward_points,
+			ADD COLUMN reward_streak_freezes INT NOT NULL DEFAULT 0 COMMENT '解锁奖励冻结卡' AFTER reward_makeup_cards,
+			ADD INDEX idx_vp_title_configs_family_key (family_key);
+		`).Error; err != nil {
+			log.Fatalf("❌ 添加 vp_title_configs 系列/限时/奖励字段失败: %v", err)
+		}
+		log.Println("✅ 成功添加 vp_title_configs 系列/限时/隐藏/奖励字段")
+	} else {
+		log.Println("⏭️  vp_title_configs 系列/限时/奖励字段已存在")
+	}
+
+	// 35. 创建 vp_notifications 表（站内通知），清理软删除的标签并删除 vp_tags.deleted_at 字段（标签改为物理删除，释放被占用的 slug）
+	if !db.Migrator().HasTable("vp_notifications") {
+		if err := db.Migrator().CreateTable(&model.Notification{}); err != nil {
+			log.Fatalf("❌ 创建 vp_notifications 表失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_notifications 表")
+	} else {
+		log.Println("⏭️  vp_notifications 表已存在")
+	}
+	if db.Migrator().HasColumn("vp_tags", "deleted_at") {
+		result := db.Exec("DELETE FROM vp_tags WHERE deleted_at IS NOT NULL")
+		if result.Error != nil {
+			log.Fatalf("❌ 清理已删除的标签失败: %v", result.Error)
+		}
+		log.Printf("✅ 清理了 %d 个已删除的标签", result.RowsAffected)
+		// 删除列时 MySQL 会一并删除只包含该列的索引
+		if err := db.Exec("ALTER TABLE vp_tags DROP COLUMN deleted_at").Error; err != nil {
+			log.Fatalf("❌ 删除 vp_tags.deleted_at 字段失败: %v", err)
+		}
+		log.Println("✅ 成功删除 vp_tags.deleted_at 字段")
+	} else {
+		log.Println("⏭️  vp_tags.deleted_at 字段已删除")
+	}
+
+	// 36. 为 vp_wordbook_cards 添加按学习时间排序的索引（已学单词按第一次学习的时间分页）
+	// 未学单词按种子在查询时排列，删除曾经用来保存其位置的 vp_wordbook_user_ranks 表
+	if db.Migrator().HasTable("vp_wordbook_user_ranks") {
+		if err := db.Migrator().DropTable("vp_wordbook_user_ranks"); err != nil {
+			log.Fatalf("❌ 删除 vp_wordbook_user_ranks 表失败: %v", err)
+		}
+		log.Println("✅ 成功删除 vp_wordbook_user_ranks 表")
+	} else {
+		log.Println("⏭️  vp_wordbook_user_ranks 表不存在")
+	}
+	if !db.Migrator().HasIndex(&model.WordbookCard{}, "idx_user_book_created") {
+		if err := db.Migrator().CreateIndex(&model.WordbookCard{}, "idx_user_book_created"); err != nil {
+			log.Fatalf("❌ 创建 vp_wordbook_cards.idx_user_book_created 索引失败: %v", err)
+		}
+		log.Println("✅ 成功创建 vp_wordbook_cards.idx_user_book_created 索引")
+	} else {
+		log.Println("⏭️  vp_wordbook_cards.idx_user_book_created 索引已存在")
+	}
+
 	fmt.Println("\n✅ 所有迁移任务完成！")
 }
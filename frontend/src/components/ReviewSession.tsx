@@ -474,7 +474,8 @@ export const ReviewSession: React.FC<ReviewSessionProps> = ({ onClose, onComplet
         // 5. 后台静默提交
         try {
             const response = await submitVocabularyReview(vocabToSubmit.id, {
-                review_type: mode,
+                // 自评只能按卡片提交，拼写等服务端判分的题型走练习接口
+                review_type: 'card',
                 quality: score,
                 response_time_ms: responseTime,
                 user_answer: userAnswer,
@@ -644,7 +644,7 @@ export const addVocabulary = async (data: {
     note?: string;
     morphemes?: string; // 词根词缀分析结果，JSON格式
     source?: 'article' | 'manual'; // 来源：article=从文章添加，manual=手动添加（默认）
-}): Promise<{ message: string; data: Vocabulary }> => {
+}): Promise<{ message: string; data: Vocabulary; related?: Vocabulary; hint?: string }> => {
     const response = await api.post('/vocabulary', data);
     return response.data;
 };
@@ -719,7 +719,7 @@ export const getTodayReviewList = async (limit?: number): Promise<{ data: Vocabu
 // 提交复习结果
 export const submitVocabularyReview = async (id: number, data: {
     quality: number;  // 0-5
-    review_type?: 'card' | 'spell' | 'choice' | 'dictation';
+    review_type?: 'card'; // 自评只能为卡片，其他题型通过练习接口由服务端判分
     response_time_ms?: number;
     user_answer?: string;
 }): Promise<{ message: string; data: Vocabulary; points_earned: number; total_points: number }> => {